@@ -1,12 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 	"testing"
 
 	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/chainexport"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
 )
@@ -59,4 +62,66 @@ func TestDBRestore(t *testing.T) {
 	d2, err := ioutil.ReadFile(dumpPath)
 	require.NoError(t, err)
 	require.Equal(t, d1, d2, "dumps differ")
+
+	t.Run("v2", func(t *testing.T) {
+		dumpV2Path := path.Join(tmpDir, "testdump2.acc")
+		e.Run(t, "neo-go", "db", "dump", "--unittest",
+			"--config-path", tmpDir, "--out", dumpV2Path, "--v2")
+
+		e.Run(t, "neo-go", "db", "verify", "--unittest",
+			"--config-path", tmpDir, "--in", dumpV2Path)
+		e.checkNextLine(t, "^Dump version 2: 51 blocks starting from 0 are OK$")
+		e.Run(t, "neo-go", "db", "verify", "--unittest",
+			"--config-path", tmpDir, "--in", dumpV2Path, "--start", "20", "--count", "10")
+		e.checkNextLine(t, "^Dump version 2: 10 blocks starting from 20 are OK$")
+		e.RunWithError(t, "neo-go", "db", "verify", "--unittest",
+			"--config-path", tmpDir, "--in", dumpV2Path, "--start", "50", "--count", "10")
+		e.Run(t, "neo-go", "db", "verify", "--unittest",
+			"--config-path", tmpDir, "--in", inDump)
+		e.checkNextLine(t, "^Dump version 1: 51 blocks starting from 0 are OK$")
+
+		// Everything is already restored.
+		e.Run(t, "neo-go", "db", "restore", "--unittest",
+			"--config-path", tmpDir, "--in", dumpV2Path)
+
+		d, err := ioutil.ReadFile(dumpV2Path)
+		require.NoError(t, err)
+		d[len(d)/2] ^= 0xff
+		require.NoError(t, ioutil.WriteFile(dumpV2Path, d, os.ModePerm))
+		e.RunWithError(t, "neo-go", "db", "verify", "--unittest",
+			"--config-path", tmpDir, "--in", dumpV2Path)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		exportDir := path.Join(tmpDir, "export")
+		e.RunWithError(t, "neo-go", "db", "export", "--unittest",
+			"--config-path", tmpDir)
+		e.RunWithError(t, "neo-go", "db", "export", "--unittest",
+			"--config-path", tmpDir, "--out", exportDir, "--format", "xml")
+		e.RunWithError(t, "neo-go", "db", "export", "--unittest",
+			"--config-path", tmpDir, "--out", exportDir, "--count", "1000")
+
+		e.Run(t, "neo-go", "db", "export", "--unittest",
+			"--config-path", tmpDir, "--out", exportDir)
+		readLines := func(t *testing.T, name string) []string {
+			data, err := ioutil.ReadFile(path.Join(exportDir, name))
+			require.NoError(t, err)
+			return strings.Split(strings.TrimSpace(string(data)), "\n")
+		}
+		var txCount int
+		blocks := readLines(t, "blocks.ndjson")
+		for i, l := range blocks {
+			b := new(chainexport.Block)
+			require.NoError(t, json.Unmarshal([]byte(l), b))
+			require.Equal(t, uint32(i), b.Index)
+			txCount += b.TxCount
+		}
+		require.Equal(t, txCount, len(readLines(t, "transactions.ndjson")))
+		require.True(t, len(readLines(t, "transfers.ndjson")) > 0)
+
+		e.Run(t, "neo-go", "db", "export", "--unittest",
+			"--config-path", tmpDir, "--out", exportDir, "--format", "csv",
+			"--start", "1", "--count", "10")
+		require.Equal(t, 11, len(readLines(t, "blocks.csv")))
+	})
 }
@@ -71,7 +71,7 @@ func newTestChain(t *testing.T, f func(*config.Config)) (*core.Blockchain, *serv
 
 	memoryStore := storage.NewMemoryStore()
 	logger := zaptest.NewLogger(t)
-	chain, err := core.NewBlockchain(memoryStore, cfg.ProtocolConfiguration, logger)
+	chain, err := core.NewBlockchain(memoryStore, cfg.ProtocolConfiguration, cfg.ApplicationConfiguration, logger)
 	require.NoError(t, err, "could not create chain")
 
 	go chain.Run()
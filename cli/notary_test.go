@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/hex"
+	"math"
+	"math/big"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/cli/paramcontext"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/noderoles"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	notaryWallet = "../pkg/services/notary/testdata/notary1.json"
+	notaryPass   = "one"
+)
+
+func TestNotary(t *testing.T) {
+	e := newExecutorWithConfig(t, true, func(cfg *config.Config) {
+		cfg.ProtocolConfiguration.P2PNotaryRequestPayloadPoolSize = 100
+		cfg.ApplicationConfiguration.P2PNotary = config.P2PNotary{
+			Enabled: true,
+			UnlockWallet: config.Wallet{
+				Path:     notaryWallet,
+				Password: notaryPass,
+			},
+		}
+	})
+
+	w, err := wallet.NewWalletFromFile(notaryWallet)
+	require.NoError(t, err)
+	require.NoError(t, w.Accounts[0].Decrypt(notaryPass, w.Scrypt))
+	designateHash, err := e.Chain.GetNativeContractScriptHash(nativenames.Designation)
+	require.NoError(t, err)
+	e.In.WriteString("one\r")
+	e.Run(t, "neo-go", "contract", "invokefunction",
+		"--rpc-endpoint", "http://"+e.RPC.Addr,
+		"--wallet", validatorWallet, "--address", validatorAddr,
+		designateHash.StringLE(), "designateAsRole",
+		"int:"+strconv.Itoa(int(noderoles.P2PNotary)),
+		"[", "bytes:"+hex.EncodeToString(w.Accounts[0].PrivateKey().PublicKey().Bytes()), "]")
+	e.checkTxPersisted(t, "Sent invocation transaction ")
+
+	// validatorAddr is 1-out-of-1 multisignature account, so notary
+	// requests are signed by the standard account of the same key.
+	keyHash := validatorPriv.GetScriptHash()
+	keyAddr := validatorPriv.Address()
+
+	t.Run("deposit", func(t *testing.T) {
+		cmd := []string{"neo-go", "wallet", "notary", "deposit",
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--wallet", validatorWallet}
+		t.Run("missing address", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--amount", "1", "--till", "1000")...)
+		})
+		t.Run("missing till", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--address", validatorAddr, "--amount", "1")...)
+		})
+		t.Run("missing amount", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--address", validatorAddr, "--till", "1000")...)
+		})
+		t.Run("bad till", func(t *testing.T) {
+			e.In.WriteString("one\r")
+			e.RunWithError(t, append(cmd, "--address", validatorAddr, "--amount", "1", "--till", "0")...)
+		})
+
+		till := e.Chain.BlockHeight() + 1000
+		e.In.WriteString("one\r")
+		e.Run(t, append(cmd, "--address", validatorAddr, "--amount", "1",
+			"--till", strconv.Itoa(int(till)))...)
+		e.checkTxPersisted(t)
+		require.Equal(t, big.NewInt(1_0000_0000), e.Chain.GetNotaryBalance(validatorHash))
+		require.Equal(t, till, e.Chain.GetNotaryDepositExpiration(validatorHash))
+
+		e.In.WriteString("one\r")
+		e.Run(t, append(cmd, "--address", validatorAddr, "--to", keyAddr,
+			"--amount", "2", "--till", strconv.Itoa(int(till)))...)
+		e.checkTxPersisted(t)
+		require.Equal(t, big.NewInt(2_0000_0000), e.Chain.GetNotaryBalance(keyHash))
+	})
+
+	tmpDir := os.TempDir()
+	txPath := path.Join(tmpDir, "notary_tx.json")
+	mainPath := path.Join(tmpDir, "notary_main.json")
+	t.Cleanup(func() {
+		os.Remove(txPath)
+		os.Remove(mainPath)
+	})
+
+	priv, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	e.In.WriteString("one\r")
+	e.Run(t, "neo-go", "wallet", "nep17", "transfer",
+		"--rpc-endpoint", "http://"+e.RPC.Addr,
+		"--wallet", validatorWallet, "--from", validatorAddr,
+		"--to", priv.Address(), "--token", "NEO", "--amount", "1",
+		"--out", txPath)
+
+	var mainHash util.Uint256
+	t.Run("create", func(t *testing.T) {
+		cmd := []string{"neo-go", "wallet", "notary", "create",
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--wallet", validatorWallet}
+		t.Run("missing output", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--in", txPath)...)
+		})
+		t.Run("missing input", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--out", mainPath)...)
+		})
+
+		e.Run(t, append(cmd, "--in", txPath, "--out", mainPath)...)
+		line := e.getNextLine(t)
+		mainHash, err = util.Uint256DecodeStringLE(line)
+		require.NoError(t, err)
+
+		pc, err := paramcontext.Read(mainPath)
+		require.NoError(t, err)
+		tx := pc.Verifiable.(*transaction.Transaction)
+		require.Equal(t, mainHash, tx.Hash())
+		require.Equal(t, 2, len(tx.Signers))
+		require.Equal(t, validatorHash, tx.Signers[0].Account)
+		require.Equal(t, transaction.Signer{
+			Account: e.Chain.GetNotaryContractScriptHash(),
+			Scopes:  transaction.None,
+		}, tx.Signers[1])
+		attrs := tx.GetAttributes(transaction.NotaryAssistedT)
+		require.Equal(t, 1, len(attrs))
+		require.Equal(t, uint8(1), attrs[0].Value.(*transaction.NotaryAssisted).NKeys)
+		require.NotNil(t, pc.Items[validatorHash])
+
+		t.Run("already notary-assisted", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--in", mainPath, "--out", mainPath)...)
+		})
+	})
+
+	t.Run("sign", func(t *testing.T) {
+		cmd := []string{"neo-go", "wallet", "notary", "sign",
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--wallet", validatorWallet}
+		t.Run("missing address", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--in", mainPath)...)
+		})
+		t.Run("not a signer", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--in", mainPath, "--address", keyAddr)...)
+		})
+		t.Run("not notary-assisted", func(t *testing.T) {
+			e.RunWithError(t, append(cmd, "--in", txPath, "--address", validatorAddr)...)
+		})
+
+		e.In.WriteString("one\r")
+		e.Run(t, append(cmd, "--in", mainPath, "--address", validatorAddr)...)
+		e.checkNextLine(t, "^Main transaction: "+mainHash.StringLE()+"$")
+		line := e.getNextLine(t)
+		require.Regexp(t, "^Fallback transaction: [0-9a-f]{64}$", line)
+
+		// Notary service completes the main transaction, so it's accepted
+		// with the network fee calculated by 'notary create'.
+		require.Eventually(t, func() bool {
+			_, height, err := e.Chain.GetTransaction(mainHash)
+			return err == nil && height != math.MaxUint32
+		}, 5*time.Second, 100*time.Millisecond, "main transaction wasn't persisted")
+		aer, err := e.Chain.GetAppExecResults(mainHash, trigger.Application)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(aer))
+		require.Equal(t, vm.HaltState, aer[0].VMState)
+		b, _ := e.Chain.GetGoverningTokenBalance(priv.GetScriptHash())
+		require.Equal(t, big.NewInt(1), b)
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeers(t *testing.T) {
+	e := newExecutorWithConfig(t, true, func(cfg *config.Config) {
+		cfg.ApplicationConfiguration.RPC.EnablePeerManagement = true
+		cfg.ApplicationConfiguration.RPC.AdminConfig.Enabled = true
+	})
+	rpcArgs := []string{"--rpc-endpoint", "http://" + e.RPC.AdminAddress()}
+
+	t.Run("public endpoint", func(t *testing.T) {
+		e.RunWithError(t, "neo-go", "peers", "ban", "--rpc-endpoint", "http://"+e.RPC.Addr, "1.2.3.4")
+	})
+
+	t.Run("invalid args", func(t *testing.T) {
+		e.RunWithError(t, append([]string{"neo-go", "peers", "ban"}, rpcArgs...)...)
+		e.RunWithError(t, "neo-go", "peers", "ban", "1.2.3.4")
+		e.RunWithError(t, append([]string{"neo-go", "peers", "ban"}, append(rpcArgs, "notanip")...)...)
+		e.RunWithError(t, append([]string{"neo-go", "peers", "unban"}, rpcArgs...)...)
+	})
+
+	e.Run(t, append([]string{"neo-go", "peers", "ban", "--duration", "1h"}, append(rpcArgs, "1.2.3.4")...)...)
+	e.checkNextLine(t, "1.2.3.4 is banned")
+
+	e.Run(t, append([]string{"neo-go", "peers", "list"}, rpcArgs...)...)
+	require.True(t, strings.Contains(e.Out.String(), "banned 1.2.3.4 until "))
+
+	e.Run(t, append([]string{"neo-go", "peers", "unban"}, append(rpcArgs, "1.2.3.4")...)...)
+	e.checkNextLine(t, "1.2.3.4 is unbanned")
+	e.RunWithError(t, append([]string{"neo-go", "peers", "unban"}, append(rpcArgs, "1.2.3.4")...)...)
+
+	e.Run(t, append([]string{"neo-go", "peers", "list"}, rpcArgs...)...)
+	require.False(t, strings.Contains(e.Out.String(), "banned 1.2.3.4"))
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/urfave/cli"
+)
+
+// newPeersCommand returns 'peers' command managing peers of the running node
+// via RPC.
+func newPeersCommand() cli.Command {
+	return cli.Command{
+		Name:  "peers",
+		Usage: "manage peers of the running node via RPC",
+		Subcommands: []cli.Command{
+			{
+				Name:      "list",
+				Usage:     "list connected, unconnected, bad and banned peers",
+				UsageText: "neo-go peers list -r endpoint [-s timeout]",
+				Action:    listPeers,
+				Flags:     options.RPC,
+			},
+			{
+				Name:      "ban",
+				Usage:     "ban peer by its IP address",
+				UsageText: "neo-go peers ban -r endpoint [-s timeout] [--duration <duration>] <address>",
+				Action:    banPeer,
+				Flags: append([]cli.Flag{
+					cli.DurationFlag{
+						Name:  "duration, d",
+						Usage: "ban duration (node's BanDuration by default)",
+					},
+				}, options.RPC...),
+			},
+			{
+				Name:      "unban",
+				Usage:     "unban peer by its IP address",
+				UsageText: "neo-go peers unban -r endpoint [-s timeout] <address>",
+				Action:    unbanPeer,
+				Flags:     options.RPC,
+			},
+		},
+	}
+}
+
+func listPeers(ctx *cli.Context) error {
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, exitErr := options.GetRPCClient(gctx, ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+	peers, err := c.GetPeers()
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	for _, p := range peers.Connected {
+		fmt.Fprintf(ctx.App.Writer, "connected %s:%s score %d\n", p.Address, p.Port, p.Score)
+	}
+	for _, p := range peers.Unconnected {
+		fmt.Fprintf(ctx.App.Writer, "unconnected %s:%s\n", p.Address, p.Port)
+	}
+	for _, p := range peers.Bad {
+		fmt.Fprintf(ctx.App.Writer, "bad %s:%s\n", p.Address, p.Port)
+	}
+	for _, p := range peers.Banned {
+		until := time.Unix(0, p.Until*int64(time.Millisecond)).UTC()
+		fmt.Fprintf(ctx.App.Writer, "banned %s until %s\n", p.Address, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func banPeer(ctx *cli.Context) error {
+	addr, exitErr := getPeerAddress(ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+	dur := ctx.Duration("duration")
+	if dur < 0 {
+		return cli.NewExitError(errors.New("negative ban duration"), 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, exitErr := options.GetRPCClient(gctx, ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+	if err := c.BanPeer(addr, dur); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "%s is banned\n", addr)
+	return nil
+}
+
+func unbanPeer(ctx *cli.Context) error {
+	addr, exitErr := getPeerAddress(ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, exitErr := options.GetRPCClient(gctx, ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+	ok, err := c.UnbanPeer(addr)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if !ok {
+		return cli.NewExitError(fmt.Errorf("%s is not banned", addr), 1)
+	}
+	fmt.Fprintf(ctx.App.Writer, "%s is unbanned\n", addr)
+	return nil
+}
+
+// getPeerAddress returns the only positional argument of the command.
+func getPeerAddress(ctx *cli.Context) (string, cli.ExitCoder) {
+	if ctx.NArg() != 1 {
+		return "", cli.NewExitError(errors.New("exactly one peer address is expected"), 1)
+	}
+	return ctx.Args().First(), nil
+}
@@ -1,17 +1,20 @@
 package server
 
 import (
+	"bufio"
 	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/cli/options"
 	"github.com/nspcc-dev/neo-go/pkg/config"
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/chaindump"
+	"github.com/nspcc-dev/neo-go/pkg/core/chainexport"
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/network"
@@ -22,6 +25,10 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
+// restoreProgressInterval is the number of blocks between restore progress
+// log messages.
+const restoreProgressInterval = 10000
+
 // NewCommands returns 'node' command.
 func NewCommands() []cli.Command {
 	var cfgFlags = []cli.Flag{
@@ -48,6 +55,43 @@ func NewCommands() []cli.Command {
 			Name:  "out, o",
 			Usage: "Output file (stdout if not given)",
 		},
+		cli.BoolFlag{
+			Name:  "v2",
+			Usage: "use compressed and checksummed v2 dump format",
+		},
+	)
+	var cfgExportFlags = make([]cli.Flag, len(cfgWithCountFlags))
+	copy(cfgExportFlags, cfgWithCountFlags)
+	cfgExportFlags = append(cfgExportFlags,
+		cli.UintFlag{
+			Name:  "start, s",
+			Usage: "block number to start from (default: 0)",
+		},
+		cli.StringFlag{
+			Name:  "out, o",
+			Usage: "output directory (one file per entity is created there)",
+		},
+		cli.StringFlag{
+			Name:  "format, f",
+			Value: "json",
+			Usage: "output format: json (newline-delimited) or csv",
+		},
+	)
+	var cfgVerifyFlags = make([]cli.Flag, len(cfgWithCountFlags))
+	copy(cfgVerifyFlags, cfgWithCountFlags)
+	cfgVerifyFlags = append(cfgVerifyFlags,
+		cli.UintFlag{
+			Name:  "start, s",
+			Usage: "block number to start from (default: the first block in the dump)",
+		},
+		cli.StringFlag{
+			Name:  "in, i",
+			Usage: "Input file (stdin if not given)",
+		},
+		cli.BoolFlag{
+			Name:  "incremental, n",
+			Usage: "use if dump is incremental (v1 dumps only)",
+		},
 	)
 	var cfgCountInFlags = make([]cli.Flag, len(cfgWithCountFlags))
 	copy(cfgCountInFlags, cfgWithCountFlags)
@@ -88,8 +132,26 @@ func NewCommands() []cli.Command {
 					Action: restoreDB,
 					Flags:  cfgCountInFlags,
 				},
+				{
+					Name:   "verify",
+					Usage:  "verify dump file integrity without restoring it",
+					Action: verifyDump,
+					Flags:  cfgVerifyFlags,
+				},
+				{
+					Name:  "export",
+					Usage: "export blocks, transactions, signers, execution results, notifications and NEP-17 transfers",
+					UsageText: "neo-go db export --out <dir> [--format json|csv] [--start <index>] [--count <number>]\n\n" +
+						"   Every kind of data is written into a separate file in the output directory\n" +
+						"   (blocks, transactions, signers, executions, notifications and transfers with\n" +
+						"   .ndjson or .csv extension). Files are overwritten if they already exist.",
+					Action: exportDB,
+					Flags:  cfgExportFlags,
+				},
 			},
 		},
+		newPeersCommand(),
+		newStateDiffCommand(),
 	}
 }
 
@@ -194,8 +256,16 @@ func dumpDB(ctx *cli.Context) error {
 	if count == 0 {
 		count = chainCount - start
 	}
-	writer.WriteU32LE(count)
-	err = chaindump.Dump(chain, writer, start, count)
+	if ctx.Bool("v2") {
+		bw := bufio.NewWriter(outStream)
+		err = chaindump.DumpV2(chain, bw, start, count)
+		if err == nil {
+			err = bw.Flush()
+		}
+	} else {
+		writer.WriteU32LE(count)
+		err = chaindump.Dump(chain, writer, start, count)
+	}
 	if err != nil {
 		return cli.NewExitError(err.Error(), 1)
 	}
@@ -205,6 +275,55 @@ func dumpDB(ctx *cli.Context) error {
 	return nil
 }
 
+func exportDB(ctx *cli.Context) error {
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	log, err := handleLoggingParams(ctx, cfg.ApplicationConfiguration)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	count := uint32(ctx.Uint("count"))
+	start := uint32(ctx.Uint("start"))
+	out := ctx.String("out")
+	if out == "" {
+		return cli.NewExitError("output directory is not specified", 1)
+	}
+	format, err := chainexport.FormatFromString(ctx.String("format"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	chain, prometheus, pprof, err := initBCWithMetrics(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer chain.Close()
+	defer prometheus.ShutDown()
+	defer pprof.ShutDown()
+
+	chainCount := chain.BlockHeight() + 1
+	if start+count > chainCount {
+		return cli.NewExitError(fmt.Errorf("chain is not that high (%d) to export %d blocks starting from %d", chainCount-1, count, start), 1)
+	}
+	if count == 0 {
+		count = chainCount - start
+	}
+	w, err := chainexport.NewFileWriter(out, format)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	err = chainexport.Export(chain, w, start, count)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
 func restoreDB(ctx *cli.Context) error {
 	cfg, err := getConfigFromContext(ctx)
 	if err != nil {
@@ -224,7 +343,10 @@ func restoreDB(ctx *cli.Context) error {
 		}
 	}
 	defer inStream.Close()
-	reader := io.NewBinReaderFromIO(inStream)
+	reader, err := chaindump.NewReader(inStream, ctx.Bool("incremental"))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to read dump header: %w", err), 1)
+	}
 
 	dumpDir := ctx.String("dump")
 	if dumpDir != "" {
@@ -239,13 +361,10 @@ func restoreDB(ctx *cli.Context) error {
 	defer prometheus.ShutDown()
 	defer pprof.ShutDown()
 
-	var start uint32
-	if ctx.Bool("incremental") {
-		start = reader.ReadU32LE()
-		if chain.BlockHeight()+1 < start {
-			return cli.NewExitError(fmt.Errorf("expected height: %d, dump starts at %d",
-				chain.BlockHeight()+1, start), 1)
-		}
+	var start = reader.Start
+	if chain.BlockHeight()+1 < start {
+		return cli.NewExitError(fmt.Errorf("expected height: %d, dump starts at %d",
+			chain.BlockHeight()+1, start), 1)
 	}
 
 	var skip uint32
@@ -253,10 +372,7 @@ func restoreDB(ctx *cli.Context) error {
 		skip = chain.BlockHeight() + 1 - start
 	}
 
-	var allBlocks = reader.ReadU32LE()
-	if reader.Err != nil {
-		return cli.NewExitError(err, 1)
-	}
+	var allBlocks = reader.Count
 	if skip+count > allBlocks {
 		return cli.NewExitError(fmt.Errorf("input file has only %d blocks, can't read %d starting from %d", allBlocks, count, skip), 1)
 	}
@@ -307,10 +423,70 @@ func restoreDB(ctx *cli.Context) error {
 		}
 	}
 
-	err = chaindump.Restore(chain, reader, skip, count, f)
+	var (
+		restored  uint32
+		startTime = time.Now()
+		rate      = func() float64 {
+			return float64(restored) / time.Since(startTime).Seconds()
+		}
+	)
+	err = chaindump.RestoreFrom(chain, reader, start+skip, count, func(b *block.Block) error {
+		restored++
+		if restored%restoreProgressInterval == 0 {
+			log.Info("restore progress",
+				zap.Uint32("height", b.Index),
+				zap.Uint32("restored", restored),
+				zap.Float64("blocks/s", rate()))
+		}
+		return f(b)
+	})
+	log.Info("restore finished",
+		zap.Uint32("height", chain.BlockHeight()),
+		zap.Uint32("restored", restored),
+		zap.Duration("took", time.Since(startTime)),
+		zap.Float64("blocks/s", rate()))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	return nil
+}
+
+func verifyDump(ctx *cli.Context) error {
+	cfg, err := getConfigFromContext(ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	var inStream = os.Stdin
+	if in := ctx.String("in"); in != "" {
+		inStream, err = os.Open(in)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	defer inStream.Close()
+	reader, err := chaindump.NewReader(inStream, ctx.Bool("incremental"))
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to read dump header: %w", err), 1)
+	}
+	var (
+		count = uint32(ctx.Uint("count"))
+		start = reader.Start
+	)
+	if ctx.IsSet("start") {
+		start = uint32(ctx.Uint("start"))
+	}
+	if start < reader.Start || start-reader.Start+count > reader.Count {
+		return cli.NewExitError(fmt.Errorf("input file has %d blocks starting from %d, can't read %d starting from %d",
+			reader.Count, reader.Start, count, start), 1)
+	}
+	if count == 0 {
+		count = reader.Count - (start - reader.Start)
+	}
+	err = chaindump.Verify(reader, cfg.ProtocolConfiguration.StateRootInHeader, start, count)
 	if err != nil {
 		return cli.NewExitError(err, 1)
 	}
+	fmt.Fprintf(ctx.App.Writer, "Dump version %d: %d blocks starting from %d are OK\n", reader.Version, count, start)
 	return nil
 }
 
@@ -415,7 +591,7 @@ func initBlockChain(cfg config.Config, log *zap.Logger) (*core.Blockchain, error
 		return nil, cli.NewExitError(fmt.Errorf("could not initialize storage: %w", err), 1)
 	}
 
-	chain, err := core.NewBlockchain(store, cfg.ProtocolConfiguration, log)
+	chain, err := core.NewBlockchain(store, cfg.ProtocolConfiguration, cfg.ApplicationConfiguration, log)
 	if err != nil {
 		return nil, cli.NewExitError(fmt.Errorf("could not initialize blockchain: %w", err), 1)
 	}
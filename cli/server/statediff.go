@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/urfave/cli"
+)
+
+// stateDiffBatch is the number of changes requested at once.
+const stateDiffBatch = 1000
+
+// newStateDiffCommand returns 'statediff' command printing storage changes
+// between two state roots via RPC.
+func newStateDiffCommand() cli.Command {
+	return cli.Command{
+		Name:  "statediff",
+		Usage: "show storage changes between two state roots via RPC",
+		UsageText: "neo-go statediff -r endpoint [-s timeout] [--max <number>] <old> <new>\n\n" +
+			"   <old> and <new> are state root hashes or block heights. Changes are printed\n" +
+			"   grouped by contract ID as '+ key: value' for added items, '* key: old -> new'\n" +
+			"   for modified and '- key: value' for deleted ones (keys and values are in hex).",
+		Action: stateDiff,
+		Flags: append([]cli.Flag{
+			cli.IntFlag{
+				Name:  "max, m",
+				Usage: "maximum number of changes to print (all by default)",
+			},
+		}, options.RPC...),
+	}
+}
+
+func stateDiff(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return cli.NewExitError(errors.New("old and new state roots are expected"), 1)
+	}
+	max := ctx.Int("max")
+	if max < 0 {
+		return cli.NewExitError(errors.New("negative number of changes"), 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, exitErr := options.GetRPCClient(gctx, ctx)
+	if exitErr != nil {
+		return exitErr
+	}
+	oldRoot, err := getStateRootHash(c, ctx.Args()[0])
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid old state root: %w", err), 1)
+	}
+	newRoot, err := getStateRootHash(c, ctx.Args()[1])
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid new state root: %w", err), 1)
+	}
+
+	var (
+		next    []byte
+		printed int
+		lastID  int32
+		started bool
+	)
+	for {
+		batch := stateDiffBatch
+		if max != 0 && max-printed < batch {
+			batch = max - printed
+		}
+		res, err := c.GetStateDiff(oldRoot, newRoot, batch, next)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		for _, cd := range res.Contracts {
+			if !started || cd.ID != lastID {
+				fmt.Fprintf(ctx.App.Writer, "contract %d:\n", cd.ID)
+				started, lastID = true, cd.ID
+			}
+			for _, item := range cd.Added {
+				fmt.Fprintf(ctx.App.Writer, "  + %x: %x\n", item.Key, item.NewValue)
+			}
+			for _, item := range cd.Modified {
+				fmt.Fprintf(ctx.App.Writer, "  * %x: %x -> %x\n", item.Key, item.OldValue, item.NewValue)
+			}
+			for _, item := range cd.Deleted {
+				fmt.Fprintf(ctx.App.Writer, "  - %x: %x\n", item.Key, item.OldValue)
+			}
+			printed += len(cd.Added) + len(cd.Modified) + len(cd.Deleted)
+		}
+		if res.Next == nil || max != 0 && printed >= max {
+			return nil
+		}
+		next = res.Next
+	}
+}
+
+// getStateRootHash returns state root hash specified either by hash or by
+// block height.
+func getStateRootHash(c *client.Client, s string) (util.Uint256, error) {
+	if height, err := strconv.ParseUint(s, 10, 32); err == nil {
+		r, err := c.GetStateRootByHeight(uint32(height))
+		if err != nil {
+			return util.Uint256{}, err
+		}
+		return r.Root, nil
+	}
+	return util.Uint256DecodeStringLE(strings.TrimPrefix(s, "0x"))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateDiff(t *testing.T) {
+	e := newExecutor(t, true)
+	rpcArgs := []string{"--rpc-endpoint", "http://" + e.RPC.Addr}
+	height := strconv.Itoa(int(e.Chain.BlockHeight()))
+
+	t.Run("invalid args", func(t *testing.T) {
+		e.RunWithError(t, append([]string{"neo-go", "statediff"}, append(rpcArgs, "0")...)...)
+		e.RunWithError(t, append([]string{"neo-go", "statediff"}, append(rpcArgs, "0", "notaroot")...)...)
+		e.RunWithError(t, append([]string{"neo-go", "statediff", "--max", "-1"}, append(rpcArgs, "0", height)...)...)
+		e.RunWithError(t, "neo-go", "statediff", "0", height)
+	})
+
+	t.Run("same root", func(t *testing.T) {
+		e.Run(t, append([]string{"neo-go", "statediff"}, append(rpcArgs, height, height)...)...)
+		e.checkEOF(t)
+	})
+
+	e.Run(t, append([]string{"neo-go", "statediff"}, append(rpcArgs, "0", height)...)...)
+	e.checkNextLine(t, `^contract -?\d+:$`)
+	e.checkNextLine(t, `^  [+*-] [0-9a-f]+: [0-9a-f]*`)
+
+	r, err := e.Chain.GetStateModule().GetStateRoot(e.Chain.BlockHeight())
+	require.NoError(t, err)
+	e.Run(t, append([]string{"neo-go", "statediff", "--max", "1"}, append(rpcArgs, "0", r.Root.StringLE())...)...)
+	e.checkNextLine(t, `^contract -?\d+:$`)
+	line := e.getNextLine(t)
+	require.True(t, strings.HasPrefix(line, "  "))
+	e.checkEOF(t)
+}
@@ -0,0 +1,432 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/nspcc-dev/neo-go/cli/paramcontext"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/context"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+func newNotaryCommands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:      "deposit",
+			Usage:     "deposit GAS to the Notary contract",
+			UsageText: "deposit -w <path> -r <rpc> -a <addr> [--to <addr>] --amount <gas> --till <height> [-g <gas>]",
+			Description: `Transfers GAS from the specified account to the Notary native contract
+   making a deposit for the account specified with '--to' (sender by default).
+   The deposit is locked until '--till' height, it's used to pay for fallback
+   transactions of notary requests. Only the owner of the deposit can set
+   the height for it.
+`,
+			Action: depositNotary,
+			Flags: append([]cli.Flag{
+				walletPathFlag,
+				gasFlag,
+				flags.AddressFlag{
+					Name:  "address, a",
+					Usage: "Address to send GAS from",
+				},
+				flags.AddressFlag{
+					Name:  "to",
+					Usage: "Address to make a deposit for (sender by default)",
+				},
+				flags.Fixed8Flag{
+					Name:  "amount",
+					Usage: "Amount of GAS to deposit",
+				},
+				cli.UintFlag{
+					Name:  "till",
+					Usage: "Height the deposit is locked until",
+				},
+			}, options.RPC...),
+		},
+		{
+			Name:      "create",
+			Usage:     "create notary-assisted main transaction",
+			UsageText: "create -w <path> -r <rpc> --in <file.in> --out <file.out> [-g <gas>]",
+			Description: `Converts unsigned transaction saved by any command with '--out' flag
+   into a notary-assisted one: adds Notary contract signer, NotaryAssisted
+   attribute and recalculates network fee. Verification scripts of all
+   signers should be known either from the input file or from the wallet.
+   The result is saved to the output file that should be passed to all
+   signers of the transaction for 'notary sign'. It can contain either one
+   multisignature signer or any number of standard signature ones (along
+   with any number of contract signers).
+`,
+			Action: createNotaryTx,
+			Flags: append([]cli.Flag{
+				walletPathFlag,
+				gasFlag,
+				inFlag,
+				outFlag,
+			}, options.RPC...),
+		},
+		{
+			Name:      "sign",
+			Usage:     "sign notary-assisted main transaction and submit notary request",
+			UsageText: "sign -w <path> -r <rpc> -a <addr> --in <file.in> [--valid-for <blocks>] [-g <gas>]",
+			Description: `Signs notary-assisted main transaction created with 'notary create'
+   and submits P2P notary request with it and a fallback transaction to the
+   node. The fallback is paid from the Notary deposit of the signing key and
+   becomes valid '--valid-for' blocks before main transaction expiration
+   (by default it's half of the main transaction validity period, but no
+   more than allowed by the Notary contract). Each signer of the main
+   transaction should submit its own request with the same input file,
+   main transaction is completed by the notary service when enough
+   signatures are collected. Hashes of the main and fallback transactions
+   are printed.
+`,
+			Action: signNotaryRequest,
+			Flags: append([]cli.Flag{
+				walletPathFlag,
+				gasFlag,
+				inFlag,
+				flags.AddressFlag{
+					Name:  "address, a",
+					Usage: "Address of the main transaction signer",
+				},
+				cli.UintFlag{
+					Name:  "valid-for",
+					Usage: "Number of blocks fallback transaction is valid for",
+				},
+			}, options.RPC...),
+		},
+	}
+}
+
+func depositNotary(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("wallet"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	addrFlag := ctx.Generic("address").(*flags.Address)
+	if !addrFlag.IsSet {
+		return cli.NewExitError("address was not provided", 1)
+	}
+	from := addrFlag.Uint160()
+	var to interface{}
+	if toFlag := ctx.Generic("to").(*flags.Address); toFlag.IsSet {
+		to = toFlag.Uint160()
+	}
+	amount := flags.Fixed8FromContext(ctx, "amount")
+	if amount <= 0 {
+		return cli.NewExitError("amount should be positive", 1)
+	}
+	if !ctx.IsSet("till") {
+		return cli.NewExitError("till height was not provided", 1)
+	}
+	till := int64(ctx.Uint("till"))
+
+	acc, err := getDecryptedAccount(ctx, wall, from)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	gasHash, err := c.GetNativeContractHash(nativenames.Gas)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	notaryHash, err := c.GetNativeContractHash(nativenames.Notary)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	gas := flags.Fixed8FromContext(ctx, "gas")
+	tx, err := c.CreateNEP17TransferTx(acc, notaryHash, gasHash, int64(amount), int64(gas),
+		[]interface{}{to, till}, nil)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	res, err := c.SignAndPushTx(tx, acc, nil)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to push transaction: %w", err), 1)
+	}
+	fmt.Fprintln(ctx.App.Writer, res.StringLE())
+	return nil
+}
+
+func createNotaryTx(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("wallet"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	pc, err := paramcontext.Read(ctx.String("in"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	old, ok := pc.Verifiable.(*transaction.Transaction)
+	if !ok {
+		return cli.NewExitError("verifiable item is not a transaction", 1)
+	}
+	if old.HasAttribute(transaction.NotaryAssistedT) {
+		return cli.NewExitError("transaction is already notary-assisted", 1)
+	}
+	out := ctx.String("out")
+	if out == "" {
+		return cli.NewExitError("output file was not provided", 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	notaryHash, err := c.GetNativeContractHash(nativenames.Notary)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if old.HasSigner(notaryHash) {
+		return cli.NewExitError("transaction is already signed by the Notary contract", 1)
+	}
+
+	accs := make([]*wallet.Account, len(old.Signers), len(old.Signers)+1)
+	for i, s := range old.Signers {
+		accs[i], err = getNotarySignerAccount(pc, wall, s.Account)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+	}
+	nKeys, err := getNotaryNKeys(accs)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	tx := transaction.New(old.Script, old.SystemFee)
+	tx.ValidUntilBlock = old.ValidUntilBlock
+	tx.Signers = append(old.Signers, transaction.Signer{
+		Account: notaryHash,
+		Scopes:  transaction.None,
+	})
+	tx.Attributes = append(old.Attributes, transaction.Attribute{
+		Type:  transaction.NotaryAssistedT,
+		Value: &transaction.NotaryAssisted{NKeys: nKeys},
+	})
+	notaryFee, err := c.CalculateNotaryFee(nKeys)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	// Notary contract witness is paid for by notaryFee.
+	accs = append(accs, &wallet.Account{Contract: &wallet.Contract{Deployed: false}})
+	gas := flags.Fixed8FromContext(ctx, "gas")
+	if err := c.AddNetworkFee(tx, notaryFee+int64(gas), accs...); err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to add network fee: %w", err), 1)
+	}
+
+	scCtx := context.NewParameterContext("Neo.Core.ContractTransaction", c.GetNetwork(), tx)
+	for i, acc := range accs[:len(accs)-1] {
+		if !acc.Contract.Deployed {
+			scCtx.Items[tx.Signers[i].Account] = &context.Item{
+				Script:     acc.Contract.Script,
+				Signatures: make(map[string][]byte),
+			}
+		}
+	}
+	if err := paramcontext.Save(scCtx, out); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Fprintln(ctx.App.Writer, tx.Hash().StringLE())
+	return nil
+}
+
+// getNotarySignerAccount returns an account with the verification script of
+// the signer from the parameter context or the wallet (the account is not
+// decrypted).
+func getNotarySignerAccount(pc *context.ParameterContext, wall *wallet.Wallet, h util.Uint160) (*wallet.Account, error) {
+	if acc := wall.GetAccount(h); acc != nil {
+		return acc, nil
+	}
+	if item, ok := pc.Items[h]; ok && len(item.Script) != 0 {
+		return &wallet.Account{Contract: &wallet.Contract{Script: item.Script}}, nil
+	}
+	return nil, fmt.Errorf("verification script for signer %s is unknown, add it to the wallet", address.Uint160ToString(h))
+}
+
+// getNotaryNKeys returns the number of keys that should sign notary request
+// for the main transaction with signers using the given accounts.
+func getNotaryNKeys(accs []*wallet.Account) (uint8, error) {
+	var (
+		nKeys        int
+		haveMultisig bool
+	)
+	for _, acc := range accs {
+		if acc.Contract.Deployed {
+			continue
+		}
+		if haveMultisig {
+			return 0, errors.New("multisignature signer can't be combined with other signature signers")
+		}
+		if _, pubs, ok := vm.ParseMultiSigContract(acc.Contract.Script); ok {
+			if nKeys != 0 {
+				return 0, errors.New("multisignature signer can't be combined with other signature signers")
+			}
+			haveMultisig = true
+			nKeys = len(pubs)
+		} else if vm.IsSignatureContract(acc.Contract.Script) {
+			nKeys++
+		} else {
+			return 0, errors.New("only signature, multisignature and contract signers are supported")
+		}
+	}
+	switch {
+	case nKeys == 0:
+		return 0, errors.New("transaction should have at least one signature or multisignature signer")
+	case nKeys > math.MaxUint8:
+		return 0, fmt.Errorf("too many keys: %d", nKeys)
+	}
+	return uint8(nKeys), nil
+}
+
+func signNotaryRequest(ctx *cli.Context) error {
+	wall, err := openWallet(ctx.String("wallet"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	pc, err := paramcontext.Read(ctx.String("in"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	tx, ok := pc.Verifiable.(*transaction.Transaction)
+	if !ok {
+		return cli.NewExitError("verifiable item is not a transaction", 1)
+	}
+	if !tx.HasAttribute(transaction.NotaryAssistedT) {
+		return cli.NewExitError("transaction is not notary-assisted, use 'notary create' first", 1)
+	}
+	addrFlag := ctx.Generic("address").(*flags.Address)
+	if !addrFlag.IsSet {
+		return cli.NewExitError("address was not provided", 1)
+	}
+	signer := addrFlag.Uint160()
+	if !tx.HasSigner(signer) {
+		return cli.NewExitError("tx signers don't contain provided account", 1)
+	}
+	acc, err := getDecryptedAccount(ctx, wall, signer)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	_, _, isMultisig := vm.ParseMultiSigContract(acc.Contract.Script)
+	if !isMultisig && !vm.IsSignatureContract(acc.Contract.Script) {
+		return cli.NewExitError("only signature and multisignature accounts can be used", 1)
+	}
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if c.GetNetwork() != pc.Network {
+		return cli.NewExitError(fmt.Errorf("transaction is created for network %s, node is in %s", pc.Network, c.GetNetwork()), 1)
+	}
+	notaryHash, err := c.GetNativeContractHash(nativenames.Notary)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	tx.Scripts = make([]transaction.Witness, len(tx.Signers))
+	for i, s := range tx.Signers {
+		switch {
+		case s.Account == notaryHash:
+			// Dummy witness, it's replaced by the notary service.
+			tx.Scripts[i] = transaction.Witness{
+				InvocationScript:   append([]byte{byte(opcode.PUSHDATA1), 64}, make([]byte, 64)...),
+				VerificationScript: []byte{},
+			}
+		case s.Account == signer:
+			tx.Scripts[i] = transaction.Witness{
+				InvocationScript:   append([]byte{byte(opcode.PUSHDATA1), 64}, acc.PrivateKey().SignHashable(uint32(pc.Network), tx)...),
+				VerificationScript: acc.Contract.Script,
+			}
+		default:
+			if item, ok := pc.Items[s.Account]; ok {
+				tx.Scripts[i].VerificationScript = item.Script
+			}
+		}
+	}
+
+	validFor, err := getFallbackValidFor(ctx, c, tx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	// Fallback transaction and request are always signed with the standard
+	// account of the key.
+	fbAcc := acc
+	if isMultisig {
+		fbAcc = wallet.NewAccountFromPrivateKey(acc.PrivateKey())
+	}
+	gas := flags.Fixed8FromContext(ctx, "gas")
+	req, err := c.SignAndPushP2PNotaryRequest(tx, []byte{byte(opcode.RET)}, -1, int64(gas), validFor, fbAcc)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Fprintln(ctx.App.Writer, "Main transaction:", req.MainTransaction.Hash().StringLE())
+	fmt.Fprintln(ctx.App.Writer, "Fallback transaction:", req.FallbackTransaction.Hash().StringLE())
+	return nil
+}
+
+// getFallbackValidFor returns the number of blocks fallback transaction for
+// the main transaction tx should be valid for.
+func getFallbackValidFor(ctx *cli.Context, c *client.Client, tx *transaction.Transaction) (uint32, error) {
+	if ctx.IsSet("valid-for") {
+		return uint32(ctx.Uint("valid-for")), nil
+	}
+	count, err := c.GetBlockCount()
+	if err != nil {
+		return 0, err
+	}
+	maxDelta, err := c.GetMaxNotValidBeforeDelta()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get MaxNotValidBeforeDelta: %w", err)
+	}
+	if tx.ValidUntilBlock < count {
+		return 0, errors.New("main transaction is expired")
+	}
+	// Fallback should become valid not later than maxDelta blocks after
+	// the current height.
+	left := int64(tx.ValidUntilBlock - count + 1)
+	minValidFor := left - maxDelta + 1
+	if minValidFor > maxDelta {
+		return 0, fmt.Errorf("main transaction is valid for too long, it should expire in less than %d blocks", 2*maxDelta)
+	}
+	validFor := left / 2
+	if validFor < minValidFor {
+		validFor = minValidFor
+	}
+	if validFor > maxDelta {
+		validFor = maxDelta
+	}
+	if validFor < 1 {
+		validFor = 1
+	}
+	return uint32(validFor), nil
+}
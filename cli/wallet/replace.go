@@ -0,0 +1,104 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/cli/flags"
+	"github.com/nspcc-dev/neo-go/cli/options"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/urfave/cli"
+)
+
+func cancelTx(ctx *cli.Context) error {
+	return replaceTx(ctx, true)
+}
+
+func speedUpTx(ctx *cli.Context) error {
+	return replaceTx(ctx, false)
+}
+
+func replaceTx(ctx *cli.Context, cancelOnly bool) error {
+	if ctx.NArg() != 1 {
+		return cli.NewExitError("transaction hash is expected", 1)
+	}
+	hash, err := util.Uint256DecodeStringLE(ctx.Args().First())
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("invalid transaction hash: %w", err), 1)
+	}
+	wall, err := openWallet(ctx.String("wallet"))
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	defer wall.Close()
+
+	gctx, cancel := options.GetTimeoutContext(ctx)
+	defer cancel()
+	c, err := options.GetRPCClient(gctx, ctx)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	old, err := c.GetRawTransaction(hash)
+	if err != nil {
+		return cli.NewExitError(fmt.Errorf("failed to get transaction: %w", err), 1)
+	}
+	sender := old.Signers[0].Account
+	if addrFlag := ctx.Generic("address").(*flags.Address); addrFlag.IsSet {
+		sender = addrFlag.Uint160()
+	}
+	acc, err := getDecryptedAccount(ctx, wall, sender)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+
+	var (
+		gas       = flags.Fixed8FromContext(ctx, "gas")
+		cosigners []client.SignerAccount
+		tx        *transaction.Transaction
+	)
+	if cancelOnly {
+		tx, err = c.CreateCancelTx(hash, acc, int64(gas))
+	} else {
+		cosigners, err = getReplacementCosigners(ctx, wall, old)
+		if err != nil {
+			return cli.NewExitError(err, 1)
+		}
+		tx, err = c.CreateSpeedUpTx(hash, acc, int64(gas), cosigners)
+	}
+	if err != nil {
+		if errors.Is(err, client.ErrNotInMemPool) {
+			return cli.NewExitError(fmt.Errorf("%w, it can't be replaced", err), 1)
+		}
+		return cli.NewExitError(err, 1)
+	}
+	newHash, err := c.SignAndPushTx(tx, acc, cosigners)
+	if err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	if err := c.CheckReplaced(hash, newHash); err != nil {
+		return cli.NewExitError(err, 1)
+	}
+	fmt.Fprintln(ctx.App.Writer, newHash.StringLE())
+	return nil
+}
+
+// getReplacementCosigners returns decrypted wallet accounts for all
+// signers of the transaction except the sender.
+func getReplacementCosigners(ctx *cli.Context, wall *wallet.Wallet, tx *transaction.Transaction) ([]client.SignerAccount, error) {
+	var cosigners []client.SignerAccount
+	for _, s := range tx.Signers[1:] {
+		acc, err := getDecryptedAccount(ctx, wall, s.Account)
+		if err != nil {
+			return nil, err
+		}
+		cosigners = append(cosigners, client.SignerAccount{
+			Signer:  s,
+			Account: acc,
+		})
+	}
+	return cosigners, nil
+}
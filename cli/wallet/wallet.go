@@ -80,6 +80,18 @@ func NewCommands() []cli.Command {
 		},
 	}
 	signFlags = append(signFlags, options.RPC...)
+	replaceFlags := []cli.Flag{
+		walletPathFlag,
+		flags.AddressFlag{
+			Name:  "address, a",
+			Usage: "Sender address of the transaction (transaction sender by default)",
+		},
+		flags.Fixed8Flag{
+			Name:  "gas, g",
+			Usage: "Network fee to add on top of the replaced transaction fee",
+		},
+	}
+	replaceFlags = append(replaceFlags, options.RPC...)
 	return []cli.Command{{
 		Name:  "wallet",
 		Usage: "create, open and manage a NEO wallet",
@@ -229,6 +241,20 @@ func NewCommands() []cli.Command {
 				Action:    signStoredTransaction,
 				Flags:     signFlags,
 			},
+			{
+				Name:      "cancel-tx",
+				Usage:     "cancel pooled transaction with a conflicting one paying higher fee",
+				UsageText: "cancel-tx -w <path> -r <endpoint> [-a <address>] [-g <gas>] <txid>",
+				Action:    cancelTx,
+				Flags:     replaceFlags,
+			},
+			{
+				Name:      "speedup-tx",
+				Usage:     "resend pooled transaction with higher fee replacing the original one",
+				UsageText: "speedup-tx -w <path> -r <endpoint> [-a <address>] [-g <gas>] <txid>",
+				Action:    speedUpTx,
+				Flags:     replaceFlags,
+			},
 			{
 				Name:        "nep17",
 				Usage:       "work with NEP17 contracts",
@@ -244,6 +270,11 @@ func NewCommands() []cli.Command {
 				Usage:       "work with candidates",
 				Subcommands: newValidatorCommands(),
 			},
+			{
+				Name:        "notary",
+				Usage:       "work with Notary contract and P2P notary requests",
+				Subcommands: newNotaryCommands(),
+			},
 		},
 	}}
 }
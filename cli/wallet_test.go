@@ -14,6 +14,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/util"
 	"github.com/nspcc-dev/neo-go/pkg/wallet"
 	"github.com/stretchr/testify/require"
 )
@@ -239,6 +240,42 @@ func TestClaimGas(t *testing.T) {
 	}
 }
 
+func TestReplaceTx(t *testing.T) {
+	e := newExecutor(t, true)
+
+	e.In.WriteString("one\r")
+	e.Run(t, "neo-go", "wallet", "nep17", "transfer",
+		"--rpc-endpoint", "http://"+e.RPC.Addr,
+		"--wallet", validatorWallet,
+		"--from", validatorAddr,
+		"--to", validatorAddr,
+		"--token", "GAS",
+		"--amount", "1")
+	tx, _ := e.checkTxPersisted(t)
+
+	for _, cmd := range []string{"cancel-tx", "speedup-tx"} {
+		args := []string{"neo-go", "wallet", cmd,
+			"--rpc-endpoint", "http://" + e.RPC.Addr,
+			"--wallet", validatorWallet,
+		}
+		t.Run(cmd, func(t *testing.T) {
+			t.Run("missing hash", func(t *testing.T) {
+				e.RunWithError(t, args...)
+			})
+			t.Run("invalid hash", func(t *testing.T) {
+				e.RunWithError(t, append(args, "notahash")...)
+			})
+			t.Run("unknown transaction", func(t *testing.T) {
+				e.RunWithError(t, append(args, util.Uint256{1, 2, 3}.StringLE())...)
+			})
+			t.Run("not in the pool", func(t *testing.T) {
+				e.In.WriteString("one\r")
+				e.RunWithError(t, append(args, tx.Hash().StringLE())...)
+			})
+		})
+	}
+}
+
 func TestImportDeployed(t *testing.T) {
 	e := newExecutor(t, true)
 
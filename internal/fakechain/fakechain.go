@@ -254,11 +254,21 @@ func (chain *FakeChain) GetNextBlockValidators() ([]*keys.PublicKey, error) {
 	panic("TODO")
 }
 
+// ForEachAccountTransaction implements Blockchainer interface.
+func (chain *FakeChain) ForEachAccountTransaction(util.Uint160, func(*state.AccountTransaction) bool, int, func(*state.AccountTransaction) (bool, error)) error {
+	panic("TODO")
+}
+
 // ForEachNEP17Transfer implements Blockchainer interface.
 func (chain *FakeChain) ForEachNEP17Transfer(util.Uint160, func(*state.NEP17Transfer) (bool, error)) error {
 	panic("TODO")
 }
 
+// ForEachNotification implements Blockchainer interface.
+func (chain *FakeChain) ForEachNotification(util.Uint160, string, func(*state.IndexedNotification) bool, int, func(*state.IndexedNotification) (bool, error)) error {
+	panic("TODO")
+}
+
 // GetNEP17Balances implements Blockchainer interface.
 func (chain *FakeChain) GetNEP17Balances(util.Uint160) *state.NEP17Balances {
 	panic("TODO")
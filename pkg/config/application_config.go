@@ -10,26 +10,48 @@ import (
 
 // ApplicationConfiguration config specific to the node.
 type ApplicationConfiguration struct {
-	Address           string                  `yaml:"Address"`
-	AnnouncedNodePort uint16                  `yaml:"AnnouncedPort"`
-	AttemptConnPeers  int                     `yaml:"AttemptConnPeers"`
-	DBConfiguration   storage.DBConfiguration `yaml:"DBConfiguration"`
-	DialTimeout       time.Duration           `yaml:"DialTimeout"`
-	LogPath           string                  `yaml:"LogPath"`
-	MaxPeers          int                     `yaml:"MaxPeers"`
-	MinPeers          int                     `yaml:"MinPeers"`
-	NodePort          uint16                  `yaml:"NodePort"`
-	PingInterval      time.Duration           `yaml:"PingInterval"`
-	PingTimeout       time.Duration           `yaml:"PingTimeout"`
-	Pprof             metrics.Config          `yaml:"Pprof"`
-	Prometheus        metrics.Config          `yaml:"Prometheus"`
-	ProtoTickInterval time.Duration           `yaml:"ProtoTickInterval"`
-	Relay             bool                    `yaml:"Relay"`
-	RPC               rpc.Config              `yaml:"RPC"`
-	UnlockWallet      Wallet                  `yaml:"UnlockWallet"`
-	Oracle            OracleConfiguration     `yaml:"Oracle"`
-	P2PNotary         P2PNotary               `yaml:"P2PNotary"`
-	StateRoot         StateRoot               `yaml:"StateRoot"`
+	Address             string                  `yaml:"Address"`
+	AddressBookFile     string                  `yaml:"AddressBookFile"`
+	AnnouncedNodePort   uint16                  `yaml:"AnnouncedPort"`
+	AttemptConnPeers    int                     `yaml:"AttemptConnPeers"`
+	BanDuration         time.Duration           `yaml:"BanDuration"`
+	BanScore            int                     `yaml:"BanScore"`
+	CompactBlocks       bool                    `yaml:"CompactBlocks"`
+	CompactBlocksPeers  []string                `yaml:"CompactBlocksPeers"`
+	DBConfiguration     storage.DBConfiguration `yaml:"DBConfiguration"`
+	DialTimeout         time.Duration           `yaml:"DialTimeout"`
+	FixedPeers          []string                `yaml:"FixedPeers"`
+	InboundAllow        []string                `yaml:"InboundAllow"`
+	InboundDeny         []string                `yaml:"InboundDeny"`
+	LogPath             string                  `yaml:"LogPath"`
+	MaxPeers            int                     `yaml:"MaxPeers"`
+	MaxPeersPerSubnet   int                     `yaml:"MaxPeersPerSubnet"`
+	MemPoolFile         string                  `yaml:"MemPoolFile"`
+	MemPoolSaveInterval time.Duration           `yaml:"MemPoolSaveInterval"`
+	MinPeers            int                     `yaml:"MinPeers"`
+	NodePort            uint16                  `yaml:"NodePort"`
+	PingInterval        time.Duration           `yaml:"PingInterval"`
+	PingTimeout         time.Duration           `yaml:"PingTimeout"`
+	Pprof               metrics.Config          `yaml:"Pprof"`
+	Prometheus          metrics.Config          `yaml:"Prometheus"`
+	PrivateMode         bool                    `yaml:"PrivateMode"`
+	ProtoTickInterval   time.Duration           `yaml:"ProtoTickInterval"`
+	Relay               bool                    `yaml:"Relay"`
+	RPC                 rpc.Config              `yaml:"RPC"`
+	UnlockWallet        Wallet                  `yaml:"UnlockWallet"`
+	Oracle              OracleConfiguration     `yaml:"Oracle"`
+	P2PNotary           P2PNotary               `yaml:"P2PNotary"`
+	P2PTLS              P2PTLS                  `yaml:"P2PTLS"`
+	P2PRateLimits       P2PRateLimits           `yaml:"P2PRateLimits"`
+	StateRoot           StateRoot               `yaml:"StateRoot"`
 	// ExtensiblePoolSize is the maximum amount of the extensible payloads from a single sender.
 	ExtensiblePoolSize int `yaml:"ExtensiblePoolSize"`
+	// AccountTransactionsIndex enables indexing of transactions by their
+	// signers, it's required for getaccounttransactions RPC call. This
+	// value should remain the same for the same database.
+	AccountTransactionsIndex bool `yaml:"AccountTransactionsIndex"`
+	// NotificationsIndex enables indexing of notifications by contract
+	// and event name, it's required for findnotifications RPC call. This
+	// value should remain the same for the same database.
+	NotificationsIndex bool `yaml:"NotificationsIndex"`
 }
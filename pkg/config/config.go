@@ -52,6 +52,7 @@ func LoadFile(configPath string) (Config, error) {
 			PingTimeout:  90,
 			RPC: rpc.Config{
 				MaxIteratorResultItems: 100,
+				MaxFindResultItems:     rpc.DefaultMaxFindResultItems,
 			},
 		},
 	}
@@ -9,6 +9,12 @@ type OracleConfiguration struct {
 	AllowedContentTypes   []string           `yaml:"AllowedContentTypes"`
 	Nodes                 []string           `yaml:"Nodes"`
 	NeoFS                 NeoFSConfiguration `yaml:"NeoFS"`
+	IPFS                  IPFSConfiguration  `yaml:"IPFS"`
+	File                  FileConfiguration  `yaml:"File"`
+	Exec                  ExecConfiguration  `yaml:"Exec"`
+	ExtendedFilters       bool               `yaml:"ExtendedFilters"`
+	Cache                 OracleCache        `yaml:"Cache"`
+	HostLimits            OracleHostLimits   `yaml:"HostLimits"`
 	MaxTaskTimeout        time.Duration      `yaml:"MaxTaskTimeout"`
 	RefreshInterval       time.Duration      `yaml:"RefreshInterval"`
 	MaxConcurrentRequests int                `yaml:"MaxConcurrentRequests"`
@@ -22,3 +28,73 @@ type NeoFSConfiguration struct {
 	Nodes   []string      `yaml:"Nodes"`
 	Timeout time.Duration `yaml:"Timeout"`
 }
+
+// OracleProtocolConfiguration contains parameters common for additional
+// oracle protocols.
+type OracleProtocolConfiguration struct {
+	Enabled bool `yaml:"Enabled"`
+	// Timeout is the request timeout, RequestTimeout is used if it's zero.
+	Timeout time.Duration `yaml:"Timeout"`
+	// MaxResponseSize is the maximum size of the data fetched, it can't
+	// exceed (and defaults to) maximum oracle result size.
+	MaxResponseSize int `yaml:"MaxResponseSize"`
+	// AllowedContentTypes is the list of allowed MIME types, everything is
+	// allowed if it's empty.
+	AllowedContentTypes []string `yaml:"AllowedContentTypes"`
+}
+
+// IPFSConfiguration is a config for the ipfs:// protocol handler.
+type IPFSConfiguration struct {
+	OracleProtocolConfiguration `yaml:",inline"`
+	// Gateway is the address of HTTP gateway to get data from, like
+	// "http://127.0.0.1:8080".
+	Gateway string `yaml:"Gateway"`
+}
+
+// FileConfiguration is a config for the file:// protocol handler.
+type FileConfiguration struct {
+	OracleProtocolConfiguration `yaml:",inline"`
+	// Root is the directory files are served from, nothing outside of it can
+	// be accessed.
+	Root string `yaml:"Root"`
+}
+
+// ExecConfiguration is a config for the exec:// protocol handler.
+type ExecConfiguration struct {
+	OracleProtocolConfiguration `yaml:",inline"`
+	// Command is the command to run with Args and request URL as the last
+	// argument, its standard output is the result.
+	Command string   `yaml:"Command"`
+	Args    []string `yaml:"Args"`
+}
+
+// OracleCache is a config for the cache of data fetched by oracle.
+type OracleCache struct {
+	// MaxAge is the maximum time data is cached for (it can be lowered by
+	// HTTP caching headers), caching is disabled if it's zero.
+	MaxAge time.Duration `yaml:"MaxAge"`
+	// MaxEntries is the maximum number of cached URLs.
+	MaxEntries int `yaml:"MaxEntries"`
+}
+
+// OracleHostLimits contains oracle request limits for remote hosts.
+type OracleHostLimits struct {
+	// OracleHostLimit contains limits for every host.
+	OracleHostLimit `yaml:",inline"`
+	// Hosts overrides limits for the given hosts (like "api.example.com"
+	// or "api.example.com:8080").
+	Hosts map[string]OracleHostLimit `yaml:"Hosts"`
+}
+
+// OracleHostLimit contains oracle request limits for a single host, zero
+// values mean no limit.
+type OracleHostLimit struct {
+	// MaxConcurrentRequests is the maximum number of requests to the host
+	// processed in parallel.
+	MaxConcurrentRequests int `yaml:"MaxConcurrentRequests"`
+	// Rate is the number of requests per second.
+	Rate float64 `yaml:"Rate"`
+	// Burst is the number of requests that can be made at once without
+	// waiting, it's 1 if not specified.
+	Burst int `yaml:"Burst"`
+}
@@ -0,0 +1,25 @@
+package config
+
+// P2PRateLimits contains per-peer P2P message rate limits configuration.
+type P2PRateLimits struct {
+	// Enabled turns rate limiting on.
+	Enabled bool `yaml:"Enabled"`
+	// Commands overrides default limits for the given commands, they're
+	// specified by names like "getdata" or "inv".
+	Commands map[string]RateLimit `yaml:"Commands"`
+	// MaxOutstandingRequests is the maximum number of inventory items
+	// requested from a single peer that are not yet received.
+	MaxOutstandingRequests int `yaml:"MaxOutstandingRequests"`
+	// MaxMempoolInventory is the maximum number of transactions announced
+	// in response to a single mempool request.
+	MaxMempoolInventory int `yaml:"MaxMempoolInventory"`
+}
+
+// RateLimit is a token bucket limit for a single command.
+type RateLimit struct {
+	// Rate is the number of items (messages or inventory items they
+	// contain) per second a peer can send.
+	Rate float64 `yaml:"Rate"`
+	// Burst is the maximum number of items a peer can send at once.
+	Burst int `yaml:"Burst"`
+}
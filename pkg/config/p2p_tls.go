@@ -0,0 +1,21 @@
+package config
+
+// P2PTLS contains settings for encrypted (mutual TLS) P2P connections.
+type P2PTLS struct {
+	Enabled bool `yaml:"Enabled"`
+	// Port is the port TLS listener is bound to (at the node's Address).
+	Port uint16 `yaml:"Port"`
+	// CertFile and KeyFile are the node's certificate and private key.
+	CertFile string `yaml:"CertFile"`
+	KeyFile  string `yaml:"KeyFile"`
+	// CAFile contains certificates of authorities trusted to sign
+	// certificates of other nodes.
+	CAFile string `yaml:"CAFile"`
+	// Peers are TLS endpoints (host:port) of nodes known to support
+	// encrypted connections, they're always connected to via TLS and
+	// plain connections from their hosts are refused.
+	Peers []string `yaml:"Peers"`
+	// Required disallows communication with peers not supporting
+	// encrypted connections.
+	Required bool `yaml:"Required"`
+}
@@ -1,7 +1,26 @@
 package config
 
-// StateRoot contains state root service configuration.
-type StateRoot struct {
-	Enabled      bool   `yaml:"Enabled"`
-	UnlockWallet Wallet `yaml:"UnlockWallet"`
-}
+import "time"
+
+type (
+	// StateRoot contains state root service configuration.
+	StateRoot struct {
+		Enabled      bool                  `yaml:"Enabled"`
+		UnlockWallet Wallet                `yaml:"UnlockWallet"`
+		Verification StateRootVerification `yaml:"Verification"`
+	}
+
+	// StateRootVerification contains configuration for local state roots
+	// verification against trusted RPC nodes.
+	StateRootVerification struct {
+		Enabled bool `yaml:"Enabled"`
+		// TrustedNodes is a list of RPC node addresses to compare state
+		// roots with.
+		TrustedNodes   []string      `yaml:"TrustedNodes"`
+		Interval       time.Duration `yaml:"Interval"`
+		RequestTimeout time.Duration `yaml:"RequestTimeout"`
+		// HaltOnMismatch stops block processing when local state diverges
+		// from the one of any trusted node.
+		HaltOnMismatch bool `yaml:"HaltOnMismatch"`
+	}
+)
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/nspcc-dev/dbft"
@@ -57,6 +58,8 @@ type Service interface {
 	OnPayload(p *npayload.Extensible)
 	// OnTransaction is a callback to notify Service about new received transaction.
 	OnTransaction(tx *transaction.Transaction)
+	// GetState returns the state of the current consensus round.
+	GetState() (State, error)
 }
 
 type service struct {
@@ -85,6 +88,9 @@ type service struct {
 	// before block is accepted, so in case of change view it will contain
 	// updated value.
 	lastTimestamp uint64
+	// roundLock protects round which is the state of the current dBFT view.
+	roundLock sync.RWMutex
+	round     round
 }
 
 // Config is a configuration for consensus services.
@@ -265,13 +271,14 @@ func (s *service) Start() {
 
 // Shutdown implements Service interface.
 func (s *service) Shutdown() {
-	if s.started.Load() {
+	if s.started.CAS(true, false) {
 		close(s.quit)
 		<-s.finished
 	}
 }
 
 func (s *service) eventLoop() {
+	s.updateRound()
 events:
 	for {
 		select {
@@ -291,6 +298,7 @@ events:
 			}
 
 			if msg.Type() == payload.RecoveryMessageType {
+				addRecoveryMessagesMetric("received")
 				rec := msg.GetRecoveryMessage().(*recoveryMessage)
 				if rec.preparationHash == nil {
 					req := rec.GetPrepareRequest(&msg, s.dbft.Validators, uint16(s.dbft.PrimaryIndex))
@@ -315,10 +323,12 @@ events:
 		case b := <-s.blockEvents:
 			s.handleChainBlock(b)
 		}
+		s.updateRound()
 		// Always process block event if there is any, we can add one above.
 		select {
 		case b := <-s.blockEvents:
 			s.handleChainBlock(b)
+			s.updateRound()
 		default:
 		}
 	}
@@ -414,6 +424,13 @@ func (s *service) broadcast(p payload.ConsensusPayload) {
 		s.log.Warn("can't sign consensus payload", zap.Error(err))
 	}
 
+	switch p.Type() {
+	case payload.ChangeViewType:
+		addChangeViewsMetric(p.GetChangeView().Reason().String())
+	case payload.RecoveryMessageType:
+		addRecoveryMessagesMetric("sent")
+	}
+
 	ep := &p.(*Payload).Extensible
 	s.Config.Broadcast(ep)
 }
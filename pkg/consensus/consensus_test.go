@@ -363,6 +363,59 @@ func TestService_OnPayload(t *testing.T) {
 	shouldReceive(t, srv.messages)
 }
 
+func TestService_GetState(t *testing.T) {
+	srv := newTestService(t)
+	srv.dbft.Start()
+	t.Cleanup(srv.dbft.Timer.Stop)
+
+	_, err := srv.GetState()
+	require.Error(t, err)
+
+	// Pretend the service is started, see TestService_OnPayload.
+	srv.started.Store(true)
+	srv.updateRound()
+	st, err := srv.GetState()
+	require.NoError(t, err)
+	require.Equal(t, srv.dbft.BlockIndex, st.Height)
+	require.Equal(t, byte(0), st.View)
+	require.Equal(t, srv.dbft.PrimaryIndex, st.Primary)
+	require.Equal(t, 4, len(st.Validators))
+	for i := range st.Validators {
+		_, pub := getTestValidator(i)
+		require.Equal(t, pub.Bytes(), st.Validators[i].PublicKey.Bytes())
+	}
+
+	sendChangeView := func(i int) {
+		p := new(Payload)
+		p.SetType(payload.ChangeViewType)
+		p.SetPayload(&changeView{newViewNumber: 1, timestamp: uint64(time.Now().UnixNano() / nsInMs)})
+		p.SetHeight(srv.dbft.BlockIndex)
+		p.SetValidatorIndex(uint16(i))
+
+		priv, _ := getTestValidator(i)
+		require.NoError(t, p.Sign(priv))
+		srv.dbft.OnReceive(p)
+		srv.updateRound()
+	}
+
+	sendChangeView(2)
+	sendChangeView(3)
+	st, err = srv.GetState()
+	require.NoError(t, err)
+	require.Equal(t, byte(0), st.View)
+	require.False(t, st.Validators[1].ChangeView)
+	require.True(t, st.Validators[2].ChangeView)
+	require.True(t, st.Validators[3].ChangeView)
+
+	sendChangeView(1)
+	st, err = srv.GetState()
+	require.NoError(t, err)
+	require.Equal(t, byte(1), st.View)
+	for _, v := range st.Validators {
+		require.False(t, v.ChangeView)
+	}
+}
+
 func TestVerifyBlock(t *testing.T) {
 	srv := newTestService(t)
 
@@ -498,7 +551,7 @@ func newSingleTestChain(t *testing.T) *core.Blockchain {
 	cfg, err := config.LoadFile(configPath)
 	require.NoError(t, err, "could not load config")
 
-	chain, err := core.NewBlockchain(storage.NewMemoryStore(), cfg.ProtocolConfiguration, zaptest.NewLogger(t))
+	chain, err := core.NewBlockchain(storage.NewMemoryStore(), cfg.ProtocolConfiguration, cfg.ApplicationConfiguration, zaptest.NewLogger(t))
 	require.NoError(t, err, "could not create chain")
 
 	go chain.Run()
@@ -511,7 +564,7 @@ func newTestChain(t *testing.T, stateRootInHeader bool) *core.Blockchain {
 	require.NoError(t, err)
 	unitTestNetCfg.ProtocolConfiguration.StateRootInHeader = stateRootInHeader
 
-	chain, err := core.NewBlockchain(storage.NewMemoryStore(), unitTestNetCfg.ProtocolConfiguration, zaptest.NewLogger(t))
+	chain, err := core.NewBlockchain(storage.NewMemoryStore(), unitTestNetCfg.ProtocolConfiguration, unitTestNetCfg.ApplicationConfiguration, zaptest.NewLogger(t))
 	require.NoError(t, err)
 
 	go chain.Run()
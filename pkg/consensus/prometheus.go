@@ -0,0 +1,85 @@
+package consensus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// durationBuckets cover block times from tens of milliseconds to minutes.
+var durationBuckets = prometheus.ExponentialBuckets(0.05, 2, 12)
+
+var (
+	viewDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Help:      "Time spent in a single dBFT view by the way it was finished",
+			Name:      "consensus_view_duration_seconds",
+			Namespace: "neogo",
+			Buckets:   durationBuckets,
+		},
+		[]string{"result"},
+	)
+	phaseDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Help:      "Time spent in each dBFT phase",
+			Name:      "consensus_phase_duration_seconds",
+			Namespace: "neogo",
+			Buckets:   durationBuckets,
+		},
+		[]string{"phase"},
+	)
+	changeViews = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of change view requests sent by the node",
+			Name:      "consensus_change_views",
+			Namespace: "neogo",
+		},
+		[]string{"reason"},
+	)
+	missedMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of views finished without a message from validator",
+			Name:      "consensus_missed_messages",
+			Namespace: "neogo",
+		},
+		[]string{"validator", "type"},
+	)
+	recoveryMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of recovery messages sent or received by the node",
+			Name:      "consensus_recovery_messages",
+			Namespace: "neogo",
+		},
+		[]string{"direction"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		viewDuration,
+		phaseDuration,
+		changeViews,
+		missedMessages,
+		recoveryMessages,
+	)
+}
+
+func observeViewDurationMetric(result string, d time.Duration) {
+	viewDuration.WithLabelValues(result).Observe(d.Seconds())
+}
+
+func observePhaseDurationMetric(phase string, d time.Duration) {
+	phaseDuration.WithLabelValues(phase).Observe(d.Seconds())
+}
+
+func addChangeViewsMetric(reason string) {
+	changeViews.WithLabelValues(reason).Inc()
+}
+
+func addMissedMessagesMetric(validator string, typ string) {
+	missedMessages.WithLabelValues(validator, typ).Inc()
+}
+
+func addRecoveryMessagesMetric(direction string) {
+	recoveryMessages.WithLabelValues(direction).Inc()
+}
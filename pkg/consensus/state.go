@@ -0,0 +1,155 @@
+package consensus
+
+import (
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+)
+
+type (
+	// State is a snapshot of the current consensus round.
+	State struct {
+		Height uint32
+		View   byte
+		// Primary is an index of the primary node in Validators.
+		Primary uint
+		// Validators are the round validators along with the messages
+		// received from them in the current view.
+		Validators []ValidatorState
+	}
+
+	// ValidatorState contains messages received from a single validator.
+	ValidatorState struct {
+		PublicKey   *keys.PublicKey
+		Preparation bool
+		Commit      bool
+		// ChangeView is true if validator has requested a view change.
+		ChangeView bool
+	}
+
+	// round tracks the progress of the current dBFT view. It's changed by
+	// the event loop only.
+	round struct {
+		State
+		start time.Time
+		// requested is the time PrepareRequest was received or sent at.
+		requested time.Time
+		// prepared is the time enough preparations were collected at.
+		prepared time.Time
+	}
+)
+
+// Finished view results and dBFT phases used as metric labels.
+const (
+	resultBlock      = "block"
+	resultChangeView = "change_view"
+
+	phaseRequest     = "request"
+	phasePreparation = "preparation"
+	phaseCommit      = "commit"
+)
+
+var (
+	errNotEnabled = errors.New("consensus service is not enabled")
+	errNotStarted = errors.New("consensus service is not started")
+)
+
+// GetState implements Service interface.
+func (s *service) GetState() (State, error) {
+	if s.dbft == nil {
+		return State{}, errNotEnabled
+	}
+	if !s.started.Load() {
+		return State{}, errNotStarted
+	}
+	s.roundLock.RLock()
+	defer s.roundLock.RUnlock()
+	st := s.round.State
+	st.Validators = make([]ValidatorState, len(s.round.Validators))
+	copy(st.Validators, s.round.Validators)
+	return st, nil
+}
+
+// updateRound synchronizes round state with dBFT context, it must be called
+// after every context change. Once height or view changes previous view is
+// finished and its metrics are updated.
+func (s *service) updateRound() {
+	var (
+		ctx = &s.dbft.Context
+		now = time.Now()
+	)
+
+	s.roundLock.Lock()
+	defer s.roundLock.Unlock()
+
+	r := &s.round
+	if r.start.IsZero() || r.Height != ctx.BlockIndex || r.View != ctx.ViewNumber {
+		if !r.start.IsZero() {
+			s.finishRound(now, r.Height != ctx.BlockIndex)
+		}
+		s.round = round{
+			State: State{
+				Height:     ctx.BlockIndex,
+				View:       ctx.ViewNumber,
+				Primary:    ctx.PrimaryIndex,
+				Validators: make([]ValidatorState, len(ctx.Validators)),
+			},
+			start: now,
+		}
+		for i, v := range ctx.Validators {
+			r.Validators[i].PublicKey = v.(*publicKey).PublicKey
+		}
+	}
+
+	var preparations int
+	for i := range r.Validators {
+		v := &r.Validators[i]
+		if i < len(ctx.PreparationPayloads) {
+			v.Preparation = ctx.PreparationPayloads[i] != nil
+		}
+		if i < len(ctx.CommitPayloads) {
+			p := ctx.CommitPayloads[i]
+			v.Commit = p != nil && p.ViewNumber() == ctx.ViewNumber
+		}
+		if i < len(ctx.ChangeViewPayloads) {
+			p := ctx.ChangeViewPayloads[i]
+			v.ChangeView = p != nil && p.GetChangeView().NewViewNumber() > ctx.ViewNumber
+		}
+		if v.Preparation {
+			preparations++
+		}
+	}
+	if r.requested.IsZero() && r.Primary < uint(len(r.Validators)) && r.Validators[r.Primary].Preparation {
+		r.requested = now
+		observePhaseDurationMetric(phaseRequest, now.Sub(r.start))
+	}
+	if r.prepared.IsZero() && !r.requested.IsZero() && preparations >= ctx.M() {
+		r.prepared = now
+		observePhaseDurationMetric(phasePreparation, now.Sub(r.requested))
+	}
+}
+
+// finishRound updates metrics for the current view which is finished either
+// with a new block or with a view change.
+func (s *service) finishRound(now time.Time, accepted bool) {
+	r := &s.round
+	res := resultChangeView
+	if accepted {
+		res = resultBlock
+		if !r.prepared.IsZero() {
+			observePhaseDurationMetric(phaseCommit, now.Sub(r.prepared))
+		}
+	}
+	observeViewDurationMetric(res, now.Sub(r.start))
+	for _, v := range r.Validators {
+		key := hex.EncodeToString(v.PublicKey.Bytes())
+		if !v.Preparation {
+			addMissedMessagesMetric(key, phasePreparation)
+		}
+		if accepted && !v.Commit {
+			addMissedMessagesMetric(key, phaseCommit)
+		}
+	}
+}
@@ -71,6 +71,13 @@ var (
 	// conflicts with other transaction in the chain or pool according to
 	// Conflicts attribute.
 	ErrHasConflicts = errors.New("has conflicts")
+	// ErrAccountTransactionsIndexDisabled is returned when trying to get
+	// account transactions from the chain with AccountTransactionsIndex
+	// setting disabled.
+	ErrAccountTransactionsIndexDisabled = errors.New("account transactions index is disabled")
+	// ErrNotificationsIndexDisabled is returned when trying to get indexed
+	// notifications from the chain with NotificationsIndex setting disabled.
+	ErrNotificationsIndexDisabled = errors.New("notifications index is disabled")
 )
 var (
 	persistInterval = 1 * time.Second
@@ -82,6 +89,10 @@ var (
 type Blockchain struct {
 	config config.ProtocolConfiguration
 
+	// Optional node-local indexes, see ApplicationConfiguration.
+	accountTxIndex     bool
+	notificationsIndex bool
+
 	// The only way chain state changes is by adding blocks, so we can't
 	// allow concurrent block additions. It differs from the next lock in
 	// that it's only for AddBlock method itself, the chain state is
@@ -108,6 +119,8 @@ type Blockchain struct {
 
 	// Current persisted block count.
 	persistedHeight uint32
+	// persistLock serializes flushes made by Run and Persist.
+	persistLock sync.Mutex
 
 	// Number of headers stored in the chain file.
 	storedHeaderCount uint32
@@ -142,6 +155,10 @@ type Blockchain struct {
 
 	stateRoot *stateroot.Module
 
+	// witnesses contains results of witness checks made in advance by
+	// PreverifyBlock.
+	witnesses witnessCache
+
 	// Notification subsystem.
 	events  chan bcEvent
 	subCh   chan interface{}
@@ -159,8 +176,9 @@ type bcEvent struct {
 
 // NewBlockchain returns a new blockchain object the will use the
 // given Store as its underlying storage. For it to work correctly you need
-// to spawn a goroutine for its Run method after this initialization.
-func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.Logger) (*Blockchain, error) {
+// to spawn a goroutine for its Run method after this initialization. Only
+// node-local chain settings (like optional indexes) are taken from appCfg.
+func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, appCfg config.ApplicationConfiguration, log *zap.Logger) (*Blockchain, error) {
 	if log == nil {
 		return nil, errors.New("empty logger")
 	}
@@ -206,16 +224,18 @@ func NewBlockchain(s storage.Store, cfg config.ProtocolConfiguration, log *zap.L
 		log.Info("NativeActivations are not set, using default values")
 	}
 	bc := &Blockchain{
-		config:      cfg,
-		dao:         dao.NewSimple(s, cfg.StateRootInHeader),
-		stopCh:      make(chan struct{}),
-		runToExitCh: make(chan struct{}),
-		memPool:     mempool.New(cfg.MemPoolSize, 0, false),
-		sbCommittee: committee,
-		log:         log,
-		events:      make(chan bcEvent),
-		subCh:       make(chan interface{}),
-		unsubCh:     make(chan interface{}),
+		config:             cfg,
+		accountTxIndex:     appCfg.AccountTransactionsIndex,
+		notificationsIndex: appCfg.NotificationsIndex,
+		dao:                dao.NewSimple(s, cfg.StateRootInHeader),
+		stopCh:             make(chan struct{}),
+		runToExitCh:        make(chan struct{}),
+		memPool:            mempool.New(cfg.MemPoolSize, 0, false),
+		sbCommittee:        committee,
+		log:                log,
+		events:             make(chan bcEvent),
+		subCh:              make(chan interface{}),
+		unsubCh:            make(chan interface{}),
 
 		contracts: *native.NewContracts(cfg.P2PSigExtensions, cfg.NativeUpdateHistories),
 	}
@@ -535,6 +555,7 @@ func (bc *Blockchain) Close() {
 func (bc *Blockchain) AddBlock(block *block.Block) error {
 	bc.addLock.Lock()
 	defer bc.addLock.Unlock()
+	defer bc.witnesses.forget(block.Index)
 
 	var mp *mempool.Pool
 	expectedHeight := bc.BlockHeight() + 1
@@ -690,7 +711,7 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 	}
 	writeBuf.Reset()
 
-	aer, err := bc.runPersist(bc.contracts.GetPersistScript(), block, cache, trigger.OnPersist)
+	aer, err := bc.runPersist(bc.contracts.GetPersistScript(), block, cache, trigger.OnPersist, writeBuf)
 	if err != nil {
 		return fmt.Errorf("onPersist failed: %w", err)
 	}
@@ -707,6 +728,20 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 		}
 		writeBuf.Reset()
 
+		if bc.accountTxIndex {
+			tr := &state.AccountTransaction{
+				Block:     block.Index,
+				Timestamp: block.Timestamp,
+				Tx:        tx.Hash(),
+			}
+			for _, s := range tx.Signers {
+				if err := cache.PutAccountTransaction(s.Account, tr, writeBuf); err != nil {
+					return fmt.Errorf("failed to store account transaction %s: %w", tx.Hash().StringLE(), err)
+				}
+				writeBuf.Reset()
+			}
+		}
+
 		systemInterop := bc.newInteropContext(trigger.Application, cache, block, tx)
 		v := systemInterop.SpawnVM()
 		v.LoadScriptWithFlags(tx.Script, callflag.All)
@@ -722,7 +757,9 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 				return fmt.Errorf("failed to persist invocation results: %w", err)
 			}
 			for j := range systemInterop.Notifications {
-				bc.handleNotification(&systemInterop.Notifications[j], cache, block, tx.Hash())
+				if err := bc.handleNotification(&systemInterop.Notifications[j], cache, block, tx.Hash(), j, writeBuf); err != nil {
+					return err
+				}
 			}
 		} else {
 			bc.log.Warn("contract invocation failed",
@@ -762,7 +799,7 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 		}
 	}
 
-	aer, err = bc.runPersist(bc.contracts.GetPostPersistScript(), block, cache, trigger.PostPersist)
+	aer, err = bc.runPersist(bc.contracts.GetPostPersistScript(), block, cache, trigger.PostPersist, writeBuf)
 	if err != nil {
 		return fmt.Errorf("postPersist failed: %w", err)
 	}
@@ -798,6 +835,12 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 	if bc.config.RemoveUntraceableBlocks {
 		if block.Index > bc.config.MaxTraceableBlocks {
 			index := block.Index - bc.config.MaxTraceableBlocks // is at least 1
+			if bc.accountTxIndex {
+				bc.removeAccountTransactions(cache, bc.headerHashes[index])
+			}
+			if bc.notificationsIndex {
+				bc.removeIndexedNotifications(cache, bc.headerHashes[index])
+			}
 			err := cache.DeleteBlock(bc.headerHashes[index], writeBuf)
 			if err != nil {
 				bc.log.Warn("error while removing old block",
@@ -846,6 +889,62 @@ func (bc *Blockchain) storeBlock(block *block.Block, txpool *mempool.Pool) error
 	return nil
 }
 
+// removeAccountTransactions drops account transactions index entries for all
+// transactions of the block with the given hash.
+func (bc *Blockchain) removeAccountTransactions(cache *dao.Cached, h util.Uint256) {
+	b, err := cache.GetBlock(h)
+	if err != nil {
+		bc.log.Warn("error while removing old account transactions",
+			zap.String("block", h.StringLE()),
+			zap.Error(err))
+		return
+	}
+	for _, tx := range b.Transactions {
+		for _, s := range tx.Signers {
+			if err := cache.DeleteAccountTransaction(s.Account, b.Index); err != nil {
+				bc.log.Warn("error while removing old account transaction",
+					zap.String("tx", tx.Hash().StringLE()),
+					zap.Error(err))
+			}
+		}
+	}
+}
+
+// removeIndexedNotifications drops notifications index entries for all
+// notifications emitted during the block with the given hash processing.
+func (bc *Blockchain) removeIndexedNotifications(cache *dao.Cached, h util.Uint256) {
+	b, err := cache.GetBlock(h)
+	if err != nil {
+		bc.log.Warn("error while removing old indexed notifications",
+			zap.String("block", h.StringLE()),
+			zap.Error(err))
+		return
+	}
+	remove := func(hash util.Uint256, trig trigger.Type) {
+		aers, err := cache.GetAppExecResults(hash, trig)
+		if err != nil {
+			return
+		}
+		for _, aer := range aers {
+			if aer.VMState.HasFlag(vm.FaultState) {
+				continue
+			}
+			for _, ev := range aer.Events {
+				if err := cache.DeleteIndexedNotification(ev.ScriptHash, ev.Name, b.Index); err != nil {
+					bc.log.Warn("error while removing old indexed notification",
+						zap.String("container", hash.StringLE()),
+						zap.Error(err))
+				}
+			}
+		}
+	}
+	remove(h, trigger.OnPersist)
+	for _, tx := range b.Transactions {
+		remove(tx.Hash(), trigger.Application)
+	}
+	remove(h, trigger.PostPersist)
+}
+
 func (bc *Blockchain) updateExtensibleWhitelist(height uint32) error {
 	updateCommittee := native.ShouldUpdateCommittee(height, bc)
 	stateVals, sh, err := bc.contracts.Designate.GetDesignatedByRole(bc.dao, noderoles.StateValidator, height)
@@ -895,7 +994,7 @@ func (bc *Blockchain) IsExtensibleAllowed(u util.Uint160) bool {
 	return n < len(us)
 }
 
-func (bc *Blockchain) runPersist(script []byte, block *block.Block, cache *dao.Cached, trig trigger.Type) (*state.AppExecResult, error) {
+func (bc *Blockchain) runPersist(script []byte, block *block.Block, cache *dao.Cached, trig trigger.Type, buf *io.BufBinWriter) (*state.AppExecResult, error) {
 	systemInterop := bc.newInteropContext(trig, cache, block, nil)
 	v := systemInterop.SpawnVM()
 	v.LoadScriptWithFlags(script, callflag.All)
@@ -906,7 +1005,9 @@ func (bc *Blockchain) runPersist(script []byte, block *block.Block, cache *dao.C
 		return nil, fmt.Errorf("can't save changes: %w", err)
 	}
 	for i := range systemInterop.Notifications {
-		bc.handleNotification(&systemInterop.Notifications[i], cache, block, block.Hash())
+		if err := bc.handleNotification(&systemInterop.Notifications[i], cache, block, block.Hash(), i, buf); err != nil {
+			return nil, err
+		}
 	}
 	return &state.AppExecResult{
 		Container: block.Hash(), // application logs can be retrieved by block hash
@@ -920,13 +1021,28 @@ func (bc *Blockchain) runPersist(script []byte, block *block.Block, cache *dao.C
 	}, nil
 }
 
-func (bc *Blockchain) handleNotification(note *state.NotificationEvent, d *dao.Cached, b *block.Block, h util.Uint256) {
+// handleNotification processes notification emitted by the container with
+// hash h at the given index in its notifications list.
+func (bc *Blockchain) handleNotification(note *state.NotificationEvent, d *dao.Cached, b *block.Block, h util.Uint256, index int, buf *io.BufBinWriter) error {
+	if bc.notificationsIndex {
+		n := &state.IndexedNotification{
+			Block:     b.Index,
+			Container: h,
+			Index:     uint32(index),
+			Event:     *note,
+		}
+		err := d.PutIndexedNotification(n, buf)
+		buf.Reset()
+		if err != nil {
+			return fmt.Errorf("failed to index notification #%d of %s: %w", index, h.StringLE(), err)
+		}
+	}
 	if note.Name != "Transfer" {
-		return
+		return nil
 	}
 	arr, ok := note.Item.Value().([]stackitem.Item)
 	if !ok || len(arr) != 3 {
-		return
+		return nil
 	}
 	var from []byte
 	fromValue := arr[0].Value()
@@ -934,7 +1050,7 @@ func (bc *Blockchain) handleNotification(note *state.NotificationEvent, d *dao.C
 	if fromValue != nil {
 		from, ok = fromValue.([]byte)
 		if !ok {
-			return
+			return nil
 		}
 	}
 	var to []byte
@@ -943,21 +1059,22 @@ func (bc *Blockchain) handleNotification(note *state.NotificationEvent, d *dao.C
 	if toValue != nil {
 		to, ok = toValue.([]byte)
 		if !ok {
-			return
+			return nil
 		}
 	}
 	amount, ok := arr[2].Value().(*big.Int)
 	if !ok {
 		bs, ok := arr[2].Value().([]byte)
 		if !ok {
-			return
+			return nil
 		}
 		if len(bs) > bigint.MaxBytesLen {
-			return // Not a proper number.
+			return nil // Not a proper number.
 		}
 		amount = bigint.FromBytes(bs)
 	}
 	bc.processNEP17Transfer(d, h, b, note.ScriptHash, from, to, amount)
+	return nil
 }
 
 func parseUint160(addr []byte) util.Uint160 {
@@ -1058,6 +1175,29 @@ func (bc *Blockchain) ForEachNEP17Transfer(acc util.Uint160, f func(*state.NEP17
 	return nil
 }
 
+// ForEachAccountTransaction executes f for transactions signed by acc starting
+// from the newest one. Transactions for which newer returns true (if it's not
+// nil) and skip transactions following them are omitted. It returns an error
+// if account transactions index is not enabled.
+func (bc *Blockchain) ForEachAccountTransaction(acc util.Uint160, newer func(*state.AccountTransaction) bool, skip int, f func(*state.AccountTransaction) (bool, error)) error {
+	if !bc.accountTxIndex {
+		return ErrAccountTransactionsIndexDisabled
+	}
+	return bc.dao.ForEachAccountTransaction(acc, newer, skip, f)
+}
+
+// ForEachNotification executes f for indexed notifications with the given
+// name emitted by the contract starting from the newest one. If name is empty,
+// all notifications of the contract are iterated over. Notifications for which
+// newer returns true (if it's not nil) and skip notifications following them
+// are omitted. It returns an error if notifications index is not enabled.
+func (bc *Blockchain) ForEachNotification(contract util.Uint160, name string, newer func(*state.IndexedNotification) bool, skip int, f func(*state.IndexedNotification) (bool, error)) error {
+	if !bc.notificationsIndex {
+		return ErrNotificationsIndexDisabled
+	}
+	return bc.dao.ForEachIndexedNotification(contract, name, newer, skip, f)
+}
+
 // GetNEP17Balances returns NEP17 balances for the acc.
 func (bc *Blockchain) GetNEP17Balances(acc util.Uint160) *state.NEP17Balances {
 	bs, err := bc.dao.GetNEP17Balances(acc)
@@ -1111,6 +1251,13 @@ func (bc *Blockchain) LastBatch() *storage.MemBatch {
 	return bc.lastBatch
 }
 
+// Persist flushes all changes made to the chain state to the underlying
+// persistent storage as a single batch. It's done periodically by Run, but
+// can be used to flush changes explicitly, e.g. when adding lots of blocks.
+func (bc *Blockchain) Persist() error {
+	return bc.persist()
+}
+
 // persist flushes current in-memory Store contents to the persistent storage.
 func (bc *Blockchain) persist() error {
 	var (
@@ -1119,6 +1266,9 @@ func (bc *Blockchain) persist() error {
 		err       error
 	)
 
+	bc.persistLock.Lock()
+	defer bc.persistLock.Unlock()
+
 	persisted, err = bc.dao.Persist()
 	if err != nil {
 		return err
@@ -1808,6 +1958,10 @@ func (bc *Blockchain) verifyHashAgainstScript(hash util.Uint160, witness *transa
 	if gas > gasPolicy {
 		gas = gasPolicy
 	}
+	if r, ok := bc.witnesses.take(interopCtx.Container, hash, witness); ok &&
+		r.gas <= gas && r.execFee == bc.GetBaseExecFee() {
+		return r.gas, nil
+	}
 
 	vm := interopCtx.SpawnVM()
 	vm.SetPriceGetter(interopCtx.GetPrice)
@@ -1866,6 +2020,47 @@ func (bc *Blockchain) verifyTxWitnesses(t *transaction.Transaction, block *block
 	return nil
 }
 
+// PreverifyBlock checks standard (signature and multisignature) witnesses of
+// the given block and its transactions in advance. These checks don't depend
+// on the chain state, so this method can be called concurrently for blocks
+// that are not yet added to the chain. Successful results are remembered and
+// then used by AddBlock instead of running the same witness scripts again,
+// failed checks are just ignored (AddBlock will repeat them and return
+// proper error). Results are kept until the block with the same (or higher)
+// index is processed by AddBlock.
+func (bc *Blockchain) PreverifyBlock(b *block.Block) {
+	if !bc.config.VerifyBlocks {
+		return
+	}
+	bc.preverifyWitness(b.Index, &b.Header, nil, b.Script.ScriptHash(), &b.Script, headerVerificationGasLimit)
+	for _, tx := range b.Transactions {
+		if len(tx.Scripts) != len(tx.Signers) {
+			continue
+		}
+		for i := range tx.Signers {
+			bc.preverifyWitness(b.Index, tx, tx, tx.Signers[i].Account, &tx.Scripts[i], math.MaxInt64)
+		}
+	}
+}
+
+// preverifyWitness checks standard witness w for container c included into
+// block with the given index and remembers successful result.
+func (bc *Blockchain) preverifyWitness(index uint32, c hash.Hashable, tx *transaction.Transaction, h util.Uint160, w *transaction.Witness, gas int64) {
+	if !vm.IsStandardContract(w.VerificationScript) || w.ScriptHash() != h {
+		return
+	}
+	execFee := bc.GetBaseExecFee()
+	ic := bc.newInteropContext(trigger.Verification, bc.dao, nil, tx)
+	ic.Container = c
+	consumed, err := bc.verifyHashAgainstScript(h, w, ic, gas)
+	// Execution fee factor can be changed by the block being added
+	// concurrently, GAS consumed is only valid for the factor used.
+	if err != nil || execFee != bc.GetBaseExecFee() {
+		return
+	}
+	bc.witnesses.add(newWitnessKey(c, h, w), witnessResult{index: index, gas: consumed, execFee: execFee})
+}
+
 // verifyHeaderWitnesses is a block-specific implementation of VerifyWitnesses logic.
 func (bc *Blockchain) verifyHeaderWitnesses(currHeader, prevHeader *block.Header) error {
 	var hash util.Uint160
@@ -1,12 +1,14 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
 	"path"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -1551,6 +1553,29 @@ func testDumpAndRestore(t *testing.T, dumpF, restoreF func(c *config.Config)) {
 			require.Equal(t, bc.BlockHeight()-1, lastIndex)
 		})
 	})
+	t.Run("v2", func(t *testing.T) {
+		w := new(bytes.Buffer)
+		require.NoError(t, chaindump.DumpV2(bc, w, 0, bc.BlockHeight()+1))
+		buf := w.Bytes()
+
+		r, err := chaindump.NewReader(bytes.NewReader(buf), false)
+		require.NoError(t, err)
+		require.NoError(t, chaindump.Verify(r, bc.config.StateRootInHeader, 0, bc.BlockHeight()+1))
+
+		bc2 := newTestChainWithCustomCfg(t, restoreF)
+		r, err = chaindump.NewReader(bytes.NewReader(buf), false)
+		require.NoError(t, err)
+		require.NoError(t, chaindump.RestoreFrom(bc2, r, 0, 2, nil))
+		require.Equal(t, uint32(1), bc2.BlockHeight())
+
+		r, err = chaindump.NewReader(bytes.NewReader(buf), false)
+		require.NoError(t, err)
+		require.NoError(t, chaindump.RestoreFrom(bc2, r, 2, bc.BlockHeight()-1, nil))
+		require.Equal(t, bc.BlockHeight(), bc2.BlockHeight())
+		require.Equal(t, bc.CurrentBlockHash(), bc2.CurrentBlockHash())
+		// All restored blocks are flushed to the persistent storage.
+		require.Equal(t, bc2.BlockHeight(), atomic.LoadUint32(&bc2.persistedHeight))
+	})
 }
 
 func TestDumpAndRestore(t *testing.T) {
@@ -1605,6 +1630,186 @@ func TestRemoveUntraceable(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestAccountTransactionsIndex(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		bc := newTestChain(t)
+		err := bc.ForEachAccountTransaction(neoOwner, nil, 0, func(*state.AccountTransaction) (bool, error) {
+			return true, nil
+		})
+		require.True(t, errors.Is(err, ErrAccountTransactionsIndexDisabled))
+	})
+
+	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
+		c.ApplicationConfiguration.AccountTransactionsIndex = true
+		c.ProtocolConfiguration.MaxTraceableBlocks = 2
+		c.ProtocolConfiguration.RemoveUntraceableBlocks = true
+	})
+	getTransactions := func(t *testing.T, acc util.Uint160) []state.AccountTransaction {
+		var res []state.AccountTransaction
+		require.NoError(t, bc.ForEachAccountTransaction(acc, nil, 0, func(tr *state.AccountTransaction) (bool, error) {
+			res = append(res, *tr)
+			return true, nil
+		}))
+		return res
+	}
+
+	tx1, err := testchain.NewTransferFromOwner(bc, bc.contracts.NEO.Hash, util.Uint160{}, 1, 0, bc.BlockHeight()+1)
+	require.NoError(t, err)
+	b1 := bc.newBlock(tx1)
+	require.NoError(t, bc.AddBlock(b1))
+
+	tx2, err := testchain.NewTransferFromOwner(bc, bc.contracts.NEO.Hash, util.Uint160{}, 1, 0, bc.BlockHeight()+1)
+	require.NoError(t, err)
+	b2 := bc.newBlock(tx2)
+	require.NoError(t, bc.AddBlock(b2))
+
+	require.Equal(t, []state.AccountTransaction{
+		{Block: b2.Index, Timestamp: b2.Timestamp, Tx: tx2.Hash()},
+		{Block: b1.Index, Timestamp: b1.Timestamp, Tx: tx1.Hash()},
+	}, getTransactions(t, neoOwner))
+	require.Equal(t, 0, len(getTransactions(t, util.Uint160{})))
+
+	t.Run("stop iterating", func(t *testing.T) {
+		var count int
+		require.NoError(t, bc.ForEachAccountTransaction(neoOwner, nil, 0, func(*state.AccountTransaction) (bool, error) {
+			count++
+			return false, nil
+		}))
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("newer and skip", func(t *testing.T) {
+		getHashes := func(t *testing.T, newer func(*state.AccountTransaction) bool, skip int) []util.Uint256 {
+			var res []util.Uint256
+			require.NoError(t, bc.ForEachAccountTransaction(neoOwner, newer, skip, func(tr *state.AccountTransaction) (bool, error) {
+				res = append(res, tr.Tx)
+				return true, nil
+			}))
+			return res
+		}
+		newerThan := func(index uint32) func(*state.AccountTransaction) bool {
+			return func(tr *state.AccountTransaction) bool { return tr.Block > index }
+		}
+		require.Equal(t, []util.Uint256{tx2.Hash(), tx1.Hash()}, getHashes(t, newerThan(b2.Index), 0))
+		require.Equal(t, []util.Uint256{tx1.Hash()}, getHashes(t, newerThan(b1.Index), 0))
+		require.Equal(t, []util.Uint256{tx1.Hash()}, getHashes(t, nil, 1))
+		require.Nil(t, getHashes(t, newerThan(b1.Index), 1))
+		require.Nil(t, getHashes(t, newerThan(b1.Index-1), 0))
+	})
+
+	t.Run("remove untraceable", func(t *testing.T) {
+		require.NoError(t, bc.AddBlock(bc.newBlock()))
+		require.Equal(t, []state.AccountTransaction{
+			{Block: b2.Index, Timestamp: b2.Timestamp, Tx: tx2.Hash()},
+		}, getTransactions(t, neoOwner))
+	})
+}
+
+func TestNotificationsIndex(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		bc := newTestChain(t)
+		err := bc.ForEachNotification(bc.contracts.NEO.Hash, "Transfer", nil, 0, func(*state.IndexedNotification) (bool, error) {
+			return true, nil
+		})
+		require.True(t, errors.Is(err, ErrNotificationsIndexDisabled))
+	})
+
+	bc := newTestChainWithCustomCfg(t, func(c *config.Config) {
+		c.ApplicationConfiguration.NotificationsIndex = true
+		c.ProtocolConfiguration.MaxTraceableBlocks = 2
+		c.ProtocolConfiguration.RemoveUntraceableBlocks = true
+	})
+	getNotifications := func(t *testing.T, contract util.Uint160, name string) []state.IndexedNotification {
+		var res []state.IndexedNotification
+		require.NoError(t, bc.ForEachNotification(contract, name, nil, 0, func(n *state.IndexedNotification) (bool, error) {
+			res = append(res, *n)
+			return true, nil
+		}))
+		return res
+	}
+
+	tx, err := testchain.NewTransferFromOwner(bc, bc.contracts.NEO.Hash, util.Uint160{}, 1, 0, bc.BlockHeight()+1)
+	require.NoError(t, err)
+	b := bc.newBlock(tx)
+	require.NoError(t, bc.AddBlock(b))
+
+	// Genesis block mints all NEO, so there is one more Transfer event.
+	neoTransfers := getNotifications(t, bc.contracts.NEO.Hash, "Transfer")
+	require.Equal(t, 2, len(neoTransfers))
+	require.Equal(t, uint32(0), neoTransfers[1].Block)
+	require.Equal(t, b.Index, neoTransfers[0].Block)
+	require.Equal(t, tx.Hash(), neoTransfers[0].Container)
+	require.Equal(t, bc.contracts.NEO.Hash, neoTransfers[0].Event.ScriptHash)
+	require.Equal(t, "Transfer", neoTransfers[0].Event.Name)
+	aer, err := bc.GetAppExecResults(tx.Hash(), trigger.Application)
+	require.NoError(t, err)
+	require.Equal(t, aer[0].Events[neoTransfers[0].Index], neoTransfers[0].Event)
+
+	require.Equal(t, neoTransfers, getNotifications(t, bc.contracts.NEO.Hash, ""))
+	require.Equal(t, 0, len(getNotifications(t, bc.contracts.NEO.Hash, "Unknown")))
+
+	gasTransfers := getNotifications(t, bc.contracts.GAS.Hash, "Transfer")
+	require.True(t, len(gasTransfers) > 1)
+	// Fees are distributed in PostPersist, so it's the newest notification.
+	require.Equal(t, b.Hash(), gasTransfers[0].Container)
+	for i := 1; i < len(gasTransfers); i++ {
+		require.True(t, gasTransfers[i-1].Block >= gasTransfers[i].Block)
+	}
+
+	t.Run("newer and skip", func(t *testing.T) {
+		getBlocks := func(t *testing.T, newer func(*state.IndexedNotification) bool, skip int) []uint32 {
+			var res []uint32
+			require.NoError(t, bc.ForEachNotification(bc.contracts.NEO.Hash, "Transfer", newer, skip, func(n *state.IndexedNotification) (bool, error) {
+				res = append(res, n.Block)
+				return true, nil
+			}))
+			return res
+		}
+		newerThan := func(index uint32) func(*state.IndexedNotification) bool {
+			return func(n *state.IndexedNotification) bool { return n.Block > index }
+		}
+		require.Equal(t, []uint32{b.Index, 0}, getBlocks(t, newerThan(b.Index), 0))
+		require.Equal(t, []uint32{0}, getBlocks(t, newerThan(b.Index-1), 0))
+		require.Equal(t, []uint32{0}, getBlocks(t, nil, 1))
+		require.Nil(t, getBlocks(t, newerThan(b.Index), 2))
+	})
+
+	t.Run("remove untraceable", func(t *testing.T) {
+		require.NoError(t, bc.AddBlock(bc.newBlock()))
+		require.NoError(t, bc.AddBlock(bc.newBlock()))
+		// Genesis block is never removed.
+		require.Equal(t, neoTransfers[1:], getNotifications(t, bc.contracts.NEO.Hash, "Transfer"))
+		for _, n := range getNotifications(t, bc.contracts.GAS.Hash, "") {
+			require.True(t, n.Block == 0 || n.Block > b.Index)
+		}
+	})
+}
+
+func TestPreverifyBlock(t *testing.T) {
+	bc := newTestChain(t)
+
+	tx, err := testchain.NewTransferFromOwner(bc, bc.contracts.NEO.Hash, util.Uint160{}, 1, 0, bc.BlockHeight()+1)
+	require.NoError(t, err)
+	b := bc.newBlock(tx)
+
+	bc.PreverifyBlock(b)
+	require.Equal(t, 2, len(bc.witnesses.m))
+	require.NoError(t, bc.AddBlock(b))
+	require.True(t, bc.witnesses.isEmpty())
+
+	t.Run("invalid witness", func(t *testing.T) {
+		tx, err := testchain.NewTransferFromOwner(bc, bc.contracts.NEO.Hash, util.Uint160{}, 1, 0, bc.BlockHeight()+1)
+		require.NoError(t, err)
+		b := bc.newBlock(tx)
+		tx.Scripts[0].InvocationScript[10] ^= 0xff
+
+		bc.PreverifyBlock(b)
+		require.Equal(t, 1, len(bc.witnesses.m))
+		require.Error(t, bc.AddBlock(b))
+		require.True(t, bc.witnesses.isEmpty())
+	})
+}
+
 func TestInvalidNotification(t *testing.T) {
 	bc := newTestChain(t)
 
@@ -1,7 +1,9 @@
 package blockchainer
 
 import (
+	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/util"
 )
@@ -9,8 +11,13 @@ import (
 // StateRoot represents local state root module.
 type StateRoot interface {
 	AddStateRoot(root *state.MPTRoot) error
+	CurrentLocalHeight() uint32
 	CurrentLocalStateRoot() util.Uint256
 	CurrentValidatedHeight() uint32
+	FindStates(root util.Uint256, prefix, from []byte, max int) ([]storage.KeyValue, error)
+	GetStateDiff(oldRoot, newRoot util.Uint256, after []byte, max int) ([]mpt.DiffItem, bool, error)
+	GetState(root util.Uint256, key []byte) ([]byte, error)
+	GetStatePathProof(root util.Uint256, key []byte) ([][]byte, error)
 	GetStateProof(root util.Uint256, key []byte) ([][]byte, error)
 	GetStateRoot(height uint32) (*state.MPTRoot, error)
 	GetStateValidators(height uint32) keys.PublicKeys
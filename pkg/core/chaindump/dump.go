@@ -1,7 +1,11 @@
 package chaindump
 
 import (
+	"errors"
 	"fmt"
+	gio "io"
+	"runtime"
+	"sync"
 
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
@@ -12,14 +16,10 @@ import (
 // Note: header needs to be written separately by client.
 func Dump(bc blockchainer.Blockchainer, w *io.BinWriter, start, count uint32) error {
 	for i := start; i < start+count; i++ {
-		bh := bc.GetHeaderHash(int(i))
-		b, err := bc.GetBlock(bh)
+		bytes, err := getBlockBytes(bc, i)
 		if err != nil {
 			return err
 		}
-		buf := io.NewBufBinWriter()
-		b.EncodeBinary(buf.BinWriter)
-		bytes := buf.Bytes()
 		w.WriteU32LE(uint32(len(bytes)))
 		w.WriteBytes(bytes)
 		if w.Err != nil {
@@ -29,41 +29,229 @@ func Dump(bc blockchainer.Blockchainer, w *io.BinWriter, start, count uint32) er
 	return nil
 }
 
+// DumpV2 writes count blocks from start to the provided writer using V2
+// dump format (including header and index).
+func DumpV2(bc blockchainer.Blockchainer, w gio.Writer, start, count uint32) error {
+	dw, err := NewWriter(w, start, count)
+	if err != nil {
+		return err
+	}
+	for i := start; i < start+count; i++ {
+		bytes, err := getBlockBytes(bc, i)
+		if err != nil {
+			return err
+		}
+		if err := dw.WriteBlock(bytes); err != nil {
+			return err
+		}
+	}
+	return dw.Close()
+}
+
+func getBlockBytes(bc blockchainer.Blockchainer, index uint32) ([]byte, error) {
+	bh := bc.GetHeaderHash(int(index))
+	b, err := bc.GetBlock(bh)
+	if err != nil {
+		return nil, err
+	}
+	buf := io.NewBufBinWriter()
+	b.EncodeBinary(buf.BinWriter)
+	return buf.Bytes(), buf.Err
+}
+
+// Preverifier is an optional interface that can be implemented by the chain
+// passed to Restore. If it's implemented, blocks are verified concurrently
+// before they're added to the chain.
+type Preverifier interface {
+	PreverifyBlock(*block.Block)
+}
+
+// Persister is an optional interface that can be implemented by the chain
+// passed to Restore. If it's implemented, changes made by restored blocks are
+// flushed to the persistent storage every restorePersistInterval blocks (each
+// flush is a single batch) and after the last block.
+type Persister interface {
+	Persist() error
+}
+
+const (
+	// restoreQueueSize is the maximum number of blocks read and decoded in
+	// advance.
+	restoreQueueSize = 256
+	// restorePersistInterval is the number of blocks restored between
+	// flushes.
+	restorePersistInterval = 1000
+)
+
+// restoreJob is a single block passing through the restore pipeline.
+type restoreJob struct {
+	// seq is the sequence number of the block in the current run.
+	seq   uint32
+	raw   []byte
+	block *block.Block
+	err   error
+	// done is closed when the block is decoded (and verified).
+	done chan struct{}
+}
+
 // Restore restores blocks from provided reader.
 // f is called after addition of every block.
+// Blocks are read sequentially, but decoded and preverified (if the chain
+// implements Preverifier interface) by a set of concurrent workers ahead of
+// AddBlock calls, blocks are still added one by one in their original order.
+// Exactly skip+count blocks are read from the reader (unless there is an
+// error).
 func Restore(bc blockchainer.Blockchainer, r *io.BinReader, skip, count uint32, f func(b *block.Block) error) error {
-	readBlock := func(r *io.BinReader) ([]byte, error) {
+	readBlock := func() ([]byte, error) {
 		var size = r.ReadU32LE()
 		buf := make([]byte, size)
 		r.ReadBytes(buf)
 		return buf, r.Err
 	}
 
-	i := uint32(0)
-	for ; i < skip; i++ {
-		_, err := readBlock(r)
+	for i := uint32(0); i < skip; i++ {
+		_, err := readBlock()
 		if err != nil {
 			return err
 		}
 	}
+	return restore(bc, readBlock, count, skip == 0, f)
+}
 
-	stateRootInHeader := bc.GetConfig().StateRootInHeader
+// RestoreFrom restores count blocks starting from the block with the given
+// index using dump reader of any supported version. It works the same way
+// Restore does, but seeks to the required block using dump index if it's
+// available.
+func RestoreFrom(bc blockchainer.Blockchainer, r *Reader, start, count uint32, f func(b *block.Block) error) error {
+	if err := r.Seek(start); err != nil {
+		return err
+	}
+	return restore(bc, r.Next, count, start == 0, f)
+}
 
-	for ; i < skip+count; i++ {
-		buf, err := readBlock(r)
+// Verify checks count blocks starting from the block with the given index
+// without adding them to any chain. For V2 dumps it checks chunk checksums,
+// for any dump it checks that blocks can be decoded, have proper indexes and
+// are properly chained.
+func Verify(r *Reader, stateRootInHeader bool, start, count uint32) error {
+	if err := r.Seek(start); err != nil {
+		return err
+	}
+	var prev *block.Block
+	for i := start; i < start+count; i++ {
+		buf, err := r.Next()
 		if err != nil {
-			return err
+			if errors.Is(err, gio.EOF) {
+				err = gio.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("block %d: %w", i, err)
 		}
 		b := block.New(stateRootInHeader)
-		r := io.NewBinReaderFromBuf(buf)
-		b.DecodeBinary(r)
-		if r.Err != nil {
-			return r.Err
+		br := io.NewBinReaderFromBuf(buf)
+		b.DecodeBinary(br)
+		if br.Err != nil {
+			return fmt.Errorf("block %d: %w", i, br.Err)
+		}
+		if b.Index != i {
+			return fmt.Errorf("block %d has unexpected index %d", i, b.Index)
+		}
+		if !b.MerkleRoot.Equals(b.ComputeMerkleRoot()) {
+			return fmt.Errorf("block %d: MerkleRoot mismatch", i)
 		}
-		if b.Index != 0 || i != 0 || skip != 0 {
-			err = bc.AddBlock(b)
+		if prev != nil && !b.PrevHash.Equals(prev.Hash()) {
+			return fmt.Errorf("block %d: previous block hash mismatch", i)
+		}
+		prev = b
+	}
+	return nil
+}
+
+// restore adds count blocks returned by next to the chain, genesis block
+// (if it's the first one and skipGenesis is set) is not added.
+func restore(bc blockchainer.Blockchainer, next func() ([]byte, error), count uint32, skipGenesis bool, f func(b *block.Block) error) error {
+	var (
+		stateRootInHeader = bc.GetConfig().StateRootInHeader
+		pre, _            = bc.(Preverifier)
+		per, _            = bc.(Persister)
+		stop              = make(chan struct{})
+		jobs              = make(chan *restoreJob, restoreQueueSize)
+		queue             = make(chan *restoreJob, restoreQueueSize)
+		wg                sync.WaitGroup
+	)
+	// Genesis block is only checked for equality with the one the chain
+	// already has.
+	isGenesis := func(b *block.Block, seq uint32) bool {
+		return b.Index == 0 && seq == 0 && skipGenesis
+	}
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(queue)
+		defer close(jobs)
+		for seq := uint32(0); seq < count; seq++ {
+			job := &restoreJob{seq: seq, done: make(chan struct{})}
+			job.raw, job.err = next()
+			if job.err != nil {
+				close(job.done)
+			}
+			select {
+			case queue <- job:
+			case <-stop:
+				return
+			}
+			if job.err != nil {
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for k := 0; k < runtime.NumCPU(); k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				b := block.New(stateRootInHeader)
+				br := io.NewBinReaderFromBuf(job.raw)
+				b.DecodeBinary(br)
+				job.raw = nil
+				if br.Err != nil {
+					job.err = br.Err
+				} else {
+					job.block = b
+					if pre != nil && !isGenesis(b, job.seq) {
+						pre.PreverifyBlock(b)
+					}
+				}
+				close(job.done)
+			}
+		}()
+	}
+
+	for job := range queue {
+		<-job.done
+		if job.err != nil {
+			return job.err
+		}
+		b := job.block
+		if !isGenesis(b, job.seq) {
+			err := bc.AddBlock(b)
 			if err != nil {
-				return fmt.Errorf("failed to add block %d: %w", i, err)
+				return fmt.Errorf("failed to add block %d: %w", b.Index, err)
+			}
+		}
+		if per != nil && (job.seq+1)%restorePersistInterval == 0 {
+			if err := per.Persist(); err != nil {
+				return fmt.Errorf("failed to persist block %d: %w", b.Index, err)
 			}
 		}
 		if f != nil {
@@ -72,5 +260,10 @@ func Restore(bc blockchainer.Blockchainer, r *io.BinReader, skip, count uint32,
 			}
 		}
 	}
+	if per != nil {
+		if err := per.Persist(); err != nil {
+			return fmt.Errorf("failed to persist restored blocks: %w", err)
+		}
+	}
 	return nil
 }
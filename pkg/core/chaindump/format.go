@@ -0,0 +1,529 @@
+package chaindump
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	gio "io"
+
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/pierrec/lz4"
+)
+
+/*
+V2 dump format consists of:
+ * header: Magic, version (uint32), index of the first block (uint32) and the
+   number of blocks (uint32)
+ * chunks, each chunk starts with a header containing the number of blocks in
+   the chunk (uint32), the index of the first block (uint32), flags (byte),
+   uncompressed and stored data sizes (uint32 both) and CRC32 (Castagnoli)
+   of the stored data (uint32) followed by data itself. Uncompressed chunk
+   data is a sequence of size-prefixed serialized blocks (the same as in V1).
+ * chunk list terminator (uint32 zero)
+ * index: an array of chunk descriptions (the first block index (uint32), the
+   number of blocks (uint32) and chunk offset from the beginning of the file
+   (uint64))
+ * footer: index offset (uint64), the number of index entries (uint32),
+   CRC32 of the index (uint32) and Magic.
+
+All integers are little-endian. V1 format is just the number of blocks
+(uint32) followed by size-prefixed serialized blocks, optionally prepended
+with the index of the first block (uint32) for incremental dumps.
+*/
+
+// Dump format versions.
+const (
+	Version1 = 1
+	Version2 = 2
+)
+
+// Magic is the magic sequence that V2 dumps start and end with.
+var Magic = []byte("NEOGODMP")
+
+const (
+	// ChunkSize is the target size of uncompressed chunk data.
+	ChunkSize = 1 << 20
+	// MaxChunkSize is the maximum allowed size of uncompressed chunk data,
+	// chunks can be bigger than ChunkSize because of big blocks.
+	MaxChunkSize = 64 << 20
+
+	// chunkCompressed flag is set for lz4-compressed chunks.
+	chunkCompressed byte = 1
+
+	// footerSize is the size of V2 dump footer.
+	footerSize = 8 + 4 + 4 + 8
+	// indexEntrySize is the size of a single index entry.
+	indexEntrySize = 4 + 4 + 8
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Various errors returned by dump reader.
+var (
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrInvalidFormat    = errors.New("invalid dump format")
+	ErrNoIndex          = errors.New("dump index is not available")
+)
+
+// ChunkInfo describes a single V2 dump chunk.
+type ChunkInfo struct {
+	First  uint32
+	Blocks uint32
+	Offset uint64
+}
+
+// countingWriter counts the number of bytes written.
+type countingWriter struct {
+	w gio.Writer
+	n uint64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+// Writer writes V2 dumps.
+type Writer struct {
+	out   *countingWriter
+	w     *io.BinWriter
+	chunk *io.BufBinWriter
+	first uint32
+	// blocks is the number of blocks in the current chunk.
+	blocks uint32
+	start  uint32
+	count  uint32
+	next   uint32
+	index  []ChunkInfo
+}
+
+// NewWriter writes V2 dump header for count blocks starting from start to w
+// and returns a Writer to write these blocks with.
+func NewWriter(w gio.Writer, start, count uint32) (*Writer, error) {
+	out := &countingWriter{w: w}
+	dw := &Writer{
+		out:   out,
+		w:     io.NewBinWriterFromIO(out),
+		chunk: io.NewBufBinWriter(),
+		first: start,
+		start: start,
+		count: count,
+		next:  start,
+	}
+	dw.w.WriteBytes(Magic)
+	dw.w.WriteU32LE(Version2)
+	dw.w.WriteU32LE(start)
+	dw.w.WriteU32LE(count)
+	return dw, dw.w.Err
+}
+
+// WriteBlock adds the next serialized block to the dump.
+func (w *Writer) WriteBlock(b []byte) error {
+	if w.next-w.start >= w.count {
+		return fmt.Errorf("can't write more than %d blocks", w.count)
+	}
+	w.chunk.WriteU32LE(uint32(len(b)))
+	w.chunk.WriteBytes(b)
+	if w.chunk.Err != nil {
+		return w.chunk.Err
+	}
+	w.blocks++
+	w.next++
+	if w.chunk.Len() >= ChunkSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// flush writes the current chunk.
+func (w *Writer) flush() error {
+	if w.blocks == 0 {
+		return nil
+	}
+	raw := w.chunk.Bytes()
+	if len(raw) > MaxChunkSize {
+		return fmt.Errorf("chunk is too big: %d", len(raw))
+	}
+	data := make([]byte, lz4.CompressBlockBound(len(raw)))
+	size, err := lz4.CompressBlock(raw, data, nil)
+	if err != nil {
+		return err
+	}
+	var flags byte
+	// Zero size means the data is not compressible.
+	if size != 0 && size < len(raw) {
+		data = data[:size]
+		flags |= chunkCompressed
+	} else {
+		data = raw
+	}
+	w.index = append(w.index, ChunkInfo{
+		First:  w.first,
+		Blocks: w.blocks,
+		Offset: w.out.n,
+	})
+	w.w.WriteU32LE(w.blocks)
+	w.w.WriteU32LE(w.first)
+	w.w.WriteB(flags)
+	w.w.WriteU32LE(uint32(len(raw)))
+	w.w.WriteU32LE(uint32(len(data)))
+	w.w.WriteU32LE(crc32.Checksum(data, crcTable))
+	w.w.WriteBytes(data)
+	w.chunk.Reset()
+	w.first = w.next
+	w.blocks = 0
+	return w.w.Err
+}
+
+// Close flushes the last chunk and writes the index. It doesn't close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if w.next-w.start != w.count {
+		return fmt.Errorf("%d blocks written, %d expected", w.next-w.start, w.count)
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+	w.w.WriteU32LE(0)
+
+	idx := io.NewBufBinWriter()
+	for _, c := range w.index {
+		idx.WriteU32LE(c.First)
+		idx.WriteU32LE(c.Blocks)
+		idx.WriteU64LE(c.Offset)
+	}
+	offset := w.out.n
+	w.w.WriteBytes(idx.Bytes())
+	w.w.WriteU64LE(offset)
+	w.w.WriteU32LE(uint32(len(w.index)))
+	w.w.WriteU32LE(crc32.Checksum(idx.Bytes(), crcTable))
+	w.w.WriteBytes(Magic)
+	return w.w.Err
+}
+
+// Reader reads blocks from dumps of any supported version.
+type Reader struct {
+	// Version is the dump format version.
+	Version int
+	// Start is the index of the first block in the dump.
+	Start uint32
+	// Count is the number of blocks in the dump.
+	Count uint32
+
+	// seeker is set for seekable V2 inputs.
+	seeker gio.ReadSeeker
+	br     *io.BinReader
+	// chunk is the current chunk data (V2 only).
+	chunk *io.BinReader
+	// left is the number of blocks left in the current chunk (V2 only).
+	left uint32
+	// pending is the header of the chunk which data is not yet read.
+	pending *chunkHeader
+	// next is the index of the next block.
+	next  uint32
+	index []ChunkInfo
+}
+
+// NewReader detects dump version and reads its header. Incremental flag is
+// only used for V1 dumps (V2 always contains the index of the first block).
+// V2 dumps can be read with random access (see Seek) if r implements
+// io.ReadSeeker.
+func NewReader(r gio.Reader, incremental bool) (*Reader, error) {
+	head := make([]byte, len(Magic))
+	n, err := gio.ReadFull(r, head)
+	if err != nil && !errors.Is(err, gio.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+	rd := new(Reader)
+	if !bytes.Equal(head, Magic) {
+		rd.Version = Version1
+		rd.br = io.NewBinReaderFromIO(bufio.NewReader(gio.MultiReader(bytes.NewReader(head), r)))
+		if incremental {
+			rd.Start = rd.br.ReadU32LE()
+		}
+		rd.Count = rd.br.ReadU32LE()
+		rd.next = rd.Start
+		return rd, rd.br.Err
+	}
+	if s, ok := r.(gio.ReadSeeker); ok {
+		if _, err := s.Seek(0, gio.SeekCurrent); err == nil {
+			rd.seeker = s
+		}
+	}
+	rd.br = io.NewBinReaderFromIO(bufio.NewReader(r))
+	rd.Version = int(rd.br.ReadU32LE())
+	rd.Start = rd.br.ReadU32LE()
+	rd.Count = rd.br.ReadU32LE()
+	if rd.br.Err != nil {
+		return nil, rd.br.Err
+	}
+	if rd.Version != Version2 {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidFormat, rd.Version)
+	}
+	rd.next = rd.Start
+	return rd, nil
+}
+
+// Next returns the next serialized block, io.EOF is returned after the last
+// block.
+func (r *Reader) Next() ([]byte, error) {
+	if r.next-r.Start >= r.Count {
+		return nil, gio.EOF
+	}
+	if r.Version == Version1 {
+		b, err := readSized(r.br, MaxChunkSize)
+		if err == nil {
+			r.next++
+		}
+		return b, err
+	}
+	if r.left == 0 {
+		if err := r.readChunk(false); err != nil {
+			return nil, err
+		}
+	}
+	b, err := readSized(r.chunk, MaxChunkSize)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	r.left--
+	r.next++
+	return b, nil
+}
+
+// Seek positions the reader at the block with the given index, so that it
+// will be returned by the next call to Next. Seeking backwards is only
+// possible for seekable V2 inputs, seekable V2 inputs use dump index to find
+// the chunk with the required block, other dumps are read sequentially up to
+// the block requested.
+func (r *Reader) Seek(index uint32) error {
+	if index < r.Start || index-r.Start > r.Count {
+		return fmt.Errorf("block %d is out of dump range (%d blocks starting from %d)", index, r.Count, r.Start)
+	}
+	if index == r.next {
+		return nil
+	}
+	if r.seeker != nil {
+		return r.seekIndex(index)
+	}
+	if index < r.next {
+		return fmt.Errorf("can't seek backwards to %d (current block is %d)", index, r.next)
+	}
+	for r.next < index {
+		if r.Version == Version2 && r.left == 0 {
+			if err := r.readChunk(true); err != nil {
+				return err
+			}
+			// The whole chunk is before the index required.
+			if r.next+r.left <= index {
+				if err := r.skipChunkData(); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.readChunkData(); err != nil {
+				return err
+			}
+		}
+		if _, err := r.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seekIndex seeks to the block with the given index using dump index.
+func (r *Reader) seekIndex(index uint32) error {
+	chunks, err := r.Index()
+	if err != nil {
+		return err
+	}
+	var target *ChunkInfo
+	for i := range chunks {
+		if chunks[i].First <= index && index-chunks[i].First < chunks[i].Blocks {
+			target = &chunks[i]
+			break
+		}
+	}
+	if target == nil {
+		// Seeking right after the last block.
+		if index-r.Start != r.Count {
+			return fmt.Errorf("%w: block %d is not in the index", ErrInvalidFormat, index)
+		}
+		r.next, r.left = index, 0
+		return nil
+	}
+	if _, err := r.seeker.Seek(int64(target.Offset), gio.SeekStart); err != nil {
+		return err
+	}
+	r.br = io.NewBinReaderFromIO(bufio.NewReader(r.seeker))
+	r.next, r.left = target.First, 0
+	if err := r.readChunk(false); err != nil {
+		return err
+	}
+	for r.next < index {
+		if _, err := r.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Index returns V2 dump index, it's only available for seekable inputs.
+func (r *Reader) Index() ([]ChunkInfo, error) {
+	if r.index != nil {
+		return r.index, nil
+	}
+	if r.seeker == nil {
+		return nil, ErrNoIndex
+	}
+	cur, err := r.seeker.Seek(0, gio.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	// Restore position, but bufio buffer is not affected by it.
+	defer func() { _, _ = r.seeker.Seek(cur, gio.SeekStart) }()
+
+	end, err := r.seeker.Seek(-footerSize, gio.SeekEnd)
+	if err != nil {
+		return nil, fmt.Errorf("%w: can't read footer: %v", ErrInvalidFormat, err)
+	}
+	footer := make([]byte, footerSize)
+	if _, err := gio.ReadFull(r.seeker, footer); err != nil {
+		return nil, err
+	}
+	fr := io.NewBinReaderFromBuf(footer)
+	offset := fr.ReadU64LE()
+	n := fr.ReadU32LE()
+	sum := fr.ReadU32LE()
+	if !bytes.Equal(footer[footerSize-len(Magic):], Magic) {
+		return nil, fmt.Errorf("%w: invalid footer", ErrInvalidFormat)
+	}
+	if offset+uint64(n)*indexEntrySize != uint64(end) {
+		return nil, fmt.Errorf("%w: invalid index offset", ErrInvalidFormat)
+	}
+	if _, err := r.seeker.Seek(int64(offset), gio.SeekStart); err != nil {
+		return nil, err
+	}
+	data := make([]byte, uint64(n)*indexEntrySize)
+	if _, err := gio.ReadFull(r.seeker, data); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(data, crcTable) != sum {
+		return nil, fmt.Errorf("%w: index", ErrChecksumMismatch)
+	}
+	ir := io.NewBinReaderFromBuf(data)
+	index := make([]ChunkInfo, n)
+	for i := range index {
+		index[i].First = ir.ReadU32LE()
+		index[i].Blocks = ir.ReadU32LE()
+		index[i].Offset = ir.ReadU64LE()
+	}
+	r.index = index
+	return index, nil
+}
+
+// chunkHeader is a V2 chunk header.
+type chunkHeader struct {
+	blocks  uint32
+	first   uint32
+	flags   byte
+	rawSize uint32
+	size    uint32
+	sum     uint32
+}
+
+// readChunk reads the next chunk header and (if headerOnly is not set) its
+// data.
+func (r *Reader) readChunk(headerOnly bool) error {
+	h := chunkHeader{blocks: r.br.ReadU32LE()}
+	if r.br.Err != nil {
+		return r.br.Err
+	}
+	if h.blocks == 0 {
+		return fmt.Errorf("%w: unexpected end of chunks at block %d", ErrInvalidFormat, r.next)
+	}
+	h.first = r.br.ReadU32LE()
+	h.flags = r.br.ReadB()
+	h.rawSize = r.br.ReadU32LE()
+	h.size = r.br.ReadU32LE()
+	h.sum = r.br.ReadU32LE()
+	if r.br.Err != nil {
+		return r.br.Err
+	}
+	if h.first != r.next {
+		return fmt.Errorf("%w: chunk starts at %d, expected %d", ErrInvalidFormat, h.first, r.next)
+	}
+	if h.rawSize > MaxChunkSize || h.size > MaxChunkSize {
+		return fmt.Errorf("%w: chunk is too big", ErrInvalidFormat)
+	}
+	r.left = h.blocks
+	r.pending = &h
+	if headerOnly {
+		return nil
+	}
+	return r.readChunkData()
+}
+
+// skipChunkData discards the data of the chunk which header was read last
+// along with all of its blocks.
+func (r *Reader) skipChunkData() error {
+	h := r.pending
+	r.pending = nil
+	r.br.ReadBytes(make([]byte, h.size))
+	if r.br.Err != nil {
+		return r.br.Err
+	}
+	r.next += r.left
+	r.left = 0
+	return nil
+}
+
+// readChunkData reads and checks the data of the chunk which header was read
+// last.
+func (r *Reader) readChunkData() error {
+	h := r.pending
+	if h == nil {
+		return nil
+	}
+	r.pending = nil
+	data := make([]byte, h.size)
+	r.br.ReadBytes(data)
+	if r.br.Err != nil {
+		return r.br.Err
+	}
+	if crc32.Checksum(data, crcTable) != h.sum {
+		return fmt.Errorf("%w: chunk starting at %d", ErrChecksumMismatch, h.first)
+	}
+	if h.flags&chunkCompressed != 0 {
+		raw := make([]byte, h.rawSize)
+		n, err := lz4.UncompressBlock(data, raw)
+		if err != nil {
+			return fmt.Errorf("%w: chunk starting at %d: %v", ErrInvalidFormat, h.first, err)
+		}
+		if uint32(n) != h.rawSize {
+			return fmt.Errorf("%w: chunk starting at %d: size mismatch", ErrInvalidFormat, h.first)
+		}
+		data = raw
+	}
+	r.chunk = io.NewBinReaderFromBuf(data)
+	return nil
+}
+
+// readSized reads size-prefixed data.
+func readSized(r *io.BinReader, max uint32) ([]byte, error) {
+	var size = r.ReadU32LE()
+	if r.Err == nil && size > max {
+		r.Err = fmt.Errorf("%w: block is too big (%d)", ErrInvalidFormat, size)
+	}
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	buf := make([]byte, size)
+	r.ReadBytes(buf)
+	return buf, r.Err
+}
@@ -0,0 +1,156 @@
+package chaindump
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	gio "io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testBlocks returns serialized "blocks", they're not real blocks, but
+// Reader and Writer don't care. Some are big enough to fill several chunks.
+func testBlocks(start uint32, n int) [][]byte {
+	res := make([][]byte, n)
+	for i := range res {
+		size := 100
+		if i%7 == 3 {
+			size = ChunkSize / 3
+		}
+		res[i] = make([]byte, size)
+		for j := range res[i] {
+			res[i][j] = byte(j*int(start+uint32(i))) ^ byte(j>>8)
+		}
+		binary.LittleEndian.PutUint32(res[i], start+uint32(i))
+	}
+	return res
+}
+
+func writeV2(t *testing.T, start uint32, blocks [][]byte) []byte {
+	buf := new(bytes.Buffer)
+	w, err := NewWriter(buf, start, uint32(len(blocks)))
+	require.NoError(t, err)
+	for _, b := range blocks {
+		require.NoError(t, w.WriteBlock(b))
+	}
+	require.Error(t, w.WriteBlock(blocks[0]))
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// onlyReader hides Seek method of the underlying reader.
+type onlyReader struct {
+	gio.Reader
+}
+
+func TestWriterReader(t *testing.T) {
+	const start = 10
+	blocks := testBlocks(start, 50)
+	data := writeV2(t, start, blocks)
+
+	t.Run("unfinished", func(t *testing.T) {
+		w, err := NewWriter(new(bytes.Buffer), 0, 2)
+		require.NoError(t, err)
+		require.NoError(t, w.WriteBlock(blocks[0]))
+		require.Error(t, w.Close())
+	})
+
+	checkSequential := func(t *testing.T, r *Reader, from int) {
+		for i := from; i < len(blocks); i++ {
+			b, err := r.Next()
+			require.NoError(t, err)
+			require.Equal(t, blocks[i], b)
+		}
+		_, err := r.Next()
+		require.True(t, errors.Is(err, gio.EOF))
+	}
+	for name, wrap := range map[string]func([]byte) gio.Reader{
+		"seekable":     func(d []byte) gio.Reader { return bytes.NewReader(d) },
+		"not seekable": func(d []byte) gio.Reader { return onlyReader{bytes.NewReader(d)} },
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewReader(wrap(data), false)
+			require.NoError(t, err)
+			require.Equal(t, Version2, r.Version)
+			require.Equal(t, uint32(start), r.Start)
+			require.Equal(t, uint32(len(blocks)), r.Count)
+			checkSequential(t, r, 0)
+
+			for _, i := range []int{0, 1, 17, 24, 49, 50} {
+				r, err := NewReader(wrap(data), false)
+				require.NoError(t, err)
+				require.NoError(t, r.Seek(start+uint32(i)))
+				checkSequential(t, r, i)
+			}
+			r, err = NewReader(wrap(data), false)
+			require.NoError(t, err)
+			require.Error(t, r.Seek(start-1))
+			require.Error(t, r.Seek(start+uint32(len(blocks))+1))
+		})
+	}
+	t.Run("index", func(t *testing.T) {
+		r, err := NewReader(bytes.NewReader(data), false)
+		require.NoError(t, err)
+		index, err := r.Index()
+		require.NoError(t, err)
+		require.True(t, len(index) > 1)
+		require.Equal(t, uint32(start), index[0].First)
+		var n uint32
+		for _, c := range index {
+			n += c.Blocks
+		}
+		require.Equal(t, uint32(len(blocks)), n)
+
+		// Seeking backwards.
+		require.NoError(t, r.Seek(start+30))
+		require.NoError(t, r.Seek(start+5))
+		checkSequential(t, r, 5)
+
+		r, err = NewReader(onlyReader{bytes.NewReader(data)}, false)
+		require.NoError(t, err)
+		_, err = r.Index()
+		require.True(t, errors.Is(err, ErrNoIndex))
+	})
+	t.Run("corrupted", func(t *testing.T) {
+		bad := make([]byte, len(data))
+		copy(bad, data)
+		// Somewhere in the first chunk data.
+		bad[len(Magic)+12+21+10] ^= 0xff
+		r, err := NewReader(bytes.NewReader(bad), false)
+		require.NoError(t, err)
+		_, err = r.Next()
+		require.True(t, errors.Is(err, ErrChecksumMismatch))
+
+		copy(bad, data)
+		bad[len(bad)-footerSize-1] ^= 0xff
+		r, err = NewReader(bytes.NewReader(bad), false)
+		require.NoError(t, err)
+		require.True(t, errors.Is(r.Seek(start+20), ErrChecksumMismatch))
+	})
+	t.Run("v1", func(t *testing.T) {
+		for _, incremental := range []bool{false, true} {
+			buf := new(bytes.Buffer)
+			if incremental {
+				require.NoError(t, binary.Write(buf, binary.LittleEndian, uint32(start)))
+			}
+			require.NoError(t, binary.Write(buf, binary.LittleEndian, uint32(len(blocks))))
+			for _, b := range blocks {
+				require.NoError(t, binary.Write(buf, binary.LittleEndian, uint32(len(b))))
+				buf.Write(b)
+			}
+			r, err := NewReader(bytes.NewReader(buf.Bytes()), incremental)
+			require.NoError(t, err)
+			require.Equal(t, Version1, r.Version)
+			require.Equal(t, uint32(len(blocks)), r.Count)
+			if !incremental {
+				require.Equal(t, uint32(0), r.Start)
+				continue
+			}
+			require.Equal(t, uint32(start), r.Start)
+			require.NoError(t, r.Seek(start+20))
+			checkSequential(t, r, 20)
+		}
+	})
+}
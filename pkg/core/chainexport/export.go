@@ -0,0 +1,259 @@
+/*
+Package chainexport allows to export chain data (blocks, transactions, signers,
+execution results, notifications and NEP-17 transfers) into a set of
+line-oriented files that can be easily loaded into external analytical tools.
+*/
+package chainexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/bigint"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract/trigger"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+// Export writes data for count blocks starting from start to the provided
+// writer. Blocks are processed one by one, so the amount of memory used
+// doesn't depend on the number of blocks exported.
+func Export(bc blockchainer.Blockchainer, w Writer, start, count uint32) error {
+	for i := start; i < start+count; i++ {
+		bh := bc.GetHeaderHash(int(i))
+		b, err := bc.GetBlock(bh)
+		if err != nil {
+			return fmt.Errorf("failed to get block %d: %w", i, err)
+		}
+		if err := exportBlock(bc, w, b); err != nil {
+			return fmt.Errorf("failed to export block %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func exportBlock(bc blockchainer.Blockchainer, w Writer, b *block.Block) error {
+	err := w.Write(&Block{
+		Index:         b.Index,
+		Hash:          hashString(b.Hash()),
+		Timestamp:     b.Timestamp,
+		Version:       b.Version,
+		PrevHash:      hashString(b.PrevHash),
+		MerkleRoot:    hashString(b.MerkleRoot),
+		Nonce:         b.Nonce,
+		Primary:       b.PrimaryIndex,
+		NextConsensus: address.Uint160ToString(b.NextConsensus),
+		Size:          io.GetVarSize(b),
+		TxCount:       len(b.Transactions),
+	})
+	if err != nil {
+		return err
+	}
+	aers, err := bc.GetAppExecResults(b.Hash(), trigger.All)
+	if err != nil {
+		return fmt.Errorf("failed to get block execution results: %w", err)
+	}
+	for i := range aers {
+		if err := exportExecution(w, b.Index, &aers[i]); err != nil {
+			return err
+		}
+	}
+	for i, tx := range b.Transactions {
+		if err := exportTransaction(bc, w, b, i, tx); err != nil {
+			return fmt.Errorf("transaction %s: %w", tx.Hash().StringLE(), err)
+		}
+	}
+	return nil
+}
+
+func exportTransaction(bc blockchainer.Blockchainer, w Writer, b *block.Block, index int, tx *transaction.Transaction) error {
+	h := hashString(tx.Hash())
+	err := w.Write(&Transaction{
+		Hash:            h,
+		BlockIndex:      b.Index,
+		BlockTime:       b.Timestamp,
+		TxIndex:         index,
+		Version:         tx.Version,
+		Nonce:           tx.Nonce,
+		Sender:          address.Uint160ToString(tx.Sender()),
+		SystemFee:       tx.SystemFee,
+		NetworkFee:      tx.NetworkFee,
+		ValidUntilBlock: tx.ValidUntilBlock,
+		Size:            tx.Size(),
+		Script:          tx.Script,
+	})
+	if err != nil {
+		return err
+	}
+	for i := range tx.Signers {
+		scopes, err := json.Marshal(tx.Signers[i].Scopes)
+		if err != nil {
+			return err
+		}
+		var s string
+		if err := json.Unmarshal(scopes, &s); err != nil {
+			return err
+		}
+		err = w.Write(&Signer{
+			TxHash:     h,
+			BlockIndex: b.Index,
+			Index:      i,
+			Account:    address.Uint160ToString(tx.Signers[i].Account),
+			Scopes:     s,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	aers, err := bc.GetAppExecResults(tx.Hash(), trigger.Application)
+	if err != nil {
+		return fmt.Errorf("failed to get execution results: %w", err)
+	}
+	for i := range aers {
+		if err := exportExecution(w, b.Index, &aers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportExecution(w Writer, index uint32, aer *state.AppExecResult) error {
+	container := hashString(aer.Container)
+	trig := aer.Trigger.String()
+	err := w.Write(&Execution{
+		Container:   container,
+		BlockIndex:  index,
+		Trigger:     trig,
+		VMState:     aer.VMState.String(),
+		GasConsumed: aer.GasConsumed,
+		Exception:   aer.FaultException,
+		Stack:       stackToJSON(aer.Stack),
+	})
+	if err != nil {
+		return err
+	}
+	for i := range aer.Events {
+		ev := &aer.Events[i]
+		err := w.Write(&Notification{
+			Container:  container,
+			BlockIndex: index,
+			Trigger:    trig,
+			Index:      i,
+			Contract:   "0x" + ev.ScriptHash.StringLE(),
+			EventName:  ev.Name,
+			State:      itemToJSON(ev.Item),
+		})
+		if err != nil {
+			return err
+		}
+		// Transfers are only processed for successful executions, the same
+		// way the node itself does it.
+		if aer.VMState != vm.HaltState {
+			continue
+		}
+		from, to, amount, ok := parseTransfer(ev)
+		if !ok {
+			continue
+		}
+		err = w.Write(&Transfer{
+			Container:  container,
+			BlockIndex: index,
+			Trigger:    trig,
+			Index:      i,
+			Asset:      "0x" + ev.ScriptHash.StringLE(),
+			From:       from,
+			To:         to,
+			Amount:     amount.String(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTransfer checks whether the notification is a proper NEP-17 Transfer
+// event and returns its parameters (sender and receiver addresses and amount).
+func parseTransfer(ev *state.NotificationEvent) (string, string, *big.Int, bool) {
+	if ev.Name != "Transfer" || ev.Item == nil {
+		return "", "", nil, false
+	}
+	arr, ok := ev.Item.Value().([]stackitem.Item)
+	if !ok || len(arr) != 3 {
+		return "", "", nil, false
+	}
+	from, ok := parseAddress(arr[0])
+	if !ok {
+		return "", "", nil, false
+	}
+	to, ok := parseAddress(arr[1])
+	if !ok {
+		return "", "", nil, false
+	}
+	amount, ok := arr[2].Value().(*big.Int)
+	if !ok {
+		bs, ok := arr[2].Value().([]byte)
+		if !ok || len(bs) > bigint.MaxBytesLen {
+			return "", "", nil, false
+		}
+		amount = bigint.FromBytes(bs)
+	}
+	return from, to, amount, true
+}
+
+// parseAddress converts stack item to an address, Null item is converted to
+// an empty string (it's used for mints and burns).
+func parseAddress(item stackitem.Item) (string, bool) {
+	v := item.Value()
+	if v == nil {
+		return "", true
+	}
+	bs, ok := v.([]byte)
+	if !ok {
+		return "", false
+	}
+	u, err := util.Uint160DecodeBytesBE(bs)
+	if err != nil {
+		return "", false
+	}
+	return address.Uint160ToString(u), true
+}
+
+func hashString(h util.Uint256) string {
+	return "0x" + h.StringLE()
+}
+
+// errorToJSON returns JSON string describing err, it's used instead of values
+// that can't be converted to JSON.
+func errorToJSON(err error) json.RawMessage {
+	data, _ := json.Marshal("error: " + err.Error())
+	return data
+}
+
+func itemToJSON(item stackitem.Item) json.RawMessage {
+	data, err := stackitem.ToJSONWithTypes(item)
+	if err != nil {
+		return errorToJSON(err)
+	}
+	return data
+}
+
+func stackToJSON(stack []stackitem.Item) json.RawMessage {
+	arr := make([]json.RawMessage, len(stack))
+	for i := range stack {
+		arr[i] = itemToJSON(stack[i])
+	}
+	data, err := json.Marshal(arr)
+	if err != nil {
+		return errorToJSON(err)
+	}
+	return data
+}
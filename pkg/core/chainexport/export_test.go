@@ -0,0 +1,26 @@
+package chainexport
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackToJSON(t *testing.T) {
+	recursive := stackitem.NewArray(nil)
+	recursive.Append(recursive)
+	data := stackToJSON([]stackitem.Item{stackitem.Make(1), recursive})
+
+	var res []json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &res))
+	require.Equal(t, 2, len(res))
+	require.JSONEq(t, `{"type":"Integer","value":"1"}`, string(res[0]))
+	var msg string
+	require.NoError(t, json.Unmarshal(res[1], &msg))
+	require.Equal(t, "error: "+stackitem.ErrRecursive.Error(), msg)
+
+	require.Equal(t, `"error: \"quoted\""`, string(errorToJSON(errors.New(`"quoted"`))))
+}
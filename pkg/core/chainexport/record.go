@@ -0,0 +1,222 @@
+package chainexport
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+)
+
+// Entity is a kind of exported data. Every entity is written into its own
+// file and has its own stable schema.
+type Entity byte
+
+// Entities that can be exported.
+const (
+	Blocks Entity = iota
+	Transactions
+	Signers
+	Executions
+	Notifications
+	Transfers
+)
+
+// Entities contains all exported entities in the order they're listed in.
+var Entities = []Entity{Blocks, Transactions, Signers, Executions, Notifications, Transfers}
+
+// String implements fmt.Stringer interface, the result is used as a file name
+// for the entity.
+func (e Entity) String() string {
+	switch e {
+	case Blocks:
+		return "blocks"
+	case Transactions:
+		return "transactions"
+	case Signers:
+		return "signers"
+	case Executions:
+		return "executions"
+	case Notifications:
+		return "notifications"
+	case Transfers:
+		return "transfers"
+	default:
+		return "unknown"
+	}
+}
+
+// Columns returns CSV column names for the entity, they're the same as JSON
+// field names of the corresponding record.
+func (e Entity) Columns() []string {
+	switch e {
+	case Blocks:
+		return []string{"index", "hash", "timestamp", "version", "prevhash",
+			"merkleroot", "nonce", "primary", "nextconsensus", "size", "txcount"}
+	case Transactions:
+		return []string{"hash", "blockindex", "blocktime", "txindex", "version",
+			"nonce", "sender", "sysfee", "netfee", "validuntilblock", "size", "script"}
+	case Signers:
+		return []string{"txhash", "blockindex", "index", "account", "scopes"}
+	case Executions:
+		return []string{"container", "blockindex", "trigger", "vmstate",
+			"gasconsumed", "exception", "stack"}
+	case Notifications:
+		return []string{"container", "blockindex", "trigger", "index",
+			"contract", "eventname", "state"}
+	case Transfers:
+		return []string{"container", "blockindex", "trigger", "index",
+			"asset", "from", "to", "amount"}
+	default:
+		return nil
+	}
+}
+
+// Record is a single row of exported data.
+type Record interface {
+	// Entity returns the kind of the record.
+	Entity() Entity
+	// Values returns CSV values for the record in the order specified by
+	// Entity().Columns().
+	Values() []string
+}
+
+// Block is an exported block header.
+type Block struct {
+	Index         uint32 `json:"index"`
+	Hash          string `json:"hash"`
+	Timestamp     uint64 `json:"timestamp"`
+	Version       uint32 `json:"version"`
+	PrevHash      string `json:"prevhash"`
+	MerkleRoot    string `json:"merkleroot"`
+	Nonce         uint64 `json:"nonce"`
+	Primary       byte   `json:"primary"`
+	NextConsensus string `json:"nextconsensus"`
+	Size          int    `json:"size"`
+	TxCount       int    `json:"txcount"`
+}
+
+// Transaction is an exported transaction.
+type Transaction struct {
+	Hash            string `json:"hash"`
+	BlockIndex      uint32 `json:"blockindex"`
+	BlockTime       uint64 `json:"blocktime"`
+	TxIndex         int    `json:"txindex"`
+	Version         uint8  `json:"version"`
+	Nonce           uint32 `json:"nonce"`
+	Sender          string `json:"sender"`
+	SystemFee       int64  `json:"sysfee"`
+	NetworkFee      int64  `json:"netfee"`
+	ValidUntilBlock uint32 `json:"validuntilblock"`
+	Size            int    `json:"size"`
+	Script          []byte `json:"script"`
+}
+
+// Signer is an exported transaction signer.
+type Signer struct {
+	TxHash     string `json:"txhash"`
+	BlockIndex uint32 `json:"blockindex"`
+	Index      int    `json:"index"`
+	Account    string `json:"account"`
+	Scopes     string `json:"scopes"`
+}
+
+// Execution is an exported application execution result. Container is either
+// a transaction hash (for Application trigger) or a block hash (for OnPersist
+// and PostPersist triggers). Stack is the JSON representation of the resulting
+// stack.
+type Execution struct {
+	Container   string          `json:"container"`
+	BlockIndex  uint32          `json:"blockindex"`
+	Trigger     string          `json:"trigger"`
+	VMState     string          `json:"vmstate"`
+	GasConsumed int64           `json:"gasconsumed"`
+	Exception   string          `json:"exception"`
+	Stack       json.RawMessage `json:"stack"`
+}
+
+// Notification is an exported notification, Index is its position in the
+// list of execution's notifications and State is the JSON representation of
+// the notification's stack item (the same one RPC server uses).
+type Notification struct {
+	Container  string          `json:"container"`
+	BlockIndex uint32          `json:"blockindex"`
+	Trigger    string          `json:"trigger"`
+	Index      int             `json:"index"`
+	Contract   string          `json:"contract"`
+	EventName  string          `json:"eventname"`
+	State      json.RawMessage `json:"state"`
+}
+
+// Transfer is an exported NEP-17 transfer. From and To are empty for
+// mints and burns correspondingly, Amount is a decimal integer.
+type Transfer struct {
+	Container  string `json:"container"`
+	BlockIndex uint32 `json:"blockindex"`
+	Trigger    string `json:"trigger"`
+	Index      int    `json:"index"`
+	Asset      string `json:"asset"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Amount     string `json:"amount"`
+}
+
+// Entity implements Record interface.
+func (b *Block) Entity() Entity { return Blocks }
+
+// Values implements Record interface.
+func (b *Block) Values() []string {
+	return []string{u64(uint64(b.Index)), b.Hash, u64(b.Timestamp), u64(uint64(b.Version)), b.PrevHash,
+		b.MerkleRoot, u64(b.Nonce), u64(uint64(b.Primary)), b.NextConsensus, strconv.Itoa(b.Size), strconv.Itoa(b.TxCount)}
+}
+
+// Entity implements Record interface.
+func (t *Transaction) Entity() Entity { return Transactions }
+
+// Values implements Record interface.
+func (t *Transaction) Values() []string {
+	return []string{t.Hash, u64(uint64(t.BlockIndex)), u64(t.BlockTime), strconv.Itoa(t.TxIndex), u64(uint64(t.Version)),
+		u64(uint64(t.Nonce)), t.Sender, i64(t.SystemFee), i64(t.NetworkFee), u64(uint64(t.ValidUntilBlock)),
+		strconv.Itoa(t.Size), base64.StdEncoding.EncodeToString(t.Script)}
+}
+
+// Entity implements Record interface.
+func (s *Signer) Entity() Entity { return Signers }
+
+// Values implements Record interface.
+func (s *Signer) Values() []string {
+	return []string{s.TxHash, u64(uint64(s.BlockIndex)), strconv.Itoa(s.Index), s.Account, s.Scopes}
+}
+
+// Entity implements Record interface.
+func (e *Execution) Entity() Entity { return Executions }
+
+// Values implements Record interface.
+func (e *Execution) Values() []string {
+	return []string{e.Container, u64(uint64(e.BlockIndex)), e.Trigger, e.VMState,
+		i64(e.GasConsumed), e.Exception, string(e.Stack)}
+}
+
+// Entity implements Record interface.
+func (n *Notification) Entity() Entity { return Notifications }
+
+// Values implements Record interface.
+func (n *Notification) Values() []string {
+	return []string{n.Container, u64(uint64(n.BlockIndex)), n.Trigger, strconv.Itoa(n.Index),
+		n.Contract, n.EventName, string(n.State)}
+}
+
+// Entity implements Record interface.
+func (t *Transfer) Entity() Entity { return Transfers }
+
+// Values implements Record interface.
+func (t *Transfer) Values() []string {
+	return []string{t.Container, u64(uint64(t.BlockIndex)), t.Trigger, strconv.Itoa(t.Index),
+		t.Asset, t.From, t.To, t.Amount}
+}
+
+func u64(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
+
+func i64(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
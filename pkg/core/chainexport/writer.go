@@ -0,0 +1,144 @@
+package chainexport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrUnknownEntity is returned when record has unknown entity type.
+var ErrUnknownEntity = errors.New("unknown entity")
+
+// Format is an output format of exported data.
+type Format byte
+
+// Supported output formats.
+const (
+	// JSON is newline-delimited JSON, one record per line.
+	JSON Format = iota
+	// CSV is comma-separated values with a header line.
+	CSV
+)
+
+// FormatFromString converts format name to Format.
+func FormatFromString(s string) (Format, error) {
+	switch s {
+	case "json", "ndjson":
+		return JSON, nil
+	case "csv":
+		return CSV, nil
+	default:
+		return 0, fmt.Errorf("unknown export format: %s", s)
+	}
+}
+
+// Extension returns file extension used for the format.
+func (f Format) Extension() string {
+	if f == CSV {
+		return ".csv"
+	}
+	return ".ndjson"
+}
+
+// Writer accepts exported records.
+type Writer interface {
+	Write(Record) error
+	Close() error
+}
+
+// fileWriter writes every entity into a separate file in the specified
+// directory.
+type fileWriter struct {
+	format Format
+	files  []*os.File
+	bufs   []*bufio.Writer
+	csvs   []*csv.Writer
+	jsons  []*json.Encoder
+}
+
+// NewFileWriter creates a Writer that stores every entity into a separate
+// file named after it (like "blocks.csv") in the given directory. The
+// directory is created if needed, existing files are overwritten. CSV files
+// get header line right away, so they're valid even if there is no data for
+// them.
+func NewFileWriter(dir string, format Format) (Writer, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	w := &fileWriter{
+		format: format,
+		files:  make([]*os.File, len(Entities)),
+		bufs:   make([]*bufio.Writer, len(Entities)),
+		csvs:   make([]*csv.Writer, len(Entities)),
+		jsons:  make([]*json.Encoder, len(Entities)),
+	}
+	for _, e := range Entities {
+		f, err := os.Create(filepath.Join(dir, e.String()+format.Extension()))
+		if err != nil {
+			w.closeFiles()
+			return nil, err
+		}
+		w.files[e] = f
+		w.bufs[e] = bufio.NewWriter(f)
+		if format == CSV {
+			w.csvs[e] = csv.NewWriter(w.bufs[e])
+			if err := w.csvs[e].Write(e.Columns()); err != nil {
+				w.closeFiles()
+				return nil, err
+			}
+		} else {
+			w.jsons[e] = json.NewEncoder(w.bufs[e])
+		}
+	}
+	return w, nil
+}
+
+// Write implements Writer interface.
+func (w *fileWriter) Write(r Record) error {
+	e := r.Entity()
+	if int(e) >= len(w.files) {
+		return fmt.Errorf("%w: %d", ErrUnknownEntity, e)
+	}
+	if w.format == CSV {
+		return w.csvs[e].Write(r.Values())
+	}
+	return w.jsons[e].Encode(r)
+}
+
+// Close flushes all buffered data and closes files.
+func (w *fileWriter) Close() error {
+	var err error
+	for i := range w.files {
+		if w.csvs[i] != nil {
+			w.csvs[i].Flush()
+			if cerr := w.csvs[i].Error(); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+		if ferr := w.bufs[i].Flush(); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	if cerr := w.closeFiles(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (w *fileWriter) closeFiles() error {
+	var err error
+	for i := range w.files {
+		if w.files[i] == nil {
+			continue
+		}
+		if cerr := w.files[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		w.files[i] = nil
+	}
+	return err
+}
@@ -0,0 +1,107 @@
+package chainexport
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatFromString(t *testing.T) {
+	f, err := FormatFromString("json")
+	require.NoError(t, err)
+	require.Equal(t, JSON, f)
+	f, err = FormatFromString("ndjson")
+	require.NoError(t, err)
+	require.Equal(t, JSON, f)
+	f, err = FormatFromString("csv")
+	require.NoError(t, err)
+	require.Equal(t, CSV, f)
+	_, err = FormatFromString("parquet")
+	require.Error(t, err)
+}
+
+func TestColumns(t *testing.T) {
+	records := []Record{&Block{}, &Transaction{}, &Signer{}, &Execution{Stack: []byte("[]")},
+		&Notification{State: []byte("{}")}, &Transfer{}}
+	require.Equal(t, len(Entities), len(records))
+	for i, r := range records {
+		require.Equal(t, Entities[i], r.Entity())
+		require.Equal(t, len(r.Entity().Columns()), len(r.Values()), r.Entity().String())
+
+		// CSV columns should match JSON fields.
+		data, err := json.Marshal(r)
+		require.NoError(t, err)
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &m))
+		require.Equal(t, len(m), len(r.Entity().Columns()))
+		for _, c := range r.Entity().Columns() {
+			require.Contains(t, m, c)
+		}
+	}
+}
+
+func TestFileWriter(t *testing.T) {
+	sig := &Signer{
+		TxHash:     "0x01",
+		BlockIndex: 1,
+		Index:      0,
+		Account:    "NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc",
+		Scopes:     "CalledByEntry, CustomContracts",
+	}
+	t.Run("json", func(t *testing.T) {
+		dir := newTestDir(t)
+		w, err := NewFileWriter(dir, JSON)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(sig))
+		require.NoError(t, w.Write(sig))
+		require.NoError(t, w.Close())
+
+		data, err := ioutil.ReadFile(filepath.Join(dir, "signers.ndjson"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Equal(t, 2, len(lines))
+		for _, l := range lines {
+			actual := new(Signer)
+			require.NoError(t, json.Unmarshal([]byte(l), actual))
+			require.Equal(t, sig, actual)
+		}
+		for _, e := range Entities {
+			_, err := os.Stat(filepath.Join(dir, e.String()+".ndjson"))
+			require.NoError(t, err)
+		}
+	})
+	t.Run("csv", func(t *testing.T) {
+		dir := newTestDir(t)
+		w, err := NewFileWriter(dir, CSV)
+		require.NoError(t, err)
+		require.NoError(t, w.Write(sig))
+		require.NoError(t, w.Close())
+
+		f, err := os.Open(filepath.Join(dir, "signers.csv"))
+		require.NoError(t, err)
+		defer f.Close()
+		rows, err := csv.NewReader(f).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{Signers.Columns(), sig.Values()}, rows)
+
+		// Header is written even if there is no data.
+		data, err := ioutil.ReadFile(filepath.Join(dir, "blocks.csv"))
+		require.NoError(t, err)
+		require.Equal(t, strings.Join(Blocks.Columns(), ",")+"\n", string(data))
+	})
+}
+
+func newTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "neogo.exporttest")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+	return dir
+}
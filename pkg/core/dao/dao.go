@@ -30,9 +30,13 @@ var (
 type DAO interface {
 	AppendAppExecResult(aer *state.AppExecResult, buf *io.BufBinWriter) error
 	AppendNEP17Transfer(acc util.Uint160, index uint32, isNew bool, tr *state.NEP17Transfer) (bool, error)
+	DeleteAccountTransaction(acc util.Uint160, index uint32) error
 	DeleteBlock(h util.Uint256, buf *io.BufBinWriter) error
 	DeleteContractID(id int32) error
+	DeleteIndexedNotification(contract util.Uint160, name string, index uint32) error
 	DeleteStorageItem(id int32, key []byte) error
+	ForEachAccountTransaction(acc util.Uint160, newer func(*state.AccountTransaction) bool, skip int, f func(*state.AccountTransaction) (bool, error)) error
+	ForEachIndexedNotification(contract util.Uint160, name string, newer func(*state.IndexedNotification) bool, skip int, f func(*state.IndexedNotification) (bool, error)) error
 	GetAndDecode(entity io.Serializable, key []byte) error
 	GetAppExecResults(hash util.Uint256, trig trigger.Type) ([]state.AppExecResult, error)
 	GetBatch() *storage.MemBatch
@@ -51,9 +55,11 @@ type DAO interface {
 	GetWrapped() DAO
 	HasTransaction(hash util.Uint256) error
 	Persist() (int, error)
+	PutAccountTransaction(acc util.Uint160, tr *state.AccountTransaction, buf *io.BufBinWriter) error
 	PutAppExecResult(aer *state.AppExecResult, buf *io.BufBinWriter) error
 	PutContractID(id int32, hash util.Uint160) error
 	PutCurrentHeader(hashAndIndex []byte) error
+	PutIndexedNotification(n *state.IndexedNotification, buf *io.BufBinWriter) error
 	PutNEP17Balances(acc util.Uint160, bs *state.NEP17Balances) error
 	PutNEP17TransferLog(acc util.Uint160, index uint32, lg *state.NEP17TransferLog) error
 	PutStorageItem(id int32, key []byte, si state.StorageItem) error
@@ -216,6 +222,336 @@ func (dao *Simple) AppendNEP17Transfer(acc util.Uint160, index uint32, isNew boo
 
 // -- end transfer log.
 
+// -- start index log.
+
+// errLogHeadMismatch is returned when the oldest removable log entry is not
+// the one expected to be removed.
+var errLogHeadMismatch = errors.New("unexpected log head entry")
+
+// logBounds describes an append-only log of index entries stored under some
+// key, entries themselves are stored with their sequence numbers appended to
+// this key. Entries with sequence numbers in [0, Base) are permanent, the ones
+// in [First, Next) can be removed from the head of the log.
+type logBounds struct {
+	Base  uint32
+	First uint32
+	Next  uint32
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (b *logBounds) EncodeBinary(w *io.BinWriter) {
+	w.WriteU32LE(b.Base)
+	w.WriteU32LE(b.First)
+	w.WriteU32LE(b.Next)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (b *logBounds) DecodeBinary(r *io.BinReader) {
+	b.Base = r.ReadU32LE()
+	b.First = r.ReadU32LE()
+	b.Next = r.ReadU32LE()
+}
+
+// len returns the number of entries in the log.
+func (b *logBounds) len() uint32 {
+	return b.Base + b.Next - b.First
+}
+
+// seq returns the sequence number of the i-th entry of the log.
+func (b *logBounds) seq(i uint32) uint32 {
+	if i < b.Base {
+		return i
+	}
+	return b.First + i - b.Base
+}
+
+func makeLogEntryKey(key []byte, seq uint32) []byte {
+	k := make([]byte, len(key)+4)
+	copy(k, key)
+	binary.BigEndian.PutUint32(k[len(key):], seq)
+	return k
+}
+
+func (dao *Simple) getLogBounds(key []byte) (*logBounds, error) {
+	b := new(logBounds)
+	err := dao.GetAndDecode(b, key)
+	if err != nil && err != storage.ErrKeyNotFound {
+		return nil, err
+	}
+	return b, nil
+}
+
+// appendLogEntry adds entry to the end of the log stored with the given key
+// and returns its sequence number. Permanent entries can only be added to the
+// log without removable ones. It uses given buffer for entry serialization.
+func (dao *Simple) appendLogEntry(key []byte, entry io.Serializable, permanent bool, buf *io.BufBinWriter) (uint32, error) {
+	b, err := dao.getLogBounds(key)
+	if err != nil {
+		return 0, err
+	}
+	seq := b.Next
+	if permanent {
+		if b.Base != b.Next {
+			return 0, errors.New("can't add permanent entry after removable ones")
+		}
+		b.Base++
+		b.First++
+	}
+	b.Next++
+	if err := dao.putWithBuffer(entry, makeLogEntryKey(key, seq), buf); err != nil {
+		return 0, err
+	}
+	return seq, dao.Put(b, key)
+}
+
+// removeLogHead removes the oldest removable entry of the log stored with the
+// given key. load is used to get this entry by its sequence number and match
+// should return true for it, otherwise nothing is removed and
+// errLogHeadMismatch is returned.
+func (dao *Simple) removeLogHead(key []byte, load func(seq uint32) error, match func() bool) error {
+	b, err := dao.getLogBounds(key)
+	if err != nil {
+		return err
+	}
+	if b.First == b.Next {
+		return errLogHeadMismatch
+	}
+	if err := load(b.First); err != nil {
+		return err
+	}
+	if !match() {
+		return errLogHeadMismatch
+	}
+	if err := dao.Store.Delete(makeLogEntryKey(key, b.First)); err != nil {
+		return err
+	}
+	b.First++
+	return dao.Put(b, key)
+}
+
+// seekLog iterates over entries of the log stored with the given key going
+// from the newest to the oldest one. load is used to get an entry by its
+// sequence number, then newer and f are called for it. Entries for which newer
+// returns true (they must form a suffix of the log) are found with binary
+// search and skipped, then skip more entries are omitted and f is called for
+// the rest of them until it returns false or an error. So only O(log(N))
+// entries are read before f is called for the first time.
+func (dao *Simple) seekLog(key []byte, load func(seq uint32) error, newer func() bool, skip int, f func() (bool, error)) error {
+	b, err := dao.getLogBounds(key)
+	if err != nil {
+		return err
+	}
+	end := b.len()
+	if newer != nil {
+		var lo, hi uint32 = 0, end
+		for lo < hi {
+			mid := lo + (hi-lo)/2
+			if err := load(b.seq(mid)); err != nil {
+				return err
+			}
+			if newer() {
+				hi = mid
+			} else {
+				lo = mid + 1
+			}
+		}
+		end = lo
+	}
+	if skip < 0 {
+		skip = 0
+	}
+	if uint64(end) <= uint64(skip) {
+		return nil
+	}
+	for i := end - uint32(skip); i > 0; i-- {
+		if err := load(b.seq(i - 1)); err != nil {
+			return err
+		}
+		cont, err := f()
+		if err != nil || !cont {
+			return err
+		}
+	}
+	return nil
+}
+
+// -- end index log.
+
+// -- start account transactions.
+
+func makeAccountTransactionsKey(acc util.Uint160) []byte {
+	return storage.AppendPrefix(storage.IXAccountTransactions, acc.BytesBE())
+}
+
+// PutAccountTransaction appends an entry for transaction tr signed by acc to
+// the account transactions index. It can reuse given buffer for the purpose of
+// value serialization.
+func (dao *Simple) PutAccountTransaction(acc util.Uint160, tr *state.AccountTransaction, buf *io.BufBinWriter) error {
+	if buf == nil {
+		buf = io.NewBufBinWriter()
+	}
+	_, err := dao.appendLogEntry(makeAccountTransactionsKey(acc), tr, false, buf)
+	return err
+}
+
+// DeleteAccountTransaction removes the oldest entry from acc's transactions
+// index, it must belong to the block with the given index.
+func (dao *Simple) DeleteAccountTransaction(acc util.Uint160, index uint32) error {
+	key := makeAccountTransactionsKey(acc)
+	tr := new(state.AccountTransaction)
+	return dao.removeLogHead(key, func(seq uint32) error {
+		return dao.GetAndDecode(tr, makeLogEntryKey(key, seq))
+	}, func() bool {
+		return tr.Block == index
+	})
+}
+
+// ForEachAccountTransaction executes f for transactions signed by acc going
+// from the newest to the oldest one until f returns false or an error.
+// Transactions for which newer returns true (if it's not nil) are skipped
+// along with skip transactions following them without iterating over them.
+func (dao *Simple) ForEachAccountTransaction(acc util.Uint160, newer func(*state.AccountTransaction) bool, skip int, f func(*state.AccountTransaction) (bool, error)) error {
+	var (
+		key     = makeAccountTransactionsKey(acc)
+		tr      = new(state.AccountTransaction)
+		isNewer func() bool
+	)
+	if newer != nil {
+		isNewer = func() bool { return newer(tr) }
+	}
+	return dao.seekLog(key, func(seq uint32) error {
+		return dao.GetAndDecode(tr, makeLogEntryKey(key, seq))
+	}, isNewer, skip, func() (bool, error) {
+		res := *tr
+		return f(&res)
+	})
+}
+
+// -- end account transactions.
+
+// -- start notifications index.
+
+// Notifications index is stored as two logs. Contract log contains all
+// notifications emitted by the contract and event log contains sequence
+// numbers of the contract log entries for the notifications with some name.
+const (
+	contractNotificationsLog byte = iota
+	eventNotificationsLog
+)
+
+func makeContractNotificationsKey(contract util.Uint160) []byte {
+	key := make([]byte, 1+util.Uint160Size+1)
+	key[0] = byte(storage.IXNotifications)
+	copy(key[1:], contract.BytesBE())
+	key[len(key)-1] = contractNotificationsLog
+	return key
+}
+
+func makeEventNotificationsKey(contract util.Uint160, name string) []byte {
+	key := makeContractNotificationsKey(contract)
+	key[len(key)-1] = eventNotificationsLog
+	key = append(key, byte(len(name)))
+	return append(key, name...)
+}
+
+// logPointer is an event log entry referencing contract log entry.
+type logPointer uint32
+
+// EncodeBinary implements io.Serializable interface.
+func (p *logPointer) EncodeBinary(w *io.BinWriter) {
+	w.WriteU32LE(uint32(*p))
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (p *logPointer) DecodeBinary(r *io.BinReader) {
+	*p = logPointer(r.ReadU32LE())
+}
+
+// PutIndexedNotification adds given notification to the notifications index.
+// Notifications from the genesis block are never removed from the index. It
+// can reuse given buffer for the purpose of value serialization.
+func (dao *Simple) PutIndexedNotification(n *state.IndexedNotification, buf *io.BufBinWriter) error {
+	if buf == nil {
+		buf = io.NewBufBinWriter()
+	}
+	permanent := n.Block == 0
+	seq, err := dao.appendLogEntry(makeContractNotificationsKey(n.Event.ScriptHash), n, permanent, buf)
+	if err != nil || len(n.Event.Name) == 0 {
+		return err
+	}
+	buf.Reset()
+	p := logPointer(seq)
+	_, err = dao.appendLogEntry(makeEventNotificationsKey(n.Event.ScriptHash, n.Event.Name), &p, permanent, buf)
+	return err
+}
+
+// DeleteIndexedNotification removes the oldest notification with the given
+// name emitted by the contract from the notifications index, it must belong to
+// the block with the given index.
+func (dao *Simple) DeleteIndexedNotification(contract util.Uint160, name string, index uint32) error {
+	var (
+		key = makeContractNotificationsKey(contract)
+		n   = new(state.IndexedNotification)
+		seq uint32
+	)
+	err := dao.removeLogHead(key, func(s uint32) error {
+		seq = s
+		return dao.GetAndDecode(n, makeLogEntryKey(key, s))
+	}, func() bool {
+		return n.Block == index && n.Event.Name == name
+	})
+	if err != nil || len(name) == 0 {
+		return err
+	}
+	key = makeEventNotificationsKey(contract, name)
+	p := new(logPointer)
+	return dao.removeLogHead(key, func(s uint32) error {
+		return dao.GetAndDecode(p, makeLogEntryKey(key, s))
+	}, func() bool {
+		return uint32(*p) == seq
+	})
+}
+
+// ForEachIndexedNotification executes f for indexed notifications with the
+// given name emitted by the contract (or for all of its notifications if name
+// is empty) going from the newest to the oldest one until f returns false or
+// an error. Notifications for which newer returns true (if it's not nil) are
+// skipped along with skip notifications following them without iterating over
+// them.
+func (dao *Simple) ForEachIndexedNotification(contract util.Uint160, name string, newer func(*state.IndexedNotification) bool, skip int, f func(*state.IndexedNotification) (bool, error)) error {
+	var (
+		contractKey = makeContractNotificationsKey(contract)
+		key         = contractKey
+		n           = new(state.IndexedNotification)
+		load        = func(seq uint32) error {
+			return dao.GetAndDecode(n, makeLogEntryKey(contractKey, seq))
+		}
+		isNewer func() bool
+	)
+	if len(name) != 0 {
+		var (
+			p            = new(logPointer)
+			loadContract = load
+		)
+		key = makeEventNotificationsKey(contract, name)
+		load = func(seq uint32) error {
+			if err := dao.GetAndDecode(p, makeLogEntryKey(key, seq)); err != nil {
+				return err
+			}
+			return loadContract(uint32(*p))
+		}
+	}
+	if newer != nil {
+		isNewer = func() bool { return newer(n) }
+	}
+	return dao.seekLog(key, load, isNewer, skip, func() (bool, error) {
+		res := *n
+		return f(&res)
+	})
+}
+
+// -- end notifications index.
+
 // -- start notification event.
 
 // GetAppExecResults gets application execution results with the specified trigger from the
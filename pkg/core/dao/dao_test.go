@@ -41,6 +41,65 @@ func (t *TestSerializable) DecodeBinary(reader *io.BinReader) {
 	t.field = reader.ReadString()
 }
 
+func TestIndexLog(t *testing.T) {
+	dao := NewSimple(storage.NewMemoryStore(), false)
+	key := []byte{0xff}
+	entry := new(TestSerializable)
+	load := func(seq uint32) error {
+		return dao.GetAndDecode(entry, makeLogEntryKey(key, seq))
+	}
+	add := func(t *testing.T, field string, permanent bool) {
+		_, err := dao.appendLogEntry(key, &TestSerializable{field: field}, permanent, io.NewBufBinWriter())
+		require.NoError(t, err)
+	}
+	get := func(t *testing.T, newer string, skip int) []string {
+		var (
+			res     []string
+			isNewer func() bool
+		)
+		if newer != "" {
+			isNewer = func() bool { return entry.field > newer }
+		}
+		require.NoError(t, dao.seekLog(key, load, isNewer, skip, func() (bool, error) {
+			res = append(res, entry.field)
+			return true, nil
+		}))
+		return res
+	}
+
+	require.Nil(t, get(t, "", 0))
+	add(t, "0", true)
+	add(t, "1", true)
+	for _, f := range []string{"a", "b", "c", "d"} {
+		add(t, f, false)
+	}
+	_, err := dao.appendLogEntry(key, &TestSerializable{field: "2"}, true, io.NewBufBinWriter())
+	require.Error(t, err)
+
+	require.Equal(t, []string{"d", "c", "b", "a", "1", "0"}, get(t, "", 0))
+	require.Equal(t, []string{"b", "a", "1", "0"}, get(t, "b", 0))
+	require.Equal(t, []string{"a", "1", "0"}, get(t, "b", 1))
+	require.Equal(t, []string{"0"}, get(t, "", 5))
+	require.Nil(t, get(t, "", 6))
+	require.Nil(t, get(t, "/", 0))
+
+	match := func(field string) func() bool {
+		return func() bool { return entry.field == field }
+	}
+	require.Equal(t, errLogHeadMismatch, dao.removeLogHead(key, load, match("b")))
+	require.NoError(t, dao.removeLogHead(key, load, match("a")))
+	require.NoError(t, dao.removeLogHead(key, load, match("b")))
+	require.Equal(t, []string{"d", "c", "1", "0"}, get(t, "", 0))
+	require.Equal(t, []string{"1", "0"}, get(t, "c", 1))
+	add(t, "e", false)
+	require.Equal(t, []string{"e", "d", "c", "1", "0"}, get(t, "", 0))
+	require.NoError(t, dao.removeLogHead(key, load, match("c")))
+	require.NoError(t, dao.removeLogHead(key, load, match("d")))
+	require.NoError(t, dao.removeLogHead(key, load, match("e")))
+	require.Equal(t, errLogHeadMismatch, dao.removeLogHead(key, load, match("0")))
+	require.Equal(t, []string{"1", "0"}, get(t, "", 0))
+}
+
 func TestPutGetAppExecResult(t *testing.T) {
 	dao := NewSimple(storage.NewMemoryStore(), false)
 	hash := random.Uint256()
@@ -103,7 +103,7 @@ func initTestChain(t testing.TB, st storage.Store, f func(*config.Config)) *Bloc
 	if _, ok := t.(*testing.B); ok {
 		log = zap.NewNop()
 	}
-	chain, err := NewBlockchain(st, unitTestNetCfg.ProtocolConfiguration, log)
+	chain, err := NewBlockchain(st, unitTestNetCfg.ProtocolConfiguration, unitTestNetCfg.ApplicationConfiguration, log)
 	require.NoError(t, err)
 	return chain
 }
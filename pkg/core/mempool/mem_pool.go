@@ -501,6 +501,49 @@ func (mp *Pool) GetVerifiedTransactions() []*transaction.Transaction {
 	return t
 }
 
+// TxStats contains fee-related parameters of a pooled transaction.
+type TxStats struct {
+	FeePerByte   int64
+	Size         int
+	HighPriority bool
+}
+
+// Stats contains memory pool statistics.
+type Stats struct {
+	// Capacity is the maximum number of transactions in the pool.
+	Capacity int
+	// Size is the total size of pooled transactions in bytes.
+	Size int
+	// Conflicts is the number of transactions pooled ones conflict with via
+	// Conflicts attribute.
+	Conflicts int
+	// Transactions contains stats of pooled transactions in the order
+	// they're to be included into blocks (highest priority first).
+	Transactions []TxStats
+}
+
+// GetStats returns statistics of verified transactions.
+func (mp *Pool) GetStats() Stats {
+	mp.lock.RLock()
+	defer mp.lock.RUnlock()
+
+	var st = Stats{
+		Capacity:     mp.capacity,
+		Conflicts:    len(mp.conflicts),
+		Transactions: make([]TxStats, len(mp.verifiedTxes)),
+	}
+	for i := range mp.verifiedTxes {
+		tx := mp.verifiedTxes[i].txn
+		st.Transactions[i] = TxStats{
+			FeePerByte:   tx.FeePerByte(),
+			Size:         tx.Size(),
+			HighPriority: tx.HasAttribute(transaction.HighPriority),
+		}
+		st.Size += st.Transactions[i].Size
+	}
+	return st
+}
+
 // checkTxConflicts is an internal unprotected version of Verify. It takes into
 // consideration conflicting transactions which are about to be removed from mempool.
 func (mp *Pool) checkTxConflicts(tx *transaction.Transaction, fee Feer) ([]*transaction.Transaction, error) {
@@ -213,6 +213,38 @@ func TestGetVerified(t *testing.T) {
 	require.Equal(t, 0, len(verTxes))
 }
 
+func TestGetStats(t *testing.T) {
+	var fs = &FeerStub{balance: 1000000}
+	mp := New(10, 0, false)
+
+	st := mp.GetStats()
+	require.Equal(t, 10, st.Capacity)
+	require.Equal(t, 0, st.Size)
+	require.Equal(t, 0, len(st.Transactions))
+
+	var size int
+	for i := 0; i < 3; i++ {
+		tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+		tx.Nonce = uint32(i)
+		tx.NetworkFee = int64(1000 * (i + 1))
+		tx.Signers = []transaction.Signer{{Account: util.Uint160{1, 2, 3}}}
+		if i == 0 {
+			tx.Attributes = []transaction.Attribute{{Type: transaction.HighPriority}}
+		}
+		require.NoError(t, mp.Add(tx, fs))
+		size += tx.Size()
+	}
+	st = mp.GetStats()
+	require.Equal(t, size, st.Size)
+	require.Equal(t, 0, st.Conflicts)
+	require.Equal(t, 3, len(st.Transactions))
+	// High priority transaction goes first despite the lowest fee.
+	require.True(t, st.Transactions[0].HighPriority)
+	require.Equal(t, int64(1000)/int64(st.Transactions[0].Size), st.Transactions[0].FeePerByte)
+	require.False(t, st.Transactions[1].HighPriority)
+	require.True(t, st.Transactions[1].FeePerByte > st.Transactions[2].FeePerByte)
+}
+
 func TestRemoveStale(t *testing.T) {
 	var fs = &FeerStub{}
 	const mempoolSize = 10
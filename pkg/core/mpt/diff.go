@@ -0,0 +1,149 @@
+package mpt
+
+import (
+	"bytes"
+	"errors"
+)
+
+// DiffItem is a single difference between two tries.
+type DiffItem struct {
+	Key []byte
+	// OldValue is nil if the key was added.
+	OldValue []byte
+	// NewValue is nil if the key was deleted.
+	NewValue []byte
+}
+
+// differ walks two tries in parallel collecting differences between them.
+type differ struct {
+	oldT, newT *Trie
+	after      []byte
+	max        int
+	items      []DiffItem
+}
+
+// errLimitReached is used to stop trie walk when enough items are collected.
+var errLimitReached = errors.New("limit reached")
+
+// Diff returns up to max differences between t (old trie) and other (new
+// trie) ordered by key. Subtries with the same hash are not traversed. Only
+// keys greater than after are returned (all keys are returned if it's empty),
+// so the last key returned can be used to get the next batch of differences.
+// The second result is true if there are more differences than max.
+func (t *Trie) Diff(other *Trie, after []byte, max int) ([]DiffItem, bool, error) {
+	if max <= 0 {
+		return nil, false, errors.New("non-positive diff limit")
+	}
+	d := &differ{
+		oldT:  t,
+		newT:  other,
+		after: toNibbles(after),
+		max:   max,
+	}
+	err := d.diff(t.root, other.root, []byte{})
+	if errors.Is(err, errLimitReached) {
+		return d.items, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return d.items, false, nil
+}
+
+func (d *differ) diff(a, b Node, path []byte) error {
+	if isBefore(path, d.after) {
+		return nil
+	}
+	var err error
+	if a, err = d.oldT.resolve(a); err != nil {
+		return err
+	}
+	if b, err = d.newT.resolve(b); err != nil {
+		return err
+	}
+	if a == nil && b == nil || a != nil && b != nil && a.Hash() == b.Hash() {
+		return nil
+	}
+	aVal, aOK, aChildren, err := d.oldT.expand(a)
+	if err != nil {
+		return err
+	}
+	bVal, bOK, bChildren, err := d.newT.expand(b)
+	if err != nil {
+		return err
+	}
+	if (aOK || bOK) && (aOK != bOK || !bytes.Equal(aVal, bVal)) &&
+		(len(d.after) == 0 || bytes.Compare(path, d.after) > 0) {
+		if len(d.items) == d.max {
+			return errLimitReached
+		}
+		item := DiffItem{Key: fromNibbles(path)}
+		if aOK {
+			item.OldValue = copySlice(aVal)
+		}
+		if bOK {
+			item.NewValue = copySlice(bVal)
+		}
+		d.items = append(d.items, item)
+	}
+	for i := range aChildren {
+		if aChildren[i] == nil && bChildren[i] == nil {
+			continue
+		}
+		if err := d.diff(aChildren[i], bChildren[i], append(path[:len(path):len(path)], byte(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isBefore returns true if all keys starting with path are less than after
+// (both are in nibbles), i.e. they can be skipped.
+func isBefore(path, after []byte) bool {
+	n := len(path)
+	if n > len(after) {
+		n = len(after)
+	}
+	return bytes.Compare(path[:n], after[:n]) < 0
+}
+
+// resolve replaces hash node with the node from the store, empty hash node
+// is returned as nil.
+func (t *Trie) resolve(n Node) (Node, error) {
+	if h, ok := n.(*HashNode); ok {
+		if h.IsEmpty() {
+			return nil, nil
+		}
+		return t.getFromStore(h.Hash())
+	}
+	return n, nil
+}
+
+// expand returns value stored at the path of n (if any) and nodes for every
+// next nibble of the path.
+func (t *Trie) expand(n Node) ([]byte, bool, [lastChild]Node, error) {
+	var children [lastChild]Node
+	switch n := n.(type) {
+	case nil:
+	case *LeafNode:
+		return n.value, true, children, nil
+	case *BranchNode:
+		copy(children[:], n.Children[:lastChild])
+		v, err := t.resolve(n.Children[lastChild])
+		if err != nil {
+			return nil, false, children, err
+		}
+		if l, ok := v.(*LeafNode); ok {
+			return l.value, true, children, nil
+		}
+	case *ExtensionNode:
+		if len(n.key) == 1 {
+			children[n.key[0]] = n.next
+		} else {
+			children[n.key[0]] = NewExtensionNode(n.key[1:], n.next)
+		}
+	default:
+		panic("invalid MPT node type")
+	}
+	return nil, false, children, nil
+}
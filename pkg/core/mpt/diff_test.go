@@ -0,0 +1,103 @@
+package mpt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrie_Diff(t *testing.T) {
+	oldT := NewTrie(nil, false, newTestStore())
+	require.NoError(t, oldT.Put([]byte{0x01, 0x01}, []byte("same")))
+	require.NoError(t, oldT.Put([]byte{0x01, 0x02}, []byte("old")))
+	require.NoError(t, oldT.Put([]byte{0x01, 0x02, 0x03}, []byte("deleted")))
+	require.NoError(t, oldT.Put([]byte{0x02, 0x01, 0x02, 0x03}, []byte("deleted")))
+	require.NoError(t, oldT.Put([]byte{0xAB, 0xCD}, []byte("same")))
+	oldT.Flush()
+
+	newT := NewTrie(NewHashNode(oldT.StateRoot()), false, oldT.Store)
+	require.NoError(t, newT.Put([]byte{0x01, 0x02}, []byte("new")))
+	require.NoError(t, newT.Delete([]byte{0x01, 0x02, 0x03}))
+	require.NoError(t, newT.Delete([]byte{0x02, 0x01, 0x02, 0x03}))
+	require.NoError(t, newT.Put([]byte{0x02, 0x01}, []byte("added")))
+	require.NoError(t, newT.Put([]byte{0xAB, 0xCE}, []byte("added")))
+	newT.Flush()
+
+	expected := []DiffItem{
+		{Key: []byte{0x01, 0x02}, OldValue: []byte("old"), NewValue: []byte("new")},
+		{Key: []byte{0x01, 0x02, 0x03}, OldValue: []byte("deleted")},
+		{Key: []byte{0x02, 0x01}, NewValue: []byte("added")},
+		{Key: []byte{0x02, 0x01, 0x02, 0x03}, OldValue: []byte("deleted")},
+		{Key: []byte{0xAB, 0xCE}, NewValue: []byte("added")},
+	}
+
+	t.Run("all", func(t *testing.T) {
+		items, more, err := oldT.Diff(newT, nil, 100)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Equal(t, expected, items)
+
+		// Tries are collapsed, so nodes are read from the store.
+		oldC := NewTrie(NewHashNode(oldT.StateRoot()), false, oldT.Store)
+		newC := NewTrie(NewHashNode(newT.StateRoot()), false, oldT.Store)
+		items, more, err = oldC.Diff(newC, nil, 100)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Equal(t, expected, items)
+	})
+	t.Run("reverse", func(t *testing.T) {
+		items, _, err := newT.Diff(oldT, nil, 100)
+		require.NoError(t, err)
+		require.Equal(t, len(expected), len(items))
+		for i := range items {
+			require.Equal(t, expected[i].Key, items[i].Key)
+			require.Equal(t, expected[i].OldValue, items[i].NewValue)
+			require.Equal(t, expected[i].NewValue, items[i].OldValue)
+		}
+	})
+	t.Run("same", func(t *testing.T) {
+		items, more, err := oldT.Diff(oldT, nil, 100)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Equal(t, 0, len(items))
+	})
+	t.Run("empty", func(t *testing.T) {
+		items, more, err := NewTrie(nil, false, newTestStore()).Diff(newT, nil, 100)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Equal(t, 5, len(items))
+		for i := range items {
+			require.Nil(t, items[i].OldValue)
+		}
+	})
+	t.Run("paging", func(t *testing.T) {
+		var (
+			after []byte
+			all   []DiffItem
+		)
+		for {
+			items, more, err := oldT.Diff(newT, after, 2)
+			require.NoError(t, err)
+			require.True(t, len(items) <= 2)
+			all = append(all, items...)
+			if !more {
+				break
+			}
+			after = items[len(items)-1].Key
+		}
+		require.Equal(t, expected, all)
+
+		items, more, err := oldT.Diff(newT, []byte{0x02}, 100)
+		require.NoError(t, err)
+		require.False(t, more)
+		require.Equal(t, expected[2:], items)
+	})
+	t.Run("invalid limit", func(t *testing.T) {
+		_, _, err := oldT.Diff(newT, nil, 0)
+		require.Error(t, err)
+	})
+	t.Run("missing node", func(t *testing.T) {
+		_, _, err := NewTrie(NewHashNode(oldT.StateRoot()), false, newTestStore()).Diff(newT, nil, 100)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,81 @@
+package mpt
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+)
+
+// finder walks the trie collecting key-value pairs with the given prefix.
+type finder struct {
+	t      *Trie
+	prefix []byte
+	after  []byte
+	max    int
+	items  []storage.KeyValue
+}
+
+// Find returns up to max key-value pairs with keys starting with prefix
+// ordered by key. Only keys greater than from are returned (all keys with
+// the prefix are returned if it's empty), so the last key returned can be
+// used to get the next batch of pairs.
+func (t *Trie) Find(prefix, from []byte, max int) ([]storage.KeyValue, error) {
+	if max <= 0 {
+		return nil, errors.New("non-positive find limit")
+	}
+	if len(from) != 0 && !bytes.HasPrefix(from, prefix) {
+		return nil, errors.New("'from' key doesn't have the specified prefix")
+	}
+	f := &finder{
+		t:      t,
+		prefix: toNibbles(prefix),
+		after:  toNibbles(from),
+		max:    max,
+	}
+	err := f.find(t.root, []byte{})
+	if err != nil && !errors.Is(err, errLimitReached) {
+		return nil, err
+	}
+	return f.items, nil
+}
+
+func (f *finder) find(n Node, path []byte) error {
+	if isBefore(path, f.after) {
+		return nil
+	}
+	// Path and prefix should be the same up to the length of the shortest one.
+	l := len(path)
+	if l > len(f.prefix) {
+		l = len(f.prefix)
+	}
+	if !bytes.Equal(path[:l], f.prefix[:l]) {
+		return nil
+	}
+	n, err := f.t.resolve(n)
+	if err != nil || n == nil {
+		return err
+	}
+	val, ok, children, err := f.t.expand(n)
+	if err != nil {
+		return err
+	}
+	if ok && len(path) >= len(f.prefix) && (len(f.after) == 0 || bytes.Compare(path, f.after) > 0) {
+		if len(f.items) == f.max {
+			return errLimitReached
+		}
+		f.items = append(f.items, storage.KeyValue{
+			Key:   fromNibbles(path),
+			Value: copySlice(val),
+		})
+	}
+	for i := range children {
+		if children[i] == nil {
+			continue
+		}
+		if err := f.find(children[i], append(path[:len(path):len(path)], byte(i))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
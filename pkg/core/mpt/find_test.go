@@ -0,0 +1,62 @@
+package mpt
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrie_Find(t *testing.T) {
+	tr := NewTrie(nil, false, newTestStore())
+	items := []storage.KeyValue{
+		{Key: []byte{0x01}, Value: []byte("a")},
+		{Key: []byte{0x01, 0x01}, Value: []byte("b")},
+		{Key: []byte{0x01, 0x02}, Value: []byte("c")},
+		{Key: []byte{0x01, 0x02, 0x03}, Value: []byte("d")},
+		{Key: []byte{0x01, 0x20}, Value: []byte("e")},
+		{Key: []byte{0x02, 0x01}, Value: []byte("f")},
+		{Key: []byte{0xAB, 0xCD, 0xEF}, Value: []byte("g")},
+	}
+	for _, kv := range items {
+		require.NoError(t, tr.Put(kv.Key, kv.Value))
+	}
+	tr.Flush()
+
+	check := func(t *testing.T, tr *Trie, prefix, from []byte, max int, expected []storage.KeyValue) {
+		res, err := tr.Find(prefix, from, max)
+		require.NoError(t, err)
+		if len(expected) == 0 {
+			require.Equal(t, 0, len(res))
+			return
+		}
+		require.Equal(t, expected, res)
+	}
+	for name, tr := range map[string]*Trie{
+		"in memory":  tr,
+		"from store": NewTrie(NewHashNode(tr.StateRoot()), false, tr.Store),
+	} {
+		t.Run(name, func(t *testing.T) {
+			check(t, tr, nil, nil, 100, items)
+			check(t, tr, []byte{0x01}, nil, 100, items[:5])
+			check(t, tr, []byte{0x01, 0x02}, nil, 100, items[2:4])
+			check(t, tr, []byte{0xAB}, nil, 100, items[6:])
+			check(t, tr, []byte{0xAC}, nil, 100, nil)
+			check(t, tr, []byte{0x01, 0x02, 0x03, 0x04}, nil, 100, nil)
+			check(t, tr, []byte{0x01}, nil, 2, items[:2])
+			check(t, tr, []byte{0x01}, []byte{0x01, 0x01}, 2, items[2:4])
+			check(t, tr, []byte{0x01}, []byte{0x01, 0x02, 0x03}, 100, items[4:5])
+			check(t, tr, nil, []byte{0x01, 0x20}, 100, items[5:])
+			check(t, tr, nil, []byte{0xAB, 0xCD, 0xEF}, 100, nil)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := tr.Find(nil, nil, 0)
+		require.Error(t, err)
+		_, err = tr.Find([]byte{0x01}, []byte{0x02}, 10)
+		require.Error(t, err)
+		_, err = NewTrie(NewHashNode(tr.StateRoot()), false, newTestStore()).Find(nil, nil, 10)
+		require.Error(t, err)
+	})
+}
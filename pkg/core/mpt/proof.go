@@ -2,6 +2,7 @@ package mpt
 
 import (
 	"bytes"
+	"errors"
 
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
@@ -13,7 +14,7 @@ import (
 func (t *Trie) GetProof(key []byte) ([][]byte, error) {
 	var proof [][]byte
 	path := toNibbles(key)
-	r, err := t.getProof(t.root, path, &proof)
+	r, err := t.getProof(t.root, path, &proof, false)
 	if err != nil {
 		return proof, err
 	}
@@ -21,17 +22,40 @@ func (t *Trie) GetProof(key []byte) ([][]byte, error) {
 	return proof, nil
 }
 
-func (t *Trie) getProof(curr Node, path []byte, proofs *[][]byte) (Node, error) {
+// GetPathProof returns serialized nodes occurring on path from the root to
+// the leaf of key. If key is missing in t, the path ends with the node where
+// it diverges from the key, so the proof can also be used to check key
+// absence.
+func (t *Trie) GetPathProof(key []byte) ([][]byte, error) {
+	var proof [][]byte
+	path := toNibbles(key)
+	r, err := t.getProof(t.root, path, &proof, true)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return proof, nil
+		}
+		return nil, err
+	}
+	t.root = r
+	return proof, nil
+}
+
+// getProof collects nodes on the path to the key, if withMissing is set the
+// node proving key absence is added to proofs too.
+func (t *Trie) getProof(curr Node, path []byte, proofs *[][]byte, withMissing bool) (Node, error) {
 	switch n := curr.(type) {
 	case *LeafNode:
 		if len(path) == 0 {
 			*proofs = append(*proofs, copySlice(n.Bytes()))
 			return n, nil
 		}
+		if withMissing {
+			*proofs = append(*proofs, copySlice(n.Bytes()))
+		}
 	case *BranchNode:
 		*proofs = append(*proofs, copySlice(n.Bytes()))
 		i, path := splitPath(path)
-		r, err := t.getProof(n.Children[i], path, proofs)
+		r, err := t.getProof(n.Children[i], path, proofs, withMissing)
 		if err != nil {
 			return nil, err
 		}
@@ -40,20 +64,23 @@ func (t *Trie) getProof(curr Node, path []byte, proofs *[][]byte) (Node, error)
 	case *ExtensionNode:
 		if bytes.HasPrefix(path, n.key) {
 			*proofs = append(*proofs, copySlice(n.Bytes()))
-			r, err := t.getProof(n.next, path[len(n.key):], proofs)
+			r, err := t.getProof(n.next, path[len(n.key):], proofs, withMissing)
 			if err != nil {
 				return nil, err
 			}
 			n.next = r
 			return n, nil
 		}
+		if withMissing {
+			*proofs = append(*proofs, copySlice(n.Bytes()))
+		}
 	case *HashNode:
 		if !n.IsEmpty() {
 			r, err := t.getFromStore(n.Hash())
 			if err != nil {
 				return nil, err
 			}
-			return t.getProof(r, path, proofs)
+			return t.getProof(r, path, proofs, withMissing)
 		}
 	}
 	return nil, ErrNotFound
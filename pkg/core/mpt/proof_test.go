@@ -1,8 +1,10 @@
 package mpt
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
 	"github.com/stretchr/testify/require"
 )
 
@@ -42,6 +44,44 @@ func TestTrie_GetProof(t *testing.T) {
 	})
 }
 
+func TestTrie_GetPathProof(t *testing.T) {
+	tr := newProofTrie(t)
+
+	t.Run("Valid", func(t *testing.T) {
+		expected, err := tr.GetProof([]byte{0x12, 0x31})
+		require.NoError(t, err)
+		proof, err := tr.GetPathProof([]byte{0x12, 0x31})
+		require.NoError(t, err)
+		require.Equal(t, expected, proof)
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		for _, key := range [][]byte{{0x12}, {0x12, 0x33}, {0x12, 0x31, 0x00}, {0x45}, {0x46}} {
+			proof, err := tr.GetPathProof(key)
+			require.NoError(t, err)
+			require.NotEqual(t, 0, len(proof))
+
+			// All nodes on the path are in the proof, so it can be
+			// restored from the proof only.
+			pt := NewTrie(NewHashNode(tr.root.Hash()), false, newTestStore())
+			for i := range proof {
+				h := hash.DoubleSha256(proof[i])
+				require.NoError(t, pt.Store.Put(makeStorageKey(h[:]), proof[i]))
+			}
+			actual, err := pt.GetPathProof(key)
+			require.NoError(t, err, "key %x", key)
+			require.Equal(t, proof, actual)
+			_, err = pt.Get(key)
+			require.True(t, errors.Is(err, ErrNotFound))
+		}
+	})
+
+	t.Run("MissingHashNode", func(t *testing.T) {
+		_, err := tr.GetPathProof([]byte{0x55})
+		require.Error(t, err)
+	})
+}
+
 func TestVerifyProof(t *testing.T) {
 	tr := newProofTrie(t)
 
@@ -39,7 +39,9 @@ type Management struct {
 const (
 	managementContractID = -1
 
-	prefixContract = 8
+	// PrefixContract is a prefix used to store contract states inside
+	// ContractManagement native contract.
+	PrefixContract = 8
 
 	defaultMinimumDeploymentFee     = 10_00000000
 	contractDeployNotificationName  = "Deploy"
@@ -56,7 +58,7 @@ var (
 
 // makeContractKey creates a key from account script hash.
 func makeContractKey(h util.Uint160) []byte {
-	return makeUint160Key(prefixContract, h)
+	return makeUint160Key(PrefixContract, h)
 }
 
 // newManagement creates new Management native contract.
@@ -486,7 +488,7 @@ func (m *Management) InitializeCache(d dao.DAO) error {
 	defer m.mtx.Unlock()
 
 	var initErr error
-	d.Seek(m.ID, []byte{prefixContract}, func(_, v []byte) {
+	d.Seek(m.ID, []byte{PrefixContract}, func(_, v []byte) {
 		var cs state.Contract
 		r := io.NewBinReaderFromBuf(v)
 		cs.DecodeBinary(r)
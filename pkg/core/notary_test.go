@@ -418,6 +418,46 @@ func TestNotary(t *testing.T) {
 	r = checkCompleteMultisigRequest(t, 3, 10, true)
 	checkFallbackTxs(t, r, false)
 
+	// GetRequests: partially collected multisignature request
+	t.Run("GetRequests", func(t *testing.T) {
+		requesters := make([]*wallet.Account, 3)
+		for i := range requesters {
+			requesters[i], _ = wallet.NewAccount()
+		}
+		r := createMultisigRequest(2, requesters)
+		ntr1.OnNewRequest(r[1])
+		mainHash := r[1].MainTransaction.Hash()
+
+		var st *notary.RequestStatus
+		for _, s := range ntr1.GetRequests() {
+			if s.Main.Hash() == mainHash {
+				st = &s
+				break
+			}
+		}
+		require.NotNil(t, st)
+		require.Equal(t, notary.MultiSignature, st.Type)
+		require.False(t, st.Sent)
+		require.Equal(t, 2, st.NSigs)
+		require.Equal(t, 1, st.NSigsCollected)
+		require.Equal(t, 1, len(st.Witnesses))
+		w := st.Witnesses[0]
+		require.Equal(t, r[1].MainTransaction.Signers[0].Account, w.Account)
+		require.False(t, w.Contract)
+		require.Equal(t, 2, w.M)
+		require.Equal(t, 3, len(w.Keys))
+		require.Equal(t, keys.PublicKeys{requesters[1].PrivateKey().PublicKey()}, w.Signed)
+		require.Equal(t, []notary.FallbackStatus{{
+			Hash:           r[1].FallbackTransaction.Hash(),
+			NotValidBefore: bc.BlockHeight() + nvbDiffFallback,
+		}}, st.Fallbacks)
+
+		ntr1.OnRequestRemoval(r[1])
+		for _, s := range ntr1.GetRequests() {
+			require.NotEqual(t, mainHash, s.Main.Hash())
+		}
+	})
+
 	// PostPersist: missing account
 	finalizeWithError = true
 	r = checkCompleteStandardRequest(t, 1, false)
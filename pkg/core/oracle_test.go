@@ -271,6 +271,64 @@ func TestOracle(t *testing.T) {
 			Code: transaction.ContentTypeNotSupported,
 		})
 	})
+	t.Run("Status", func(t *testing.T) {
+		st := orc1.GetStatus()
+		require.Equal(t, 2, st.Nodes)
+		require.Equal(t, 2, st.Required)
+		require.NotNil(t, st.Account)
+		require.Equal(t, acc1.Contract.ScriptHash(), *st.Account)
+		require.Equal(t, 12, st.Pending)
+		require.Equal(t, 0, len(st.InFlight))
+
+		errs := make(map[string]oracle.URLError)
+		for _, e := range st.Errors {
+			errs[e.URL] = e
+		}
+		require.Equal(t, 6, len(errs))
+		require.Equal(t, transaction.Timeout, errs["https://get.timeout"].Code)
+		require.Equal(t, 1, errs["https://get.timeout"].Count)
+		require.Equal(t, transaction.Forbidden, errs["https://private.url"].Code)
+		_, ok := errs["https://get.1234"]
+		require.False(t, ok)
+
+		reqs := orc1.GetRequests()
+		require.Equal(t, 12, len(reqs))
+		require.Equal(t, uint64(0), reqs[0].ID)
+		require.Equal(t, "https://get.1234", reqs[0].URL)
+		require.True(t, reqs[0].Sent)
+		require.Equal(t, 2, reqs[0].Signatures)
+		require.NotNil(t, reqs[0].TxHash)
+
+		r, err := orc1.GetRequest(2)
+		require.NoError(t, err)
+		require.Equal(t, "https://get.timeout", r.URL)
+		require.False(t, r.Sent)
+		require.Equal(t, 1, r.Attempts)
+		require.Equal(t, 1, r.Signatures)
+		require.Equal(t, 1, r.BackupSignatures)
+
+		_, err = orc1.GetRequest(100)
+		require.True(t, errors.Is(err, oracle.ErrRequestNotFound))
+	})
+	t.Run("Control", func(t *testing.T) {
+		require.True(t, errors.Is(orc1.RetryRequest(100), oracle.ErrRequestNotFound))
+		require.True(t, errors.Is(orc1.SkipRequest(100), oracle.ErrRequestNotFound))
+		require.True(t, errors.Is(orc1.RetryRequest(0), oracle.ErrResponseSent))
+		require.True(t, errors.Is(orc1.SkipRequest(0), oracle.ErrResponseSent))
+
+		// Only other node's signature is known.
+		orc1.AddResponse(acc2.PrivateKey().PublicKey(), 50, []byte{1, 2, 3})
+		require.True(t, errors.Is(orc1.RetryRequest(50), oracle.ErrRequestNotProcessed))
+		require.True(t, errors.Is(orc1.SkipRequest(50), oracle.ErrRequestNotProcessed))
+
+		require.NoError(t, orc1.RetryRequest(3))
+
+		require.NoError(t, orc1.SkipRequest(2))
+		require.Equal(t, transaction.Error, m1[2].resp.Code)
+		r, err := orc1.GetRequest(2)
+		require.NoError(t, err)
+		require.Equal(t, 2, r.Attempts)
+	})
 }
 
 func TestOracleFull(t *testing.T) {
@@ -0,0 +1,31 @@
+package state
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// AccountTransaction is an entry of the account transactions index, it
+// represents a single transaction signed by some account.
+type AccountTransaction struct {
+	// Block is a number of block containing the transaction.
+	Block uint32
+	// Timestamp is the timestamp of the block containing the transaction.
+	Timestamp uint64
+	// Tx is a hash of the transaction.
+	Tx util.Uint256
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (t *AccountTransaction) EncodeBinary(w *io.BinWriter) {
+	w.WriteU32LE(t.Block)
+	w.WriteU64LE(t.Timestamp)
+	w.WriteBytes(t.Tx[:])
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (t *AccountTransaction) DecodeBinary(r *io.BinReader) {
+	t.Block = r.ReadU32LE()
+	t.Timestamp = r.ReadU64LE()
+	r.ReadBytes(t.Tx[:])
+}
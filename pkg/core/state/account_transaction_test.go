@@ -0,0 +1,18 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+)
+
+func TestAccountTransaction_EncodeBinary(t *testing.T) {
+	expected := &AccountTransaction{
+		Block:     12345,
+		Timestamp: 54321,
+		Tx:        random.Uint256(),
+	}
+
+	testserdes.EncodeDecodeBinary(t, expected, new(AccountTransaction))
+}
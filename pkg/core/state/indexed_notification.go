@@ -0,0 +1,37 @@
+package state
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// IndexedNotification is an entry of the notifications index, it's a
+// notification event along with its location in the chain.
+type IndexedNotification struct {
+	// Block is a number of block where the notification was emitted.
+	Block uint32
+	// Container is a hash of the transaction that emitted the notification
+	// (or block hash for OnPersist and PostPersist notifications).
+	Container util.Uint256
+	// Index is the index of the notification in the list of notifications
+	// emitted by the container's execution.
+	Index uint32
+	// Event is the notification itself.
+	Event NotificationEvent
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (n *IndexedNotification) EncodeBinary(w *io.BinWriter) {
+	w.WriteU32LE(n.Block)
+	w.WriteBytes(n.Container[:])
+	w.WriteU32LE(n.Index)
+	n.Event.EncodeBinary(w)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (n *IndexedNotification) DecodeBinary(r *io.BinReader) {
+	n.Block = r.ReadU32LE()
+	r.ReadBytes(n.Container[:])
+	n.Index = r.ReadU32LE()
+	n.Event.DecodeBinary(r)
+}
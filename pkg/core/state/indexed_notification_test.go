@@ -0,0 +1,24 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/vm/stackitem"
+)
+
+func TestIndexedNotification_EncodeBinary(t *testing.T) {
+	expected := &IndexedNotification{
+		Block:     12345,
+		Container: random.Uint256(),
+		Index:     3,
+		Event: NotificationEvent{
+			ScriptHash: random.Uint160(),
+			Name:       "Event",
+			Item:       stackitem.NewArray([]stackitem.Item{stackitem.NewBool(true)}),
+		},
+	}
+
+	testserdes.EncodeDecodeBinary(t, expected, new(IndexedNotification))
+}
@@ -60,6 +60,42 @@ func (s *Module) GetStateProof(root util.Uint256, key []byte) ([][]byte, error)
 	return tr.GetProof(key)
 }
 
+// GetStatePathProof returns proof of having key in the MPT with the specified
+// root or proof of its absence if it's missing.
+func (s *Module) GetStatePathProof(root util.Uint256, key []byte) ([][]byte, error) {
+	tr := mpt.NewTrie(mpt.NewHashNode(root), false, storage.NewMemCachedStore(s.Store))
+	return tr.GetPathProof(key)
+}
+
+// GetState returns value for the key from MPT with the specified root.
+func (s *Module) GetState(root util.Uint256, key []byte) ([]byte, error) {
+	tr := mpt.NewTrie(mpt.NewHashNode(root), false, storage.NewMemCachedStore(s.Store))
+	return tr.Get(key)
+}
+
+// FindStates returns up to max key-value pairs with the specified prefix from
+// MPT with the specified root, only keys greater than from are returned.
+func (s *Module) FindStates(root util.Uint256, prefix, from []byte, max int) ([]storage.KeyValue, error) {
+	tr := mpt.NewTrie(mpt.NewHashNode(root), false, storage.NewMemCachedStore(s.Store))
+	return tr.Find(prefix, from, max)
+}
+
+// GetStateDiff returns up to max storage changes made between oldRoot and
+// newRoot MPT roots with keys greater than after (see mpt.Trie.Diff).
+func (s *Module) GetStateDiff(oldRoot, newRoot util.Uint256, after []byte, max int) ([]mpt.DiffItem, bool, error) {
+	oldT := mpt.NewTrie(rootNode(oldRoot), false, storage.NewMemCachedStore(s.Store))
+	newT := mpt.NewTrie(rootNode(newRoot), false, storage.NewMemCachedStore(s.Store))
+	return oldT.Diff(newT, after, max)
+}
+
+// rootNode returns MPT root node for the given hash, zero hash is an empty trie.
+func rootNode(root util.Uint256) mpt.Node {
+	if root.Equals(util.Uint256{}) {
+		return nil
+	}
+	return mpt.NewHashNode(root)
+}
+
 // GetStateRoot returns state root for a given height.
 func (s *Module) GetStateRoot(height uint32) (*state.MPTRoot, error) {
 	return s.getStateRoot(makeStateRootKey(height))
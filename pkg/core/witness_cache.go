@@ -0,0 +1,93 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// witnessKey identifies witness checked for some container.
+type witnessKey struct {
+	container util.Uint256
+	account   util.Uint160
+	witness   util.Uint256
+}
+
+// witnessResult is a result of successful witness check, it contains the
+// amount of GAS consumed and execution fee factor used for this check along
+// with the index of the block containing this witness.
+type witnessResult struct {
+	index   uint32
+	gas     int64
+	execFee int64
+}
+
+// witnessCache stores results of standard witnesses checks made before the
+// corresponding block is added to the chain (see Blockchain.PreverifyBlock).
+// Standard witnesses can't depend on the chain state except for the
+// execution fee factor, so the same check made later will have the same
+// result.
+type witnessCache struct {
+	lock sync.Mutex
+	m    map[witnessKey]witnessResult
+}
+
+func newWitnessKey(container hash.Hashable, account util.Uint160, w *transaction.Witness) witnessKey {
+	buf := io.NewBufBinWriter()
+	w.EncodeBinary(buf.BinWriter)
+	return witnessKey{
+		container: container.Hash(),
+		account:   account,
+		witness:   hash.Sha256(buf.Bytes()),
+	}
+}
+
+// add remembers successful witness check result.
+func (c *witnessCache) add(k witnessKey, r witnessResult) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.m == nil {
+		c.m = make(map[witnessKey]witnessResult)
+	}
+	c.m[k] = r
+}
+
+// take returns and removes the result for the given witness if there is any.
+func (c *witnessCache) take(container hash.Hashable, account util.Uint160, w *transaction.Witness) (witnessResult, bool) {
+	if container == nil || c.isEmpty() {
+		return witnessResult{}, false
+	}
+	k := newWitnessKey(container, account, w)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	r, ok := c.m[k]
+	if ok {
+		delete(c.m, k)
+	}
+	return r, ok
+}
+
+// forget removes all results for blocks up to the given index (they're left
+// unused if block or header was not added to the chain or was added without
+// witness checks).
+func (c *witnessCache) forget(index uint32) {
+	if c.isEmpty() {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for k, r := range c.m {
+		if r.index <= index {
+			delete(c.m, k)
+		}
+	}
+}
+
+func (c *witnessCache) isEmpty() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.m) == 0
+}
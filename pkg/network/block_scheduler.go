@@ -0,0 +1,192 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+)
+
+const (
+	// blockRequestTimeout is the time peer has to send the next block of
+	// the requested range before the range is reassigned to another peer.
+	blockRequestTimeout = 20 * time.Second
+	// maxPeerBlockRequests is the maximum number of block ranges that can
+	// be requested from a single peer simultaneously.
+	maxPeerBlockRequests = 2
+)
+
+// blockRange is a range of blocks requested from a single peer.
+type blockRange struct {
+	peer     Peer
+	start    uint32
+	end      uint32
+	sent     time.Time
+	deadline time.Time
+}
+
+// blockScheduler splits missing block heights into chunks of
+// payload.MaxHashesCount blocks and distributes them among peers, so that
+// blocks are downloaded from several peers in parallel. Lower chunks are
+// always requested first, so blockQueue can add blocks to the chain as soon
+// as they're received.
+type blockScheduler struct {
+	lock    sync.Mutex
+	timeout time.Duration
+	// ranges contains in-flight requests by chunk start.
+	ranges map[uint32]*blockRange
+	// received contains the last received block index for chunks that
+	// were (possibly partially) downloaded, but not yet processed by
+	// the chain.
+	received map[uint32]uint32
+	// height is the last known chain height and progress is the time it
+	// was changed.
+	height   uint32
+	progress time.Time
+}
+
+func newBlockScheduler(timeout time.Duration) *blockScheduler {
+	return &blockScheduler{
+		timeout:  timeout,
+		ranges:   make(map[uint32]*blockRange),
+		received: make(map[uint32]uint32),
+		progress: time.Now(),
+	}
+}
+
+// chunkOf returns the start of the chunk the given block belongs to.
+func chunkOf(index uint32) uint32 {
+	return index - index%payload.MaxHashesCount
+}
+
+// assign returns requests for the lowest missing block ranges that should
+// be sent to the given peer. The number of ranges requested from one peer
+// is limited by maxPeerBlockRequests, ranges are also limited by the peer's
+// height and blockQueue capacity.
+func (bs *blockScheduler) assign(p Peer, height uint32) []*payload.GetBlockByIndex {
+	var (
+		limit    = height + blockCacheSize
+		now      = time.Now()
+		inflight int
+		res      []*payload.GetBlockByIndex
+	)
+	if peerHeight := p.LastBlockIndex(); peerHeight < limit {
+		limit = peerHeight
+	}
+
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+	bs.cleanup(height)
+	for _, r := range bs.ranges {
+		if r.peer == p {
+			inflight++
+		}
+	}
+	for c := chunkOf(height + 1); c <= limit && inflight < maxPeerBlockRequests; c += payload.MaxHashesCount {
+		if _, ok := bs.ranges[c]; ok {
+			continue
+		}
+		start := c
+		if start <= height {
+			start = height + 1
+		}
+		if last, ok := bs.received[c]; ok && last >= start {
+			start = last + 1
+		}
+		end := c + payload.MaxHashesCount - 1
+		if end > limit {
+			end = limit
+		}
+		if start > end {
+			continue
+		}
+		bs.ranges[c] = &blockRange{
+			peer:     p,
+			start:    start,
+			end:      end,
+			sent:     now,
+			deadline: now.Add(bs.timeout),
+		}
+		res = append(res, payload.NewGetBlockByIndex(start, int16(end-start+1)))
+		inflight++
+	}
+	return res
+}
+
+// blockReceived marks the block with the given index received from the peer,
+// it returns true if this block completes some range requested from the peer.
+func (bs *blockScheduler) blockReceived(p Peer, index uint32) bool {
+	c := chunkOf(index)
+
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+	r, ok := bs.ranges[c]
+	if !ok || r.peer != p || index < r.start || index > r.end {
+		return false
+	}
+	now := time.Now()
+	r.deadline = now.Add(bs.timeout)
+	if index != r.end {
+		return false
+	}
+	delete(bs.ranges, c)
+	bs.received[c] = r.end
+	updatePeerBlockRateMetric(p, r.end-r.start+1, now.Sub(r.sent))
+	return true
+}
+
+// expire removes ranges that weren't answered in time returning peers they
+// were requested from, these ranges can then be assigned to other peers.
+func (bs *blockScheduler) expire(height uint32) []Peer {
+	var (
+		now  = time.Now()
+		slow []Peer
+	)
+	bs.lock.Lock()
+	defer bs.lock.Unlock()
+	if height != bs.height {
+		bs.height = height
+		bs.progress = now
+	}
+	bs.cleanup(height)
+	for c, r := range bs.ranges {
+		if now.After(r.deadline) {
+			delete(bs.ranges, c)
+			slow = append(slow, r.peer)
+		}
+	}
+	// The next block was received, but the chain doesn't move, so it could
+	// have been dropped by the queue and needs to be requested again.
+	if now.Sub(bs.progress) > bs.timeout {
+		delete(bs.received, chunkOf(height+1))
+		bs.progress = now
+	}
+	return slow
+}
+
+// removePeer releases all ranges requested from the given peer.
+func (bs *blockScheduler) removePeer(p Peer) {
+	bs.lock.Lock()
+	for c, r := range bs.ranges {
+		if r.peer == p {
+			delete(bs.ranges, c)
+		}
+	}
+	bs.lock.Unlock()
+	removePeerBlockRateMetric(p)
+}
+
+// cleanup removes ranges that are already processed by the chain. It must be
+// called with the lock held.
+func (bs *blockScheduler) cleanup(height uint32) {
+	for c, r := range bs.ranges {
+		if r.end <= height {
+			delete(bs.ranges, c)
+		}
+	}
+	for c, last := range bs.received {
+		if last <= height {
+			delete(bs.received, c)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockScheduler(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	bs := newBlockScheduler(timeout)
+	p1 := newLocalPeer(t, nil)
+	p1.lastBlockIndex = 3000
+	p2 := newLocalPeer(t, nil)
+	p2.lastBlockIndex = 3000
+
+	require.Equal(t, []*payload.GetBlockByIndex{
+		payload.NewGetBlockByIndex(1, payload.MaxHashesCount-1),
+		payload.NewGetBlockByIndex(500, payload.MaxHashesCount),
+	}, bs.assign(p1, 0))
+	require.Equal(t, []*payload.GetBlockByIndex{
+		payload.NewGetBlockByIndex(1000, payload.MaxHashesCount),
+		payload.NewGetBlockByIndex(1500, payload.MaxHashesCount),
+	}, bs.assign(p2, 0))
+
+	// Only blocks from the requested ranges are accounted.
+	require.False(t, bs.blockReceived(p1, 1000))
+	for i := uint32(500); i < 999; i++ {
+		require.False(t, bs.blockReceived(p1, i))
+	}
+	require.True(t, bs.blockReceived(p1, 999))
+	require.False(t, bs.blockReceived(p1, 999))
+
+	// Received chunk is not requested again.
+	require.Equal(t, []*payload.GetBlockByIndex{
+		payload.NewGetBlockByIndex(2000, 1),
+	}, bs.assign(p1, 0))
+	require.Equal(t, 0, len(bs.expire(0)))
+
+	// Expired requests are reassigned.
+	time.Sleep(2 * timeout)
+	slow := bs.expire(0)
+	require.Equal(t, 4, len(slow))
+	require.ElementsMatch(t, []Peer{p1, p1, p2, p2}, slow)
+	require.Equal(t, []*payload.GetBlockByIndex{
+		payload.NewGetBlockByIndex(1, payload.MaxHashesCount-1),
+		payload.NewGetBlockByIndex(1000, payload.MaxHashesCount),
+	}, bs.assign(p2, 0))
+
+	// Processed ranges are removed.
+	require.Equal(t, 0, len(bs.expire(1500)))
+	require.Equal(t, []*payload.GetBlockByIndex{
+		payload.NewGetBlockByIndex(1501, payload.MaxHashesCount-1),
+		payload.NewGetBlockByIndex(2000, payload.MaxHashesCount),
+	}, bs.assign(p2, 1500))
+}
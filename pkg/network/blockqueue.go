@@ -4,6 +4,7 @@ import (
 	"github.com/Workiva/go-datastructures/queue"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"go.uber.org/atomic"
 	"go.uber.org/zap"
 )
 
@@ -13,6 +14,7 @@ type blockQueue struct {
 	checkBlocks chan struct{}
 	chain       blockchainer.Blockchainer
 	relayF      func(*block.Block)
+	halted      *atomic.Bool
 }
 
 const (
@@ -32,6 +34,7 @@ func newBlockQueue(capacity int, bc blockchainer.Blockchainer, log *zap.Logger,
 		checkBlocks: make(chan struct{}, 1),
 		chain:       bc,
 		relayF:      relayer,
+		halted:      atomic.NewBool(false),
 	}
 }
 
@@ -41,6 +44,9 @@ func (bq *blockQueue) run() {
 		if !ok {
 			break
 		}
+		if bq.halted.Load() {
+			continue
+		}
 		for {
 			item := bq.queue.Peek()
 			if item == nil {
@@ -90,6 +96,11 @@ func (bq *blockQueue) putBlock(block *block.Block) error {
 	return err
 }
 
+// halt stops adding blocks from the queue to the chain.
+func (bq *blockQueue) halt() {
+	bq.halted.Store(true)
+}
+
 func (bq *blockQueue) discard() {
 	close(bq.checkBlocks)
 	bq.queue.Dispose()
@@ -26,7 +26,7 @@ func (cs *Capabilities) EncodeBinary(br *io.BinWriter) {
 // checkUniqueCapabilities checks whether payload capabilities have unique type.
 func (cs Capabilities) checkUniqueCapabilities() error {
 	err := errors.New("capabilities with the same type are not allowed")
-	var isFullNode, isTCP, isWS bool
+	var isFullNode, isTCP, isWS, isTLS, isCompact bool
 	for _, cap := range cs {
 		switch cap.Type {
 		case FullNode:
@@ -44,6 +44,16 @@ func (cs Capabilities) checkUniqueCapabilities() error {
 				return err
 			}
 			isWS = true
+		case TLSServer:
+			if isTLS {
+				return err
+			}
+			isTLS = true
+		case CompactBlocks:
+			if isCompact {
+				return err
+			}
+			isCompact = true
 		}
 	}
 	return nil
@@ -61,8 +71,10 @@ func (c *Capability) DecodeBinary(br *io.BinReader) {
 	switch c.Type {
 	case FullNode:
 		c.Data = &Node{}
-	case TCPServer, WSServer:
+	case TCPServer, WSServer, TLSServer:
 		c.Data = &Server{}
+	case CompactBlocks:
+		c.Data = &Compact{}
 	default:
 		br.Err = errors.New("unknown node capability type")
 		return
@@ -110,3 +122,20 @@ func (s *Server) DecodeBinary(br *io.BinReader) {
 func (s *Server) EncodeBinary(bw *io.BinWriter) {
 	bw.WriteU16LE(s.Port)
 }
+
+// Compact represents compact block relay capability with protocol version.
+type Compact struct {
+	// Version is the compact block relay protocol version, only 0 is
+	// defined now.
+	Version byte
+}
+
+// DecodeBinary implements Serializable interface.
+func (c *Compact) DecodeBinary(br *io.BinReader) {
+	c.Version = br.ReadB()
+}
+
+// EncodeBinary implements Serializable interface.
+func (c *Compact) EncodeBinary(bw *io.BinWriter) {
+	bw.WriteB(c.Version)
+}
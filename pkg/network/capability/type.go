@@ -10,4 +10,12 @@ const (
 	WSServer Type = 0x02
 	// FullNode represents full node capability type.
 	FullNode Type = 0x10
+	// TLSServer represents encrypted (mutual TLS) TCP node capability type.
+	// It's NeoGo-specific and is not supported by other implementations,
+	// so it's only announced by nodes with encrypted transport enabled.
+	TLSServer Type = 0xf0
+	// CompactBlocks represents compact block relay support. It's
+	// NeoGo-specific as well and is only announced by nodes with compact
+	// block relay enabled.
+	CompactBlocks Type = 0xf1
 )
@@ -0,0 +1,261 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/network/capability"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+const (
+	// maxPendingCompactBlocks is the maximum number of compact blocks
+	// waiting for missing transactions, full blocks are requested when
+	// it's exceeded.
+	maxPendingCompactBlocks = 16
+	// maxCompactPrefill is the maximum number of blocks prefilled
+	// transactions are remembered for.
+	maxCompactPrefill = 16
+)
+
+// pendingBlock is a block reconstructed from compact announcement that lacks
+// some transactions.
+type pendingBlock struct {
+	peer    Peer
+	block   *block.Block
+	missing []uint16
+}
+
+// compactRelay keeps compact block relay state.
+type compactRelay struct {
+	lock sync.Mutex
+	// pending contains blocks waiting for missing transactions.
+	pending map[util.Uint256]*pendingBlock
+	// prefill contains indexes of transactions that were missing when
+	// the block was reconstructed, they're likely to be missing for our
+	// peers too, so they're prefilled when the block is relayed further.
+	prefill map[util.Uint256][]uint16
+	// hosts are IP addresses of peers known to support compact block
+	// relay.
+	hosts map[string]bool
+}
+
+func newCompactRelay() *compactRelay {
+	return &compactRelay{
+		pending: make(map[util.Uint256]*pendingBlock),
+		prefill: make(map[util.Uint256][]uint16),
+	}
+}
+
+// setHosts resolves host names of peers known to support compact block relay.
+func (c *compactRelay) setHosts(hosts []string) error {
+	c.hosts = make(map[string]bool, len(hosts))
+	for _, h := range hosts {
+		ips, err := net.LookupIP(h)
+		if err != nil {
+			return fmt.Errorf("can't resolve compact blocks peer %s: %w", h, err)
+		}
+		for _, ip := range ips {
+			c.hosts[ip.String()] = true
+		}
+	}
+	return nil
+}
+
+// isKnown checks whether the peer is known to support compact block relay.
+func (c *compactRelay) isKnown(p Peer) bool {
+	ip := addrIP(p.RemoteAddr())
+	return ip != nil && c.hosts[ip.String()]
+}
+
+// isPending checks whether the block with the given hash is waiting for
+// missing transactions.
+func (c *compactRelay) isPending(h util.Uint256) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, ok := c.pending[h]
+	return ok
+}
+
+// addPending adds the block to the list of pending ones dropping blocks that
+// are already in the chain. It returns false if there are too many pending
+// blocks.
+func (c *compactRelay) addPending(pb *pendingBlock, height uint32) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for h, b := range c.pending {
+		if b.block.Index <= height {
+			delete(c.pending, h)
+		}
+	}
+	if len(c.pending) >= maxPendingCompactBlocks {
+		return false
+	}
+	c.pending[pb.block.Hash()] = pb
+	return true
+}
+
+// takePending removes pending block with the given hash requested from the
+// given peer and returns it (if any).
+func (c *compactRelay) takePending(h util.Uint256, p Peer) *pendingBlock {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	pb, ok := c.pending[h]
+	if !ok || pb.peer != p {
+		return nil
+	}
+	delete(c.pending, h)
+	return pb
+}
+
+// setPrefill remembers transactions to prefill when the block is relayed.
+func (c *compactRelay) setPrefill(h util.Uint256, indexes []uint16) {
+	if len(indexes) == 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.prefill) >= maxCompactPrefill {
+		c.prefill = make(map[util.Uint256][]uint16)
+	}
+	c.prefill[h] = indexes
+}
+
+// takePrefill returns (and forgets) transactions to prefill for the block.
+func (c *compactRelay) takePrefill(h util.Uint256) []uint16 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	indexes := c.prefill[h]
+	delete(c.prefill, h)
+	return indexes
+}
+
+// supportsCompactBlocks checks whether the peer has announced compact block
+// relay support.
+func supportsCompactBlocks(p Peer) bool {
+	v := p.Version()
+	if v == nil {
+		return false
+	}
+	for _, c := range v.Capabilities {
+		if c.Type == capability.CompactBlocks {
+			return true
+		}
+	}
+	return false
+}
+
+// compactBlockMsg creates compact announcement of the given block.
+func (s *Server) compactBlockMsg(b *block.Block) *Message {
+	nonce := uint64(randomID())<<32 | uint64(randomID())
+	return NewMessage(CMDCompactBlock, payload.NewCompactBlock(b, nonce, s.compact.takePrefill(b.Hash())))
+}
+
+// handleCompactBlockCmd reconstructs the block from compact announcement using
+// transactions from the memory pool and requests missing ones from the peer.
+func (s *Server) handleCompactBlockCmd(p Peer, cb *payload.CompactBlock) error {
+	if !s.CompactBlocks {
+		return fmt.Errorf("%w: unexpected compact block", errProtocolViolation)
+	}
+	h := cb.Hash()
+	height := s.chain.BlockHeight()
+	if cb.Index <= height || s.compact.isPending(h) {
+		return nil
+	}
+
+	b := &block.Block{
+		Header:       *cb.Header,
+		Transactions: make([]*transaction.Transaction, cb.TxCount()),
+	}
+	for _, pt := range cb.Prefilled {
+		b.Transactions[pt.Index] = pt.Transaction
+	}
+	// Transactions with colliding short IDs are treated as missing.
+	pool := make(map[uint64]*transaction.Transaction)
+	for _, tx := range s.chain.GetMemPool().GetVerifiedTransactions() {
+		id := cb.ShortID(tx.Hash())
+		if _, ok := pool[id]; ok {
+			pool[id] = nil
+		} else {
+			pool[id] = tx
+		}
+	}
+	var (
+		missing []uint16
+		j       int
+	)
+	for i := range b.Transactions {
+		if b.Transactions[i] != nil {
+			continue
+		}
+		if tx := pool[cb.ShortIDs[j]]; tx != nil {
+			b.Transactions[i] = tx
+		} else {
+			missing = append(missing, uint16(i))
+		}
+		j++
+	}
+	if len(missing) == 0 {
+		return s.completeCompactBlock(p, b, nil)
+	}
+	if !s.compact.addPending(&pendingBlock{peer: p, block: b, missing: missing}, height) {
+		return s.requestFullBlock(p, h)
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockTxn, &payload.GetBlockTxn{BlockHash: h, Indexes: missing}))
+}
+
+// handleGetBlockTxnCmd sends requested transactions of the block to the peer.
+func (s *Server) handleGetBlockTxnCmd(p Peer, req *payload.GetBlockTxn) error {
+	b, err := s.chain.GetBlock(req.BlockHash)
+	if err != nil {
+		// Peer will get the full block from someone else.
+		return nil
+	}
+	res := &payload.BlockTxn{
+		BlockHash:    req.BlockHash,
+		Transactions: make([]*transaction.Transaction, 0, len(req.Indexes)),
+	}
+	for _, i := range req.Indexes {
+		if int(i) >= len(b.Transactions) {
+			return fmt.Errorf("%w: bad transaction index %d requested", errProtocolViolation, i)
+		}
+		res.Transactions = append(res.Transactions, b.Transactions[i])
+	}
+	return p.EnqueueP2PMessage(NewMessage(CMDBlockTxn, res))
+}
+
+// handleBlockTxnCmd completes pending block with the received transactions.
+func (s *Server) handleBlockTxnCmd(p Peer, bt *payload.BlockTxn) error {
+	pb := s.compact.takePending(bt.BlockHash, p)
+	if pb == nil {
+		// Not requested or too late.
+		return nil
+	}
+	if len(bt.Transactions) != len(pb.missing) {
+		return s.requestFullBlock(p, bt.BlockHash)
+	}
+	for i, idx := range pb.missing {
+		pb.block.Transactions[idx] = bt.Transactions[i]
+	}
+	return s.completeCompactBlock(p, pb.block, pb.missing)
+}
+
+// completeCompactBlock checks reconstructed block and processes it as a
+// regular one. Full block is requested if the block doesn't match its
+// header which can happen because of short ID collisions.
+func (s *Server) completeCompactBlock(p Peer, b *block.Block, missing []uint16) error {
+	if !b.ComputeMerkleRoot().Equals(b.MerkleRoot) {
+		return s.requestFullBlock(p, b.Hash())
+	}
+	s.compact.setPrefill(b.Hash(), missing)
+	return s.handleBlockCmd(p, b)
+}
+
+// requestFullBlock requests the block with the given hash from the peer.
+func (s *Server) requestFullBlock(p Peer, h util.Uint256) error {
+	return p.EnqueueP2PMessage(NewMessage(CMDGetData, payload.NewInventory(payload.BlockType, []util.Uint256{h})))
+}
@@ -0,0 +1,195 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/fakechain"
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/network/capability"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+	atomic2 "go.uber.org/atomic"
+)
+
+func newCompactTestBlock(index uint32, txCount int) *block.Block {
+	b := block.New(false)
+	b.Index = index
+	b.PrevHash = random.Uint256()
+	b.Script.InvocationScript = random.Bytes(2)
+	b.Script.VerificationScript = random.Bytes(3)
+	for i := 0; i < txCount; i++ {
+		b.Transactions = append(b.Transactions, newDummyTx())
+	}
+	b.RebuildMerkleRoot()
+	b.Hash()
+	return b
+}
+
+func TestCompactBlockDisabled(t *testing.T) {
+	s := newTestServer(t, ServerConfig{})
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	cb := payload.NewCompactBlock(newCompactTestBlock(1, 1), 1, nil)
+	require.Error(t, s.handleMessage(p, NewMessage(CMDCompactBlock, cb)))
+}
+
+func TestCompactBlock(t *testing.T) {
+	s := newTestServer(t, ServerConfig{CompactBlocks: true})
+	ch := startWithChannel(s)
+	t.Cleanup(func() {
+		s.Shutdown()
+		<-ch
+	})
+	bc := s.chain.(*fakechain.FakeChain)
+	atomic2.StoreUint32(&bc.Blockheight, 1)
+
+	var received []*Message
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		received = append(received, msg)
+	}
+
+	t.Run("reconstruct", func(t *testing.T) {
+		b := newCompactTestBlock(2, 4)
+		for _, tx := range b.Transactions[1:3] {
+			require.NoError(t, bc.Pool.Add(tx, &feerStub{blockHeight: 10}))
+		}
+		received = nil
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42, []uint16{0}))
+		require.Equal(t, 1, len(received))
+		require.Equal(t, CMDGetBlockTxn, received[0].Command)
+		req := received[0].Payload.(*payload.GetBlockTxn)
+		require.Equal(t, b.Hash(), req.BlockHash)
+		require.Equal(t, []uint16{3}, req.Indexes)
+		require.True(t, s.compact.isPending(b.Hash()))
+
+		// Another announcement of the same block is ignored.
+		received = nil
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 43, nil))
+		require.Equal(t, 0, len(received))
+
+		// Unrequested transactions are ignored.
+		s.testHandleMessage(t, nil, CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    b.Hash(),
+			Transactions: b.Transactions[3:],
+		})
+		require.True(t, s.compact.isPending(b.Hash()))
+
+		s.testHandleMessage(t, p, CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    b.Hash(),
+			Transactions: b.Transactions[3:],
+		})
+		require.False(t, s.compact.isPending(b.Hash()))
+		require.Eventually(t, func() bool { return bc.BlockHeight() == 2 }, time.Second, 10*time.Millisecond)
+
+		// Missing transaction is prefilled when the block is relayed.
+		msg := s.compactBlockMsg(b)
+		cb := msg.Payload.(*payload.CompactBlock)
+		require.Equal(t, 1, len(cb.Prefilled))
+		require.Equal(t, uint16(3), cb.Prefilled[0].Index)
+		require.Equal(t, 3, len(cb.ShortIDs))
+	})
+
+	t.Run("all transactions known", func(t *testing.T) {
+		b := newCompactTestBlock(3, 2)
+		for _, tx := range b.Transactions {
+			require.NoError(t, bc.Pool.Add(tx, &feerStub{blockHeight: 10}))
+		}
+		received = nil
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42, nil))
+		require.Equal(t, 0, len(received))
+		require.Eventually(t, func() bool { return bc.BlockHeight() == 3 }, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("old block", func(t *testing.T) {
+		received = nil
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(newCompactTestBlock(3, 1), 42, nil))
+		require.Equal(t, 0, len(received))
+	})
+
+	t.Run("fallback to full block", func(t *testing.T) {
+		b := newCompactTestBlock(4, 2)
+		received = nil
+		s.testHandleMessage(t, p, CMDCompactBlock, payload.NewCompactBlock(b, 42, nil))
+		require.Equal(t, 1, len(received))
+		require.Equal(t, []uint16{0, 1}, received[0].Payload.(*payload.GetBlockTxn).Indexes)
+
+		received = nil
+		s.testHandleMessage(t, p, CMDBlockTxn, &payload.BlockTxn{
+			BlockHash:    b.Hash(),
+			Transactions: []*transaction.Transaction{b.Transactions[0], newDummyTx()},
+		})
+		require.Equal(t, 1, len(received))
+		require.Equal(t, CMDGetData, received[0].Command)
+		inv := received[0].Payload.(*payload.Inventory)
+		require.Equal(t, payload.BlockType, inv.Type)
+		require.Equal(t, []util.Uint256{b.Hash()}, inv.Hashes)
+	})
+}
+
+func TestGetBlockTxn(t *testing.T) {
+	s := newTestServer(t, ServerConfig{CompactBlocks: true})
+	b := newCompactTestBlock(1, 3)
+	s.chain.(*fakechain.FakeChain).PutBlock(b)
+
+	var received []*Message
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		received = append(received, msg)
+	}
+
+	s.testHandleMessage(t, p, CMDGetBlockTxn, &payload.GetBlockTxn{BlockHash: b.Hash(), Indexes: []uint16{0, 2}})
+	require.Equal(t, 1, len(received))
+	require.Equal(t, CMDBlockTxn, received[0].Command)
+	bt := received[0].Payload.(*payload.BlockTxn)
+	require.Equal(t, b.Hash(), bt.BlockHash)
+	require.Equal(t, 2, len(bt.Transactions))
+	require.Equal(t, b.Transactions[0].Hash(), bt.Transactions[0].Hash())
+	require.Equal(t, b.Transactions[2].Hash(), bt.Transactions[1].Hash())
+
+	// Unknown block.
+	received = nil
+	s.testHandleMessage(t, p, CMDGetBlockTxn, &payload.GetBlockTxn{BlockHash: random.Uint256(), Indexes: []uint16{0}})
+	require.Equal(t, 0, len(received))
+
+	err := s.handleMessage(p, NewMessage(CMDGetBlockTxn, &payload.GetBlockTxn{BlockHash: b.Hash(), Indexes: []uint16{3}}))
+	require.True(t, errors.Is(err, errProtocolViolation))
+}
+
+func TestSupportsCompactBlocks(t *testing.T) {
+	s := newTestServer(t, ServerConfig{CompactBlocks: true})
+	p := newLocalPeer(t, s)
+	require.False(t, supportsCompactBlocks(p))
+	p.version = payload.NewVersion(s.Net, 42, "/test/", nil)
+	require.False(t, supportsCompactBlocks(p))
+	p.version = payload.NewVersion(s.Net, 42, "/test/", []capability.Capability{{
+		Type: capability.CompactBlocks,
+		Data: &capability.Compact{},
+	}})
+	require.True(t, supportsCompactBlocks(p))
+
+	announced := func(t *testing.T, p Peer) bool {
+		msg, err := s.getVersionMsg(p)
+		require.NoError(t, err)
+		var found bool
+		for _, c := range msg.Payload.(*payload.Version).Capabilities {
+			found = found || c.Type == capability.CompactBlocks
+		}
+		return found
+	}
+	// Capability is only announced to known peers.
+	require.False(t, announced(t, p))
+	require.NoError(t, s.compact.setHosts([]string{"localhost"}))
+	p.netaddr.IP = net.IPv4(127, 0, 0, 1)
+	require.True(t, announced(t, p))
+	s.CompactBlocks = false
+	require.False(t, announced(t, p))
+}
@@ -1,15 +1,31 @@
 package network
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/network/capability"
+	"go.uber.org/zap"
 )
 
 const (
 	maxPoolSize = 200
 	connRetries = 3
+
+	// defaultBanScore is the default penalty peer can accumulate before
+	// being banned.
+	defaultBanScore = 100
+	// defaultBanDuration is the default duration of automatic bans.
+	defaultBanDuration = 24 * time.Hour
+	// maxScore is the maximum reputation score peer can get for useful
+	// data, it limits the amount of misbehaviour good peers can get away with.
+	maxScore = 100
 )
 
 // Discoverer is an interface that is responsible for maintaining
@@ -26,6 +42,21 @@ type Discoverer interface {
 	UnconnectedPeers() []string
 	BadPeers() []string
 	GoodPeers() []AddressWithCapabilities
+
+	// AdjustScore changes reputation score of the peer with the given
+	// address by delta, it returns true if the peer gets banned because
+	// of that.
+	AdjustScore(string, int) bool
+	// Score returns current reputation score of the given address.
+	Score(string) int
+	// Ban bans the given address (only host part of it is used) for the
+	// given duration, default ban duration is used if it's zero.
+	Ban(string, time.Duration)
+	// Unban removes the ban for the given address returning false if it
+	// wasn't banned.
+	Unban(string) bool
+	IsBanned(string) bool
+	BannedPeers() []BannedAddress
 }
 
 // AddressWithCapabilities represents node address with its capabilities.
@@ -34,6 +65,37 @@ type AddressWithCapabilities struct {
 	Capabilities capability.Capabilities
 }
 
+// BannedAddress represents banned host along with the ban expiration time.
+type BannedAddress struct {
+	Host  string
+	Until time.Time
+}
+
+// DiscoveryConfig contains DefaultDiscovery parameters.
+type DiscoveryConfig struct {
+	// Seeds are the addresses used when there is nothing else to connect to.
+	Seeds []string
+	// DialTimeout is the maximum duration a single dial may take.
+	DialTimeout time.Duration
+	// BanScore is the penalty peer can accumulate before being banned.
+	BanScore int
+	// BanDuration is the duration of automatic bans.
+	BanDuration time.Duration
+	// AddressBookFile is the file to store known addresses, reputation
+	// scores and bans to, nothing is stored if it's empty.
+	AddressBookFile string
+	// Log is used to report address book errors.
+	Log *zap.Logger
+}
+
+// addressBook is the persistent part of the DefaultDiscovery state.
+type addressBook struct {
+	Addresses []string             `json:"addresses"`
+	Bad       []string             `json:"bad"`
+	Scores    map[string]int       `json:"scores"`
+	Bans      map[string]time.Time `json:"bans"`
+}
+
 // DefaultDiscovery default implementation of the Discoverer interface.
 type DefaultDiscovery struct {
 	seeds            []string
@@ -41,36 +103,73 @@ type DefaultDiscovery struct {
 	lock             sync.RWMutex
 	closeMtx         sync.RWMutex
 	dialTimeout      time.Duration
+	banScore         int
+	banDuration      time.Duration
+	bookFile         string
+	log              *zap.Logger
 	badAddrs         map[string]bool
 	connectedAddrs   map[string]bool
 	goodAddrs        map[string]capability.Capabilities
 	unconnectedAddrs map[string]int
 	attempted        map[string]bool
+	scores           map[string]int
+	bans             map[string]time.Time
 	isDead           bool
 	requestCh        chan int
 	pool             chan string
 }
 
-// NewDefaultDiscovery returns a new DefaultDiscovery.
-func NewDefaultDiscovery(addrs []string, dt time.Duration, ts Transporter) *DefaultDiscovery {
+// NewDefaultDiscovery returns a new DefaultDiscovery. If address book file is
+// configured, its contents are loaded to the discoverer.
+func NewDefaultDiscovery(cfg DiscoveryConfig, ts Transporter) (*DefaultDiscovery, error) {
 	d := &DefaultDiscovery{
-		seeds:            addrs,
+		seeds:            cfg.Seeds,
 		transport:        ts,
-		dialTimeout:      dt,
+		dialTimeout:      cfg.DialTimeout,
+		banScore:         cfg.BanScore,
+		banDuration:      cfg.BanDuration,
+		bookFile:         cfg.AddressBookFile,
+		log:              cfg.Log,
 		badAddrs:         make(map[string]bool),
 		connectedAddrs:   make(map[string]bool),
 		goodAddrs:        make(map[string]capability.Capabilities),
 		unconnectedAddrs: make(map[string]int),
 		attempted:        make(map[string]bool),
+		scores:           make(map[string]int),
+		bans:             make(map[string]time.Time),
 		requestCh:        make(chan int),
 		pool:             make(chan string, maxPoolSize),
 	}
+	if d.banScore <= 0 {
+		d.banScore = defaultBanScore
+	}
+	if d.banDuration <= 0 {
+		d.banDuration = defaultBanDuration
+	}
+	if d.log == nil {
+		d.log = zap.NewNop()
+	}
+	if d.bookFile != "" {
+		if err := d.loadAddressBook(); err != nil {
+			return nil, err
+		}
+	}
 	go d.run()
-	return d
+	return d, nil
 }
 
-func newDefaultDiscovery(addrs []string, dt time.Duration, ts Transporter) Discoverer {
-	return NewDefaultDiscovery(addrs, dt, ts)
+func newDefaultDiscovery(cfg DiscoveryConfig, ts Transporter) (Discoverer, error) {
+	return NewDefaultDiscovery(cfg, ts)
+}
+
+// hostOf returns host part of the given address (or the address itself if
+// it has no port).
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
 }
 
 // BackFill implements the Discoverer interface and will backfill the
@@ -79,7 +178,7 @@ func (d *DefaultDiscovery) BackFill(addrs ...string) {
 	d.lock.Lock()
 	for _, addr := range addrs {
 		if d.badAddrs[addr] || d.connectedAddrs[addr] ||
-			d.unconnectedAddrs[addr] > 0 {
+			d.unconnectedAddrs[addr] > 0 || d.isBanned(addr) {
 			continue
 		}
 		d.unconnectedAddrs[addr] = connRetries
@@ -201,11 +300,192 @@ func (d *DefaultDiscovery) tryAddress(addr string) {
 	}
 }
 
+// AdjustScore implements the Discoverer interface. Scores are kept per host,
+// positive values are capped by maxScore and the host is banned for the
+// configured duration once its score drops to -BanScore.
+func (d *DefaultDiscovery) AdjustScore(addr string, delta int) bool {
+	host := hostOf(addr)
+	d.lock.Lock()
+	score := d.scores[host] + delta
+	if score > maxScore {
+		score = maxScore
+	}
+	if score > -d.banScore {
+		if score == 0 {
+			delete(d.scores, host)
+		} else {
+			d.scores[host] = score
+		}
+		d.lock.Unlock()
+		return false
+	}
+	delete(d.scores, host)
+	d.bans[host] = time.Now().Add(d.banDuration)
+	d.lock.Unlock()
+	d.saveAddressBook()
+	return true
+}
+
+// Score implements the Discoverer interface.
+func (d *DefaultDiscovery) Score(addr string) int {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.scores[hostOf(addr)]
+}
+
+// Ban implements the Discoverer interface.
+func (d *DefaultDiscovery) Ban(addr string, dur time.Duration) {
+	if dur <= 0 {
+		dur = d.banDuration
+	}
+	host := hostOf(addr)
+	d.lock.Lock()
+	delete(d.scores, host)
+	d.bans[host] = time.Now().Add(dur)
+	d.lock.Unlock()
+	d.saveAddressBook()
+}
+
+// Unban implements the Discoverer interface.
+func (d *DefaultDiscovery) Unban(addr string) bool {
+	host := hostOf(addr)
+	d.lock.Lock()
+	_, ok := d.bans[host]
+	delete(d.bans, host)
+	d.lock.Unlock()
+	if ok {
+		d.saveAddressBook()
+	}
+	return ok
+}
+
+// IsBanned implements the Discoverer interface.
+func (d *DefaultDiscovery) IsBanned(addr string) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.isBanned(addr)
+}
+
+// isBanned checks whether the given address is banned and removes expired
+// ban if there is one. It must be called with the lock held.
+func (d *DefaultDiscovery) isBanned(addr string) bool {
+	host := hostOf(addr)
+	until, ok := d.bans[host]
+	if ok && !time.Now().Before(until) {
+		delete(d.bans, host)
+		return false
+	}
+	return ok
+}
+
+// BannedPeers implements the Discoverer interface.
+func (d *DefaultDiscovery) BannedPeers() []BannedAddress {
+	var now = time.Now()
+	d.lock.RLock()
+	res := make([]BannedAddress, 0, len(d.bans))
+	for host, until := range d.bans {
+		if now.Before(until) {
+			res = append(res, BannedAddress{Host: host, Until: until})
+		}
+	}
+	d.lock.RUnlock()
+	return res
+}
+
+// loadAddressBook restores discoverer state from the address book file if
+// it exists. Known addresses are put into the pool for future connections
+// (their capabilities are not stored, so they're not considered to be good
+// until the handshake).
+func (d *DefaultDiscovery) loadAddressBook() error {
+	data, err := ioutil.ReadFile(d.bookFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("can't read address book: %w", err)
+	}
+	var book addressBook
+	if err := json.Unmarshal(data, &book); err != nil {
+		return fmt.Errorf("can't decode address book: %w", err)
+	}
+	var now = time.Now()
+	for host, until := range book.Bans {
+		if now.Before(until) {
+			d.bans[host] = until
+		}
+	}
+	for host, score := range book.Scores {
+		d.scores[host] = score
+	}
+	for _, addr := range book.Bad {
+		d.badAddrs[addr] = true
+	}
+	d.BackFill(book.Addresses...)
+	return nil
+}
+
+// saveAddressBook writes current discoverer state to the address book file
+// (if it's configured).
+func (d *DefaultDiscovery) saveAddressBook() {
+	if d.bookFile == "" {
+		return
+	}
+	var book = addressBook{
+		Scores: make(map[string]int),
+		Bans:   make(map[string]time.Time),
+	}
+	d.lock.RLock()
+	known := make(map[string]bool, len(d.goodAddrs)+len(d.unconnectedAddrs)+len(d.connectedAddrs))
+	for addr := range d.goodAddrs {
+		known[addr] = true
+	}
+	for addr := range d.unconnectedAddrs {
+		known[addr] = true
+	}
+	for addr := range d.connectedAddrs {
+		known[addr] = true
+	}
+	for addr := range known {
+		book.Addresses = append(book.Addresses, addr)
+	}
+	for addr := range d.badAddrs {
+		book.Bad = append(book.Bad, addr)
+	}
+	for host, score := range d.scores {
+		book.Scores[host] = score
+	}
+	for host, until := range d.bans {
+		book.Bans[host] = until
+	}
+	d.lock.RUnlock()
+	sort.Strings(book.Addresses)
+	sort.Strings(book.Bad)
+
+	data, err := json.MarshalIndent(book, "", "  ")
+	if err == nil {
+		// Rename is atomic, so the file is either old or new, never broken.
+		tmp := d.bookFile + ".tmp"
+		err = ioutil.WriteFile(tmp, data, 0644)
+		if err == nil {
+			err = os.Rename(tmp, d.bookFile)
+		}
+	}
+	if err != nil {
+		d.log.Warn("failed to save address book", zap.String("file", d.bookFile), zap.Error(err))
+	}
+}
+
 // Close stops discoverer pool processing making discoverer almost useless.
+// Address book is saved if it's configured.
 func (d *DefaultDiscovery) Close() {
 	d.closeMtx.Lock()
+	if d.isDead {
+		d.closeMtx.Unlock()
+		return
+	}
 	d.isDead = true
 	d.closeMtx.Unlock()
+	d.saveAddressBook()
 	select {
 	case <-d.requestCh: // Drain the channel if there is anything there.
 	default:
@@ -233,7 +513,7 @@ func (d *DefaultDiscovery) run() {
 			case addr := <-d.pool:
 				updatePoolCountMetric(d.PoolCount())
 				d.lock.Lock()
-				if !d.connectedAddrs[addr] && !d.attempted[addr] {
+				if !d.connectedAddrs[addr] && !d.attempted[addr] && !d.isBanned(addr) {
 					d.attempted[addr] = true
 					go d.tryAddress(addr)
 					requested--
@@ -243,7 +523,7 @@ func (d *DefaultDiscovery) run() {
 				var added int
 				d.lock.Lock()
 				for _, addr := range d.seeds {
-					if !d.connectedAddrs[addr] {
+					if !d.connectedAddrs[addr] && !d.isBanned(addr) {
 						delete(d.badAddrs, addr)
 						d.unconnectedAddrs[addr] = connRetries
 						d.pushToPoolOrDrop(addr)
@@ -2,7 +2,10 @@ package network
 
 import (
 	"errors"
+	"io/ioutil"
 	"net"
+	"os"
+	"path/filepath"
 	"sort"
 	"sync/atomic"
 	"testing"
@@ -57,7 +60,8 @@ func (ft *fakeTransp) Close() {
 func TestDefaultDiscoverer(t *testing.T) {
 	ts := &fakeTransp{}
 	ts.dialCh = make(chan string)
-	d := NewDefaultDiscovery(nil, time.Second/16, ts)
+	d, err := NewDefaultDiscovery(DiscoveryConfig{DialTimeout: time.Second / 16}, ts)
+	require.NoError(t, err)
 
 	var set1 = []string{"1.1.1.1:10333", "2.2.2.2:10333"}
 	sort.Strings(set1)
@@ -187,7 +191,8 @@ func TestSeedDiscovery(t *testing.T) {
 	atomic.StoreInt32(&ts.retFalse, 1) // Fail all dial requests.
 	sort.Strings(seeds)
 
-	d := NewDefaultDiscovery(seeds, time.Second/10, ts)
+	d, err := NewDefaultDiscovery(DiscoveryConfig{Seeds: seeds, DialTimeout: time.Second / 10}, ts)
+	require.NoError(t, err)
 
 	d.RequestRemote(len(seeds))
 	for i := 0; i < connRetries*2; i++ {
@@ -200,3 +205,85 @@ func TestSeedDiscovery(t *testing.T) {
 		}
 	}
 }
+
+func TestDiscoveryBans(t *testing.T) {
+	ts := &fakeTransp{}
+	ts.dialCh = make(chan string)
+	d, err := NewDefaultDiscovery(DiscoveryConfig{BanScore: 30, BanDuration: time.Hour}, ts)
+	require.NoError(t, err)
+	defer d.Close()
+
+	const addr = "1.1.1.1:10333"
+
+	// Scores are tracked per host and capped.
+	require.False(t, d.AdjustScore(addr, maxScore+10))
+	require.Equal(t, maxScore, d.Score("1.1.1.1:20333"))
+	require.False(t, d.AdjustScore(addr, -maxScore))
+	require.Equal(t, 0, d.Score(addr))
+
+	require.False(t, d.AdjustScore(addr, -20))
+	require.Equal(t, -20, d.Score(addr))
+	require.False(t, d.IsBanned(addr))
+	require.True(t, d.AdjustScore(addr, -10))
+	require.True(t, d.IsBanned(addr))
+	require.True(t, d.IsBanned("1.1.1.1:20333"))
+	require.Equal(t, 0, d.Score(addr))
+
+	// Banned addresses are not added to the pool.
+	d.BackFill(addr)
+	require.Equal(t, 0, d.PoolCount())
+
+	banned := d.BannedPeers()
+	require.Equal(t, 1, len(banned))
+	require.Equal(t, "1.1.1.1", banned[0].Host)
+	require.True(t, banned[0].Until.After(time.Now().Add(time.Hour-time.Minute)))
+
+	require.True(t, d.Unban(addr))
+	require.False(t, d.Unban(addr))
+	require.False(t, d.IsBanned(addr))
+	d.BackFill(addr)
+	require.Equal(t, 1, d.PoolCount())
+
+	// Expired bans are removed.
+	d.Ban("2.2.2.2", -1)
+	require.True(t, d.IsBanned("2.2.2.2:10333"))
+	d.Ban("2.2.2.2", time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	require.False(t, d.IsBanned("2.2.2.2:10333"))
+	require.Equal(t, 0, len(d.BannedPeers()))
+}
+
+func TestDiscoveryAddressBook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "addrbook")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	var (
+		book = filepath.Join(dir, "peers.json")
+		cfg  = DiscoveryConfig{AddressBookFile: book}
+	)
+	ts := &fakeTransp{}
+	ts.dialCh = make(chan string)
+	d, err := NewDefaultDiscovery(cfg, ts)
+	require.NoError(t, err)
+
+	d.BackFill("1.1.1.1:10333", "2.2.2.2:10333")
+	d.RegisterBadAddr("3.3.3.3:10333")
+	d.AdjustScore("4.4.4.4:10333", -5)
+	d.Ban("5.5.5.5", 0)
+	d.Close()
+
+	d, err = NewDefaultDiscovery(cfg, ts)
+	require.NoError(t, err)
+	defer d.Close()
+
+	unconnected := d.UnconnectedPeers()
+	sort.Strings(unconnected)
+	require.Equal(t, []string{"1.1.1.1:10333", "2.2.2.2:10333"}, unconnected)
+	require.Equal(t, []string{"3.3.3.3:10333"}, d.BadPeers())
+	require.Equal(t, -5, d.Score("4.4.4.4"))
+	require.True(t, d.IsBanned("5.5.5.5:10333"))
+
+	require.NoError(t, ioutil.WriteFile(book, []byte("not a json"), 0644))
+	_, err = NewDefaultDiscovery(cfg, ts)
+	require.Error(t, err)
+}
@@ -24,7 +24,9 @@ type testDiscovery struct {
 	backfill     []string
 }
 
-func newTestDiscovery([]string, time.Duration, Transporter) Discoverer { return new(testDiscovery) }
+func newTestDiscovery(DiscoveryConfig, Transporter) (Discoverer, error) {
+	return new(testDiscovery), nil
+}
 
 func (d *testDiscovery) BackFill(addrs ...string) {
 	d.Lock()
@@ -61,6 +63,12 @@ func (d *testDiscovery) BadPeers() []string {
 	return d.bad
 }
 func (d *testDiscovery) GoodPeers() []AddressWithCapabilities { return []AddressWithCapabilities{} }
+func (d *testDiscovery) AdjustScore(string, int) bool         { return false }
+func (d *testDiscovery) Score(string) int                     { return 0 }
+func (d *testDiscovery) Ban(string, time.Duration)            {}
+func (d *testDiscovery) Unban(string) bool                    { return false }
+func (d *testDiscovery) IsBanned(string) bool                 { return false }
+func (d *testDiscovery) BannedPeers() []BannedAddress         { return nil }
 
 var defaultMessageHandler = func(t *testing.T, msg *Message) {}
 
@@ -76,6 +84,7 @@ type localPeer struct {
 	pingSent       int
 	getAddrSent    int
 	droppedWith    atomic.Value
+	encrypted      bool
 }
 
 func newLocalPeer(t *testing.T, s *Server) *localPeer {
@@ -88,6 +97,9 @@ func newLocalPeer(t *testing.T, s *Server) *localPeer {
 	}
 }
 
+func (p *localPeer) isEncrypted() bool {
+	return p.encrypted
+}
 func (p *localPeer) RemoteAddr() net.Addr {
 	return &p.netaddr
 }
@@ -139,7 +151,7 @@ func (p *localPeer) HandleVersion(v *payload.Version) error {
 	return nil
 }
 func (p *localPeer) SendVersion() error {
-	m, err := p.server.getVersionMsg()
+	m, err := p.server.getVersionMsg(p)
 	if err != nil {
 		return err
 	}
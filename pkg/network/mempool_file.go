@@ -0,0 +1,196 @@
+package network
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"go.uber.org/zap"
+)
+
+const (
+	// memPoolFileVersion is the version of memory pool file format.
+	memPoolFileVersion = 0
+	// defaultMemPoolSaveInterval is the default interval between periodic
+	// memory pool saves.
+	defaultMemPoolSaveInterval = 5 * time.Minute
+)
+
+var errMemPoolFileMismatch = errors.New("memory pool file is for another network or format version")
+
+// memPoolSnapshot is the memory pools contents stored in the file.
+type memPoolSnapshot struct {
+	Transactions   []*transaction.Transaction
+	NotaryRequests []*payload.P2PNotaryRequest
+}
+
+// EncodeBinary implements io.Serializable interface.
+func (m *memPoolSnapshot) EncodeBinary(w *io.BinWriter) {
+	w.WriteArray(m.Transactions)
+	w.WriteArray(m.NotaryRequests)
+}
+
+// DecodeBinary implements io.Serializable interface.
+func (m *memPoolSnapshot) DecodeBinary(r *io.BinReader) {
+	r.ReadArray(&m.Transactions)
+	r.ReadArray(&m.NotaryRequests)
+}
+
+// getMemPoolSnapshot returns verified contents of memory pools.
+func (s *Server) getMemPoolSnapshot() *memPoolSnapshot {
+	m := &memPoolSnapshot{
+		Transactions: s.chain.GetMemPool().GetVerifiedTransactions(),
+	}
+	if s.chain.P2PSigExtensionsEnabled() {
+		for _, tx := range s.notaryRequestPool.GetVerifiedTransactions() {
+			if data, ok := s.notaryRequestPool.TryGetData(tx.Hash()); ok {
+				m.NotaryRequests = append(m.NotaryRequests, data.(*payload.P2PNotaryRequest))
+			}
+		}
+	}
+	return m
+}
+
+// saveMemPool writes memory pools contents to the file (if it's configured).
+func (s *Server) saveMemPool() {
+	if s.MemPoolFile == "" {
+		return
+	}
+	m := s.getMemPoolSnapshot()
+	w := io.NewBufBinWriter()
+	w.WriteU32LE(uint32(s.network))
+	w.WriteB(memPoolFileVersion)
+	m.EncodeBinary(w.BinWriter)
+	err := w.Err
+	if err == nil {
+		err = writeFileAtomic(s.MemPoolFile, w.Bytes())
+	}
+	if err != nil {
+		s.log.Warn("failed to save memory pool", zap.String("file", s.MemPoolFile), zap.Error(err))
+		return
+	}
+	s.log.Debug("memory pool saved",
+		zap.Int("transactions", len(m.Transactions)),
+		zap.Int("notaryRequests", len(m.NotaryRequests)))
+}
+
+// writeFileAtomic replaces the file with the given data so that after a crash
+// it's either old or new, but never broken. Data is synced to the temporary
+// file before the rename and the rename itself is synced via the directory.
+func writeFileAtomic(name string, data []byte) error {
+	tmp := name + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	// Directories can't be opened for syncing on Windows.
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	dir, err := os.Open(filepath.Dir(name))
+	if err != nil {
+		return err
+	}
+	err = dir.Sync()
+	if cerr := dir.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// loadMemPool reads memory pools contents from the file (if it exists) and
+// adds them back to the pools. Every item is verified again, expired and
+// invalid ones are dropped.
+func (s *Server) loadMemPool() {
+	if s.MemPoolFile == "" {
+		return
+	}
+	data, err := ioutil.ReadFile(s.MemPoolFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			s.log.Warn("failed to read memory pool file", zap.String("file", s.MemPoolFile), zap.Error(err))
+		}
+		return
+	}
+	var (
+		m = new(memPoolSnapshot)
+		r = io.NewBinReaderFromBuf(data)
+	)
+	magic := netmode.Magic(r.ReadU32LE())
+	version := r.ReadB()
+	if r.Err == nil && (magic != s.network || version != memPoolFileVersion) {
+		r.Err = errMemPoolFileMismatch
+	}
+	m.DecodeBinary(r)
+	if r.Err != nil {
+		s.log.Warn("failed to decode memory pool file", zap.String("file", s.MemPoolFile), zap.Error(r.Err))
+		return
+	}
+
+	var (
+		height           = s.chain.BlockHeight()
+		restored, failed int
+	)
+	for _, tx := range m.Transactions {
+		if tx.ValidUntilBlock <= height || s.chain.PoolTx(tx) != nil {
+			failed++
+			continue
+		}
+		restored++
+	}
+	for _, req := range m.NotaryRequests {
+		if !s.chain.P2PSigExtensionsEnabled() ||
+			req.FallbackTransaction.ValidUntilBlock <= height ||
+			s.verifyAndPoolNotaryRequest(req) != nil {
+			failed++
+			continue
+		}
+		restored++
+	}
+	s.log.Info("memory pool restored",
+		zap.Int("restored", restored),
+		zap.Int("dropped", failed))
+}
+
+// saveMemPoolLoop periodically saves memory pools contents until the server
+// is shut down.
+func (s *Server) saveMemPoolLoop() {
+	if s.MemPoolFile == "" {
+		return
+	}
+	interval := s.MemPoolSaveInterval
+	if interval <= 0 {
+		interval = defaultMemPoolSaveInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-t.C:
+			s.saveMemPool()
+		}
+	}
+}
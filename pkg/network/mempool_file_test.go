@@ -0,0 +1,127 @@
+package network
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/fakechain"
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNotaryRequest(validUntil uint32) *payload.P2PNotaryRequest {
+	mainTx := &transaction.Transaction{
+		Attributes:      []transaction.Attribute{{Type: transaction.NotaryAssistedT, Value: &transaction.NotaryAssisted{NKeys: 1}}},
+		Script:          []byte{0, 1, 2},
+		ValidUntilBlock: validUntil,
+		Signers:         []transaction.Signer{{Account: random.Uint160()}},
+		Scripts:         []transaction.Witness{{InvocationScript: []byte{1, 2, 3}, VerificationScript: []byte{1, 2, 3}}},
+	}
+	fallbackTx := &transaction.Transaction{
+		Script:          []byte{1, 2, 3},
+		ValidUntilBlock: validUntil,
+		Attributes: []transaction.Attribute{
+			{Type: transaction.NotValidBeforeT, Value: &transaction.NotValidBefore{Height: validUntil}},
+			{Type: transaction.ConflictsT, Value: &transaction.Conflicts{Hash: mainTx.Hash()}},
+			{Type: transaction.NotaryAssistedT, Value: &transaction.NotaryAssisted{NKeys: 0}},
+		},
+		Signers: []transaction.Signer{{Account: random.Uint160()}, {Account: random.Uint160()}},
+		Scripts: []transaction.Witness{{InvocationScript: append([]byte{byte(opcode.PUSHDATA1), 64}, make([]byte, 64)...), VerificationScript: make([]byte, 0)}, {InvocationScript: []byte{}, VerificationScript: []byte{}}},
+	}
+	r := &payload.P2PNotaryRequest{
+		MainTransaction:     mainTx,
+		FallbackTransaction: fallbackTx,
+		Witness: transaction.Witness{
+			InvocationScript:   []byte{1, 2, 3},
+			VerificationScript: []byte{1, 2, 3},
+		},
+	}
+	r.Hash()
+	return r
+}
+
+func TestMemPoolFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mempool")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	file := filepath.Join(dir, "mempool.bin")
+
+	s := newTestServer(t, ServerConfig{MemPoolFile: file})
+	// Nothing is loaded if there is no file.
+	s.loadMemPool()
+
+	txs := make([]*transaction.Transaction, 3)
+	for i := range txs {
+		txs[i] = transaction.New(random.Bytes(100), 123)
+		txs[i].ValidUntilBlock = uint32(10 * (i + 1))
+		txs[i].Signers = []transaction.Signer{{Account: random.Uint160()}}
+		txs[i].Scripts = []transaction.Witness{{InvocationScript: []byte{}, VerificationScript: []byte{}}}
+		require.NoError(t, s.chain.GetMemPool().Add(txs[i], &feerStub{blockHeight: 5}))
+	}
+	req := newTestNotaryRequest(123)
+	require.NoError(t, s.notaryRequestPool.Add(req.FallbackTransaction, s.chain, req))
+	s.saveMemPool()
+
+	data, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	r := io.NewBinReaderFromBuf(data)
+	require.Equal(t, uint32(netmode.UnitTestNet), r.ReadU32LE())
+	require.Equal(t, byte(memPoolFileVersion), r.ReadB())
+	m := new(memPoolSnapshot)
+	m.DecodeBinary(r)
+	require.NoError(t, r.Err)
+	require.Equal(t, 3, len(m.Transactions))
+	require.Equal(t, 1, len(m.NotaryRequests))
+	require.Equal(t, req.Hash(), m.NotaryRequests[0].Hash())
+
+	t.Run("restore", func(t *testing.T) {
+		s := newTestServer(t, ServerConfig{MemPoolFile: file})
+		bc := s.chain.(*fakechain.FakeChain)
+		bc.Blockheight = 10
+		var pooled []util.Uint256
+		bc.PoolTxF = func(tx *transaction.Transaction) error {
+			pooled = append(pooled, tx.Hash())
+			return nil
+		}
+		s.loadMemPool()
+		// The first transaction is expired.
+		require.ElementsMatch(t, []util.Uint256{txs[1].Hash(), txs[2].Hash()}, pooled)
+	})
+
+	t.Run("another network", func(t *testing.T) {
+		s := newTestServer(t, ServerConfig{MemPoolFile: file})
+		s.network = netmode.TestNet
+		var pooled int
+		s.chain.(*fakechain.FakeChain).PoolTxF = func(*transaction.Transaction) error {
+			pooled++
+			return nil
+		}
+		s.loadMemPool()
+		require.Equal(t, 0, pooled)
+	})
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "neogo.mempool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "mempool.dat")
+
+	require.NoError(t, writeFileAtomic(name, []byte{1, 2, 3}))
+	require.NoError(t, writeFileAtomic(name, []byte{4, 5}))
+	data, err := ioutil.ReadFile(name)
+	require.NoError(t, err)
+	require.Equal(t, []byte{4, 5}, data)
+	_, err = os.Stat(name + ".tmp")
+	require.True(t, os.IsNotExist(err))
+
+	require.Error(t, writeFileAtomic(filepath.Join(dir, "missing", "mempool.dat"), []byte{1}))
+}
@@ -79,6 +79,11 @@ const (
 	CMDFilterClear CommandType = 0x32
 	CMDMerkleBlock CommandType = 0x38
 
+	// Compact block relay (NeoGo-specific).
+	CMDCompactBlock CommandType = 0x51
+	CMDGetBlockTxn  CommandType = 0x52
+	CMDBlockTxn     CommandType = 0x53
+
 	// Others.
 	CMDAlert CommandType = 0x40
 )
@@ -157,6 +162,12 @@ func (m *Message) decodePayload() error {
 		p = &transaction.Transaction{}
 	case CMDMerkleBlock:
 		p = &payload.MerkleBlock{}
+	case CMDCompactBlock:
+		p = &payload.CompactBlock{StateRootInHeader: m.StateRootInHeader}
+	case CMDGetBlockTxn:
+		p = &payload.GetBlockTxn{}
+	case CMDBlockTxn:
+		p = &payload.BlockTxn{}
 	case CMDPing, CMDPong:
 		p = &payload.Ping{}
 	case CMDNotFound:
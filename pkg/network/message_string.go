@@ -31,6 +31,9 @@ func _() {
 	_ = x[CMDFilterAdd-49]
 	_ = x[CMDFilterClear-50]
 	_ = x[CMDMerkleBlock-56]
+	_ = x[CMDCompactBlock-81]
+	_ = x[CMDGetBlockTxn-82]
+	_ = x[CMDBlockTxn-83]
 	_ = x[CMDAlert-64]
 }
 
@@ -44,7 +47,7 @@ const (
 	_CommandType_name_6 = "CMDExtensibleCMDRejectCMDFilterLoadCMDFilterAddCMDFilterClear"
 	_CommandType_name_7 = "CMDMerkleBlock"
 	_CommandType_name_8 = "CMDAlert"
-	_CommandType_name_9 = "CMDP2PNotaryRequest"
+	_CommandType_name_9 = "CMDP2PNotaryRequestCMDCompactBlockCMDGetBlockTxnCMDBlockTxn"
 )
 
 var (
@@ -55,6 +58,7 @@ var (
 	_CommandType_index_4 = [...]uint8{0, 12, 22}
 	_CommandType_index_5 = [...]uint8{0, 6, 16, 34, 45, 50, 58}
 	_CommandType_index_6 = [...]uint8{0, 13, 22, 35, 47, 61}
+	_CommandType_index_9 = [...]uint8{0, 19, 34, 48, 59}
 )
 
 func (i CommandType) String() string {
@@ -83,8 +87,9 @@ func (i CommandType) String() string {
 		return _CommandType_name_7
 	case i == 64:
 		return _CommandType_name_8
-	case i == 80:
-		return _CommandType_name_9
+	case 80 <= i && i <= 83:
+		i -= 80
+		return _CommandType_name_9[_CommandType_index_9[i]:_CommandType_index_9[i+1]]
 	default:
 		return "CommandType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
@@ -0,0 +1,657 @@
+package network
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/testchain"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/consensus"
+	"github.com/nspcc-dev/neo-go/pkg/core"
+	"github.com/nspcc-dev/neo-go/pkg/core/storage"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// This file contains in-process network of nodes for tests. Every node is a
+// regular Server with its own in-memory blockchain, but instead of TCP
+// connections nodes are connected via in-memory pipes managed by simNetwork.
+// Packets written to these connections are not delivered by themselves, they're
+// queued and the network delivers them one by one in the order of their
+// delivery time as given by its own clock. Every packet is handled by the
+// receiver before the next one is delivered and network time jumps straight to
+// the next delivery, so latency, packet loss and partitions are all expressed
+// in network time and nothing is slept. Random decisions are made by seeded
+// per-connection generators, so they don't depend on goroutine scheduling.
+// Nodes still have their own timers (like consensus ones), when there are no
+// packets in flight the network waits for nodes to send something.
+
+const (
+	// simNodePort is the port all simulated nodes listen on.
+	simNodePort = 20333
+	// simWalletPassword is the password of generated validator wallets.
+	simWalletPassword = "one"
+	// defaultSimTick is the default network time step.
+	defaultSimTick = time.Millisecond
+	// simIdleTimeout is the maximum real time runUntil waits for nodes to
+	// send something when there are no packets in flight.
+	simIdleTimeout = 5 * time.Second
+)
+
+var (
+	errSimNoRoute     = errors.New("no route to host")
+	errSimRefused     = errors.New("connection refused")
+	errSimPartitioned = errors.New("host is in another partition")
+)
+
+// simConfig contains simulated network parameters.
+type simConfig struct {
+	// Seed is the seed for random generator used to drop and delay packets.
+	Seed int64
+	// Tick is the network time step.
+	Tick time.Duration
+	// Latency is the minimum packet delivery delay.
+	Latency time.Duration
+	// Jitter is the maximum random delay added to Latency.
+	Jitter time.Duration
+	// Loss is the probability of a packet to be dropped, from 0 to 1.
+	Loss float64
+}
+
+// simNetwork is a simulated network of nodes.
+type simNetwork struct {
+	log *zap.Logger
+
+	lock    sync.Mutex
+	seed    int64
+	tick    time.Duration
+	latency time.Duration
+	jitter  time.Duration
+	loss    float64
+	now     time.Duration
+	queue   packetQueue
+	// sent is signalled every time a packet is queued.
+	sent      chan struct{}
+	nodes     []*simNode
+	partition map[*simNode]int
+	lastPort  int
+}
+
+// simNode is a single node of the simulated network.
+type simNode struct {
+	index     int
+	chain     *core.Blockchain
+	server    *Server
+	addr      string
+	transport *simTransport
+	started   bool
+	// received is the number of packets delivered to the node.
+	received int
+}
+
+func newSimNetwork(cfg simConfig) *simNetwork {
+	if cfg.Tick == 0 {
+		cfg.Tick = defaultSimTick
+	}
+	return &simNetwork{
+		log:       zap.NewNop(),
+		seed:      cfg.Seed,
+		tick:      cfg.Tick,
+		latency:   cfg.Latency,
+		jitter:    cfg.Jitter,
+		loss:      cfg.Loss,
+		sent:      make(chan struct{}, 1),
+		partition: make(map[*simNode]int),
+		lastPort:  40000,
+	}
+}
+
+// addNode creates a new node with the given configuration, nodes are to be
+// started with start.
+func (n *simNetwork) addNode(t *testing.T, pcfg config.ProtocolConfiguration, scfg ServerConfig) *simNode {
+	chain, err := core.NewBlockchain(storage.NewMemoryStore(), pcfg, config.ApplicationConfiguration{}, n.log)
+	require.NoError(t, err)
+	go chain.Run()
+	t.Cleanup(chain.Close)
+
+	n.lock.Lock()
+	node := &simNode{
+		index: len(n.nodes),
+		chain: chain,
+		addr:  fmt.Sprintf("10.0.0.%d:%d", len(n.nodes)+1, simNodePort),
+	}
+	n.nodes = append(n.nodes, node)
+	n.lock.Unlock()
+
+	setSimDefaults(&scfg, pcfg)
+	node.server, err = newServerFromConstructors(scfg, chain, n.log, func(*Server) Transporter {
+		node.transport = &simTransport{network: n, node: node, quit: make(chan struct{})}
+		return node.transport
+	}, consensus.NewService, newDefaultDiscovery)
+	require.NoError(t, err)
+	return node
+}
+
+// addValidator creates a new node running consensus with the key of the
+// i-th testchain validator.
+func (n *simNetwork) addValidator(t *testing.T, pcfg config.ProtocolConfiguration, scfg ServerConfig, i int) *simNode {
+	dir, err := ioutil.TempDir("", "netsim")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "wallet.json")
+	w, err := wallet.NewWallet(path)
+	require.NoError(t, err)
+	acc := wallet.NewAccountFromPrivateKey(testchain.PrivateKey(i))
+	require.NoError(t, acc.Encrypt(simWalletPassword, w.Scrypt))
+	w.AddAccount(acc)
+	require.NoError(t, w.Save())
+	w.Close()
+
+	scfg.Wallet = &config.Wallet{Path: path, Password: simWalletPassword}
+	return n.addNode(t, pcfg, scfg)
+}
+
+// setSimDefaults sets zero server configuration parameters to values
+// suitable for tests.
+func setSimDefaults(scfg *ServerConfig, pcfg config.ProtocolConfiguration) {
+	scfg.Net = pcfg.Magic
+	scfg.Port = simNodePort
+	if scfg.UserAgent == "" {
+		scfg.UserAgent = "/netsim/"
+	}
+	if scfg.DialTimeout == 0 {
+		scfg.DialTimeout = time.Second
+	}
+	if scfg.ProtoTickInterval == 0 {
+		scfg.ProtoTickInterval = 100 * time.Millisecond
+	}
+	if scfg.PingInterval == 0 {
+		scfg.PingInterval = 30 * time.Second
+	}
+	if scfg.PingTimeout == 0 {
+		scfg.PingTimeout = time.Minute
+	}
+	if scfg.MaxPeers == 0 {
+		scfg.MaxPeers = 100
+	}
+	if scfg.AttemptConnPeers == 0 {
+		scfg.AttemptConnPeers = 1
+	}
+	if scfg.TimePerBlock == 0 {
+		scfg.TimePerBlock = time.Duration(pcfg.SecondsPerBlock) * time.Second
+	}
+}
+
+// getNodes returns all nodes of the network.
+func (n *simNetwork) getNodes() []*simNode {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return append([]*simNode(nil), n.nodes...)
+}
+
+// start starts all nodes that are not yet started, they're stopped when the
+// test finishes. Nodes accept connections right after this call.
+func (n *simNetwork) start(t *testing.T) {
+	for _, node := range n.getNodes() {
+		node := node
+		if node.started {
+			continue
+		}
+		node.started = true
+		node.transport.listen()
+		done := make(chan struct{})
+		go func() {
+			node.server.Start(make(chan error, 1))
+			close(done)
+		}()
+		t.Cleanup(func() {
+			node.server.Shutdown()
+			<-done
+		})
+	}
+}
+
+// connect connects the i-th node to the j-th one.
+func (n *simNetwork) connect(i, j int) error {
+	nodes := n.getNodes()
+	return nodes[i].transport.Dial(nodes[j].addr, 0)
+}
+
+// connectAll connects every pair of nodes.
+func (n *simNetwork) connectAll() error {
+	nodes := n.getNodes()
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j++ {
+			if err := n.connect(i, j); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setPartition splits the network into groups of nodes that can't
+// communicate with each other. Nodes not mentioned in groups form one more
+// group. Existing connections between partitions are not closed, but all
+// packets sent via them (including the ones already sent) are dropped.
+func (n *simNetwork) setPartition(groups ...[]int) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	n.partition = make(map[*simNode]int)
+	for i, g := range groups {
+		for _, idx := range g {
+			n.partition[n.nodes[idx]] = i + 1
+		}
+	}
+}
+
+// heal removes all partitions.
+func (n *simNetwork) heal() {
+	n.setPartition()
+}
+
+// receivedBy returns the number of packets delivered to the node.
+func (n *simNetwork) receivedBy(node *simNode) int {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return node.received
+}
+
+// step advances network time by one tick and delivers all packets that are
+// due by this time in the order of their delivery time.
+func (n *simNetwork) step() {
+	n.lock.Lock()
+	n.now += n.tick
+	n.lock.Unlock()
+	n.deliverDue()
+}
+
+// next advances network time to the delivery time of the first queued packet
+// and delivers all packets that are due by then. It returns false if there
+// are no packets in flight.
+func (n *simNetwork) next() bool {
+	n.lock.Lock()
+	if len(n.queue) == 0 {
+		n.lock.Unlock()
+		return false
+	}
+	if at := n.queue[0].at; at > n.now {
+		n.now = at
+	}
+	n.lock.Unlock()
+	n.deliverDue()
+	return true
+}
+
+// deliverDue delivers packets that are due by the current network time one by
+// one, every packet is handled by the receiver before the next one is
+// delivered.
+func (n *simNetwork) deliverDue() {
+	for {
+		n.lock.Lock()
+		if len(n.queue) == 0 || n.queue[0].at > n.now {
+			n.lock.Unlock()
+			return
+		}
+		p := heap.Pop(&n.queue).(*simPacket)
+		reachable := n.partition[p.conn.from] == n.partition[p.conn.to]
+		if reachable {
+			p.conn.to.received++
+		}
+		n.lock.Unlock()
+		if reachable {
+			p.conn.deliver(p.data)
+		}
+	}
+}
+
+// elapsed returns current network time.
+func (n *simNetwork) elapsed() time.Duration {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.now
+}
+
+// runUntil delivers packets until cond is true, it fails the test if it
+// doesn't happen in the given network time or if nodes don't send anything
+// for simIdleTimeout.
+func (n *simNetwork) runUntil(t *testing.T, cond func() bool, timeout time.Duration) {
+	deadline := n.elapsed() + timeout
+	for !cond() {
+		require.True(t, n.elapsed() <= deadline, "condition is not met in %s", timeout)
+		if !n.next() {
+			select {
+			case <-n.sent:
+			case <-time.After(simIdleTimeout):
+				require.True(t, cond(), "no packets sent in %s", simIdleTimeout)
+				return
+			}
+		}
+	}
+}
+
+// run makes steps for the given network time.
+func (n *simNetwork) run(d time.Duration) {
+	for steps := d / n.tick; steps > 0; steps-- {
+		n.step()
+	}
+}
+
+// nodeByAddr returns node listening on the given address.
+func (n *simNetwork) nodeByAddr(addr string) *simNode {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	for _, node := range n.nodes {
+		if node.addr == addr {
+			return node
+		}
+	}
+	return nil
+}
+
+// reachable checks whether nodes are in the same partition.
+func (n *simNetwork) reachable(from, to *simNode) bool {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.partition[from] == n.partition[to]
+}
+
+// send queues the packet sent via the given connection for delivery unless
+// it's dropped.
+func (n *simNetwork) send(c *simConn, data []byte) {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.partition[c.from] != n.partition[c.to] {
+		return
+	}
+	if n.loss > 0 && c.rand.Float64() < n.loss {
+		return
+	}
+	delay := n.latency
+	if n.jitter > 0 {
+		delay += time.Duration(c.rand.Int63n(int64(n.jitter)))
+	}
+	c.seq++
+	heap.Push(&n.queue, &simPacket{
+		conn: c,
+		data: append([]byte(nil), data...),
+		at:   n.now + delay,
+		seq:  c.seq,
+	})
+	select {
+	case n.sent <- struct{}{}:
+	default:
+	}
+}
+
+// newConnPair creates a connection between two nodes and returns both its
+// ends.
+func (n *simNetwork) newConnPair(from, to *simNode) (*simConn, *simConn) {
+	n.lock.Lock()
+	n.lastPort++
+	id := uint64(n.lastPort) * 2
+	host, _, _ := net.SplitHostPort(from.addr)
+	fromAddr, _ := net.ResolveTCPAddr("tcp", net.JoinHostPort(host, fmt.Sprint(n.lastPort)))
+	n.lock.Unlock()
+	toAddr, _ := net.ResolveTCPAddr("tcp", to.addr)
+
+	a, b := net.Pipe()
+	ca := newSimConn(n, id, a, from, to, fromAddr, toAddr)
+	cb := newSimConn(n, id+1, b, to, from, toAddr, fromAddr)
+	ca.peer, cb.peer = cb, ca
+	return ca, cb
+}
+
+// simTransport implements Transporter for the simulated network.
+type simTransport struct {
+	network *simNetwork
+	node    *simNode
+
+	lock      sync.Mutex
+	listening bool
+	closed    bool
+	conns     []*simConn
+	quit      chan struct{}
+}
+
+// Dial implements the Transporter interface.
+func (t *simTransport) Dial(addr string, _ time.Duration) error {
+	target := t.network.nodeByAddr(addr)
+	if target == nil {
+		return errSimNoRoute
+	}
+	if !t.network.reachable(t.node, target) {
+		return errSimPartitioned
+	}
+	local, remote := t.network.newConnPair(t.node, target)
+	if !target.transport.accept(remote) {
+		local.Close()
+		remote.Close()
+		return errSimRefused
+	}
+	if !t.add(local) {
+		local.Close()
+		remote.Close()
+		return errSimRefused
+	}
+	go t.node.server.serveConn(local)
+	return nil
+}
+
+// accept adds incoming connection if the transport is listening.
+func (t *simTransport) accept(c *simConn) bool {
+	t.lock.Lock()
+	listening := t.listening && !t.closed
+	t.lock.Unlock()
+	if !listening || !t.add(c) {
+		return false
+	}
+	go t.node.server.serveConn(c)
+	return true
+}
+
+// add remembers the connection to close it with the transport.
+func (t *simTransport) add(c *simConn) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.closed {
+		return false
+	}
+	t.conns = append(t.conns, c)
+	return true
+}
+
+// listen makes the transport accept incoming connections.
+func (t *simTransport) listen() {
+	t.lock.Lock()
+	t.listening = true
+	t.lock.Unlock()
+}
+
+// Accept implements the Transporter interface.
+func (t *simTransport) Accept() {
+	t.listen()
+	<-t.quit
+}
+
+// Proto implements the Transporter interface.
+func (t *simTransport) Proto() string {
+	return "tcp"
+}
+
+// Address implements the Transporter interface.
+func (t *simTransport) Address() string {
+	return t.node.addr
+}
+
+// Close implements the Transporter interface.
+func (t *simTransport) Close() {
+	t.lock.Lock()
+	if t.closed {
+		t.lock.Unlock()
+		return
+	}
+	t.closed = true
+	conns := t.conns
+	t.conns = nil
+	close(t.quit)
+	t.lock.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+}
+
+// simPacket is a single message waiting for delivery.
+type simPacket struct {
+	conn *simConn
+	data []byte
+	at   time.Duration
+	// seq is the number of the packet in its connection, it keeps the order
+	// of packets sent at the same time.
+	seq uint64
+}
+
+// packetQueue is a heap of packets ordered by their delivery time.
+type packetQueue []*simPacket
+
+func (q packetQueue) Len() int { return len(q) }
+func (q packetQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	if q[i].conn.id != q[j].conn.id {
+		return q[i].conn.id < q[j].conn.id
+	}
+	return q[i].seq < q[j].seq
+}
+func (q packetQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *packetQueue) Push(x interface{}) { *q = append(*q, x.(*simPacket)) }
+func (q *packetQueue) Pop() interface{} {
+	old := *q
+	p := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return p
+}
+
+// simConn is one end of the simulated connection. Reads are served by the
+// underlying pipe while writes are queued in the network and delivered to the
+// other end by step, so they never block.
+type simConn struct {
+	net.Conn
+	id            uint64
+	network       *simNetwork
+	from, to      *simNode
+	local, remote net.Addr
+	peer          *simConn
+
+	// rand and seq are only used under network lock.
+	rand *rand.Rand
+	seq  uint64
+
+	// reading is signalled every time the connection is read from.
+	reading   chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newSimConn(n *simNetwork, id uint64, c net.Conn, from, to *simNode, local, remote net.Addr) *simConn {
+	return &simConn{
+		Conn:    c,
+		id:      id,
+		network: n,
+		from:    from,
+		to:      to,
+		local:   local,
+		remote:  remote,
+		rand:    rand.New(rand.NewSource(n.seed ^ int64(id))),
+		reading: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+}
+
+// Read implements the net.Conn interface.
+func (c *simConn) Read(b []byte) (int, error) {
+	select {
+	case c.reading <- struct{}{}:
+	default:
+	}
+	return c.Conn.Read(b)
+}
+
+// Write implements the net.Conn interface.
+func (c *simConn) Write(b []byte) (int, error) {
+	select {
+	case <-c.done:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	c.network.send(c, b)
+	return len(b), nil
+}
+
+// deliver writes the packet to the pipe, so that it can be read by the other
+// end, and waits for the other end to handle it. Every packet is a single
+// message and pipe writes return when all data is read, so the message is
+// handled when the other end starts reading again.
+func (c *simConn) deliver(data []byte) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	if _, err := c.Conn.Write(data); err != nil {
+		c.Close()
+		return
+	}
+	select {
+	case <-c.peer.reading:
+	default:
+	}
+	select {
+	case <-c.peer.reading:
+	case <-c.peer.done:
+	}
+}
+
+// Close implements the net.Conn interface.
+func (c *simConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.Conn.Close()
+	})
+	return nil
+}
+
+// LocalAddr implements the net.Conn interface.
+func (c *simConn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr implements the net.Conn interface.
+func (c *simConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline implements the net.Conn interface, it only affects reads
+// because writes never block.
+func (c *simConn) SetDeadline(t time.Time) error {
+	return c.Conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline implements the net.Conn interface, it's a no-op because
+// writes never block.
+func (c *simConn) SetWriteDeadline(time.Time) error {
+	return nil
+}
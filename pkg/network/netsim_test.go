@@ -0,0 +1,184 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/internal/testchain"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
+	"github.com/stretchr/testify/require"
+)
+
+func getSimTestConfigs(t *testing.T) (config.ProtocolConfiguration, ServerConfig) {
+	cfg, err := config.Load("../../config", netmode.UnitTestNet)
+	require.NoError(t, err)
+	pcfg := cfg.ProtocolConfiguration
+	pcfg.SecondsPerBlock = 1
+	return pcfg, ServerConfig{
+		TimePerBlock: 200 * time.Millisecond,
+		PingInterval: 200 * time.Millisecond,
+		Relay:        true,
+	}
+}
+
+// newConsensusSimNetwork creates a network with all testchain validators and
+// additional regular nodes.
+func newConsensusSimNetwork(t *testing.T, cfg simConfig, regular int) *simNetwork {
+	pcfg, scfg := getSimTestConfigs(t)
+	n := newSimNetwork(cfg)
+	for i := 0; i < testchain.Size(); i++ {
+		n.addValidator(t, pcfg, scfg, i)
+	}
+	for i := 0; i < regular; i++ {
+		n.addNode(t, pcfg, scfg)
+	}
+	n.start(t)
+	require.NoError(t, n.connectAll())
+	return n
+}
+
+// heightReached returns condition checking that all nodes have reached the
+// given height.
+func heightReached(height uint32, nodes ...*simNode) func() bool {
+	return func() bool {
+		for _, node := range nodes {
+			if node.chain.BlockHeight() < height {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func TestSimBlockRelay(t *testing.T) {
+	n := newConsensusSimNetwork(t, simConfig{Seed: 1, Latency: 5 * time.Millisecond, Jitter: 5 * time.Millisecond}, 2)
+	nodes := n.getNodes()
+	n.runUntil(t, heightReached(3, nodes...), 5*time.Second)
+
+	for _, node := range nodes[1:] {
+		require.Equal(t, nodes[0].chain.GetHeaderHash(3), node.chain.GetHeaderHash(3))
+	}
+}
+
+func TestSimPacketLoss(t *testing.T) {
+	n := newConsensusSimNetwork(t, simConfig{Seed: 2, Latency: time.Millisecond, Loss: 0.05}, 1)
+	n.runUntil(t, heightReached(3, n.getNodes()...), 5*time.Second)
+}
+
+func TestSimViewChange(t *testing.T) {
+	n := newConsensusSimNetwork(t, simConfig{Seed: 3, Latency: time.Millisecond}, 0)
+	nodes := n.getNodes()
+	n.runUntil(t, heightReached(1, nodes...), 2*time.Second)
+
+	// Every validator is a primary once in validators count blocks, so the
+	// rest can only proceed via view changes when its turn comes.
+	n.setPartition([]int{0})
+	height := nodes[1].chain.BlockHeight() + uint32(len(nodes))
+	n.runUntil(t, heightReached(height, nodes[1:]...), 5*time.Second)
+	require.True(t, nodes[0].chain.BlockHeight() < height)
+
+	n.heal()
+	n.runUntil(t, heightReached(nodes[1].chain.BlockHeight(), nodes[0]), 2*time.Second)
+}
+
+func TestSimMempoolPropagation(t *testing.T) {
+	pcfg, scfg := getSimTestConfigs(t)
+	n := newSimNetwork(simConfig{Seed: 4, Latency: time.Millisecond})
+	for i := 0; i < 3; i++ {
+		n.addNode(t, pcfg, scfg)
+	}
+	n.start(t)
+	// Line topology, so the transaction has to be relayed.
+	require.NoError(t, n.connect(0, 1))
+	require.NoError(t, n.connect(1, 2))
+
+	nodes := n.getNodes()
+	gas, err := nodes[0].chain.GetNativeContractScriptHash(nativenames.Gas)
+	require.NoError(t, err)
+	tx, err := testchain.NewTransferFromOwner(nodes[0].chain, gas, random.Uint160(), 1, 1, 100)
+	require.NoError(t, err)
+	require.NoError(t, nodes[0].server.RelayTxn(tx))
+	n.runUntil(t, func() bool {
+		return nodes[2].chain.GetMemPool().ContainsKey(tx.Hash())
+	}, time.Second)
+
+	// Partitioned node doesn't get anything, so it can't get new
+	// transactions.
+	n.setPartition([]int{2})
+	received := n.receivedBy(nodes[2])
+	tx2, err := testchain.NewTransferFromOwner(nodes[0].chain, gas, random.Uint160(), 1, 2, 100)
+	require.NoError(t, err)
+	require.NoError(t, nodes[0].server.RelayTxn(tx2))
+	n.runUntil(t, func() bool {
+		return nodes[1].chain.GetMemPool().ContainsKey(tx2.Hash())
+	}, time.Second)
+	n.run(10 * n.tick)
+	require.Equal(t, received, n.receivedBy(nodes[2]))
+	require.False(t, nodes[2].chain.GetMemPool().ContainsKey(tx2.Hash()))
+}
+
+func TestSimDial(t *testing.T) {
+	pcfg, scfg := getSimTestConfigs(t)
+	n := newSimNetwork(simConfig{})
+	a := n.addNode(t, pcfg, scfg)
+	b := n.addNode(t, pcfg, scfg)
+
+	// Not started node doesn't accept connections.
+	require.Equal(t, errSimRefused, n.connect(0, 1))
+	n.start(t)
+	require.Equal(t, errSimNoRoute, a.transport.Dial("10.0.0.100:20333", 0))
+
+	n.setPartition([]int{0}, []int{1})
+	require.Equal(t, errSimPartitioned, n.connect(0, 1))
+	n.heal()
+	require.NoError(t, n.connect(0, 1))
+	n.runUntil(t, func() bool {
+		return a.server.HandshakedPeersCount() == 1 && b.server.HandshakedPeersCount() == 1
+	}, 100*time.Millisecond)
+}
+
+func TestSimStep(t *testing.T) {
+	pcfg, scfg := getSimTestConfigs(t)
+	n := newSimNetwork(simConfig{Latency: 3 * time.Millisecond})
+	a := n.addNode(t, pcfg, scfg)
+	b := n.addNode(t, pcfg, scfg)
+	local, remote := n.newConnPair(a, b)
+	defer local.Close()
+	defer remote.Close()
+
+	// Packets are only delivered by steps when their time comes.
+	_, err := local.Write([]byte{1, 2})
+	require.NoError(t, err)
+	n.step()
+	n.step()
+	require.Equal(t, 0, n.receivedBy(b))
+	require.Equal(t, 1, n.queue.Len())
+
+	done := make(chan struct{})
+	go func() {
+		n.step()
+		close(done)
+	}()
+	buf := make([]byte, 2)
+	_, err = remote.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2}, buf)
+	// Packet is handled when the receiver starts reading again.
+	select {
+	case <-done:
+		require.FailNow(t, "step finished before the packet is handled")
+	default:
+	}
+	go func() { _, _ = remote.Read(make([]byte, 1)) }()
+	<-done
+	require.Equal(t, 1, n.receivedBy(b))
+
+	// Packets between partitions are dropped.
+	n.setPartition([]int{1})
+	_, err = local.Write([]byte{3})
+	require.NoError(t, err)
+	require.Equal(t, 0, n.queue.Len())
+}
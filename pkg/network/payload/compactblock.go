@@ -0,0 +1,184 @@
+package payload
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
+	"github.com/nspcc-dev/neo-go/pkg/io"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// CompactBlock is a block announcement containing block header and short
+// transaction IDs instead of full transactions. Receiver reconstructs the
+// block using transactions from its memory pool and requests the missing ones
+// via GetBlockTxn. Transactions the sender expects receiver not to have can be
+// prefilled.
+type CompactBlock struct {
+	*block.Header
+	// Nonce is a random number used to derive short IDs key, so that short
+	// IDs are different for every announcement.
+	Nonce uint64
+	// ShortIDs contains short IDs of all block's transactions except the
+	// prefilled ones, in block order.
+	ShortIDs []uint64
+	// Prefilled contains full transactions with their positions in block.
+	Prefilled []PrefilledTransaction
+	// StateRootInHeader specifies whether header contains state root.
+	StateRootInHeader bool
+
+	key []byte
+}
+
+// PrefilledTransaction is a transaction included into CompactBlock with its
+// index in the block.
+type PrefilledTransaction struct {
+	Index       uint16
+	Transaction *transaction.Transaction
+}
+
+var (
+	errBadPrefilledIndex = errors.New("invalid prefilled transaction index")
+	errTooManyIndexes    = errors.New("too many transaction indexes")
+)
+
+// NewCompactBlock creates compact announcement of the given block prefilling
+// transactions with the given indexes (that must be sorted).
+func NewCompactBlock(b *block.Block, nonce uint64, prefill []uint16) *CompactBlock {
+	c := &CompactBlock{
+		Header:            &b.Header,
+		Nonce:             nonce,
+		ShortIDs:          make([]uint64, 0, len(b.Transactions)-len(prefill)),
+		Prefilled:         make([]PrefilledTransaction, 0, len(prefill)),
+		StateRootInHeader: b.StateRootEnabled,
+	}
+	for i, tx := range b.Transactions {
+		if len(prefill) != 0 && int(prefill[0]) == i {
+			c.Prefilled = append(c.Prefilled, PrefilledTransaction{Index: uint16(i), Transaction: tx})
+			prefill = prefill[1:]
+			continue
+		}
+		c.ShortIDs = append(c.ShortIDs, c.ShortID(tx.Hash()))
+	}
+	return c
+}
+
+// TxCount returns the number of transactions in the block.
+func (c *CompactBlock) TxCount() int {
+	return len(c.ShortIDs) + len(c.Prefilled)
+}
+
+// ShortID returns short ID of the transaction with the given hash. It's the
+// first 8 bytes of SHA256 of the transaction hash keyed by the block hash and
+// nonce, so it can't be precomputed by an attacker trying to cause collisions.
+func (c *CompactBlock) ShortID(h util.Uint256) uint64 {
+	if c.key == nil {
+		buf := make([]byte, util.Uint256Size+8)
+		copy(buf, c.Header.Hash().BytesBE())
+		binary.LittleEndian.PutUint64(buf[util.Uint256Size:], c.Nonce)
+		key := hash.Sha256(buf)
+		c.key = key.BytesBE()
+	}
+	buf := make([]byte, 2*util.Uint256Size)
+	copy(buf, c.key)
+	copy(buf[util.Uint256Size:], h.BytesBE())
+	sum := hash.Sha256(buf)
+	return binary.LittleEndian.Uint64(sum.BytesBE())
+}
+
+// DecodeBinary implements Serializable interface.
+func (c *CompactBlock) DecodeBinary(br *io.BinReader) {
+	c.Header = &block.Header{StateRootEnabled: c.StateRootInHeader}
+	c.Header.DecodeBinary(br)
+	c.Nonce = br.ReadU64LE()
+	n := br.ReadVarUint()
+	if n > block.MaxTransactionsPerBlock {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	c.ShortIDs = make([]uint64, n)
+	for i := range c.ShortIDs {
+		c.ShortIDs[i] = br.ReadU64LE()
+	}
+	n = br.ReadVarUint()
+	if n > block.MaxTransactionsPerBlock-uint64(len(c.ShortIDs)) {
+		br.Err = block.ErrMaxContentsPerBlock
+		return
+	}
+	c.Prefilled = make([]PrefilledTransaction, n)
+	for i := range c.Prefilled {
+		c.Prefilled[i].Index = br.ReadU16LE()
+		c.Prefilled[i].Transaction = new(transaction.Transaction)
+		c.Prefilled[i].Transaction.DecodeBinary(br)
+		if br.Err == nil && (int(c.Prefilled[i].Index) >= c.TxCount() ||
+			i > 0 && c.Prefilled[i].Index <= c.Prefilled[i-1].Index) {
+			br.Err = errBadPrefilledIndex
+		}
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (c *CompactBlock) EncodeBinary(bw *io.BinWriter) {
+	c.Header.EncodeBinary(bw)
+	bw.WriteU64LE(c.Nonce)
+	bw.WriteVarUint(uint64(len(c.ShortIDs)))
+	for _, id := range c.ShortIDs {
+		bw.WriteU64LE(id)
+	}
+	bw.WriteVarUint(uint64(len(c.Prefilled)))
+	for _, p := range c.Prefilled {
+		bw.WriteU16LE(p.Index)
+		p.Transaction.EncodeBinary(bw)
+	}
+}
+
+// GetBlockTxn is a request for transactions of the compact block that
+// receiver wasn't able to find in its memory pool.
+type GetBlockTxn struct {
+	BlockHash util.Uint256
+	Indexes   []uint16
+}
+
+// DecodeBinary implements Serializable interface.
+func (g *GetBlockTxn) DecodeBinary(br *io.BinReader) {
+	g.BlockHash.DecodeBinary(br)
+	n := br.ReadVarUint()
+	if n == 0 || n > block.MaxTransactionsPerBlock {
+		br.Err = errTooManyIndexes
+		return
+	}
+	g.Indexes = make([]uint16, n)
+	for i := range g.Indexes {
+		g.Indexes[i] = br.ReadU16LE()
+	}
+}
+
+// EncodeBinary implements Serializable interface.
+func (g *GetBlockTxn) EncodeBinary(bw *io.BinWriter) {
+	g.BlockHash.EncodeBinary(bw)
+	bw.WriteVarUint(uint64(len(g.Indexes)))
+	for _, i := range g.Indexes {
+		bw.WriteU16LE(i)
+	}
+}
+
+// BlockTxn is a response to GetBlockTxn containing requested transactions in
+// the same order.
+type BlockTxn struct {
+	BlockHash    util.Uint256
+	Transactions []*transaction.Transaction
+}
+
+// DecodeBinary implements Serializable interface.
+func (b *BlockTxn) DecodeBinary(br *io.BinReader) {
+	b.BlockHash.DecodeBinary(br)
+	br.ReadArray(&b.Transactions, block.MaxTransactionsPerBlock)
+}
+
+// EncodeBinary implements Serializable interface.
+func (b *BlockTxn) EncodeBinary(bw *io.BinWriter) {
+	b.BlockHash.EncodeBinary(bw)
+	bw.WriteArray(b.Transactions)
+}
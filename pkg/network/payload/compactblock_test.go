@@ -0,0 +1,99 @@
+package payload
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompactTestBlock(n int) *block.Block {
+	b := &block.Block{Header: *newDumbBlock()}
+	for i := 0; i < n; i++ {
+		tx := transaction.New([]byte{byte(i)}, 0)
+		tx.Signers = []transaction.Signer{{Account: util.Uint160{1, 2, 3}}}
+		tx.Scripts = []transaction.Witness{{}}
+		b.Transactions = append(b.Transactions, tx)
+	}
+	b.RebuildMerkleRoot()
+	return b
+}
+
+func TestCompactBlock_EncodeDecodeBinary(t *testing.T) {
+	b := newCompactTestBlock(5)
+	c := NewCompactBlock(b, 42, []uint16{0, 3})
+	require.Equal(t, 5, c.TxCount())
+	require.Equal(t, 3, len(c.ShortIDs))
+	require.Equal(t, 2, len(c.Prefilled))
+	require.Equal(t, uint16(3), c.Prefilled[1].Index)
+	require.Equal(t, c.ShortID(b.Transactions[1].Hash()), c.ShortIDs[0])
+
+	data, err := testserdes.EncodeBinary(c)
+	require.NoError(t, err)
+	actual := new(CompactBlock)
+	require.NoError(t, testserdes.DecodeBinary(data, actual))
+	require.Equal(t, c.Hash(), actual.Hash())
+	require.Equal(t, c.Nonce, actual.Nonce)
+	require.Equal(t, c.ShortIDs, actual.ShortIDs)
+	require.Equal(t, len(c.Prefilled), len(actual.Prefilled))
+	for i := range c.Prefilled {
+		require.Equal(t, c.Prefilled[i].Index, actual.Prefilled[i].Index)
+		require.Equal(t, c.Prefilled[i].Transaction.Hash(), actual.Prefilled[i].Transaction.Hash())
+	}
+	// Short IDs depend on nonce.
+	require.NotEqual(t, c.ShortIDs[0], NewCompactBlock(b, 43, nil).ShortIDs[1])
+
+	t.Run("bad prefilled index", func(t *testing.T) {
+		c := NewCompactBlock(b, 42, []uint16{1})
+		c.Prefilled[0].Index = 5
+		data, err := testserdes.EncodeBinary(c)
+		require.NoError(t, err)
+		require.True(t, errors.Is(testserdes.DecodeBinary(data, new(CompactBlock)), errBadPrefilledIndex))
+	})
+	t.Run("unsorted prefilled", func(t *testing.T) {
+		c := NewCompactBlock(b, 42, []uint16{1, 2})
+		c.Prefilled[0].Index, c.Prefilled[1].Index = 2, 1
+		data, err := testserdes.EncodeBinary(c)
+		require.NoError(t, err)
+		require.True(t, errors.Is(testserdes.DecodeBinary(data, new(CompactBlock)), errBadPrefilledIndex))
+	})
+	t.Run("too many transactions", func(t *testing.T) {
+		c := NewCompactBlock(b, 42, nil)
+		c.ShortIDs = make([]uint64, block.MaxTransactionsPerBlock+1)
+		data, err := testserdes.EncodeBinary(c)
+		require.NoError(t, err)
+		require.True(t, errors.Is(testserdes.DecodeBinary(data, new(CompactBlock)), block.ErrMaxContentsPerBlock))
+	})
+}
+
+func TestGetBlockTxn_EncodeDecodeBinary(t *testing.T) {
+	testserdes.EncodeDecodeBinary(t, &GetBlockTxn{
+		BlockHash: util.Uint256{1, 2, 3},
+		Indexes:   []uint16{1, 5, 42},
+	}, new(GetBlockTxn))
+
+	data, err := testserdes.EncodeBinary(&GetBlockTxn{})
+	require.NoError(t, err)
+	require.Error(t, testserdes.DecodeBinary(data, new(GetBlockTxn)))
+}
+
+func TestBlockTxn_EncodeDecodeBinary(t *testing.T) {
+	b := newCompactTestBlock(2)
+	expected := &BlockTxn{
+		BlockHash:    b.Hash(),
+		Transactions: b.Transactions,
+	}
+	data, err := testserdes.EncodeBinary(expected)
+	require.NoError(t, err)
+	actual := new(BlockTxn)
+	require.NoError(t, testserdes.DecodeBinary(data, actual))
+	require.Equal(t, expected.BlockHash, actual.BlockHash)
+	require.Equal(t, 2, len(actual.Transactions))
+	for i := range actual.Transactions {
+		require.Equal(t, expected.Transactions[i].Hash(), actual.Transactions[i].Hash())
+	}
+}
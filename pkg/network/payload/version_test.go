@@ -13,6 +13,7 @@ func TestVersionEncodeDecode(t *testing.T) {
 	var magic netmode.Magic = 56753
 	var tcpPort uint16 = 3000
 	var wsPort uint16 = 3001
+	var tlsPort uint16 = 3002
 	var id uint32 = 13337
 	useragent := "/NEO:0.0.1/"
 	var height uint32 = 100500
@@ -29,6 +30,16 @@ func TestVersionEncodeDecode(t *testing.T) {
 				Port: wsPort,
 			},
 		},
+		{
+			Type: capability.TLSServer,
+			Data: &capability.Server{
+				Port: tlsPort,
+			},
+		},
+		{
+			Type: capability.CompactBlocks,
+			Data: &capability.Compact{},
+		},
 		{
 			Type: capability.FullNode,
 			Data: &capability.Node{
@@ -0,0 +1,245 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// minFixedPeerBackoff and maxFixedPeerBackoff bound the delay between
+	// reconnection attempts to fixed peers.
+	minFixedPeerBackoff = 5 * time.Second
+	maxFixedPeerBackoff = 5 * time.Minute
+	// fixedPeersCheckInterval is the interval between fixed peers
+	// connection checks.
+	fixedPeersCheckInterval = time.Second
+)
+
+var errSubnetLimit = errors.New("too many peers from the same subnet")
+
+// connPolicy restricts inbound connections the node accepts.
+type connPolicy struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// fixedPeer is the always connected peer along with its reconnection state.
+type fixedPeer struct {
+	// ips are the addresses the peer host name is resolved to.
+	ips []net.IP
+	// addrs are the resolved peer addresses (with port).
+	addrs   []string
+	backoff time.Duration
+	next    time.Time
+}
+
+// newFixedPeer resolves host name of the given fixed peer address once, so
+// that peers can be compared by IP addresses later.
+func newFixedPeer(addr string) (*fixedPeer, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("bad fixed peer address %s: %w", addr, err)
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, fmt.Errorf("can't resolve fixed peer %s: %w", addr, err)
+	}
+	fp := &fixedPeer{ips: ips, addrs: make([]string, len(ips))}
+	for i := range ips {
+		fp.addrs[i] = net.JoinHostPort(ips[i].String(), port)
+	}
+	return fp, nil
+}
+
+func newConnPolicy(allow, deny []string) (*connPolicy, error) {
+	var (
+		cp  = new(connPolicy)
+		err error
+	)
+	cp.allow, err = parseSubnets(allow)
+	if err != nil {
+		return nil, fmt.Errorf("bad InboundAllow list: %w", err)
+	}
+	cp.deny, err = parseSubnets(deny)
+	if err != nil {
+		return nil, fmt.Errorf("bad InboundDeny list: %w", err)
+	}
+	return cp, nil
+}
+
+// parseSubnets parses a list of CIDR subnets, plain IP addresses are treated
+// as single-address subnets.
+func parseSubnets(list []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(list))
+	for _, s := range list {
+		_, subnet, err := net.ParseCIDR(s)
+		if err != nil {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid subnet %s", s)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			subnet = &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+		}
+		res = append(res, subnet)
+	}
+	return res, nil
+}
+
+// allowed checks whether connections from the given IP are allowed. Deny
+// list has a priority, everything not denied is allowed if allow list is
+// empty.
+func (cp *connPolicy) allowed(ip net.IP) bool {
+	for _, subnet := range cp.deny {
+		if subnet.Contains(ip) {
+			return false
+		}
+	}
+	if len(cp.allow) == 0 {
+		return true
+	}
+	for _, subnet := range cp.allow {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// addrIP returns IP of the given address or nil if it has no IP.
+func addrIP(addr net.Addr) net.IP {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP
+	}
+	return net.ParseIP(hostOf(addr.String()))
+}
+
+// subnetOf returns /24 (for IPv4) or /64 (for IPv6) subnet of the given IP.
+func subnetOf(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 8*net.IPv4len)).String() + "/24"
+	}
+	return ip.Mask(net.CIDRMask(64, 8*net.IPv6len)).String() + "/64"
+}
+
+// isInboundAllowed checks whether inbound connection from the given address
+// can be accepted. Fixed peers are always allowed.
+func (s *Server) isInboundAllowed(addr net.Addr) bool {
+	ip := addrIP(addr)
+	if ip == nil || s.isFixedIP(ip) {
+		return true
+	}
+	return s.policy.allowed(ip)
+}
+
+// isFixedIP checks whether the given IP belongs to one of fixed peers.
+func (s *Server) isFixedIP(ip net.IP) bool {
+	for _, fp := range s.fixedPeers {
+		for _, fip := range fp.ips {
+			if fip.Equal(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isFixed checks whether the given peer is one of fixed peers. Peers are
+// compared by IP since inbound connections have random ports.
+func (s *Server) isFixed(p Peer) bool {
+	if len(s.fixedPeers) == 0 {
+		return false
+	}
+	ip := addrIP(p.RemoteAddr())
+	return ip != nil && s.isFixedIP(ip)
+}
+
+// subnetLimitReached checks whether there are too many connected peers from
+// the subnet of the given one (including it).
+func (s *Server) subnetLimitReached(p Peer) bool {
+	if s.MaxPeersPerSubnet <= 0 {
+		return false
+	}
+	ip := addrIP(p.RemoteAddr())
+	if ip == nil {
+		return false
+	}
+	var (
+		subnet = subnetOf(ip)
+		count  int
+	)
+	for peer := range s.Peers() {
+		if pip := addrIP(peer.RemoteAddr()); pip != nil && subnetOf(pip) == subnet {
+			count++
+		}
+	}
+	return count > s.MaxPeersPerSubnet
+}
+
+// maintainFixedPeers keeps connections with fixed peers until the server is
+// stopped.
+func (s *Server) maintainFixedPeers() {
+	if len(s.fixedPeers) == 0 {
+		return
+	}
+	t := time.NewTicker(fixedPeersCheckInterval)
+	defer t.Stop()
+	for {
+		s.connectFixedPeers(time.Now())
+		select {
+		case <-s.quit:
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// connectFixedPeers dials fixed peers that are not connected if their
+// reconnection delay has passed. The delay is doubled after every attempt
+// and is reset once the peer is connected.
+func (s *Server) connectFixedPeers(now time.Time) {
+	connected := make(map[string]bool)
+	for p := range s.Peers() {
+		connected[p.PeerAddr().String()] = true
+		connected[p.RemoteAddr().String()] = true
+	}
+	for addr, fp := range s.fixedPeers {
+		if fp.isConnected(connected) {
+			fp.backoff, fp.next = 0, time.Time{}
+			continue
+		}
+		if now.Before(fp.next) {
+			continue
+		}
+		fp.backoff *= 2
+		if fp.backoff < minFixedPeerBackoff {
+			fp.backoff = minFixedPeerBackoff
+		} else if fp.backoff > maxFixedPeerBackoff {
+			fp.backoff = maxFixedPeerBackoff
+		}
+		fp.next = now.Add(fp.backoff)
+		go func(addr string) {
+			if err := s.transport.Dial(addr, s.DialTimeout); err != nil {
+				s.log.Debug("failed to connect to fixed peer", zap.String("addr", addr), zap.Error(err))
+			}
+		}(addr)
+	}
+}
+
+// isConnected checks whether any of the peer's resolved addresses is in the
+// given set.
+func (fp *fixedPeer) isConnected(connected map[string]bool) bool {
+	for _, addr := range fp.addrs {
+		if connected[addr] {
+			return true
+		}
+	}
+	return false
+}
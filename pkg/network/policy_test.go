@@ -0,0 +1,155 @@
+package network
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPolicy(t *testing.T) {
+	_, err := newConnPolicy([]string{"10.0.0.0/33"}, nil)
+	require.Error(t, err)
+	_, err = newConnPolicy(nil, []string{"not an IP"})
+	require.Error(t, err)
+
+	cp, err := newConnPolicy(nil, nil)
+	require.NoError(t, err)
+	require.True(t, cp.allowed(net.ParseIP("1.2.3.4")))
+
+	cp, err = newConnPolicy([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"}, []string{"10.1.0.0/16"})
+	require.NoError(t, err)
+	require.True(t, cp.allowed(net.ParseIP("10.0.0.1")))
+	require.True(t, cp.allowed(net.ParseIP("192.168.1.1")))
+	require.True(t, cp.allowed(net.ParseIP("fd00::1")))
+	require.False(t, cp.allowed(net.ParseIP("192.168.1.2")))
+	require.False(t, cp.allowed(net.ParseIP("10.1.2.3")))
+	require.False(t, cp.allowed(net.ParseIP("1.2.3.4")))
+
+	cp, err = newConnPolicy(nil, []string{"1.2.3.4"})
+	require.NoError(t, err)
+	require.False(t, cp.allowed(net.ParseIP("1.2.3.4")))
+	require.True(t, cp.allowed(net.ParseIP("1.2.3.5")))
+}
+
+func TestSubnetOf(t *testing.T) {
+	require.Equal(t, "1.2.3.0/24", subnetOf(net.ParseIP("1.2.3.4")))
+	require.Equal(t, "2001:db8:1:2::/64", subnetOf(net.ParseIP("2001:db8:1:2:3:4:5:6")))
+}
+
+func TestInboundPolicy(t *testing.T) {
+	s := newTestServer(t, ServerConfig{
+		InboundAllow: []string{"10.0.0.0/8"},
+		FixedPeers:   []string{"1.2.3.4:20333"},
+	})
+	require.True(t, s.isInboundAllowed(&net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}))
+	require.True(t, s.isInboundAllowed(&net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 1234}))
+	require.False(t, s.isInboundAllowed(&net.TCPAddr{IP: net.ParseIP("1.2.3.5"), Port: 1234}))
+
+	_, err := newServerFromConstructors(ServerConfig{InboundDeny: []string{"bad"}}, s.chain, s.log,
+		newFakeTransp, newFakeConsensus, newTestDiscovery)
+	require.Error(t, err)
+
+	t.Run("host name", func(t *testing.T) {
+		s := newTestServer(t, ServerConfig{
+			InboundAllow: []string{"10.0.0.0/8"},
+			FixedPeers:   []string{"localhost:20333"},
+		})
+		require.True(t, s.isInboundAllowed(&net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}))
+		require.Contains(t, s.fixedPeers["localhost:20333"].addrs, "127.0.0.1:20333")
+	})
+	t.Run("bad fixed peer", func(t *testing.T) {
+		_, err := newServerFromConstructors(ServerConfig{FixedPeers: []string{"1.2.3.4"}}, s.chain, s.log,
+			newFakeTransp, newFakeConsensus, newTestDiscovery)
+		require.Error(t, err)
+	})
+}
+
+func TestSubnetLimit(t *testing.T) {
+	s := newTestServer(t, ServerConfig{MaxPeersPerSubnet: 2})
+	newPeer := func(ip string) *localPeer {
+		p := newLocalPeer(t, s)
+		p.netaddr = net.TCPAddr{IP: net.ParseIP(ip), Port: 20333}
+		s.lock.Lock()
+		s.peers[p] = true
+		s.lock.Unlock()
+		return p
+	}
+	p1 := newPeer("1.2.3.4")
+	require.False(t, s.subnetLimitReached(p1))
+	p2 := newPeer("1.2.3.5")
+	require.False(t, s.subnetLimitReached(p2))
+	p3 := newPeer("1.2.3.6")
+	require.True(t, s.subnetLimitReached(p3))
+	p4 := newPeer("1.2.4.1")
+	require.False(t, s.subnetLimitReached(p4))
+
+	s.MaxPeersPerSubnet = 0
+	require.False(t, s.subnetLimitReached(p3))
+}
+
+func TestConnectFixedPeers(t *testing.T) {
+	const addr = "1.2.3.4:20333"
+	s := newTestServer(t, ServerConfig{FixedPeers: []string{addr}})
+	ft := &fakeTransp{dialCh: make(chan string, 1)}
+	s.transport = ft
+
+	checkDial := func(expected bool) {
+		if expected {
+			select {
+			case a := <-ft.dialCh:
+				require.Equal(t, addr, a)
+			case <-time.After(time.Second):
+				require.Fail(t, "no dial")
+			}
+			return
+		}
+		select {
+		case <-ft.dialCh:
+			require.Fail(t, "unexpected dial")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	now := time.Now()
+	s.connectFixedPeers(now)
+	checkDial(true)
+	require.Equal(t, minFixedPeerBackoff, s.fixedPeers[addr].backoff)
+
+	s.connectFixedPeers(now.Add(minFixedPeerBackoff / 2))
+	checkDial(false)
+
+	now = now.Add(minFixedPeerBackoff)
+	s.connectFixedPeers(now)
+	checkDial(true)
+	require.Equal(t, 2*minFixedPeerBackoff, s.fixedPeers[addr].backoff)
+
+	s.fixedPeers[addr].backoff = maxFixedPeerBackoff
+	now = now.Add(2 * minFixedPeerBackoff)
+	s.connectFixedPeers(now)
+	checkDial(true)
+	require.Equal(t, maxFixedPeerBackoff, s.fixedPeers[addr].backoff)
+
+	// Backoff is reset once the peer is connected.
+	p := newLocalPeer(t, s)
+	p.netaddr = net.TCPAddr{IP: net.ParseIP("1.2.3.4"), Port: 20333}
+	s.lock.Lock()
+	s.peers[p] = true
+	s.lock.Unlock()
+	s.connectFixedPeers(now.Add(maxFixedPeerBackoff))
+	checkDial(false)
+	require.Equal(t, time.Duration(0), s.fixedPeers[addr].backoff)
+	require.True(t, s.isFixed(p))
+}
+
+func TestPrivateModeGetAddr(t *testing.T) {
+	s := newTestServer(t, ServerConfig{PrivateMode: true})
+	s.discovery.RegisterGoodAddr("1.2.3.4:20333", nil)
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		require.Fail(t, "no messages are expected", "got %s", msg.Command)
+	}
+	require.NoError(t, s.handleGetAddrCmd(p))
+}
@@ -1,6 +1,8 @@
 package network
 
 import (
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -38,6 +40,41 @@ var (
 			Namespace: "neogo",
 		},
 	)
+
+	peerBlockRate = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Help:      "Block download rate for the last range received from the peer (blocks per second)",
+			Name:      "peer_block_rate",
+			Namespace: "neogo",
+		},
+		[]string{"peer"},
+	)
+
+	blockRequestTimeouts = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of block requests not answered in time",
+			Name:      "block_request_timeouts",
+			Namespace: "neogo",
+		},
+	)
+
+	limitedMessages = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of P2P messages dropped because of per-peer rate limits",
+			Name:      "p2p_limited_messages",
+			Namespace: "neogo",
+		},
+		[]string{"command"},
+	)
+
+	droppedItems = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of inventory items not requested or announced because of outstanding requests and mempool response limits",
+			Name:      "p2p_dropped_items",
+			Namespace: "neogo",
+		},
+		[]string{"command"},
+	)
 )
 
 func init() {
@@ -46,6 +83,10 @@ func init() {
 		servAndNodeVersion,
 		poolCount,
 		blockQueueLength,
+		peerBlockRate,
+		blockRequestTimeouts,
+		limitedMessages,
+		droppedItems,
 	)
 }
 
@@ -53,6 +94,31 @@ func updateBlockQueueLenMetric(bqLen int) {
 	blockQueueLength.Set(float64(bqLen))
 }
 
+func updatePeerBlockRateMetric(p Peer, blocks uint32, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	peerBlockRate.WithLabelValues(p.RemoteAddr().String()).Set(float64(blocks) / d.Seconds())
+}
+
+func removePeerBlockRateMetric(p Peer) {
+	peerBlockRate.DeleteLabelValues(p.RemoteAddr().String())
+}
+
+func addBlockRequestTimeoutsMetric(n int) {
+	blockRequestTimeouts.Add(float64(n))
+}
+
+func addLimitedMessagesMetric(cmd string) {
+	limitedMessages.WithLabelValues(cmd).Inc()
+}
+
+func addDroppedItemsMetric(cmd string, n int) {
+	if n > 0 {
+		droppedItems.WithLabelValues(cmd).Add(float64(n))
+	}
+}
+
 func updatePoolCountMetric(pCount int) {
 	poolCount.Set(float64(pCount))
 }
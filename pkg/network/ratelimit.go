@@ -0,0 +1,281 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+const (
+	// defaultMaxOutstandingRequests is the default number of inventory
+	// items that can be requested from a single peer at once.
+	defaultMaxOutstandingRequests = 4 * payload.MaxHashesCount
+	// defaultMaxMempoolInventory is the default number of transactions
+	// announced in response to a mempool request.
+	defaultMaxMempoolInventory = 10 * payload.MaxHashesCount
+	// outstandingRequestTimeout is the time after which requested item is
+	// no longer accounted as outstanding even if it wasn't received.
+	outstandingRequestTimeout = time.Minute
+)
+
+var errRateLimited = errors.New("rate limit exceeded")
+
+// defaultRateLimits contains default per-peer limits for commands that can
+// make the node do a lot of work. Requests and announcements are limited by
+// the number of items they contain, other messages are limited by count.
+var defaultRateLimits = map[CommandType]config.RateLimit{
+	CMDGetData:         {Rate: 1000, Burst: 2000},
+	CMDGetHeaders:      {Rate: 2000, Burst: 4000},
+	CMDGetBlocks:       {Rate: 10, Burst: 20},
+	CMDGetBlockByIndex: {Rate: 1000, Burst: 2000},
+	CMDGetBlockTxn:     {Rate: 1000, Burst: 2000},
+	CMDMempool:         {Rate: 0.1, Burst: 1},
+	CMDInv:             {Rate: 2000, Burst: 4000},
+	CMDExtensible:      {Rate: 100, Burst: 200},
+}
+
+// tokenBucket is a token bucket rate limiter.
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	// dropped is the number of items dropped since the last successful
+	// take.
+	dropped float64
+}
+
+func newTokenBucket(l config.RateLimit) *tokenBucket {
+	return &tokenBucket{
+		rate:   l.Rate,
+		burst:  float64(l.Burst),
+		tokens: float64(l.Burst),
+		last:   time.Now(),
+	}
+}
+
+// take tries to take cost tokens from the bucket.
+func (b *tokenBucket) take(cost float64, now time.Time) bool {
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	if cost > b.tokens {
+		b.dropped += cost
+		return false
+	}
+	b.tokens -= cost
+	b.dropped = 0
+	return true
+}
+
+// peerLimits contains rate limiting state of a single peer.
+type peerLimits struct {
+	buckets map[CommandType]*tokenBucket
+	// requested contains inventory items requested from the peer along
+	// with request times.
+	requested map[util.Uint256]time.Time
+}
+
+// rateLimiter limits the amount of work peers can make the node do.
+type rateLimiter struct {
+	limits         map[CommandType]config.RateLimit
+	maxOutstanding int
+	maxMempoolInv  int
+
+	lock  sync.Mutex
+	peers map[Peer]*peerLimits
+}
+
+func newRateLimiter(cfg config.P2PRateLimits) (*rateLimiter, error) {
+	rl := &rateLimiter{
+		limits:         make(map[CommandType]config.RateLimit, len(defaultRateLimits)),
+		maxOutstanding: cfg.MaxOutstandingRequests,
+		maxMempoolInv:  cfg.MaxMempoolInventory,
+		peers:          make(map[Peer]*peerLimits),
+	}
+	if rl.maxOutstanding <= 0 {
+		rl.maxOutstanding = defaultMaxOutstandingRequests
+	}
+	if rl.maxMempoolInv <= 0 {
+		rl.maxMempoolInv = defaultMaxMempoolInventory
+	}
+	for cmd, l := range defaultRateLimits {
+		rl.limits[cmd] = l
+	}
+	for name, l := range cfg.Commands {
+		cmd, ok := commandByName(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown command in rate limits: %s", name)
+		}
+		if l.Rate <= 0 || l.Burst <= 0 {
+			return nil, fmt.Errorf("invalid rate limit for %s: rate and burst must be positive", name)
+		}
+		rl.limits[cmd] = l
+	}
+	return rl, nil
+}
+
+// commandName returns configuration name of the command (like "getdata").
+func commandName(cmd CommandType) string {
+	return strings.ToLower(strings.TrimPrefix(cmd.String(), "CMD"))
+}
+
+// commandByName returns command by its configuration name.
+func commandByName(name string) (CommandType, bool) {
+	for i := 0; i < 256; i++ {
+		if commandName(CommandType(i)) == strings.ToLower(name) {
+			return CommandType(i), true
+		}
+	}
+	return 0, false
+}
+
+// messageCost returns the number of items the message contains for rate
+// limiting purposes.
+func messageCost(msg *Message) float64 {
+	switch p := msg.Payload.(type) {
+	case *payload.Inventory:
+		return float64(len(p.Hashes))
+	case *payload.GetBlockByIndex:
+		if p.Count == -1 {
+			return payload.MaxHeadersAllowed
+		}
+		return float64(p.Count)
+	case *payload.GetBlockTxn:
+		return float64(len(p.Indexes))
+	default:
+		return 1
+	}
+}
+
+// getPeer returns limits of the given peer creating them if needed. It must
+// be called with the lock held.
+func (rl *rateLimiter) getPeer(p Peer) *peerLimits {
+	pl, ok := rl.peers[p]
+	if !ok {
+		pl = &peerLimits{
+			buckets:   make(map[CommandType]*tokenBucket),
+			requested: make(map[util.Uint256]time.Time),
+		}
+		rl.peers[p] = pl
+	}
+	return pl
+}
+
+// allow checks whether the message received from the peer can be processed.
+// Messages exceeding the limit are to be dropped, but if the peer continues
+// sending them after it has exceeded the limit by more than burst, it returns
+// an error and the peer is to be disconnected.
+func (rl *rateLimiter) allow(p Peer, msg *Message) (bool, error) {
+	l, ok := rl.limits[msg.Command]
+	if !ok {
+		return true, nil
+	}
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	pl := rl.getPeer(p)
+	b, ok := pl.buckets[msg.Command]
+	if !ok {
+		b = newTokenBucket(l)
+		pl.buckets[msg.Command] = b
+	}
+	if b.take(messageCost(msg), time.Now()) {
+		return true, nil
+	}
+	if b.dropped > b.burst {
+		return false, fmt.Errorf("%w: %s", errRateLimited, commandName(msg.Command))
+	}
+	return false, nil
+}
+
+// request accounts the given items as requested from the peer. Items that
+// would exceed the outstanding requests limit are not accounted and not
+// returned, so they shouldn't be requested.
+func (rl *rateLimiter) request(p Peer, hashes []util.Uint256) []util.Uint256 {
+	now := time.Now()
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	pl := rl.getPeer(p)
+	for h, t := range pl.requested {
+		if now.Sub(t) > outstandingRequestTimeout {
+			delete(pl.requested, h)
+		}
+	}
+	res := hashes[:0]
+	for _, h := range hashes {
+		if _, ok := pl.requested[h]; !ok && len(pl.requested) >= rl.maxOutstanding {
+			break
+		}
+		pl.requested[h] = now
+		res = append(res, h)
+	}
+	return res
+}
+
+// received marks items as no longer outstanding for the peer.
+func (rl *rateLimiter) received(p Peer, hashes ...util.Uint256) {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	pl, ok := rl.peers[p]
+	if !ok {
+		return
+	}
+	for _, h := range hashes {
+		delete(pl.requested, h)
+	}
+}
+
+// outstanding returns the number of items requested from the peer.
+func (rl *rateLimiter) outstanding(p Peer) int {
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+	if pl, ok := rl.peers[p]; ok {
+		return len(pl.requested)
+	}
+	return 0
+}
+
+// removePeer drops all state of the peer.
+func (rl *rateLimiter) removePeer(p Peer) {
+	rl.lock.Lock()
+	delete(rl.peers, p)
+	rl.lock.Unlock()
+}
+
+// checkRateLimits checks the message against peer's rate limits and
+// accounts received inventory items. It returns false if the message should
+// be dropped.
+func (s *Server) checkRateLimits(p Peer, msg *Message) (bool, error) {
+	if s.limiter == nil {
+		return true, nil
+	}
+	switch pl := msg.Payload.(type) {
+	case *transaction.Transaction:
+		s.limiter.received(p, pl.Hash())
+	case *block.Block:
+		s.limiter.received(p, pl.Hash())
+	case *payload.Extensible:
+		s.limiter.received(p, pl.Hash())
+	case *payload.P2PNotaryRequest:
+		s.limiter.received(p, pl.FallbackTransaction.Hash())
+	case *payload.Inventory:
+		if msg.Command == CMDNotFound {
+			s.limiter.received(p, pl.Hashes...)
+		}
+	}
+	ok, err := s.limiter.allow(p, msg)
+	if !ok {
+		addLimitedMessagesMetric(commandName(msg.Command))
+	}
+	return ok, err
+}
@@ -0,0 +1,146 @@
+package network
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/internal/fakechain"
+	"github.com/nspcc-dev/neo-go/internal/random"
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(config.RateLimit{Rate: 10, Burst: 5})
+	now := b.last
+	require.True(t, b.take(5, now))
+	require.False(t, b.take(1, now))
+	require.Equal(t, float64(1), b.dropped)
+	require.True(t, b.take(1, now.Add(100*time.Millisecond)))
+	require.Equal(t, float64(0), b.dropped)
+	// Tokens don't accumulate beyond burst.
+	require.False(t, b.take(6, now.Add(time.Hour)))
+	require.True(t, b.take(5, now.Add(time.Hour)))
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	rl, err := newRateLimiter(config.P2PRateLimits{})
+	require.NoError(t, err)
+	require.Equal(t, defaultMaxOutstandingRequests, rl.maxOutstanding)
+	require.Equal(t, defaultMaxMempoolInventory, rl.maxMempoolInv)
+	require.Equal(t, defaultRateLimits, rl.limits)
+
+	rl, err = newRateLimiter(config.P2PRateLimits{Commands: map[string]config.RateLimit{
+		"getdata": {Rate: 1, Burst: 2},
+		"Addr":    {Rate: 3, Burst: 4},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, config.RateLimit{Rate: 1, Burst: 2}, rl.limits[CMDGetData])
+	require.Equal(t, config.RateLimit{Rate: 3, Burst: 4}, rl.limits[CMDAddr])
+
+	_, err = newRateLimiter(config.P2PRateLimits{Commands: map[string]config.RateLimit{"unknown": {Rate: 1, Burst: 1}}})
+	require.Error(t, err)
+	_, err = newRateLimiter(config.P2PRateLimits{Commands: map[string]config.RateLimit{"inv": {Rate: 1}}})
+	require.Error(t, err)
+}
+
+func TestRateLimits(t *testing.T) {
+	s := newTestServer(t, ServerConfig{RateLimitsCfg: config.P2PRateLimits{
+		Enabled:  true,
+		Commands: map[string]config.RateLimit{"getdata": {Rate: 0.001, Burst: 3}},
+	}})
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+
+	getData := func(n int) *Message {
+		hs := make([]util.Uint256, n)
+		for i := range hs {
+			hs[i] = random.Uint256()
+		}
+		return NewMessage(CMDGetData, payload.NewInventory(payload.TXType, hs))
+	}
+	var notFound int
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		if msg.Command == CMDNotFound {
+			notFound += len(msg.Payload.(*payload.Inventory).Hashes)
+		}
+	}
+	require.NoError(t, s.handleMessage(p, getData(2)))
+	require.Equal(t, 2, notFound)
+	// Limited message is dropped.
+	require.NoError(t, s.handleMessage(p, getData(2)))
+	require.Equal(t, 2, notFound)
+	require.NoError(t, s.handleMessage(p, getData(1)))
+	require.Equal(t, 3, notFound)
+
+	// Other peers and commands are not affected.
+	p2 := newLocalPeer(t, s)
+	p2.handshaked = true
+	p2.messageHandler = p.messageHandler
+	require.NoError(t, s.handleMessage(p2, getData(3)))
+	require.Equal(t, 6, notFound)
+
+	// Continuous flood leads to disconnection.
+	require.NoError(t, s.handleMessage(p, getData(3)))
+	err := s.handleMessage(p, getData(1))
+	require.True(t, errors.Is(err, errRateLimited))
+	require.Equal(t, scoreRateLimited, misbehaviourScore(err))
+}
+
+func TestOutstandingRequests(t *testing.T) {
+	s := newTestServer(t, ServerConfig{RateLimitsCfg: config.P2PRateLimits{
+		Enabled:                true,
+		MaxOutstandingRequests: 3,
+	}})
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	var requested []util.Uint256
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		if msg.Command == CMDGetData {
+			requested = append(requested, msg.Payload.(*payload.Inventory).Hashes...)
+		}
+	}
+	hs := []util.Uint256{random.Uint256(), random.Uint256(), random.Uint256(), random.Uint256()}
+	s.testHandleMessage(t, p, CMDInv, payload.NewInventory(payload.TXType, hs[:2]))
+	require.Equal(t, hs[:2], requested)
+
+	requested = nil
+	s.testHandleMessage(t, p, CMDInv, payload.NewInventory(payload.TXType, hs[2:]))
+	require.Equal(t, hs[2:3], requested)
+	require.Equal(t, 3, s.limiter.outstanding(p))
+
+	// Received items are no longer outstanding.
+	s.testHandleMessage(t, p, CMDNotFound, payload.NewInventory(payload.TXType, hs[:1]))
+	require.Equal(t, 2, s.limiter.outstanding(p))
+
+	requested = nil
+	s.testHandleMessage(t, p, CMDInv, payload.NewInventory(payload.TXType, hs[3:]))
+	require.Equal(t, hs[3:], requested)
+
+	s.limiter.removePeer(p)
+	require.Equal(t, 0, s.limiter.outstanding(p))
+}
+
+func TestMempoolInventoryLimit(t *testing.T) {
+	s := newTestServer(t, ServerConfig{RateLimitsCfg: config.P2PRateLimits{
+		Enabled:             true,
+		MaxMempoolInventory: 2,
+	}})
+	bc := s.chain.(*fakechain.FakeChain)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, bc.Pool.Add(newDummyTx(), &feerStub{blockHeight: 10}))
+	}
+	p := newLocalPeer(t, s)
+	p.handshaked = true
+	var announced int
+	p.messageHandler = func(t *testing.T, msg *Message) {
+		if msg.Command == CMDInv {
+			announced += len(msg.Payload.(*payload.Inventory).Hashes)
+		}
+	}
+	s.testHandleMessage(t, p, CMDMempool, payload.NullPayload{})
+	require.Equal(t, 2, announced)
+}
@@ -0,0 +1,116 @@
+package network
+
+import (
+	"errors"
+	gio "io"
+	"net"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reputation score changes for various peer behaviours. Useful data slowly
+// increases peer's score, misbehaviour decreases it and peer gets banned
+// when its score drops low enough (see DiscoveryConfig.BanScore).
+const (
+	scoreUsefulBlock       = 1
+	scoreSlowResponse      = -10
+	scoreInvalidMessage    = -20
+	scoreProtocolViolation = -50
+	scoreRateLimited       = -20
+)
+
+var (
+	errBanned            = errors.New("peer is banned")
+	errProtocolViolation = errors.New("protocol violation")
+)
+
+// misbehaviourScore returns score change for the peer disconnected or
+// failed to be handled with the given error.
+func misbehaviourScore(err error) int {
+	switch {
+	case err == nil,
+		errors.Is(err, errAlreadyConnected),
+		errors.Is(err, errIdenticalID),
+		errors.Is(err, errMaxPeers),
+		errors.Is(err, errServerShutdown),
+		errors.Is(err, errBanned),
+		errors.Is(err, errTLSRequired),
+		errors.Is(err, errSubnetLimit),
+		errors.Is(err, errGone),
+		errors.Is(err, errBusy),
+		isConnectionError(err):
+		return 0
+	case errors.Is(err, errPingPong):
+		return scoreSlowResponse
+	case errors.Is(err, errRateLimited):
+		return scoreRateLimited
+	case errors.Is(err, errInvalidNetwork),
+		errors.Is(err, errUnexpectedPong),
+		errors.Is(err, errStateMismatch),
+		errors.Is(err, errProtocolViolation):
+		return scoreProtocolViolation
+	default:
+		return scoreInvalidMessage
+	}
+}
+
+// isConnectionError checks whether the given error is a connection-level
+// one (rather than something wrong with the data received).
+func isConnectionError(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, gio.EOF) || errors.Is(err, gio.ErrUnexpectedEOF) ||
+		errors.As(err, &netErr)
+}
+
+// adjustScore changes reputation score of the given peer and disconnects it
+// if it gets banned because of that.
+func (s *Server) adjustScore(p Peer, delta int) {
+	if delta == 0 {
+		return
+	}
+	addr := p.RemoteAddr().String()
+	if s.discovery.AdjustScore(addr, delta) {
+		s.log.Warn("peer banned", zap.String("addr", addr), zap.Duration("duration", s.BanDuration))
+		go p.Disconnect(errBanned)
+	}
+}
+
+// checkBlockRequests penalizes peers that haven't answered block requests
+// in time, their requests are then reassigned to other peers.
+func (s *Server) checkBlockRequests() {
+	slow := s.blockSched.expire(s.chain.BlockHeight())
+	addBlockRequestTimeoutsMetric(len(slow))
+	for _, p := range slow {
+		s.adjustScore(p, scoreSlowResponse)
+	}
+}
+
+// BanPeer bans the given host (port part of the address is ignored if it's
+// present) for the given duration (BanDuration if it's zero) and drops all
+// connections with it.
+func (s *Server) BanPeer(addr string, d time.Duration) {
+	s.discovery.Ban(addr, d)
+	host := hostOf(addr)
+	for p := range s.Peers() {
+		if hostOf(p.RemoteAddr().String()) == host {
+			go p.Disconnect(errBanned)
+		}
+	}
+}
+
+// UnbanPeer removes the ban for the given host, it returns false if the host
+// wasn't banned.
+func (s *Server) UnbanPeer(addr string) bool {
+	return s.discovery.Unban(addr)
+}
+
+// BannedPeers returns a list of currently banned hosts.
+func (s *Server) BannedPeers() []BannedAddress {
+	return s.discovery.BannedPeers()
+}
+
+// PeerScore returns reputation score of the given host.
+func (s *Server) PeerScore(addr string) int {
+	return s.discovery.Score(addr)
+}
@@ -0,0 +1,21 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	gio "io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMisbehaviourScore(t *testing.T) {
+	require.Equal(t, 0, misbehaviourScore(nil))
+	require.Equal(t, 0, misbehaviourScore(errServerShutdown))
+	require.Equal(t, 0, misbehaviourScore(gio.EOF))
+	require.Equal(t, 0, misbehaviourScore(fmt.Errorf("read: %w", gio.ErrUnexpectedEOF)))
+	require.Equal(t, scoreSlowResponse, misbehaviourScore(errPingPong))
+	require.Equal(t, scoreProtocolViolation, misbehaviourScore(errInvalidNetwork))
+	require.Equal(t, scoreProtocolViolation, misbehaviourScore(fmt.Errorf("%w: bad", errProtocolViolation)))
+	require.Equal(t, scoreInvalidMessage, misbehaviourScore(errors.New("invalid block")))
+}
@@ -2,10 +2,10 @@ package network
 
 import (
 	"crypto/rand"
+	"crypto/tls"
 	"encoding/binary"
 	"errors"
 	"fmt"
-	mrand "math/rand"
 	"net"
 	"strconv"
 	"sync"
@@ -76,8 +76,19 @@ type (
 		lock  sync.RWMutex
 		peers map[Peer]bool
 
-		// lastRequestedHeight contains last requested height.
-		lastRequestedHeight atomic.Uint32
+		// blockSched distributes block requests among peers.
+		blockSched *blockScheduler
+		// compact keeps compact block relay state.
+		compact *compactRelay
+		// limiter limits the amount of work peers can make us do, it's nil
+		// if rate limiting is disabled.
+		limiter *rateLimiter
+
+		// policy restricts inbound connections.
+		policy *connPolicy
+		// fixedPeers contains reconnection state of peers the server
+		// always keeps connections with.
+		fixedPeers map[string]*fixedPeer
 
 		register   chan Peer
 		unregister chan peerDrop
@@ -107,15 +118,35 @@ func randomID() uint32 {
 
 // NewServer returns a new Server, initialized with the given configuration.
 func NewServer(config ServerConfig, chain blockchainer.Blockchainer, log *zap.Logger) (*Server, error) {
+	var (
+		tlsConfig *tls.Config
+		tlsPeers  []tlsPeer
+	)
+	if config.P2PTLSCfg.Enabled {
+		var err error
+		tlsConfig, err = newTLSConfig(config.P2PTLSCfg)
+		if err != nil {
+			return nil, err
+		}
+		tlsPeers, err = resolveTLSPeers(config.P2PTLSCfg.Peers)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return newServerFromConstructors(config, chain, log, func(s *Server) Transporter {
-		return NewTCPTransport(s, net.JoinHostPort(s.ServerConfig.Address, strconv.Itoa(int(s.ServerConfig.Port))), s.log)
+		t := NewTCPTransport(s, net.JoinHostPort(s.ServerConfig.Address, strconv.Itoa(int(s.ServerConfig.Port))), s.log)
+		if tlsConfig == nil {
+			return t
+		}
+		return newDualTransport(t, NewTLSTransport(s,
+			net.JoinHostPort(s.ServerConfig.Address, strconv.Itoa(int(s.P2PTLSCfg.Port))), tlsConfig, s.log), tlsPeers)
 	}, consensus.NewService, newDefaultDiscovery)
 }
 
 func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchainer, log *zap.Logger,
 	newTransport func(*Server) Transporter,
 	newConsensus func(consensus.Config) (consensus.Service, error),
-	newDiscovery func([]string, time.Duration, Transporter) Discoverer,
+	newDiscovery func(DiscoveryConfig, Transporter) (Discoverer, error),
 ) (*Server, error) {
 	if log == nil {
 		return nil, errors.New("logger is a required parameter")
@@ -137,6 +168,8 @@ func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchai
 		register:          make(chan Peer),
 		unregister:        make(chan peerDrop),
 		peers:             make(map[Peer]bool),
+		blockSched:        newBlockScheduler(blockRequestTimeout),
+		compact:           newCompactRelay(),
 		syncReached:       atomic.NewBool(false),
 		extensiblePool:    extpool.New(chain, config.ExtensiblePoolSize),
 		log:               log,
@@ -183,6 +216,7 @@ func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchai
 	if err != nil {
 		return nil, fmt.Errorf("can't initialize StateRoot service: %w", err)
 	}
+	sr.SetHaltCallback(s.haltBlockProcessing)
 	s.stateRoot = sr
 
 	if config.OracleCfg.Enabled {
@@ -244,12 +278,48 @@ func newServerFromConstructors(config ServerConfig, chain blockchainer.Blockchai
 		s.AttemptConnPeers = defaultAttemptConnPeers
 	}
 
+	if s.BanDuration <= 0 {
+		s.BanDuration = defaultBanDuration
+	}
+
+	s.policy, err = newConnPolicy(s.InboundAllow, s.InboundDeny)
+	if err != nil {
+		return nil, err
+	}
+	if s.CompactBlocks {
+		if err := s.compact.setHosts(s.CompactBlocksPeers); err != nil {
+			return nil, err
+		}
+	}
+	if s.RateLimitsCfg.Enabled {
+		s.limiter, err = newRateLimiter(s.RateLimitsCfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.fixedPeers = make(map[string]*fixedPeer, len(s.FixedPeers))
+	for _, addr := range s.FixedPeers {
+		s.fixedPeers[addr], err = newFixedPeer(addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if s.BanScore <= 0 {
+		s.BanScore = defaultBanScore
+	}
+
 	s.transport = newTransport(s)
-	s.discovery = newDiscovery(
-		s.Seeds,
-		s.DialTimeout,
-		s.transport,
-	)
+	s.discovery, err = newDiscovery(DiscoveryConfig{
+		Seeds:           s.Seeds,
+		DialTimeout:     s.DialTimeout,
+		BanScore:        s.BanScore,
+		BanDuration:     s.BanDuration,
+		AddressBookFile: s.AddressBookFile,
+		Log:             s.log,
+	}, s.transport)
+	if err != nil {
+		return nil, err
+	}
 
 	return s, nil
 }
@@ -267,11 +337,17 @@ func (s *Server) Start(errChan chan error) {
 
 	s.tryStartServices()
 	s.initStaleMemPools()
+	s.loadMemPool()
 
 	go s.broadcastTxLoop()
 	go s.relayBlocksLoop()
 	go s.bQueue.run()
 	go s.transport.Accept()
+	if dt, ok := s.transport.(*dualTransport); ok {
+		s.discovery.BackFill(dt.peerAddrs()...)
+	}
+	go s.maintainFixedPeers()
+	go s.saveMemPoolLoop()
 	setServerAndNodeVersions(s.UserAgent, strconv.FormatUint(uint64(s.id), 10))
 	s.run()
 }
@@ -286,7 +362,7 @@ func (s *Server) Shutdown() {
 		p.Disconnect(errServerShutdown)
 	}
 	s.bQueue.discard()
-	if s.StateRootCfg.Enabled {
+	if s.StateRootCfg.Enabled || s.StateRootCfg.Verification.Enabled {
 		s.stateRoot.Shutdown()
 	}
 	if s.oracle != nil {
@@ -298,19 +374,47 @@ func (s *Server) Shutdown() {
 	if s.chain.P2PSigExtensionsEnabled() {
 		s.notaryRequestPool.StopSubscriptions()
 	}
+	s.saveMemPool()
 	close(s.quit)
 }
 
+// GetConsensus returns consensus service instance.
+func (s *Server) GetConsensus() consensus.Service {
+	return s.consensus
+}
+
 // GetOracle returns oracle module instance.
 func (s *Server) GetOracle() *oracle.Oracle {
 	return s.oracle
 }
 
+// GetNotary returns Notary module instance, it's nil if the module is
+// disabled.
+func (s *Server) GetNotary() *notary.Notary {
+	return s.notaryModule
+}
+
 // GetStateRoot returns state root service instance.
 func (s *Server) GetStateRoot() stateroot.Service {
 	return s.stateRoot
 }
 
+// haltBlockProcessing stops adding new blocks to the chain, it's used when
+// local state diverges from the trusted one, so the node needs to be restarted
+// after the problem is investigated.
+func (s *Server) haltBlockProcessing() {
+	s.log.Error("block processing is halted", zap.Uint32("blockHeight", s.chain.BlockHeight()))
+	s.bQueue.halt()
+	s.consensus.Shutdown()
+}
+
+// IsBlockProcessingHalted returns true if block processing was stopped because
+// local state diverged from the trusted one, no new blocks should be added to
+// the chain in this case.
+func (s *Server) IsBlockProcessingHalted() bool {
+	return s.bQueue.halted.Load()
+}
+
 // UnconnectedPeers returns a list of peers that are in the discovery peer list
 // but are not connected to the server.
 func (s *Server) UnconnectedPeers() []string {
@@ -343,7 +447,7 @@ func (s *Server) run() {
 		if s.PeerCount() < s.MinPeers {
 			s.discovery.RequestRemote(s.AttemptConnPeers)
 		}
-		if s.discovery.PoolCount() < minPoolCount {
+		if !s.PrivateMode && s.discovery.PoolCount() < minPoolCount {
 			s.broadcastHPMessage(NewMessage(CMDGetAddr, payload.NewNullPayload()))
 		}
 		select {
@@ -355,10 +459,18 @@ func (s *Server) run() {
 			s.lock.Unlock()
 			peerCount := s.PeerCount()
 			s.log.Info("new peer connected", zap.Stringer("addr", p.RemoteAddr()), zap.Int("peerCount", peerCount))
-			if peerCount > s.MaxPeers {
+			if s.discovery.IsBanned(p.RemoteAddr().String()) {
+				// It will send us unregister signal.
+				go p.Disconnect(errBanned)
+			} else if !s.isFixed(p) && s.subnetLimitReached(p) {
+				go p.Disconnect(errSubnetLimit)
+			} else if peerCount > s.MaxPeers {
 				s.lock.RLock()
-				// Pick a random peer and drop connection to it.
+				// Pick a random peer (not a fixed one) and drop connection to it.
 				for peer := range s.peers {
+					if s.isFixed(peer) {
+						continue
+					}
 					// It will send us unregister signal.
 					go peer.Disconnect(errMaxPeers)
 					break
@@ -376,8 +488,18 @@ func (s *Server) run() {
 					zap.Stringer("addr", drop.peer.RemoteAddr()),
 					zap.String("reason", drop.reason.Error()),
 					zap.Int("peerCount", s.PeerCount()))
+				s.blockSched.removePeer(drop.peer)
+				if s.limiter != nil {
+					s.limiter.removePeer(drop.peer)
+				}
+				if delta := misbehaviourScore(drop.reason); delta != 0 &&
+					s.discovery.AdjustScore(drop.peer.RemoteAddr().String(), delta) {
+					s.log.Warn("peer banned",
+						zap.Stringer("addr", drop.peer.RemoteAddr()),
+						zap.Duration("duration", s.BanDuration))
+				}
 				addr := drop.peer.PeerAddr().String()
-				if drop.reason == errIdenticalID {
+				if drop.reason == errIdenticalID || drop.reason == errTLSRequired {
 					s.discovery.RegisterBadAddr(addr)
 				} else if drop.reason == errAlreadyConnected {
 					// There is a race condition when peer can be disconnected twice for the this reason
@@ -423,6 +545,7 @@ func (s *Server) runProto() {
 		case <-s.quit:
 			return
 		case <-pingTimer.C:
+			s.checkBlockRequests()
 			if s.chain.BlockHeight() == prevHeight {
 				// Get a copy of s.peers to avoid holding a lock while sending.
 				for peer := range s.Peers() {
@@ -444,7 +567,7 @@ func (s *Server) tryStartServices() {
 		if s.Wallet != nil {
 			s.consensus.Start()
 		}
-		if s.StateRootCfg.Enabled {
+		if s.StateRootCfg.Enabled || s.StateRootCfg.Verification.Enabled {
 			s.stateRoot.Run()
 		}
 		if s.oracle != nil {
@@ -521,8 +644,8 @@ func (s *Server) HandshakedPeersCount() int {
 	return count
 }
 
-// getVersionMsg returns current version message.
-func (s *Server) getVersionMsg() (*Message, error) {
+// getVersionMsg returns current version message for the given peer.
+func (s *Server) getVersionMsg(p Peer) (*Message, error) {
 	port, err := s.Port()
 	if err != nil {
 		return nil, err
@@ -536,6 +659,23 @@ func (s *Server) getVersionMsg() (*Message, error) {
 			},
 		},
 	}
+	if tlsPort, ok := s.tlsPort(p); ok {
+		capabilities = append(capabilities, capability.Capability{
+			Type: capability.TLSServer,
+			Data: &capability.Server{
+				Port: tlsPort,
+			},
+		})
+	}
+	// CompactBlocks capability is NeoGo-specific and other nodes can't
+	// even decode version message with it, so it's only sent to peers
+	// known to support it.
+	if s.CompactBlocks && s.compact.isKnown(p) {
+		capabilities = append(capabilities, capability.Capability{
+			Type: capability.CompactBlocks,
+			Data: &capability.Compact{},
+		})
+	}
 	if s.Relay {
 		capabilities = append(capabilities, capability.Capability{
 			Type: capability.FullNode,
@@ -601,6 +741,9 @@ func (s *Server) handleVersionCmd(p Peer, version *payload.Version) error {
 	if s.Net != version.Magic {
 		return errInvalidNetwork
 	}
+	if err := s.checkTLS(p, version); err != nil {
+		return err
+	}
 	peerAddr := p.PeerAddr().String()
 	s.discovery.RegisterConnectedAddr(peerAddr)
 	s.lock.RLock()
@@ -621,7 +764,18 @@ func (s *Server) handleVersionCmd(p Peer, version *payload.Version) error {
 
 // handleBlockCmd processes the received block received from its peer.
 func (s *Server) handleBlockCmd(p Peer, block *block.Block) error {
-	return s.bQueue.putBlock(block)
+	if block.Index > s.chain.BlockHeight() {
+		s.adjustScore(p, scoreUsefulBlock)
+	}
+	completed := s.blockSched.blockReceived(p, block.Index)
+	if err := s.bQueue.putBlock(block); err != nil {
+		return err
+	}
+	// Keep the peer busy if it has more blocks.
+	if completed && s.chain.BlockHeight() < p.LastBlockIndex() {
+		return s.requestBlocks(p)
+	}
+	return nil
 }
 
 // handlePing processes ping request.
@@ -672,6 +826,11 @@ func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
 			}
 		}
 	}
+	if s.limiter != nil {
+		n := len(reqHashes)
+		reqHashes = s.limiter.request(p, reqHashes)
+		addDroppedItemsMetric(commandName(CMDInv), n-len(reqHashes))
+	}
 	if len(reqHashes) > 0 {
 		msg := NewMessage(CMDGetData, payload.NewInventory(inv.Type, reqHashes))
 		pkt, err := msg.Bytes()
@@ -689,6 +848,12 @@ func (s *Server) handleInvCmd(p Peer, inv *payload.Inventory) error {
 // handleMempoolCmd handles getmempool command.
 func (s *Server) handleMempoolCmd(p Peer) error {
 	txs := s.chain.GetMemPool().GetVerifiedTransactions()
+	// Transactions are sorted by priority, so only the most valuable ones
+	// are announced if there are too many of them.
+	if s.limiter != nil && len(txs) > s.limiter.maxMempoolInv {
+		addDroppedItemsMetric(commandName(CMDMempool), len(txs)-s.limiter.maxMempoolInv)
+		txs = txs[:s.limiter.maxMempoolInv]
+	}
 	hs := make([]util.Uint256, 0, payload.MaxHashesCount)
 	for i := range txs {
 		hs = append(hs, txs[i].Hash())
@@ -934,7 +1099,7 @@ func (s *Server) broadcastP2PNotaryRequestPayload(_ *transaction.Transaction, da
 // handleAddrCmd will process received addresses.
 func (s *Server) handleAddrCmd(p Peer, addrs *payload.AddressList) error {
 	if !p.CanProcessAddr() {
-		return errors.New("unexpected addr received")
+		return fmt.Errorf("%w: unexpected addr received", errProtocolViolation)
 	}
 	dups := make(map[string]bool)
 	for _, a := range addrs.Addrs {
@@ -949,6 +1114,10 @@ func (s *Server) handleAddrCmd(p Peer, addrs *payload.AddressList) error {
 
 // handleGetAddrCmd sends to the peer some good addresses that we know of.
 func (s *Server) handleGetAddrCmd(p Peer) error {
+	// Private node never tells anyone about its peers.
+	if s.PrivateMode {
+		return nil
+	}
 	addrs := s.discovery.GoodPeers()
 	if len(addrs) > payload.MaxAddrsCount {
 		addrs = addrs[:payload.MaxAddrsCount]
@@ -963,44 +1132,20 @@ func (s *Server) handleGetAddrCmd(p Peer) error {
 	return p.EnqueueP2PMessage(NewMessage(CMDAddr, alist))
 }
 
-// requestBlocks sends a CMDGetBlockByIndex message to the peer
-// to sync up in blocks. A maximum of maxBlockBatch will
-// send at once. Two things we need to take care of:
-// 1. If possible, blocks should be fetched in parallel.
-//    height..+500 to one peer, height+500..+1000 to another etc.
-// 2. Every block must eventually be fetched even if peer sends no answer.
-// Thus the following algorithm is used:
-// 1. Block range is divided into chunks of payload.MaxHashesCount.
-// 2. Send requests for chunk in increasing order.
-// 3. After all requests were sent, request random height.
+// requestBlocks sends CMDGetBlockByIndex messages to the peer to sync up in
+// blocks. Missing blocks are divided into chunks of payload.MaxHashesCount
+// blocks that are distributed among all peers by the block scheduler, so
+// that blocks are fetched in parallel. Every chunk is requested from one peer
+// at a time and requests that are not answered in time are reassigned to
+// other peers.
 func (s *Server) requestBlocks(p Peer) error {
-	var currHeight = s.chain.BlockHeight()
-	var peerHeight = p.LastBlockIndex()
-	var needHeight uint32
-	// lastRequestedHeight can only be increased.
-	for {
-		old := s.lastRequestedHeight.Load()
-		if old <= currHeight {
-			needHeight = currHeight + 1
-			if !s.lastRequestedHeight.CAS(old, needHeight) {
-				continue
-			}
-		} else if old < currHeight+(blockCacheSize-payload.MaxHashesCount) {
-			needHeight = currHeight + 1
-			if peerHeight > old+payload.MaxHashesCount {
-				needHeight = old + payload.MaxHashesCount
-				if !s.lastRequestedHeight.CAS(old, needHeight) {
-					continue
-				}
-			}
-		} else {
-			index := mrand.Intn(blockCacheSize / payload.MaxHashesCount)
-			needHeight = currHeight + 1 + uint32(index*payload.MaxHashesCount)
+	s.checkBlockRequests()
+	for _, req := range s.blockSched.assign(p, s.chain.BlockHeight()) {
+		if err := p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, req)); err != nil {
+			return err
 		}
-		break
 	}
-	payload := payload.NewGetBlockByIndex(needHeight, -1)
-	return p.EnqueueP2PMessage(NewMessage(CMDGetBlockByIndex, payload))
+	return nil
 }
 
 // handleMessage processes the given message.
@@ -1015,6 +1160,9 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 				return errInvalidInvType
 			}
 		}
+		if ok, err := s.checkRateLimits(peer, msg); !ok {
+			return err
+		}
 		switch msg.Command {
 		case CMDAddr:
 			addrs := msg.Payload.(*payload.AddressList)
@@ -1043,6 +1191,15 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 		case CMDBlock:
 			block := msg.Payload.(*block.Block)
 			return s.handleBlockCmd(peer, block)
+		case CMDCompactBlock:
+			cb := msg.Payload.(*payload.CompactBlock)
+			return s.handleCompactBlockCmd(peer, cb)
+		case CMDGetBlockTxn:
+			req := msg.Payload.(*payload.GetBlockTxn)
+			return s.handleGetBlockTxnCmd(peer, req)
+		case CMDBlockTxn:
+			bt := msg.Payload.(*payload.BlockTxn)
+			return s.handleBlockTxnCmd(peer, bt)
 		case CMDExtensible:
 			cp := msg.Payload.(*payload.Extensible)
 			return s.handleExtensibleCmd(cp)
@@ -1059,7 +1216,7 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 			pong := msg.Payload.(*payload.Ping)
 			return s.handlePong(peer, pong)
 		case CMDVersion, CMDVerack:
-			return fmt.Errorf("received '%s' after the handshake", msg.Command.String())
+			return fmt.Errorf("%w: received '%s' after the handshake", errProtocolViolation, msg.Command.String())
 		}
 	} else {
 		switch msg.Command {
@@ -1075,7 +1232,7 @@ func (s *Server) handleMessage(peer Peer, msg *Message) error {
 
 			s.tryStartServices()
 		default:
-			return fmt.Errorf("received '%s' during handshake", msg.Command.String())
+			return fmt.Errorf("%w: received '%s' during handshake", errProtocolViolation, msg.Command.String())
 		}
 	}
 	return nil
@@ -1200,8 +1357,18 @@ func (s *Server) relayBlocksLoop() {
 			msg := NewMessage(CMDInv, payload.NewInventory(payload.BlockType, []util.Uint256{b.Hash()}))
 			// Filter out nodes that are more current (avoid spamming the network
 			// during initial sync).
-			s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+			needsBlock := func(p Peer) bool {
 				return p.Handshaked() && p.LastBlockIndex() < b.Index
+			}
+			// Peers supporting compact blocks get them directly instead
+			// of inventory, others fall back to requesting full blocks.
+			if s.CompactBlocks {
+				s.iteratePeersWithSendMsg(s.compactBlockMsg(b), Peer.EnqueuePacket, func(p Peer) bool {
+					return needsBlock(p) && supportsCompactBlocks(p)
+				})
+			}
+			s.iteratePeersWithSendMsg(msg, Peer.EnqueuePacket, func(p Peer) bool {
+				return needsBlock(p) && !(s.CompactBlocks && supportsCompactBlocks(p))
 			})
 			s.extensiblePool.RemoveStale(b.Index)
 		}
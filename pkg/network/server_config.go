@@ -76,11 +76,60 @@ type (
 		// P2PNotaryCfg is notary module configuration.
 		P2PNotaryCfg config.P2PNotary
 
+		// P2PTLSCfg is encrypted transport configuration.
+		P2PTLSCfg config.P2PTLS
+
 		// StateRootCfg is stateroot module configuration.
 		StateRootCfg config.StateRoot
 
 		// ExtensiblePoolSize is size of the pool for extensible payloads from a single sender.
 		ExtensiblePoolSize int
+
+		// BanScore is the misbehaviour penalty peer can accumulate before
+		// being banned.
+		BanScore int
+
+		// BanDuration is the duration of automatic peer bans.
+		BanDuration time.Duration
+
+		// AddressBookFile is the file known peer addresses, scores and
+		// bans are stored to.
+		AddressBookFile string
+
+		// FixedPeers is a list of addresses the node always keeps
+		// connections with.
+		FixedPeers []string
+
+		// InboundAllow and InboundDeny are lists of CIDR subnets inbound
+		// connections are allowed/denied from. All addresses are allowed
+		// if InboundAllow is empty, InboundDeny has a priority.
+		InboundAllow []string
+		InboundDeny  []string
+
+		// MaxPeersPerSubnet is the maximum number of connections with
+		// peers from the same /24 (IPv4) or /64 (IPv6) subnet.
+		MaxPeersPerSubnet int
+
+		// PrivateMode disables address exchange with other nodes.
+		PrivateMode bool
+
+		// CompactBlocks enables compact block relay with peers supporting
+		// it.
+		CompactBlocks bool
+
+		// CompactBlocksPeers are hosts of peers known to support compact
+		// block relay, it's only announced to them.
+		CompactBlocksPeers []string
+
+		// RateLimitsCfg is per-peer P2P message rate limits configuration.
+		RateLimitsCfg config.P2PRateLimits
+
+		// MemPoolFile is the file memory pools contents are stored to.
+		MemPoolFile string
+
+		// MemPoolSaveInterval is the interval between periodic memory
+		// pools saves.
+		MemPoolSaveInterval time.Duration
 	}
 )
 
@@ -96,25 +145,39 @@ func NewServerConfig(cfg config.Config) ServerConfig {
 	}
 
 	return ServerConfig{
-		UserAgent:          cfg.GenerateUserAgent(),
-		Address:            appConfig.Address,
-		AnnouncedPort:      appConfig.AnnouncedNodePort,
-		Port:               appConfig.NodePort,
-		Net:                protoConfig.Magic,
-		Relay:              appConfig.Relay,
-		Seeds:              protoConfig.SeedList,
-		DialTimeout:        appConfig.DialTimeout * time.Second,
-		ProtoTickInterval:  appConfig.ProtoTickInterval * time.Second,
-		PingInterval:       appConfig.PingInterval * time.Second,
-		PingTimeout:        appConfig.PingTimeout * time.Second,
-		MaxPeers:           appConfig.MaxPeers,
-		AttemptConnPeers:   appConfig.AttemptConnPeers,
-		MinPeers:           appConfig.MinPeers,
-		Wallet:             wc,
-		TimePerBlock:       time.Duration(protoConfig.SecondsPerBlock) * time.Second,
-		OracleCfg:          appConfig.Oracle,
-		P2PNotaryCfg:       appConfig.P2PNotary,
-		StateRootCfg:       appConfig.StateRoot,
-		ExtensiblePoolSize: appConfig.ExtensiblePoolSize,
+		UserAgent:           cfg.GenerateUserAgent(),
+		Address:             appConfig.Address,
+		AnnouncedPort:       appConfig.AnnouncedNodePort,
+		Port:                appConfig.NodePort,
+		Net:                 protoConfig.Magic,
+		Relay:               appConfig.Relay,
+		Seeds:               protoConfig.SeedList,
+		DialTimeout:         appConfig.DialTimeout * time.Second,
+		ProtoTickInterval:   appConfig.ProtoTickInterval * time.Second,
+		PingInterval:        appConfig.PingInterval * time.Second,
+		PingTimeout:         appConfig.PingTimeout * time.Second,
+		MaxPeers:            appConfig.MaxPeers,
+		AttemptConnPeers:    appConfig.AttemptConnPeers,
+		MinPeers:            appConfig.MinPeers,
+		Wallet:              wc,
+		TimePerBlock:        time.Duration(protoConfig.SecondsPerBlock) * time.Second,
+		OracleCfg:           appConfig.Oracle,
+		P2PNotaryCfg:        appConfig.P2PNotary,
+		P2PTLSCfg:           appConfig.P2PTLS,
+		StateRootCfg:        appConfig.StateRoot,
+		ExtensiblePoolSize:  appConfig.ExtensiblePoolSize,
+		BanScore:            appConfig.BanScore,
+		BanDuration:         appConfig.BanDuration * time.Second,
+		AddressBookFile:     appConfig.AddressBookFile,
+		FixedPeers:          appConfig.FixedPeers,
+		InboundAllow:        appConfig.InboundAllow,
+		InboundDeny:         appConfig.InboundDeny,
+		MaxPeersPerSubnet:   appConfig.MaxPeersPerSubnet,
+		PrivateMode:         appConfig.PrivateMode,
+		CompactBlocks:       appConfig.CompactBlocks,
+		CompactBlocksPeers:  appConfig.CompactBlocksPeers,
+		RateLimitsCfg:       appConfig.P2PRateLimits,
+		MemPoolFile:         appConfig.MemPoolFile,
+		MemPoolSaveInterval: appConfig.MemPoolSaveInterval * time.Second,
 	}
 }
@@ -44,6 +44,7 @@ func (f *fakeConsensus) Shutdown()                                     { f.stopp
 func (f *fakeConsensus) OnPayload(p *payload.Extensible)               { f.payloads = append(f.payloads, p) }
 func (f *fakeConsensus) OnTransaction(tx *transaction.Transaction)     { f.txs = append(f.txs, tx) }
 func (f *fakeConsensus) GetPayload(h util.Uint256) *payload.Extensible { panic("implement me") }
+func (f *fakeConsensus) GetState() (consensus.State, error)            { panic("implement me") }
 
 func TestNewServer(t *testing.T) {
 	bc := &fakechain.FakeChain{}
@@ -182,56 +183,49 @@ func TestServerRegisterPeer(t *testing.T) {
 
 func TestGetBlocksByIndex(t *testing.T) {
 	s := newTestServer(t, ServerConfig{Port: 0, UserAgent: "/test/"})
-	ps := make([]*localPeer, 10)
-	expectsCmd := make([]CommandType, 10)
-	expectedHeight := make([][]uint32, 10)
-	start := s.chain.BlockHeight()
+	ps := make([]*localPeer, 4)
+	requested := make([][]payload.GetBlockByIndex, len(ps))
 	for i := range ps {
 		i := i
 		ps[i] = newLocalPeer(t, s)
 		ps[i].messageHandler = func(t *testing.T, msg *Message) {
-			require.Equal(t, expectsCmd[i], msg.Command)
-			if expectsCmd[i] == CMDGetBlockByIndex {
+			if msg.Command == CMDGetBlockByIndex {
 				p, ok := msg.Payload.(*payload.GetBlockByIndex)
 				require.True(t, ok)
-				require.Contains(t, expectedHeight[i], p.IndexStart)
-				expectsCmd[i] = CMDPong
-			} else if expectsCmd[i] == CMDPong {
-				expectsCmd[i] = CMDGetBlockByIndex
+				requested[i] = append(requested[i], *p)
 			}
 		}
-		expectsCmd[i] = CMDGetBlockByIndex
-		expectedHeight[i] = []uint32{start + 1}
 	}
 	go s.transport.Accept()
 
 	nonce := uint32(0)
-	checkPingRespond := func(t *testing.T, peerIndex int, peerHeight uint32, hs ...uint32) {
+	checkPingRespond := func(t *testing.T, peerIndex int, peerHeight uint32, expected ...payload.GetBlockByIndex) {
 		nonce++
-		expectedHeight[peerIndex] = hs
+		requested[peerIndex] = nil
 		require.NoError(t, s.handlePing(ps[peerIndex], payload.NewPing(peerHeight, nonce)))
+		require.Equal(t, expected, requested[peerIndex])
+	}
+	req := func(start uint32, count int16) payload.GetBlockByIndex {
+		return payload.GetBlockByIndex{IndexStart: start, Count: count}
 	}
 
-	// Send all requests for all chunks.
-	checkPingRespond(t, 0, 5000, 1)
-	checkPingRespond(t, 1, 5000, 1+payload.MaxHashesCount)
-	checkPingRespond(t, 2, 5000, 1+2*payload.MaxHashesCount)
-	checkPingRespond(t, 3, 5000, 1+3*payload.MaxHashesCount)
+	// Chunks are distributed among peers, lower ones first.
+	checkPingRespond(t, 0, 5000, req(1, payload.MaxHashesCount-1), req(500, payload.MaxHashesCount))
+	checkPingRespond(t, 0, 5000)
+	checkPingRespond(t, 1, 5000, req(1000, payload.MaxHashesCount), req(1500, payload.MaxHashesCount))
+	// Requests are limited by the block queue capacity.
+	checkPingRespond(t, 2, 5000, req(2000, 1))
+	// And by peer's height.
+	checkPingRespond(t, 3, 700)
 
 	// Receive some blocks.
-	s.chain.(*fakechain.FakeChain).Blockheight = 2123
-
-	// Minimum chunk has priority.
-	checkPingRespond(t, 5, 5000, 2124)
-	checkPingRespond(t, 6, 5000, 2624)
-	// Request minimal height for peers behind.
-	checkPingRespond(t, 7, 3100, 2124)
-	checkPingRespond(t, 8, 5000, 3124)
-	checkPingRespond(t, 9, 5000, 3624)
-	// Request random height after that.
-	checkPingRespond(t, 1, 5000, 2124, 2624, 3124, 3624)
-	checkPingRespond(t, 2, 5000, 2124, 2624, 3124, 3624)
-	checkPingRespond(t, 3, 5000, 2124, 2624, 3124, 3624)
+	s.chain.(*fakechain.FakeChain).Blockheight = 999
+	checkPingRespond(t, 3, 1200)
+	checkPingRespond(t, 0, 5000, req(2500, payload.MaxHashesCount))
+
+	// Requests of the disconnected peer are reassigned.
+	s.blockSched.removePeer(ps[1])
+	checkPingRespond(t, 3, 1200, req(1000, 201))
 }
 
 func TestSendVersion(t *testing.T) {
@@ -396,6 +390,53 @@ func TestBlock(t *testing.T) {
 	require.Eventually(t, func() bool { return s.chain.BlockHeight() == 12345 }, time.Second, time.Millisecond*500)
 }
 
+// addBlockHookChain is a fake chain calling addBlockF for every block being
+// added.
+type addBlockHookChain struct {
+	*fakechain.FakeChain
+	addBlockF func(*block.Block)
+}
+
+// AddBlock implements Blockchainer interface.
+func (c *addBlockHookChain) AddBlock(b *block.Block) error {
+	c.addBlockF(b)
+	return c.FakeChain.AddBlock(b)
+}
+
+func TestHaltBlockProcessing(t *testing.T) {
+	var added atomic.Uint32
+	chain := &addBlockHookChain{
+		FakeChain: fakechain.NewFakeChain(),
+		addBlockF: func(b *block.Block) { added.Store(b.Index) },
+	}
+	s, err := newServerFromConstructors(ServerConfig{Port: 0, UserAgent: "/test/"}, chain, zaptest.NewLogger(t),
+		newFakeTransp, newFakeConsensus, newTestDiscovery)
+	require.NoError(t, err)
+	t.Cleanup(s.discovery.Close)
+	ch := startWithChannel(s)
+	t.Cleanup(func() {
+		s.Shutdown()
+		<-ch
+	})
+	require.False(t, s.IsBlockProcessingHalted())
+
+	b := block.New(false)
+	b.Index = 1
+	require.NoError(t, s.bQueue.putBlock(b))
+	require.Eventually(t, func() bool { return added.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	s.haltBlockProcessing()
+	require.True(t, s.IsBlockProcessingHalted())
+	require.True(t, s.consensus.(*fakeConsensus).stopped.Load())
+
+	b = block.New(false)
+	b.Index = 2
+	require.NoError(t, s.bQueue.putBlock(b))
+	require.Equal(t, 1, s.bQueue.length())
+	require.Never(t, func() bool { return added.Load() != 1 }, 200*time.Millisecond, 10*time.Millisecond)
+	require.Equal(t, uint32(1), s.chain.BlockHeight())
+}
+
 func TestConsensus(t *testing.T) {
 	s := startTestServer(t)
 
@@ -150,6 +150,12 @@ func (p *TCPPeer) writeMsg(msg *Message) error {
 	return err
 }
 
+// serveConn handles the given established connection with a peer until it's
+// closed, it's intended to be used by Transporter implementations.
+func (s *Server) serveConn(conn net.Conn) {
+	NewTCPPeer(conn, s).handleConn()
+}
+
 // handleConn handles the read side of the connection, it should be started as
 // a goroutine right after the new peer setup.
 func (p *TCPPeer) handleConn() {
@@ -304,7 +310,7 @@ func (p *TCPPeer) IsFullNode() bool {
 
 // SendVersion checks for the handshake state and sends a message to the peer.
 func (p *TCPPeer) SendVersion() error {
-	msg, err := p.server.getVersionMsg()
+	msg, err := p.server.getVersionMsg(p)
 	if err != nil {
 		return err
 	}
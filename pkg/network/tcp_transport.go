@@ -1,6 +1,7 @@
 package network
 
 import (
+	"crypto/tls"
 	"net"
 	"regexp"
 	"sync"
@@ -9,14 +10,16 @@ import (
 	"go.uber.org/zap"
 )
 
-// TCPTransport allows network communication over TCP.
+// TCPTransport allows network communication over TCP (optionally encrypted
+// with TLS).
 type TCPTransport struct {
-	log      *zap.Logger
-	server   *Server
-	listener net.Listener
-	bindAddr string
-	lock     sync.RWMutex
-	quit     bool
+	log       *zap.Logger
+	server    *Server
+	listener  net.Listener
+	bindAddr  string
+	tlsConfig *tls.Config
+	lock      sync.RWMutex
+	quit      bool
 }
 
 var reClosedNetwork = regexp.MustCompile(".* use of closed network connection")
@@ -31,14 +34,29 @@ func NewTCPTransport(s *Server, bindAddr string, log *zap.Logger) *TCPTransport
 	}
 }
 
+// NewTLSTransport returns a new TCPTransport that uses TLS with the given
+// configuration for all incoming and outgoing connections.
+func NewTLSTransport(s *Server, bindAddr string, cfg *tls.Config, log *zap.Logger) *TCPTransport {
+	t := NewTCPTransport(s, bindAddr, log)
+	t.tlsConfig = cfg
+	return t
+}
+
 // Dial implements the Transporter interface.
 func (t *TCPTransport) Dial(addr string, timeout time.Duration) error {
-	conn, err := net.DialTimeout("tcp", addr, timeout)
+	var (
+		conn net.Conn
+		err  error
+	)
+	if t.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: timeout}, "tcp", addr, t.tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, timeout)
+	}
 	if err != nil {
 		return err
 	}
-	p := NewTCPPeer(conn, t.server)
-	go p.handleConn()
+	go t.server.serveConn(conn)
 	return nil
 }
 
@@ -49,6 +67,9 @@ func (t *TCPTransport) Accept() {
 		t.log.Panic("TCP listen error", zap.Error(err))
 		return
 	}
+	if t.tlsConfig != nil {
+		l = tls.NewListener(l, t.tlsConfig)
+	}
 
 	t.lock.Lock()
 	if t.quit {
@@ -71,8 +92,12 @@ func (t *TCPTransport) Accept() {
 			t.log.Warn("TCP accept error", zap.Error(err))
 			continue
 		}
-		p := NewTCPPeer(conn, t.server)
-		go p.handleConn()
+		if !t.server.isInboundAllowed(conn.RemoteAddr()) {
+			t.log.Debug("inbound connection rejected", zap.Stringer("addr", conn.RemoteAddr()))
+			conn.Close()
+			continue
+		}
+		go t.server.serveConn(conn)
 	}
 }
 
@@ -98,6 +123,9 @@ func (t *TCPTransport) Close() {
 
 // Proto implements the Transporter interface.
 func (t *TCPTransport) Proto() string {
+	if t.tlsConfig != nil {
+		return "tls"
+	}
 	return "tcp"
 }
 
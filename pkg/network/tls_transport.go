@@ -0,0 +1,262 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/network/capability"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+)
+
+var errTLSRequired = errors.New("plain connection with peer supporting encryption")
+
+// dualTransport accepts both plain and encrypted connections and dials peers
+// using encrypted connections when their TLS endpoints are known. These are
+// only learnt out of band: from the configuration or from TLSServer
+// capability received via already encrypted connection, plain connections
+// can't be upgraded since that's trivial to tamper with.
+type dualTransport struct {
+	plain     *TCPTransport
+	encrypted *TCPTransport
+
+	// peers are TLS endpoints of the configured peers.
+	peers map[string]bool
+	// hosts are IP addresses of the configured peers.
+	hosts map[string]bool
+
+	lock sync.RWMutex
+	// endpoints maps peer addresses (as announced via TCPServer
+	// capability) to their TLS addresses.
+	endpoints map[string]string
+}
+
+func newDualTransport(plain, encrypted *TCPTransport, peers []tlsPeer) *dualTransport {
+	t := &dualTransport{
+		plain:     plain,
+		encrypted: encrypted,
+		peers:     make(map[string]bool, len(peers)),
+		hosts:     make(map[string]bool, len(peers)),
+		endpoints: make(map[string]string),
+	}
+	for _, p := range peers {
+		t.peers[p.addr] = true
+		for _, ip := range p.ips {
+			t.hosts[ip.String()] = true
+		}
+	}
+	return t
+}
+
+// tlsPeer is the configured peer supporting encrypted connections.
+type tlsPeer struct {
+	addr string
+	ips  []net.IP
+}
+
+// resolveTLSPeers resolves host names of the given TLS endpoints, so that
+// plain connections from these peers can be recognized.
+func resolveTLSPeers(addrs []string) ([]tlsPeer, error) {
+	res := make([]tlsPeer, 0, len(addrs))
+	for _, addr := range addrs {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, fmt.Errorf("bad TLS peer address %s: %w", addr, err)
+		}
+		ips, err := net.LookupIP(host)
+		if err != nil {
+			return nil, fmt.Errorf("can't resolve TLS peer %s: %w", addr, err)
+		}
+		res = append(res, tlsPeer{addr: addr, ips: ips})
+	}
+	return res, nil
+}
+
+// Dial implements the Transporter interface.
+func (t *dualTransport) Dial(addr string, timeout time.Duration) error {
+	if t.peers[addr] {
+		return t.encrypted.Dial(addr, timeout)
+	}
+	t.lock.RLock()
+	tlsAddr, ok := t.endpoints[addr]
+	t.lock.RUnlock()
+	if ok {
+		return t.encrypted.Dial(tlsAddr, timeout)
+	}
+	return t.plain.Dial(addr, timeout)
+}
+
+// Accept implements the Transporter interface.
+func (t *dualTransport) Accept() {
+	go t.encrypted.Accept()
+	t.plain.Accept()
+}
+
+// Proto implements the Transporter interface.
+func (t *dualTransport) Proto() string {
+	return t.plain.Proto()
+}
+
+// Address implements the Transporter interface.
+func (t *dualTransport) Address() string {
+	return t.plain.Address()
+}
+
+// Close implements the Transporter interface.
+func (t *dualTransport) Close() {
+	t.encrypted.Close()
+	t.plain.Close()
+}
+
+// setTLSEndpoint makes all subsequent connections to addr use TLS endpoint.
+func (t *dualTransport) setTLSEndpoint(addr, tlsAddr string) {
+	t.lock.Lock()
+	t.endpoints[addr] = tlsAddr
+	t.lock.Unlock()
+}
+
+// supportsTLS checks whether the peer with the given address (as announced
+// via TCPServer capability) and IP is known to support encrypted connections.
+func (t *dualTransport) supportsTLS(addr string, ip net.IP) bool {
+	if ip != nil && t.hosts[ip.String()] {
+		return true
+	}
+	t.lock.RLock()
+	_, ok := t.endpoints[addr]
+	t.lock.RUnlock()
+	return ok
+}
+
+// peerAddrs returns TLS endpoints of the configured peers.
+func (t *dualTransport) peerAddrs() []string {
+	res := make([]string, 0, len(t.peers))
+	for addr := range t.peers {
+		res = append(res, addr)
+	}
+	return res
+}
+
+// newTLSConfig creates TLS configuration for mutually authenticated P2P
+// connections. Both sides must present certificates signed by one of the
+// trusted authorities, host names are not checked since peers are usually
+// addressed by IP.
+func newTLSConfig(cfg config.P2PTLS) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't load TLS certificate: %w", err)
+	}
+	ca, err := ioutil.ReadFile(cfg.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("can't read TLS CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, errors.New("no certificates found in TLS CA file")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+		MinVersion:   tls.VersionTLS12,
+		// Server certificate is checked by VerifyPeerCertificate
+		// without host name verification.
+		InsecureSkipVerify: true, //nolint:gosec
+		VerifyPeerCertificate: func(raw [][]byte, _ [][]*x509.Certificate) error {
+			return verifyPeerCertificate(raw, pool)
+		},
+	}, nil
+}
+
+// verifyPeerCertificate checks that the given certificate chain is signed by
+// one of the trusted authorities.
+func verifyPeerCertificate(raw [][]byte, roots *x509.CertPool) error {
+	if len(raw) == 0 {
+		return errors.New("no peer certificate")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	var leaf *x509.Certificate
+	for i := range raw {
+		cert, err := x509.ParseCertificate(raw[i])
+		if err != nil {
+			return fmt.Errorf("bad peer certificate: %w", err)
+		}
+		if i == 0 {
+			leaf = cert
+		} else {
+			opts.Intermediates.AddCert(cert)
+		}
+	}
+	_, err := leaf.Verify(opts)
+	return err
+}
+
+// isEncrypted checks whether the connection with the given peer is encrypted.
+func isEncrypted(p Peer) bool {
+	ep, ok := p.(interface{ isEncrypted() bool })
+	return ok && ep.isEncrypted()
+}
+
+// isEncrypted checks whether the connection with the peer is encrypted.
+func (p *TCPPeer) isEncrypted() bool {
+	_, ok := p.conn.(*tls.Conn)
+	return ok
+}
+
+// tlsPort returns the port of TLS listener if encrypted transport is enabled
+// and the connection with the given peer is encrypted. TLSServer capability is
+// NeoGo-specific and other nodes can't even decode version message with it,
+// so it's only sent to peers known to support it.
+func (s *Server) tlsPort(p Peer) (uint16, bool) {
+	dt, ok := s.transport.(*dualTransport)
+	if !ok || !isEncrypted(p) {
+		return 0, false
+	}
+	port := s.P2PTLSCfg.Port
+	if _, portStr, err := net.SplitHostPort(dt.encrypted.Address()); err == nil {
+		if p, err := strconv.ParseUint(portStr, 10, 16); err == nil {
+			port = uint16(p)
+		}
+	}
+	return port, true
+}
+
+// checkTLS ensures that peers supporting encrypted connections only
+// communicate with us via them. TLS endpoint announced via encrypted
+// connection is remembered, so that the next connection with this peer is
+// encrypted too. Plain connections are refused for peers known to support
+// encryption (there is no silent fallback to plaintext) and for all peers if
+// encryption is required.
+func (s *Server) checkTLS(p Peer, version *payload.Version) error {
+	dt, ok := s.transport.(*dualTransport)
+	if !ok {
+		return nil
+	}
+	if isEncrypted(p) {
+		for _, c := range version.Capabilities {
+			if c.Type != capability.TLSServer {
+				continue
+			}
+			host, _, err := net.SplitHostPort(p.RemoteAddr().String())
+			if err == nil {
+				port := c.Data.(*capability.Server).Port
+				dt.setTLSEndpoint(p.PeerAddr().String(), net.JoinHostPort(host, strconv.Itoa(int(port))))
+			}
+		}
+		return nil
+	}
+	if s.P2PTLSCfg.Required || dt.supportsTLS(p.PeerAddr().String(), addrIP(p.RemoteAddr())) {
+		return errTLSRequired
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package network
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/network/capability"
+	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/stretchr/testify/require"
+)
+
+// genCert creates a certificate signed by parent (self-signed if it's nil).
+func genCert(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "neo-go test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// writeTLSFiles generates CA and node certificates and returns configuration
+// referencing them.
+func writeTLSFiles(t *testing.T, dir string, ca *x509.Certificate, caKey *ecdsa.PrivateKey) config.P2PTLS {
+	cert, key := genCert(t, ca, caKey, false)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cfg := config.P2PTLS{
+		Enabled:  true,
+		CertFile: filepath.Join(dir, "node.crt"),
+		KeyFile:  filepath.Join(dir, "node.key"),
+		CAFile:   filepath.Join(dir, "ca.crt"),
+	}
+	write := func(file, typ string, data []byte) {
+		require.NoError(t, ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: data}), 0600))
+	}
+	write(cfg.CertFile, "CERTIFICATE", cert.Raw)
+	write(cfg.KeyFile, "EC PRIVATE KEY", keyDER)
+	write(cfg.CAFile, "CERTIFICATE", ca.Raw)
+	return cfg
+}
+
+func tlsHandshake(t *testing.T, client, server *tls.Config) error {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	errCh := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err != nil {
+			errCh <- err
+			return
+		}
+		defer c.Close()
+		errCh <- tls.Server(c, server).Handshake()
+	}()
+	c, err := net.Dial("tcp", l.Addr().String())
+	require.NoError(t, err)
+	defer c.Close()
+	err = tls.Client(c, client).Handshake()
+	srvErr := <-errCh
+	if err == nil {
+		err = srvErr
+	}
+	return err
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p2ptls")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	ca, caKey := genCert(t, nil, nil, true)
+
+	dir1, dir2, dir3 := filepath.Join(dir, "1"), filepath.Join(dir, "2"), filepath.Join(dir, "3")
+	for _, d := range []string{dir1, dir2, dir3} {
+		require.NoError(t, os.Mkdir(d, os.ModePerm))
+	}
+	cfg1 := writeTLSFiles(t, dir1, ca, caKey)
+	cfg2 := writeTLSFiles(t, dir2, ca, caKey)
+	otherCA, otherKey := genCert(t, nil, nil, true)
+	cfg3 := writeTLSFiles(t, dir3, otherCA, otherKey)
+
+	t.Run("bad files", func(t *testing.T) {
+		cfg := cfg1
+		cfg.KeyFile = filepath.Join(dir, "unknown")
+		_, err := newTLSConfig(cfg)
+		require.Error(t, err)
+
+		cfg = cfg1
+		cfg.CAFile = filepath.Join(dir, "unknown")
+		_, err = newTLSConfig(cfg)
+		require.Error(t, err)
+
+		cfg.CAFile = cfg1.KeyFile
+		_, err = newTLSConfig(cfg)
+		require.Error(t, err)
+	})
+
+	tls1, err := newTLSConfig(cfg1)
+	require.NoError(t, err)
+	tls2, err := newTLSConfig(cfg2)
+	require.NoError(t, err)
+	tls3, err := newTLSConfig(cfg3)
+	require.NoError(t, err)
+
+	require.NoError(t, tlsHandshake(t, tls1, tls2))
+	require.NoError(t, tlsHandshake(t, tls2, tls1))
+	// Certificates signed by unknown authority are rejected by both sides.
+	require.Error(t, tlsHandshake(t, tls1, tls3))
+	require.Error(t, tlsHandshake(t, tls3, tls1))
+}
+
+func TestResolveTLSPeers(t *testing.T) {
+	peers, err := resolveTLSPeers([]string{"127.0.0.1:20334", "localhost:20335"})
+	require.NoError(t, err)
+	require.Equal(t, 2, len(peers))
+	require.Equal(t, "127.0.0.1:20334", peers[0].addr)
+	require.True(t, peers[0].ips[0].Equal(net.IPv4(127, 0, 0, 1)))
+	require.NotEqual(t, 0, len(peers[1].ips))
+
+	_, err = resolveTLSPeers([]string{"127.0.0.1"})
+	require.Error(t, err)
+}
+
+func TestCheckTLS(t *testing.T) {
+	s := newTestServer(t, ServerConfig{Port: 0, UserAgent: "/test/", P2PTLSCfg: config.P2PTLS{Enabled: true, Port: 20334}})
+	p := newLocalPeer(t, s)
+	tlsVersion := payload.NewVersion(s.Net, 42, "/test/", []capability.Capability{{
+		Type: capability.TLSServer,
+		Data: &capability.Server{Port: 30334},
+	}})
+	plainVersion := payload.NewVersion(s.Net, 42, "/test/", nil)
+	hasTLSCapability := func(t *testing.T, p Peer) bool {
+		msg, err := s.getVersionMsg(p)
+		require.NoError(t, err)
+		for _, c := range msg.Payload.(*payload.Version).Capabilities {
+			if c.Type == capability.TLSServer {
+				require.Equal(t, uint16(20334), c.Data.(*capability.Server).Port)
+				return true
+			}
+		}
+		return false
+	}
+
+	// Nothing is checked if encrypted transport is disabled.
+	require.NoError(t, s.checkTLS(p, tlsVersion))
+	p.encrypted = true
+	require.False(t, hasTLSCapability(t, p))
+	p.encrypted = false
+
+	peers, err := resolveTLSPeers([]string{"127.0.0.2:20334"})
+	require.NoError(t, err)
+	dt := newDualTransport(NewTCPTransport(s, "127.0.0.1:0", s.log),
+		NewTLSTransport(s, "127.0.0.1:0", &tls.Config{}, s.log), peers)
+	s.transport = dt
+	require.Equal(t, []string{"127.0.0.2:20334"}, dt.peerAddrs())
+
+	t.Run("plain", func(t *testing.T) {
+		// Capability is never sent via plain connections and
+		// plain connections can't announce TLS endpoints.
+		require.False(t, hasTLSCapability(t, p))
+		require.NoError(t, s.checkTLS(p, plainVersion))
+		require.NoError(t, s.checkTLS(p, tlsVersion))
+		require.Equal(t, 0, len(dt.endpoints))
+	})
+	t.Run("configured peer", func(t *testing.T) {
+		p := newLocalPeer(t, s)
+		p.netaddr.IP = net.IPv4(127, 0, 0, 2)
+		require.Equal(t, errTLSRequired, s.checkTLS(p, plainVersion))
+	})
+	t.Run("encrypted", func(t *testing.T) {
+		p := newLocalPeer(t, s)
+		p.encrypted = true
+		require.True(t, hasTLSCapability(t, p))
+		require.NoError(t, s.checkTLS(p, tlsVersion))
+		require.Equal(t, "0.0.0.0:30334", dt.endpoints[p.PeerAddr().String()])
+
+		// No plaintext with the peer that is known to support TLS.
+		p.encrypted = false
+		require.Equal(t, errTLSRequired, s.checkTLS(p, plainVersion))
+		delete(dt.endpoints, p.PeerAddr().String())
+	})
+	t.Run("required", func(t *testing.T) {
+		s.P2PTLSCfg.Required = true
+		defer func() { s.P2PTLSCfg.Required = false }()
+		require.Equal(t, errTLSRequired, s.checkTLS(p, plainVersion))
+		p.encrypted = true
+		require.NoError(t, s.checkTLS(p, plainVersion))
+		p.encrypted = false
+	})
+}
@@ -50,6 +50,10 @@ type Options struct {
 	CACert         string
 	DialTimeout    time.Duration
 	RequestTimeout time.Duration
+	// FeeTarget is the number of blocks transactions are expected to be
+	// included within, when it's set network fee added by AddNetworkFee is
+	// adjusted using estimatefee RPC call.
+	FeeTarget int
 }
 
 // cache stores cache values for the RPC client methods.
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/nspcc-dev/neo-go/pkg/wallet"
+)
+
+// ErrNotInMemPool is returned when the transaction to be replaced is not in
+// the memory pool of the node (it's either already accepted or dropped).
+var ErrNotInMemPool = errors.New("transaction is not in the memory pool")
+
+// getPooledTx returns the transaction with the given hash if it's still in
+// the memory pool.
+func (c *Client) getPooledTx(hash util.Uint256) (*transaction.Transaction, error) {
+	pooled, err := c.GetRawMemPool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get memory pool: %w", err)
+	}
+	var found bool
+	for _, h := range pooled {
+		if h.Equals(hash) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s: %w", hash.StringLE(), ErrNotInMemPool)
+	}
+	tx, err := c.GetRawTransaction(hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction %s: %w", hash.StringLE(), err)
+	}
+	return tx, nil
+}
+
+// addConflictingFee adds network fee to the replacement transaction, it's
+// at least the network fee of the replaced one plus extraFee (or plus one
+// GAS fraction if extraFee is not positive), so that the node prefers it.
+func (c *Client) addConflictingFee(tx, old *transaction.Transaction, extraFee int64, accs ...*wallet.Account) error {
+	if extraFee <= 0 {
+		extraFee = 1
+	}
+	if err := c.AddNetworkFee(tx, extraFee, accs...); err != nil {
+		return fmt.Errorf("failed to add network fee: %w", err)
+	}
+	if min := old.NetworkFee + extraFee; tx.NetworkFee < min {
+		tx.NetworkFee = min
+	}
+	return nil
+}
+
+// CreateSpeedUpTx creates a copy of the pooled transaction with the given
+// hash that conflicts with it and has higher network fee (by at least
+// extraFee). It has the same script, system fee, signers and attributes
+// (plus Conflicts attribute). acc should be an account of the sender (the
+// first signer) of the original transaction, cosigners should contain
+// accounts for all other signers. The transaction returned is not signed.
+// Conflicts attribute requires P2PSigExtensions to be enabled on the network.
+func (c *Client) CreateSpeedUpTx(hash util.Uint256, acc *wallet.Account, extraFee int64,
+	cosigners []SignerAccount) (*transaction.Transaction, error) {
+	old, err := c.getPooledTx(hash)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := getReplacementAccounts(old, acc, cosigners)
+	if err != nil {
+		return nil, err
+	}
+	tx := transaction.New(old.Script, old.SystemFee)
+	tx.Signers = old.Signers
+	tx.ValidUntilBlock = old.ValidUntilBlock
+	tx.Attributes = append(tx.Attributes, old.Attributes...)
+	tx.Attributes = append(tx.Attributes, transaction.Attribute{
+		Type:  transaction.ConflictsT,
+		Value: &transaction.Conflicts{Hash: hash},
+	})
+	if err := c.addConflictingFee(tx, old, extraFee, accounts...); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// CreateCancelTx creates a transaction that cancels the pooled transaction
+// with the given hash. It does nothing, conflicts with the original one, is
+// signed by its sender only (with None scope) and has higher network fee (by
+// at least extraFee). acc should be an account of the sender (the first
+// signer) of the original transaction. The transaction returned is not
+// signed. Conflicts attribute requires P2PSigExtensions to be enabled on the
+// network.
+func (c *Client) CreateCancelTx(hash util.Uint256, acc *wallet.Account, extraFee int64) (*transaction.Transaction, error) {
+	old, err := c.getPooledTx(hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSender(old, acc); err != nil {
+		return nil, err
+	}
+	tx := transaction.New([]byte{byte(opcode.RET)}, 0)
+	tx.Signers = []transaction.Signer{{
+		Account: old.Signers[0].Account,
+		Scopes:  transaction.None,
+	}}
+	tx.ValidUntilBlock = old.ValidUntilBlock
+	tx.Attributes = []transaction.Attribute{{
+		Type:  transaction.ConflictsT,
+		Value: &transaction.Conflicts{Hash: hash},
+	}}
+	if err := c.addConflictingFee(tx, old, extraFee, acc); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// checkSender checks that acc is the sender of the transaction being
+// replaced, only the sender can replace it.
+func checkSender(old *transaction.Transaction, acc *wallet.Account) error {
+	from, err := address.StringToUint160(acc.Address)
+	if err != nil {
+		return fmt.Errorf("bad sender account address: %w", err)
+	}
+	if !old.Signers[0].Account.Equals(from) {
+		return fmt.Errorf("transaction %s is sent by %s, not %s", old.Hash().StringLE(),
+			address.Uint160ToString(old.Signers[0].Account), acc.Address)
+	}
+	return nil
+}
+
+// getReplacementAccounts returns accounts for the signers of the transaction
+// being replaced. acc must be the sender's one.
+func getReplacementAccounts(old *transaction.Transaction, acc *wallet.Account, cosigners []SignerAccount) ([]*wallet.Account, error) {
+	if err := checkSender(old, acc); err != nil {
+		return nil, err
+	}
+	accounts := []*wallet.Account{acc}
+	for i, s := range old.Signers[1:] {
+		var found *wallet.Account
+		for _, c := range cosigners {
+			if c.Signer.Account.Equals(s.Account) {
+				found = c.Account
+				break
+			}
+		}
+		if found == nil {
+			return nil, fmt.Errorf("no account for signer #%d (%s)", i+1, address.Uint160ToString(s.Account))
+		}
+		accounts = append(accounts, found)
+	}
+	return accounts, nil
+}
+
+// CheckReplaced checks the memory pool state after sending the replacement
+// transaction: it must be pooled (or already accepted) and the replaced
+// transaction must be gone.
+func (c *Client) CheckReplaced(old, replacement util.Uint256) error {
+	pooled, err := c.GetRawMemPool()
+	if err != nil {
+		return fmt.Errorf("failed to get memory pool: %w", err)
+	}
+	var oldFound, newFound bool
+	for _, h := range pooled {
+		oldFound = oldFound || h.Equals(old)
+		newFound = newFound || h.Equals(replacement)
+	}
+	switch {
+	case oldFound:
+		return fmt.Errorf("transaction %s is still in the memory pool", old.StringLE())
+	case !newFound:
+		if _, err := c.GetTransactionHeight(replacement); err == nil {
+			return nil
+		}
+		return fmt.Errorf("replacement transaction %s is not in the memory pool", replacement.StringLE())
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/config/netmode"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
@@ -28,6 +30,21 @@ import (
 
 var errNetworkNotInitialized = errors.New("RPC client network is not initialized")
 
+// BanPeer bans the given host (port part of the address is ignored) on the
+// node for the given duration (rounded down to seconds), default node's ban
+// duration is used if it's zero. Peer management must be enabled in the RPC
+// configuration of the node for this call to succeed.
+func (c *Client) BanPeer(address string, d time.Duration) error {
+	var (
+		params = request.NewRawParams(address)
+		resp   bool
+	)
+	if d > 0 {
+		params.Values = append(params.Values, int64(d/time.Second))
+	}
+	return c.performRequest("banpeer", params, &resp)
+}
+
 // CalculateNetworkFee calculates network fee for transaction. The transaction may
 // have empty witnesses for contract signers and may have only verification scripts
 // filled for standard sig/multisig signers.
@@ -42,6 +59,91 @@ func (c *Client) CalculateNetworkFee(tx *transaction.Transaction) (int64, error)
 	return resp.Value, nil
 }
 
+// addOptionalParams appends positional optional parameters (pointers) to
+// params. Nil values are omitted, but they can only be followed by other nil
+// values.
+func addOptionalParams(params *request.RawParams, values ...interface{}) error {
+	var omitted bool
+	for _, v := range values {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			omitted = true
+			continue
+		}
+		if omitted {
+			return errors.New("bad parameters")
+		}
+		params.Values = append(params.Values, v)
+	}
+	return nil
+}
+
+// FindNotifications is a wrapper for findnotifications RPC. It returns
+// notifications with the given name emitted by the contract (or all of its
+// notifications if name is empty) from the newest to the oldest ones. Start
+// and stop are optional block heights bounding the result, limit and page
+// allow to use paging. These parameters are positional in the JSON-RPC call,
+// you can't specify limit and not specify start/stop for example.
+func (c *Client) FindNotifications(contract util.Uint160, name string, start, stop *uint32, limit, page *int) (*result.Notifications, error) {
+	params := request.NewRawParams(contract.StringLE(), name)
+	if err := addOptionalParams(&params, start, stop, limit, page); err != nil {
+		return nil, err
+	}
+	resp := new(result.Notifications)
+	if err := c.performRequest("findnotifications", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// FindStates returns historical contract storage items by the given state root,
+// historical contract hash and historical prefix. If `start` path is
+// specified, then items starting from `start` path are returned (excluding
+// item located at the start path). If `maxCount` specified, then maximum
+// number of items to be returned equals to `maxCount`. Proofs of the first
+// and the last items returned can be used to verify the result against the
+// state root.
+func (c *Client) FindStates(stateroot util.Uint256, historicalContractHash util.Uint160, historicalPrefix []byte,
+	start []byte, maxCount *int) (result.FindStates, error) {
+	if historicalPrefix == nil {
+		historicalPrefix = []byte{}
+	}
+	var (
+		params = request.NewRawParams(stateroot.StringLE(), historicalContractHash.StringLE(), historicalPrefix)
+		resp   result.FindStates
+	)
+	if start == nil && maxCount != nil {
+		start = []byte{}
+	}
+	if start != nil {
+		params.Values = append(params.Values, start)
+	}
+	if maxCount != nil {
+		params.Values = append(params.Values, *maxCount)
+	}
+	if err := c.performRequest("findstates", params, &resp); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// GetAccountTransactions is a wrapper for getaccounttransactions RPC. Address
+// parameter is mandatory, while all the others are optional. Start and stop
+// are timestamps bounding the result, limit and page allow to use paging and
+// startHeight and stopHeight are block heights bounding the result. These
+// parameters are positional in the JSON-RPC call, you can't specify limit and
+// not specify start/stop for example.
+func (c *Client) GetAccountTransactions(address string, start, stop *uint64, limit, page *int, startHeight, stopHeight *uint32) (*result.AccountTransactions, error) {
+	params := request.NewRawParams(address)
+	if err := addOptionalParams(&params, start, stop, limit, page, startHeight, stopHeight); err != nil {
+		return nil, err
+	}
+	resp := new(result.AccountTransactions)
+	if err := c.performRequest("getaccounttransactions", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetApplicationLog returns the contract log based on the specified txid.
 func (c *Client) GetApplicationLog(hash util.Uint256, trig *trigger.Type) (*result.ApplicationLog, error) {
 	var (
@@ -217,6 +319,16 @@ func (c *Client) GetConnectionCount() (int, error) {
 	return resp, nil
 }
 
+// GetConsensusState returns the state of the current consensus round. This
+// method is only available on consensus nodes.
+func (c *Client) GetConsensusState() (*result.ConsensusState, error) {
+	var resp = &result.ConsensusState{}
+	if err := c.performRequest("getconsensusstate", request.NewRawParams(), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetCommittee returns the current public keys of NEO nodes in committee.
 func (c *Client) GetCommittee() (keys.PublicKeys, error) {
 	var (
@@ -310,6 +422,84 @@ func (c *Client) GetNEP17Transfers(address string, start, stop *uint32, limit, p
 	return resp, nil
 }
 
+// GetNotaryRequests returns the state of main transactions being completed by
+// notary service of the node.
+func (c *Client) GetNotaryRequests() ([]result.NotaryRequest, error) {
+	var (
+		params = request.NewRawParams()
+		resp   []result.NotaryRequest
+	)
+	if err := c.performRequest("getnotaryrequests", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetOracleStatus returns oracle service state of the node.
+func (c *Client) GetOracleStatus() (*result.OracleStatus, error) {
+	var (
+		params = request.NewRawParams()
+		resp   = new(result.OracleStatus)
+	)
+	if err := c.performRequest("getoraclestatus", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetOracleRequests returns the state of oracle requests being processed by
+// the node.
+func (c *Client) GetOracleRequests() ([]result.OracleRequest, error) {
+	var (
+		params = request.NewRawParams()
+		resp   []result.OracleRequest
+	)
+	if err := c.performRequest("getoraclerequests", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetOracleRequest returns the state of oracle request with the given ID.
+func (c *Client) GetOracleRequest(id uint64) (*result.OracleRequest, error) {
+	var (
+		params = request.NewRawParams(id)
+		resp   []result.OracleRequest
+	)
+	if err := c.performRequest("getoraclerequests", params, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp) != 1 {
+		return nil, fmt.Errorf("unexpected number of requests: %d", len(resp))
+	}
+	return &resp[0], nil
+}
+
+// GetMempoolInfo returns memory pool statistics.
+func (c *Client) GetMempoolInfo() (*result.MempoolInfo, error) {
+	var (
+		params = request.NewRawParams()
+		resp   = new(result.MempoolInfo)
+	)
+	if err := c.performRequest("getmempoolinfo", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// EstimateFee returns network fee per byte needed for transaction to be
+// included within the given number of blocks.
+func (c *Client) EstimateFee(target int) (*result.FeeEstimate, error) {
+	var (
+		params = request.NewRawParams(target)
+		resp   = new(result.FeeEstimate)
+	)
+	if err := c.performRequest("estimatefee", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetPeers returns the list of nodes that the node is currently connected/disconnected from.
 func (c *Client) GetPeers() (*result.GetPeers, error) {
 	var (
@@ -374,6 +564,76 @@ func (c *Client) GetRawTransactionVerbose(hash util.Uint256) (*result.Transactio
 	return resp, nil
 }
 
+// GetState returns historical contract storage item state by the given state
+// root, historical contract hash and historical key.
+func (c *Client) GetState(stateroot util.Uint256, historicalContractHash util.Uint160, historicalKey []byte) ([]byte, error) {
+	var (
+		params = request.NewRawParams(stateroot.StringLE(), historicalContractHash.StringLE(), historicalKey)
+		resp   []byte
+	)
+	if err := c.performRequest("getstate", params, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStateDiff returns up to maxItems (1000 at most) storage changes made
+// between oldRoot and newRoot state roots grouped by contract ID. If there are
+// more changes, result contains a continuation token to be passed as next to
+// get the next batch of changes (nil next means from the beginning).
+func (c *Client) GetStateDiff(oldRoot, newRoot util.Uint256, maxItems int, next []byte) (*result.StateDiff, error) {
+	var (
+		params = request.NewRawParams(oldRoot.StringLE(), newRoot.StringLE(), maxItems)
+		resp   = &result.StateDiff{}
+	)
+	if next != nil {
+		params.Values = append(params.Values, base64.StdEncoding.EncodeToString(next))
+	}
+	if err := c.performRequest("getstatediff", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStateHeight returns the current block height and the height of the latest
+// validated state root.
+func (c *Client) GetStateHeight() (*result.StateHeight, error) {
+	var resp = &result.StateHeight{}
+	if err := c.performRequest("getstateheight", request.NewRawParams(), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStateRootByHeight returns state root for the specified height.
+func (c *Client) GetStateRootByHeight(height uint32) (*state.MPTRoot, error) {
+	return c.getStateRoot(request.NewRawParams(height))
+}
+
+// GetStateRootByBlockHash returns state root for the block with the specified hash.
+func (c *Client) GetStateRootByBlockHash(hash util.Uint256) (*state.MPTRoot, error) {
+	return c.getStateRoot(request.NewRawParams(hash.StringLE()))
+}
+
+func (c *Client) getStateRoot(params request.RawParams) (*state.MPTRoot, error) {
+	var resp = &state.MPTRoot{}
+	if err := c.performRequest("getstateroot", params, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStateRootVerification returns the state of local state roots verification
+// against trusted nodes. This method is only available if state root
+// verification is enabled on the node.
+func (c *Client) GetStateRootVerification() (*result.StateRootVerification, error) {
+	var resp = &result.StateRootVerification{}
+	if err := c.performRequest("getstaterootverification", request.NewRawParams(), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // GetStorageByID returns the stored value, according to the contract ID and the stored key.
 func (c *Client) GetStorageByID(id int32, key []byte) ([]byte, error) {
 	return c.getStorage(request.NewRawParams(id, base64.StdEncoding.EncodeToString(key)))
@@ -529,6 +789,30 @@ func (c *Client) SubmitRawOracleResponse(ps request.RawParams) error {
 	return c.performRequest("submitoracleresponse", ps, new(result.RelayResult))
 }
 
+// RetryOracleRequest makes node's oracle service fetch data and sign response
+// for the request with the given ID again. Oracle control must be enabled in
+// the RPC configuration of the node and the client must be connected to its
+// admin endpoint for this call to succeed.
+func (c *Client) RetryOracleRequest(id uint64) error {
+	var (
+		params = request.NewRawParams(id)
+		resp   bool
+	)
+	return c.performRequest("retryoraclerequest", params, &resp)
+}
+
+// SkipOracleRequest makes node's oracle service stop processing the request
+// with the given ID and send failed response for it. Oracle control must be
+// enabled in the RPC configuration of the node and the client must be
+// connected to its admin endpoint for this call to succeed.
+func (c *Client) SkipOracleRequest(id uint64) error {
+	var (
+		params = request.NewRawParams(id)
+		resp   bool
+	)
+	return c.performRequest("skiporaclerequest", params, &resp)
+}
+
 // SignAndPushInvocationTx signs and pushes given script as an invocation
 // transaction using given wif to sign it and given cosigners to cosign it if
 // possible. It spends the amount of gas specified. It returns a hash of the
@@ -732,7 +1016,7 @@ func (c *Client) CalculateNotaryFee(nKeys uint8) (int64, error) {
 	if err != nil {
 		return 0, fmt.Errorf("failed to get FeePerByte: %w", err)
 	}
-	return int64((nKeys+1))*transaction.NotaryServiceFeePerKey + // fee for NotaryAssisted attribute
+	return (int64(nKeys)+1)*transaction.NotaryServiceFeePerKey + // fee for NotaryAssisted attribute
 			fee.Opcode(baseExecFee, // Notary node witness
 				opcode.PUSHDATA1, opcode.RET, // invocation script
 				opcode.PUSH0, opcode.SYSCALL, opcode.RET) + // System.Contract.CallNative
@@ -756,6 +1040,20 @@ func (c *Client) SubmitP2PNotaryRequest(req *payload.P2PNotaryRequest) (util.Uin
 	return resp.Hash, nil
 }
 
+// UnbanPeer removes the ban for the given host, it returns false if the host
+// wasn't banned. Peer management must be enabled in the RPC configuration of
+// the node for this call to succeed.
+func (c *Client) UnbanPeer(address string) (bool, error) {
+	var (
+		params = request.NewRawParams(address)
+		resp   bool
+	)
+	if err := c.performRequest("unbanpeer", params, &resp); err != nil {
+		return false, err
+	}
+	return resp, nil
+}
+
 // ValidateAddress verifies that the address is a correct NEO address.
 func (c *Client) ValidateAddress(address string) error {
 	var (
@@ -803,7 +1101,9 @@ func (c *Client) CalculateValidUntilBlock() (uint32, error) {
 }
 
 // AddNetworkFee adds network fee for each witness script and optional extra
-// network fee to transaction. `accs` is an array signer's accounts.
+// network fee to transaction. `accs` is an array signer's accounts. If
+// FeeTarget option is set, network fee is increased (if needed) to the value
+// estimated for inclusion within FeeTarget blocks before adding extra fee.
 func (c *Client) AddNetworkFee(tx *transaction.Transaction, extraFee int64, accs ...*wallet.Account) error {
 	if len(tx.Signers) != len(accs) {
 		return errors.New("number of signers must match number of scripts")
@@ -849,7 +1149,17 @@ func (c *Client) AddNetworkFee(tx *transaction.Transaction, extraFee int64, accs
 	if err != nil {
 		return err
 	}
-	tx.NetworkFee += int64(size)*fee + extraFee
+	tx.NetworkFee += int64(size) * fee
+	if c.opts.FeeTarget > 0 {
+		est, err := c.EstimateFee(c.opts.FeeTarget)
+		if err != nil {
+			return fmt.Errorf("can't estimate fee: %w", err)
+		}
+		if priorityFee := est.FeePerByte * int64(size); tx.NetworkFee < priorityFee {
+			tx.NetworkFee = priorityFee
+		}
+	}
+	tx.NetworkFee += extraFee
 	return nil
 }
 
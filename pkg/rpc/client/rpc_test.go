@@ -58,6 +58,8 @@ const base64Header1 = "AAAAAMSdeyVO3QCekJTbQ4D7YEEGFv2nXPd6CfO5Kn3htI8P9tVA4+VXL
 
 const header1Verbose = `{"hash":"0x88c1cbf68695f73fb7b7d185c0037ffebdf032327488ebe65e0533d269e7de9b","size":457,"version":0,"previousblockhash":"0x0f8fb4e17d2ab9f3097af75ca7fd16064160fb8043db94909e00dd4e257b9dc4","merkleroot":"0x2855f471048a5f0c9c60c10592f8997007aa3e52815d1b8c2c2f57e5e340d5f6","time":1626251469001,"index":1,"nextconsensus":"NVTiAjNgagDkTr5HTzDmQP9kPwPHN5BgVq","witnesses":[{"invocation":"DEBg0hpK90iZlB4ZSCG7BOr7BsvPXGDax360lvqKeNFuzaGI1RYNH50/dhQLxocy90JdsIOyodd1sOJGEjZIt7ztDEAHc2avJzz6tK+FOQMIZO/FEEikJdLJX0+iZXFcsmDRpB7lo2wWMSQbcoTXNg7leuR0VeDsKJ+YdvCuTG5WbiqWDECa6Yjj+bK4te5KR5jdLF5kLt03csyozZcd/X7NPt89IsX01zpX8ec3e+B2qySJIOhEf3cK0i+5U5wyXiFcRI8x","verification":"EwwhAhA6f33QFlWFl/eWDSfFFqQ5T9loueZRVetLAT5AQEBuDCECp7xV/oaE4BGXaNEEujB5W9zIZhnoZK3SYVZyPtGFzWIMIQKzYiv0AXvf4xfFiu1fTHU/IGt9uJYEb6fXdLvEv3+NwgwhA9kMB99j5pDOd5EuEKtRrMlEtmhgI3tgjE+PgwnnHuaZFEGe0Nw6"}],"confirmations":15,"nextblockhash":"0x34c20650683940a7af1881c2798e83acf9bf98aa226025af6c1d32b5530cc900"}`
 
+const txMoveNeoJSON = `{"hash":"0xf01080c50f3198f5a539c4a06d024f1b8bdc2a360a215fa7e2488f79a56d501a","size":488,"version":0,"nonce":2,"sender":"NVTiAjNgagDkTr5HTzDmQP9kPwPHN5BgVq","sysfee":"11000000","netfee":"4421900","validuntilblock":1200,"attributes":[],"signers":[{"account":"0x3f223f6da778e805d1b9b479906c0e8c1ee8b968","scopes":"CalledByEntry"}],"script":"CwIY3fUFDBTunqIsJ+NL0BSPxBCOCPdOj1BIsgwUaLnoHowObJB5tLnRBeh4p20/Ij8UwB8MCHRyYW5zZmVyDBT1Y+pAvCg9TQ4FxI6jBbPyoHNA70FifVtSOQ==","witnesses":[{"invocation":"DEC8InWg8rQHWjklRojobu7kn4r0xZY2xWYs15ggVX4PQyEHpNTU6vZHT2TXRdPXAOKHhgWAttO0oTvo+9VZAjIVDEBF0qvBMlvmYJIYLqSoCjhBykcSN78UXrBjO5BKL8BpHtejWCld1VT6Z7nYrEBLgySD6HeMcp/fa6vqHzU220e/DECXtm5AA1jy9GFA7t8U6a+1uPrQFk4Ufp0UyXsun0PvN0NdhrHc37xm8k9Z0dB85V/7WLtkMaLLyjVNVIKImC76","verification":"EwwhAhA6f33QFlWFl/eWDSfFFqQ5T9loueZRVetLAT5AQEBuDCECp7xV/oaE4BGXaNEEujB5W9zIZhnoZK3SYVZyPtGFzWIMIQKzYiv0AXvf4xfFiu1fTHU/IGt9uJYEb6fXdLvEv3+NwgwhA9kMB99j5pDOd5EuEKtRrMlEtmhgI3tgjE+PgwnnHuaZFEGe0Nw6"}]}`
+
 const txMoveNeoVerbose = `{"blockhash":"0x88c1cbf68695f73fb7b7d185c0037ffebdf032327488ebe65e0533d269e7de9b","confirmations":15,"blocktime":1626251469001,"vmstate":"HALT","hash":"0xf01080c50f3198f5a539c4a06d024f1b8bdc2a360a215fa7e2488f79a56d501a","size":488,"version":0,"nonce":2,"sender":"NVTiAjNgagDkTr5HTzDmQP9kPwPHN5BgVq","sysfee":"11000000","netfee":"4421900","validuntilblock":1200,"attributes":[],"signers":[{"account":"0x3f223f6da778e805d1b9b479906c0e8c1ee8b968","scopes":"CalledByEntry"}],"script":"CwIY3fUFDBTunqIsJ+NL0BSPxBCOCPdOj1BIsgwUaLnoHowObJB5tLnRBeh4p20/Ij8UwB8MCHRyYW5zZmVyDBT1Y+pAvCg9TQ4FxI6jBbPyoHNA70FifVtSOQ==","witnesses":[{"invocation":"DEC8InWg8rQHWjklRojobu7kn4r0xZY2xWYs15ggVX4PQyEHpNTU6vZHT2TXRdPXAOKHhgWAttO0oTvo+9VZAjIVDEBF0qvBMlvmYJIYLqSoCjhBykcSN78UXrBjO5BKL8BpHtejWCld1VT6Z7nYrEBLgySD6HeMcp/fa6vqHzU220e/DECXtm5AA1jy9GFA7t8U6a+1uPrQFk4Ufp0UyXsun0PvN0NdhrHc37xm8k9Z0dB85V/7WLtkMaLLyjVNVIKImC76","verification":"EwwhAhA6f33QFlWFl/eWDSfFFqQ5T9loueZRVetLAT5AQEBuDCECp7xV/oaE4BGXaNEEujB5W9zIZhnoZK3SYVZyPtGFzWIMIQKzYiv0AXvf4xfFiu1fTHU/IGt9uJYEb6fXdLvEv3+NwgwhA9kMB99j5pDOd5EuEKtRrMlEtmhgI3tgjE+PgwnnHuaZFEGe0Nw6"}]}`
 
 // getResultBlock1 returns data for block number 1 which is used by several tests.
@@ -110,6 +112,92 @@ func getTxMoveNeo() *result.TransactionOutputRaw {
 // published in official C# JSON-RPC API v2.10.3 reference
 // (see https://docs.neo.org/docs/en-us/reference/rpc/latest-version/api.html)
 var rpcClientTestCases = map[string][]rpcClientTestCase{
+	"banpeer": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return nil, c.BanPeer("1.2.3.4", time.Hour)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":true}`,
+			result: func(c *Client) interface{} {
+				// no error expected
+				return nil
+			},
+		},
+	},
+	"findnotifications": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.FindNotifications(util.Uint160{1, 2, 3}, "Transfer", nil, nil, nil, nil)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"notifications":[{"container":"0x17145a039fca704fcdbeb46e6b210af98a1a9e5b9768e46ffc38f71c79ac2521","blockindex":436036,"notifyindex":1,"notification":{"contract":"0x0000000000000000000000000000000000030201","eventname":"Transfer","state":{"type":"Array","value":[{"type":"Integer","value":"1"}]}}}]}}`,
+			result: func(c *Client) interface{} {
+				txHash, err := util.Uint256DecodeStringLE("17145a039fca704fcdbeb46e6b210af98a1a9e5b9768e46ffc38f71c79ac2521")
+				if err != nil {
+					panic(err)
+				}
+				return &result.Notifications{
+					Notifications: []result.Notification{
+						{
+							Container:   txHash,
+							BlockIndex:  436036,
+							NotifyIndex: 1,
+							Event: state.NotificationEvent{
+								ScriptHash: util.Uint160{1, 2, 3},
+								Name:       "Transfer",
+								Item:       stackitem.NewArray([]stackitem.Item{stackitem.NewBigInteger(big.NewInt(1))}),
+							},
+						},
+					},
+				}
+			},
+		},
+	},
+	"findstates": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				root, _ := util.Uint256DecodeStringLE("252e9d73d49c95c7618d40650da504e05183a1b2eed0685e42c360413c329170")
+				cHash, _ := util.Uint160DecodeStringLE("0e1b6ba3a5b5cd9ce3ea3b9ee3f1a5ddb3ad5fb9")
+				count := 2
+				return c.FindStates(root, cHash, []byte("test"), []byte("testkey"), &count)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"results":[{"key":"dGVzdGtleTI=","value":"dGVzdHZhbHVl"}],"firstProof":"AgECAQED","truncated":true}}`,
+			result: func(c *Client) interface{} {
+				return result.FindStates{
+					Results:    []result.KeyValue{{Key: []byte("testkey2"), Value: []byte("testvalue")}},
+					FirstProof: &result.ProofWithKey{Key: []byte{1, 2}, Proof: [][]byte{{3}}},
+					Truncated:  true,
+				}
+			},
+		},
+	},
+	"getaccounttransactions": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetAccountTransactions("NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc", nil, nil, nil, nil, nil, nil)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"transactions":[{"timestamp":1555651816,"blockindex":436036,"txhash":"df7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58"}],"address":"NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc"}}`,
+			result: func(c *Client) interface{} {
+				txHash, err := util.Uint256DecodeStringLE("df7683ece554ecfb85cf41492c5f143215dd43ef9ec61181a28f922da06aba58")
+				if err != nil {
+					panic(err)
+				}
+				return &result.AccountTransactions{
+					Transactions: []result.AccountTransaction{
+						{
+							Timestamp: 1555651816,
+							Index:     436036,
+							TxHash:    txHash,
+						},
+					},
+					Address: "NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc",
+				}
+			},
+		},
+	},
 	"getapplicationlog": {
 		{
 			name: "positive",
@@ -331,6 +419,31 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"getconsensusstate": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetConsensusState()
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"height":10,"view":1,"primary":0,"validators":[{"publickey":"02103a7f7dd016558597f7960d27c516a4394fd968b9e65155eb4b013e4040406e","preparation":true,"commit":false,"changeview":true}]}}`,
+			result: func(c *Client) interface{} {
+				pub, err := keys.NewPublicKeyFromString("02103a7f7dd016558597f7960d27c516a4394fd968b9e65155eb4b013e4040406e")
+				if err != nil {
+					panic(fmt.Errorf("failed to decode public key: %w", err))
+				}
+				return &result.ConsensusState{
+					Height:  10,
+					View:    1,
+					Primary: 0,
+					Validators: []result.ConsensusValidatorState{{
+						PublicKey:   *pub,
+						Preparation: true,
+						ChangeView:  true,
+					}},
+				}
+			},
+		},
+	},
 	"getcontractstate": {
 		{
 			name: "positive, by hash",
@@ -585,13 +698,137 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"getnotaryrequests": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				reqs, err := c.GetNotaryRequests()
+				if err != nil {
+					return nil, err
+				}
+				reqs[0].Main.FeePerByte() // set fee per byte
+				return reqs, nil
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":[{"main":` + txMoveNeoJSON + `,"type":"multisignature","sent":false,"nsigs":3,"nsigscollected":1,"witnesses":[{"account":"0x3f223f6da778e805d1b9b479906c0e8c1ee8b968","contract":false,"keys":["02103a7f7dd016558597f7960d27c516a4394fd968b9e65155eb4b013e4040406e","02a7bc55fe8684e0119768d104ba30795bdcc86619e864add26156723ed185cd62","02b3622bf4017bdfe317c58aed5f4c753f206b7db896046fa7d774bbc4bf7f8dc2","03d90c07df63e690ce77912e10ab51acc944b66860237b608c4f8f8309e71ee699"],"m":3,"signed":["02a7bc55fe8684e0119768d104ba30795bdcc86619e864add26156723ed185cd62"]}],"fallbacks":[{"hash":"0xced32af656e144f6be5d7172ed37747831456cb3eeaac4ee964d0b479b45d3a8","nvb":1100}]}]}`,
+			result: func(c *Client) interface{} {
+				account, err := util.Uint160DecodeStringLE("3f223f6da778e805d1b9b479906c0e8c1ee8b968")
+				if err != nil {
+					panic(err)
+				}
+				fbHash, err := util.Uint256DecodeStringLE("ced32af656e144f6be5d7172ed37747831456cb3eeaac4ee964d0b479b45d3a8")
+				if err != nil {
+					panic(err)
+				}
+				var pubs keys.PublicKeys
+				for _, s := range []string{
+					"02103a7f7dd016558597f7960d27c516a4394fd968b9e65155eb4b013e4040406e",
+					"02a7bc55fe8684e0119768d104ba30795bdcc86619e864add26156723ed185cd62",
+					"02b3622bf4017bdfe317c58aed5f4c753f206b7db896046fa7d774bbc4bf7f8dc2",
+					"03d90c07df63e690ce77912e10ab51acc944b66860237b608c4f8f8309e71ee699",
+				} {
+					pub, err := keys.NewPublicKeyFromString(s)
+					if err != nil {
+						panic(err)
+					}
+					pubs = append(pubs, pub)
+				}
+				tx := getTxMoveNeo().Transaction
+				return []result.NotaryRequest{{
+					Main:           &tx,
+					Type:           "multisignature",
+					NSigs:          3,
+					NSigsCollected: 1,
+					Witnesses: []result.NotaryWitness{{
+						Account: account,
+						Keys:    pubs,
+						M:       3,
+						Signed:  pubs[1:2],
+					}},
+					Fallbacks: []result.NotaryFallback{{
+						Hash:           fbHash,
+						NotValidBefore: 1100,
+					}},
+				}}
+			},
+		},
+	},
+	"getoraclerequests": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetOracleRequests()
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":[{"id":5,"url":"https://example.com","attempts":1,"lastprocessed":1622548800000,"sent":false,"txhash":"0x17145a039fca704fcdbeb46e6b210af98a1a9e5b9768e46ffc38f71c79ac2521","signatures":1,"backupsignatures":1},{"id":7,"attempts":0,"sent":false,"signatures":1,"backupsignatures":0}]}`,
+			result: func(c *Client) interface{} {
+				txHash, err := util.Uint256DecodeStringLE("17145a039fca704fcdbeb46e6b210af98a1a9e5b9768e46ffc38f71c79ac2521")
+				if err != nil {
+					panic(err)
+				}
+				return []result.OracleRequest{
+					{
+						ID:               5,
+						URL:              "https://example.com",
+						Attempts:         1,
+						LastProcessed:    1622548800000,
+						TxHash:           &txHash,
+						Signatures:       1,
+						BackupSignatures: 1,
+					},
+					{
+						ID:         7,
+						Signatures: 1,
+					},
+				}
+			},
+		},
+		{
+			name: "single",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetOracleRequest(7)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":[{"id":7,"attempts":0,"sent":true,"signatures":2,"backupsignatures":0}]}`,
+			result: func(c *Client) interface{} {
+				return &result.OracleRequest{
+					ID:         7,
+					Sent:       true,
+					Signatures: 2,
+				}
+			},
+		},
+	},
+	"getoraclestatus": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetOracleStatus()
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"account":"NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc","nodes":4,"required":3,"pending":2,"inflight":["https://example.com"],"cached":1,"errors":[{"url":"https://example.org","code":"NotFound","error":"not found","time":1622548800000,"count":3}]}}`,
+			result: func(c *Client) interface{} {
+				return &result.OracleStatus{
+					Account:  "NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc",
+					Nodes:    4,
+					Required: 3,
+					Pending:  2,
+					InFlight: []string{"https://example.com"},
+					Cached:   1,
+					Errors: []result.OracleURLError{{
+						URL:   "https://example.org",
+						Code:  transaction.NotFound,
+						Error: "not found",
+						Time:  1622548800000,
+						Count: 3,
+					}},
+				}
+			},
+		},
+	},
 	"getpeers": {
 		{
 			name: "positive",
 			invoke: func(c *Client) (interface{}, error) {
 				return c.GetPeers()
 			},
-			serverResponse: `{"id":1,"jsonrpc":"2.0","result":{"unconnected":[{"address":"172.200.0.1","port":"20333"}],"connected":[{"address":"127.0.0.1","port":"20335"}],"bad":[{"address":"172.200.0.254","port":"20332"}]}}`,
+			serverResponse: `{"id":1,"jsonrpc":"2.0","result":{"unconnected":[{"address":"172.200.0.1","port":"20333"}],"connected":[{"address":"127.0.0.1","port":"20335","score":5}],"bad":[{"address":"172.200.0.254","port":"20332"}],"banned":[{"address":"172.200.0.253","until":1617000000000}]}}`,
 			result: func(c *Client) interface{} {
 				return &result.GetPeers{
 					Unconnected: result.Peers{
@@ -604,6 +841,7 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 						{
 							Address: "127.0.0.1",
 							Port:    "20335",
+							Score:   5,
 						},
 					},
 					Bad: result.Peers{
@@ -612,6 +850,12 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 							Port:    "20332",
 						},
 					},
+					Banned: []result.BannedPeer{
+						{
+							Address: "172.200.0.253",
+							Until:   1617000000000,
+						},
+					},
 				}
 			},
 		},
@@ -668,6 +912,140 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"getstate": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				root, _ := util.Uint256DecodeStringLE("252e9d73d49c95c7618d40650da504e05183a1b2eed0685e42c360413c329170")
+				cHash, _ := util.Uint160DecodeStringLE("0e1b6ba3a5b5cd9ce3ea3b9ee3f1a5ddb3ad5fb9")
+				return c.GetState(root, cHash, []byte("testkey"))
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":"dGVzdHZhbHVl"}`,
+			result: func(c *Client) interface{} {
+				return []byte("testvalue")
+			},
+		},
+	},
+	"getstatediff": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetStateDiff(util.Uint256{1, 2, 3}, util.Uint256{4, 5, 6}, 3, []byte{1, 2})
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"contracts":[{"id":-5,"added":[{"key":"AQ==","newvalue":"Ag=="}],"deleted":[{"key":"Aw==","oldvalue":"BA=="}]},{"id":1,"modified":[{"key":"BQ==","oldvalue":"Bg==","newvalue":"Bw=="}]}],"next":"AQAAAAU="}}`,
+			result: func(c *Client) interface{} {
+				return &result.StateDiff{
+					Contracts: []result.ContractStateDiff{
+						{
+							ID:      -5,
+							Added:   []result.StateDiffItem{{Key: []byte{1}, NewValue: []byte{2}}},
+							Deleted: []result.StateDiffItem{{Key: []byte{3}, OldValue: []byte{4}}},
+						},
+						{
+							ID:       1,
+							Modified: []result.StateDiffItem{{Key: []byte{5}, OldValue: []byte{6}, NewValue: []byte{7}}},
+						},
+					},
+					Next: []byte{1, 0, 0, 0, 5},
+				}
+			},
+		},
+	},
+	"getstateheight": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetStateHeight()
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"blockHeight":208,"stateHeight":200}}`,
+			result: func(c *Client) interface{} {
+				return &result.StateHeight{
+					BlockHeight: 208,
+					StateHeight: 200,
+				}
+			},
+		},
+	},
+	"getstateroot": {
+		{
+			name: "by height, positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetStateRootByHeight(5)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"version":0,"index":5,"roothash":"0x7c5bd7e8fbd4843af3aecf43acd4b8dea4e1e3a4fdbb14849eb1ce39e8c45e5e","witnesses":[]}}`,
+			result: func(c *Client) interface{} {
+				h, err := util.Uint256DecodeStringLE("7c5bd7e8fbd4843af3aecf43acd4b8dea4e1e3a4fdbb14849eb1ce39e8c45e5e")
+				if err != nil {
+					panic(err)
+				}
+				return &state.MPTRoot{
+					Index:   5,
+					Root:    h,
+					Witness: []transaction.Witness{},
+				}
+			},
+		},
+		{
+			name: "by block hash, positive",
+			invoke: func(c *Client) (interface{}, error) {
+				hash, err := util.Uint256DecodeStringLE("86fe1061140b2ea791b0739fb9732abc6e5e47de4927228a1ac41de3d93eb7cb")
+				if err != nil {
+					panic(err)
+				}
+				return c.GetStateRootByBlockHash(hash)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"version":0,"index":5,"roothash":"0x7c5bd7e8fbd4843af3aecf43acd4b8dea4e1e3a4fdbb14849eb1ce39e8c45e5e","witnesses":[]}}`,
+			result: func(c *Client) interface{} {
+				h, err := util.Uint256DecodeStringLE("7c5bd7e8fbd4843af3aecf43acd4b8dea4e1e3a4fdbb14849eb1ce39e8c45e5e")
+				if err != nil {
+					panic(err)
+				}
+				return &state.MPTRoot{
+					Index:   5,
+					Root:    h,
+					Witness: []transaction.Witness{},
+				}
+			},
+		},
+	},
+	"getstaterootverification": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetStateRootVerification()
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":{"halted":true,"nodes":[{"address":"http://127.0.0.1:10332","verifiedheight":100},{"address":"http://127.0.0.1:20332","verifiedheight":90,"lasterror":"timeout","mismatch":{"height":95,"localroot":"0x7c5bd7e8fbd4843af3aecf43acd4b8dea4e1e3a4fdbb14849eb1ce39e8c45e5e","remoteroot":"0x86fe1061140b2ea791b0739fb9732abc6e5e47de4927228a1ac41de3d93eb7cb"}}]}}`,
+			result: func(c *Client) interface{} {
+				local, err := util.Uint256DecodeStringLE("7c5bd7e8fbd4843af3aecf43acd4b8dea4e1e3a4fdbb14849eb1ce39e8c45e5e")
+				if err != nil {
+					panic(err)
+				}
+				remote, err := util.Uint256DecodeStringLE("86fe1061140b2ea791b0739fb9732abc6e5e47de4927228a1ac41de3d93eb7cb")
+				if err != nil {
+					panic(err)
+				}
+				return &result.StateRootVerification{
+					Halted: true,
+					Nodes: []result.StateRootVerificationNode{
+						{
+							Address:        "http://127.0.0.1:10332",
+							VerifiedHeight: 100,
+						},
+						{
+							Address:        "http://127.0.0.1:20332",
+							VerifiedHeight: 90,
+							LastError:      "timeout",
+							Mismatch: &result.StateRootMismatch{
+								Height:     95,
+								LocalRoot:  local,
+								RemoteRoot: remote,
+							},
+						},
+					},
+				}
+			},
+		},
+	},
 	"getstorage": {
 		{
 			name: "by hash, positive",
@@ -935,6 +1313,19 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			fails: true,
 		},
 	},
+	"retryoraclerequest": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return nil, c.RetryOracleRequest(5)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":true}`,
+			result: func(c *Client) interface{} {
+				// no error expected
+				return nil
+			},
+		},
+	},
 	"sendrawtransaction": {
 		{
 			name: "positive",
@@ -951,6 +1342,19 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"skiporaclerequest": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return nil, c.SkipOracleRequest(5)
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":true}`,
+			result: func(c *Client) interface{} {
+				// no error expected
+				return nil
+			},
+		},
+	},
 	"submitblock": {
 		{
 			name: "positive",
@@ -971,6 +1375,18 @@ var rpcClientTestCases = map[string][]rpcClientTestCase{
 			},
 		},
 	},
+	"unbanpeer": {
+		{
+			name: "positive",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.UnbanPeer("1.2.3.4")
+			},
+			serverResponse: `{"jsonrpc":"2.0","id":1,"result":false}`,
+			result: func(c *Client) interface{} {
+				return false
+			},
+		},
+	},
 	"validateaddress": {
 		{
 			name: "positive",
@@ -1097,6 +1513,40 @@ var rpcClientErrorCases = map[string][]rpcClientErrorCase{
 		},
 	},
 	`{"id":1,"jsonrpc":"2.0","error":{"code":-32602,"message":"Invalid Params"}}`: {
+		{
+			name: "findnotifications_invalid_params_error",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.FindNotifications(util.Uint160{}, "", nil, nil, nil, nil)
+			},
+		},
+		{
+			name: "findnotifications_invalid_params_error 2",
+			invoke: func(c *Client) (interface{}, error) {
+				var limit int
+				return c.FindNotifications(util.Uint160{}, "", nil, nil, &limit, nil)
+			},
+		},
+		{
+			name: "getaccounttransactions_invalid_params_error",
+			invoke: func(c *Client) (interface{}, error) {
+				return c.GetAccountTransactions("", nil, nil, nil, nil, nil, nil)
+			},
+		},
+		{
+			name: "getaccounttransactions_invalid_params_error 2",
+			invoke: func(c *Client) (interface{}, error) {
+				var start uint64
+				var limit int
+				return c.GetAccountTransactions("NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc", &start, nil, &limit, nil, nil, nil)
+			},
+		},
+		{
+			name: "getaccounttransactions_invalid_params_error 3",
+			invoke: func(c *Client) (interface{}, error) {
+				var height uint32
+				return c.GetAccountTransactions("NbTiM6h8r99kpRtb428XcsUk1TzKed2gTc", nil, nil, nil, nil, nil, &height)
+			},
+		},
 		{
 			name: "getapplicationlog_invalid_params_error",
 			invoke: func(c *Client) (interface{}, error) {
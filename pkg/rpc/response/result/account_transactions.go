@@ -0,0 +1,18 @@
+package result
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// AccountTransactions is a result for the getaccounttransactions RPC call.
+type AccountTransactions struct {
+	Transactions []AccountTransaction `json:"transactions"`
+	Address      string               `json:"address"`
+}
+
+// AccountTransaction represents single transaction signed by the account.
+type AccountTransaction struct {
+	Timestamp uint64       `json:"timestamp"`
+	Index     uint32       `json:"blockindex"`
+	TxHash    util.Uint256 `json:"txhash"`
+}
@@ -0,0 +1,22 @@
+package result
+
+import "github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+
+type (
+	// ConsensusState is a result of getconsensusstate RPC.
+	ConsensusState struct {
+		Height     uint32                    `json:"height"`
+		View       byte                      `json:"view"`
+		Primary    uint                      `json:"primary"`
+		Validators []ConsensusValidatorState `json:"validators"`
+	}
+
+	// ConsensusValidatorState contains messages received from a single
+	// validator in the current view.
+	ConsensusValidatorState struct {
+		PublicKey   keys.PublicKey `json:"publickey"`
+		Preparation bool           `json:"preparation"`
+		Commit      bool           `json:"commit"`
+		ChangeView  bool           `json:"changeview"`
+	}
+)
@@ -0,0 +1,53 @@
+package result
+
+// MempoolInfo represents a result of getmempoolinfo RPC call.
+type MempoolInfo struct {
+	Height uint32 `json:"height"`
+	// Count is the number of verified transactions in the pool.
+	Count int `json:"count"`
+	// Capacity is the maximum number of transactions in the pool.
+	Capacity int `json:"capacity"`
+	// Size is the total size of pooled transactions in bytes.
+	Size int `json:"size"`
+	// Conflicts is the number of transactions pooled ones conflict with.
+	Conflicts int `json:"conflicts"`
+	// HighPriority is the number of pooled high priority transactions.
+	HighPriority int `json:"highpriority"`
+	// MinFeePerByte is the policy fee per byte.
+	MinFeePerByte int64 `json:"minfeeperbyte,string"`
+	// FeePerByte is the distribution of network fee per byte of pooled
+	// transactions (except high priority ones).
+	FeePerByte FeeDistribution `json:"feeperbyte"`
+	// MaxTransactionsPerBlock and MaxBlockSize are block limits pooled
+	// transactions are to fit in.
+	MaxTransactionsPerBlock int `json:"maxtransactionsperblock"`
+	MaxBlockSize            int `json:"maxblocksize"`
+	// BlocksToClear is the number of full blocks needed to include all
+	// pooled transactions.
+	BlocksToClear int `json:"blockstoclear"`
+}
+
+// FeeDistribution contains percentiles of network fee per byte.
+type FeeDistribution struct {
+	Min    int64 `json:"min,string"`
+	P25    int64 `json:"p25,string"`
+	Median int64 `json:"median,string"`
+	P75    int64 `json:"p75,string"`
+	Max    int64 `json:"max,string"`
+}
+
+// FeeEstimate represents a result of estimatefee RPC call.
+type FeeEstimate struct {
+	// Target is the number of blocks transaction is expected to be included
+	// within.
+	Target int `json:"target"`
+	// FeePerByte is the network fee per byte (network fee divided by
+	// transaction size) needed to be included within Target blocks. Zero
+	// means that any valid network fee is enough.
+	FeePerByte int64 `json:"feeperbyte,string"`
+	// PoolFeePerByte is the estimation based on the current memory pool
+	// contents.
+	PoolFeePerByte int64 `json:"poolfeeperbyte,string"`
+	// HistoryFeePerByte is the estimation based on recent blocks.
+	HistoryFeePerByte int64 `json:"historyfeeperbyte,string"`
+}
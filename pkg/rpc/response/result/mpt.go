@@ -14,6 +14,20 @@ type StateHeight struct {
 	StateHeight uint32 `json:"stateHeight"`
 }
 
+// FindStates is a result of findstates RPC.
+type FindStates struct {
+	Results    []KeyValue    `json:"results"`
+	FirstProof *ProofWithKey `json:"firstProof,omitempty"`
+	LastProof  *ProofWithKey `json:"lastProof,omitempty"`
+	Truncated  bool          `json:"truncated"`
+}
+
+// KeyValue represents key-value pair.
+type KeyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
 // ProofWithKey represens key-proof pair.
 type ProofWithKey struct {
 	Key   []byte
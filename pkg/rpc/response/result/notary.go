@@ -0,0 +1,43 @@
+package result
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+type (
+	// NotaryRequest represents the state of main transaction being completed
+	// by notary service returned by getnotaryrequests RPC call.
+	NotaryRequest struct {
+		// Main is the main transaction with witnesses collected so far.
+		Main *transaction.Transaction `json:"main"`
+		// Type is the request type: "signature", "multisignature" or
+		// "unknown" (if main transaction witnesses are invalid).
+		Type string `json:"type"`
+		// Sent is true if completed main transaction was already sent.
+		Sent           bool             `json:"sent"`
+		NSigs          int              `json:"nsigs"`
+		NSigsCollected int              `json:"nsigscollected"`
+		Witnesses      []NotaryWitness  `json:"witnesses"`
+		Fallbacks      []NotaryFallback `json:"fallbacks"`
+	}
+
+	// NotaryWitness is the state of main transaction witness.
+	NotaryWitness struct {
+		Account util.Uint160 `json:"account"`
+		// Contract is true if the witness is checked by the contract.
+		Contract bool            `json:"contract"`
+		Keys     keys.PublicKeys `json:"keys"`
+		// M is the number of signatures required for the witness.
+		M int `json:"m"`
+		// Signed are the keys which signatures were already collected.
+		Signed keys.PublicKeys `json:"signed"`
+	}
+
+	// NotaryFallback is the fallback transaction received for the request.
+	NotaryFallback struct {
+		Hash           util.Uint256 `json:"hash"`
+		NotValidBefore uint32       `json:"nvb"`
+	}
+)
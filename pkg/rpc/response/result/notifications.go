@@ -0,0 +1,20 @@
+package result
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// Notifications is a result for the findnotifications RPC call.
+type Notifications struct {
+	Notifications []Notification `json:"notifications"`
+}
+
+// Notification represents single notification found in the notifications
+// index along with its location in the chain.
+type Notification struct {
+	Container   util.Uint256            `json:"container"`
+	BlockIndex  uint32                  `json:"blockindex"`
+	NotifyIndex uint32                  `json:"notifyindex"`
+	Event       state.NotificationEvent `json:"notification"`
+}
@@ -0,0 +1,59 @@
+package result
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+type (
+	// OracleStatus represents a result of getoraclestatus RPC call.
+	OracleStatus struct {
+		// Account is the address of the oracle node account, it's empty
+		// if the node is not in the oracle nodes list.
+		Account string `json:"account,omitempty"`
+		// Nodes is the number of oracle nodes.
+		Nodes int `json:"nodes"`
+		// Required is the number of signatures needed for response.
+		Required int `json:"required"`
+		// Pending is the number of requests being processed.
+		Pending int `json:"pending"`
+		// InFlight contains URLs being fetched now.
+		InFlight []string `json:"inflight"`
+		// Cached is the number of cached URLs.
+		Cached int `json:"cached"`
+		// Errors contains last errors for URLs failing to be fetched, the
+		// most recent ones go first.
+		Errors []OracleURLError `json:"errors"`
+	}
+
+	// OracleURLError is the last error occurred while fetching URL.
+	OracleURLError struct {
+		URL   string                         `json:"url"`
+		Code  transaction.OracleResponseCode `json:"code"`
+		Error string                         `json:"error"`
+		// Time is the time of the error (Unix timestamp in milliseconds).
+		Time int64 `json:"time"`
+		// Count is the number of failures in a row.
+		Count int `json:"count"`
+	}
+
+	// OracleRequest represents the state of oracle request processing
+	// returned by getoraclerequests RPC call.
+	OracleRequest struct {
+		ID uint64 `json:"id"`
+		// URL is empty if the request wasn't processed by the node yet.
+		URL      string `json:"url,omitempty"`
+		Attempts int    `json:"attempts"`
+		// LastProcessed is the time request was processed last (Unix
+		// timestamp in milliseconds).
+		LastProcessed int64 `json:"lastprocessed,omitempty"`
+		// Sent is true if response transaction was already sent.
+		Sent         bool          `json:"sent"`
+		TxHash       *util.Uint256 `json:"txhash,omitempty"`
+		BackupTxHash *util.Uint256 `json:"backuptxhash,omitempty"`
+		// Signatures and BackupSignatures are the numbers of valid
+		// signatures collected for response and backup transactions.
+		Signatures       int `json:"signatures"`
+		BackupSignatures int `json:"backupsignatures"`
+	}
+)
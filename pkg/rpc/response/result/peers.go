@@ -7,9 +7,10 @@ import (
 type (
 	// GetPeers payload for outputting peers in `getpeers` RPC call.
 	GetPeers struct {
-		Unconnected Peers `json:"unconnected"`
-		Connected   Peers `json:"connected"`
-		Bad         Peers `json:"bad"`
+		Unconnected Peers        `json:"unconnected"`
+		Connected   Peers        `json:"connected"`
+		Bad         Peers        `json:"bad"`
+		Banned      []BannedPeer `json:"banned"`
 	}
 
 	// Peers represent a slice of peers.
@@ -19,6 +20,16 @@ type (
 	Peer struct {
 		Address string `json:"address"`
 		Port    string `json:"port"`
+		// Score is the reputation score of the peer, it's only
+		// provided for connected peers.
+		Score int `json:"score,omitempty"`
+	}
+
+	// BannedPeer represents banned host.
+	BannedPeer struct {
+		Address string `json:"address"`
+		// Until is the ban expiration time (Unix timestamp in milliseconds).
+		Until int64 `json:"until"`
 	}
 )
 
@@ -28,6 +39,7 @@ func NewGetPeers() GetPeers {
 		Unconnected: []Peer{},
 		Connected:   []Peer{},
 		Bad:         []Peer{},
+		Banned:      []BannedPeer{},
 	}
 }
 
@@ -46,6 +58,11 @@ func (g *GetPeers) AddBad(addrs []string) {
 	g.Bad.addPeers(addrs)
 }
 
+// AddBanned adds a banned host to the banned peers slice.
+func (g *GetPeers) AddBanned(host string, until int64) {
+	g.Banned = append(g.Banned, BannedPeer{Address: host, Until: until})
+}
+
 // addPeers adds a set of peers to the given peer slice.
 func (p *Peers) addPeers(addrs []string) {
 	for i := range addrs {
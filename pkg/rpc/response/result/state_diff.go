@@ -0,0 +1,27 @@
+package result
+
+type (
+	// StateDiff is a result of getstatediff RPC.
+	StateDiff struct {
+		Contracts []ContractStateDiff `json:"contracts"`
+		// Next is a continuation token to be passed to get the next batch
+		// of changes, it's nil if all changes were returned.
+		Next []byte `json:"next,omitempty"`
+	}
+
+	// ContractStateDiff contains storage changes of a single contract.
+	ContractStateDiff struct {
+		ID       int32           `json:"id"`
+		Added    []StateDiffItem `json:"added,omitempty"`
+		Modified []StateDiffItem `json:"modified,omitempty"`
+		Deleted  []StateDiffItem `json:"deleted,omitempty"`
+	}
+
+	// StateDiffItem is a single storage item change, its key doesn't include
+	// contract ID.
+	StateDiffItem struct {
+		Key      []byte `json:"key"`
+		OldValue []byte `json:"oldvalue,omitempty"`
+		NewValue []byte `json:"newvalue,omitempty"`
+	}
+)
@@ -0,0 +1,28 @@
+package result
+
+import "github.com/nspcc-dev/neo-go/pkg/util"
+
+type (
+	// StateRootVerification is a result of getstaterootverification RPC.
+	StateRootVerification struct {
+		Halted bool                        `json:"halted"`
+		Nodes  []StateRootVerificationNode `json:"nodes"`
+	}
+
+	// StateRootVerificationNode is the state of local state roots
+	// verification against a single trusted node.
+	StateRootVerificationNode struct {
+		Address        string             `json:"address"`
+		VerifiedHeight uint32             `json:"verifiedheight"`
+		LastError      string             `json:"lasterror,omitempty"`
+		Mismatch       *StateRootMismatch `json:"mismatch,omitempty"`
+	}
+
+	// StateRootMismatch describes the first height local state root differs
+	// from the trusted node's one at.
+	StateRootMismatch struct {
+		Height     uint32       `json:"height"`
+		LocalRoot  util.Uint256 `json:"localroot"`
+		RemoteRoot util.Uint256 `json:"remoteroot"`
+	}
+)
@@ -4,18 +4,47 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/encoding/fixedn"
 )
 
+// DefaultMaxFindResultItems is the default maximum number of items returned
+// by findstates RPC call.
+const DefaultMaxFindResultItems = 100
+
+// DefaultAdminAddress is the address admin endpoint listens on if it's not
+// set explicitly.
+const DefaultAdminAddress = "127.0.0.1"
+
 type (
 	// Config is an RPC service configuration information.
 	Config struct {
 		Address              string `yaml:"Address"`
 		Enabled              bool   `yaml:"Enabled"`
 		EnableCORSWorkaround bool   `yaml:"EnableCORSWorkaround"`
+		// EnablePeerManagement allows to ban and unban peers via admin
+		// endpoint.
+		EnablePeerManagement bool `yaml:"EnablePeerManagement"`
+		// EnableOracleControl allows to retry and skip oracle requests
+		// via admin endpoint. Admin endpoint has no TLS and no
+		// authentication, it listens on DefaultAdminAddress unless
+		// another address is set explicitly.
+		EnableOracleControl bool `yaml:"EnableOracleControl"`
+		// MaxFindResultItems is the maximum number of items returned by
+		// findstates RPC call.
+		MaxFindResultItems int `yaml:"MaxFindResultItems"`
 		// MaxGasInvoke is a maximum amount of gas which
 		// can be spent during RPC call.
 		MaxGasInvoke           fixedn.Fixed8 `yaml:"MaxGasInvoke"`
 		MaxIteratorResultItems int           `yaml:"MaxIteratorResultItems"`
 		Port                   uint16        `yaml:"Port"`
 		TLSConfig              TLSConfig     `yaml:"TLSConfig"`
+		AdminConfig            AdminConfig   `yaml:"AdminConfig"`
+	}
+
+	// AdminConfig describes separate endpoint for administrative calls, it
+	// shouldn't be accessible publicly. Empty Address means
+	// DefaultAdminAddress, not all interfaces.
+	AdminConfig struct {
+		Address string `yaml:"Address"`
+		Enabled bool   `yaml:"Enabled"`
+		Port    uint16 `yaml:"Port"`
 	}
 
 	// TLSConfig describes SSL/TLS configuration.
@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"testing"
 
 	nns "github.com/nspcc-dev/neo-go/examples/nft-nd-nns"
@@ -650,6 +651,15 @@ func TestCalculateNotaryFee(t *testing.T) {
 		_, err := c.CalculateNotaryFee(0)
 		require.NotNil(t, err)
 	})
+
+	require.NoError(t, c.Init())
+	t.Run("max keys", func(t *testing.T) {
+		base, err := c.CalculateNotaryFee(0)
+		require.NoError(t, err)
+		max, err := c.CalculateNotaryFee(255)
+		require.NoError(t, err)
+		require.Equal(t, int64(255*transaction.NotaryServiceFeePerKey), max-base)
+	})
 }
 
 func TestPing(t *testing.T) {
@@ -719,6 +729,60 @@ func TestCreateNEP17TransferTx(t *testing.T) {
 	require.NoError(t, v.Run())
 }
 
+func TestCreateReplacementTx(t *testing.T) {
+	chain, rpcSrv, httpSrv := initServerWithInMemoryChain(t)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	c, err := client.New(context.Background(), httpSrv.URL, client.Options{})
+	require.NoError(t, err)
+	require.NoError(t, c.Init())
+
+	acc := wallet.NewAccountFromPrivateKey(testchain.PrivateKeyByID(0))
+	gasContractHash, err := c.GetNativeContractHash(nativenames.Gas)
+	require.NoError(t, err)
+
+	old, err := c.CreateNEP17TransferTx(acc, util.Uint160{}, gasContractHash, 1000, 0, nil, nil)
+	require.NoError(t, err)
+	oldHash, err := c.SignAndPushTx(old, acc, nil)
+	require.NoError(t, err)
+
+	t.Run("wrong sender", func(t *testing.T) {
+		other := wallet.NewAccountFromPrivateKey(testchain.PrivateKeyByID(1))
+		_, err := c.CreateSpeedUpTx(oldHash, other, 0, nil)
+		require.Error(t, err)
+		_, err = c.CreateCancelTx(oldHash, other, 0)
+		require.Error(t, err)
+	})
+
+	tx, err := c.CreateSpeedUpTx(oldHash, acc, 100, nil)
+	require.NoError(t, err)
+	require.Equal(t, old.Script, tx.Script)
+	require.Equal(t, old.SystemFee, tx.SystemFee)
+	require.Equal(t, old.Signers, tx.Signers)
+	require.Equal(t, []transaction.Attribute{{
+		Type:  transaction.ConflictsT,
+		Value: &transaction.Conflicts{Hash: oldHash},
+	}}, tx.Attributes)
+	require.True(t, tx.NetworkFee >= old.NetworkFee+100)
+	speedUpHash, err := c.SignAndPushTx(tx, acc, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.CheckReplaced(oldHash, speedUpHash))
+
+	_, err = c.CreateCancelTx(oldHash, acc, 0)
+	require.True(t, errors.Is(err, client.ErrNotInMemPool))
+
+	tx, err = c.CreateCancelTx(speedUpHash, acc, 0)
+	require.NoError(t, err)
+	require.Equal(t, []byte{byte(opcode.RET)}, tx.Script)
+	require.Equal(t, 1, len(tx.Signers))
+	require.Equal(t, transaction.None, tx.Signers[0].Scopes)
+	cancelHash, err := c.SignAndPushTx(tx, acc, nil)
+	require.NoError(t, err)
+	require.NoError(t, c.CheckReplaced(speedUpHash, cancelHash))
+	require.Error(t, c.CheckReplaced(cancelHash, speedUpHash))
+}
+
 func TestInvokeVerify(t *testing.T) {
 	chain, rpcSrv, httpSrv := initServerWithInMemoryChain(t)
 	defer chain.Close()
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/request"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+)
+
+const (
+	// feeHistoryBlocks is the number of recent blocks used for fee
+	// estimation, it's also the maximum inclusion target.
+	feeHistoryBlocks = 20
+	// fullBlockPercent is the block fill percentage (by transactions
+	// count or size) after which the block is considered to be full.
+	fullBlockPercent = 90
+)
+
+// blockCapacity contains block limits.
+type blockCapacity struct {
+	txs  int
+	size int
+}
+
+func (s *Server) blockCapacity() blockCapacity {
+	cfg := s.chain.GetConfig()
+	return blockCapacity{
+		txs:  int(cfg.MaxTransactionsPerBlock),
+		size: int(cfg.MaxBlockSize),
+	}
+}
+
+// fillBlocks distributes pooled transactions among blocks in the priority
+// order and returns the index of the block every transaction gets into.
+func fillBlocks(txs []mempool.TxStats, c blockCapacity) []int {
+	var (
+		res         = make([]int, len(txs))
+		blk         int
+		count, size int
+	)
+	for i, tx := range txs {
+		if count != 0 && (count >= c.txs || size+tx.Size > c.size) {
+			blk++
+			count, size = 0, 0
+		}
+		count++
+		size += tx.Size
+		res[i] = blk
+	}
+	return res
+}
+
+// poolInclusionFee returns fee per byte needed to get into the first target
+// blocks given the current pool contents. It's the fee that outranks the
+// first regular transaction that doesn't fit into these blocks, zero is
+// returned if everything fits.
+func poolInclusionFee(txs []mempool.TxStats, c blockCapacity, target int) int64 {
+	blocks := fillBlocks(txs, c)
+	for i, tx := range txs {
+		if blocks[i] >= target && !tx.HighPriority {
+			return tx.FeePerByte + 1
+		}
+	}
+	return 0
+}
+
+// blockInclusionFee returns the lowest fee per byte of regular transactions
+// included into the block if it's full and zero otherwise (any fee was
+// enough to get into it).
+func blockInclusionFee(b *block.Block, c blockCapacity) int64 {
+	var (
+		size int
+		min  int64 = -1
+	)
+	for _, tx := range b.Transactions {
+		size += tx.Size()
+		if tx.HasAttribute(transaction.HighPriority) {
+			continue
+		}
+		if fpb := tx.FeePerByte(); min < 0 || fpb < min {
+			min = fpb
+		}
+	}
+	full := len(b.Transactions)*100 >= c.txs*fullBlockPercent || size*100 >= c.size*fullBlockPercent
+	if !full || min < 0 {
+		return 0
+	}
+	return min
+}
+
+// historyInclusionFee estimates fee per byte needed to get into one of the
+// target blocks using inclusion fees of recent blocks. Transaction waiting
+// for target blocks gets into the cheapest of them, so the minimum over
+// every target consecutive blocks is taken and the median of these minimums
+// is returned.
+func historyInclusionFee(fees []int64, target int) int64 {
+	if target > len(fees) {
+		target = len(fees)
+	}
+	if target == 0 {
+		return 0
+	}
+	var windows = make([]int64, 0, len(fees)-target+1)
+	for i := 0; i+target <= len(fees); i++ {
+		min := fees[i]
+		for _, f := range fees[i+1 : i+target] {
+			if f < min {
+				min = f
+			}
+		}
+		windows = append(windows, min)
+	}
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	return windows[len(windows)/2]
+}
+
+// recentInclusionFees returns inclusion fees of the last feeHistoryBlocks
+// blocks.
+func (s *Server) recentInclusionFees(c blockCapacity) []int64 {
+	var (
+		height = s.chain.BlockHeight()
+		fees   = make([]int64, 0, feeHistoryBlocks)
+	)
+	for i := 0; i < feeHistoryBlocks && uint32(i) <= height; i++ {
+		b, err := s.chain.GetBlock(s.chain.GetHeaderHash(int(height) - i))
+		if err != nil {
+			break
+		}
+		fees = append(fees, blockInclusionFee(b, c))
+	}
+	return fees
+}
+
+// percentile returns p-th percentile of sorted values.
+func percentile(sorted []int64, p int) int64 {
+	return sorted[(len(sorted)-1)*p/100]
+}
+
+// getMempoolInfo returns memory pool statistics.
+func (s *Server) getMempoolInfo(_ request.Params) (interface{}, *response.Error) {
+	var (
+		c    = s.blockCapacity()
+		st   = s.chain.GetMemPool().GetStats()
+		fees = make([]int64, 0, len(st.Transactions))
+		res  = result.MempoolInfo{
+			Height:                  s.chain.BlockHeight(),
+			Count:                   len(st.Transactions),
+			Capacity:                st.Capacity,
+			Size:                    st.Size,
+			Conflicts:               st.Conflicts,
+			MinFeePerByte:           s.chain.FeePerByte(),
+			MaxTransactionsPerBlock: c.txs,
+			MaxBlockSize:            c.size,
+		}
+	)
+	for _, tx := range st.Transactions {
+		if tx.HighPriority {
+			res.HighPriority++
+			continue
+		}
+		fees = append(fees, tx.FeePerByte)
+	}
+	if len(fees) != 0 {
+		sort.Slice(fees, func(i, j int) bool { return fees[i] < fees[j] })
+		res.FeePerByte = result.FeeDistribution{
+			Min:    fees[0],
+			P25:    percentile(fees, 25),
+			Median: percentile(fees, 50),
+			P75:    percentile(fees, 75),
+			Max:    fees[len(fees)-1],
+		}
+	}
+	if blocks := fillBlocks(st.Transactions, c); len(blocks) != 0 {
+		res.BlocksToClear = blocks[len(blocks)-1] + 1
+	}
+	return res, nil
+}
+
+// estimateFee returns network fee per byte needed for transaction to be
+// included within the given number of blocks (1 by default).
+func (s *Server) estimateFee(reqParams request.Params) (interface{}, *response.Error) {
+	var target = 1
+	if p := reqParams.Value(0); p != nil {
+		t, err := p.GetInt()
+		if err != nil || t < 1 || t > feeHistoryBlocks {
+			return nil, response.NewInvalidParamsError("invalid target", err)
+		}
+		target = t
+	}
+	var (
+		c   = s.blockCapacity()
+		res = result.FeeEstimate{
+			Target:            target,
+			PoolFeePerByte:    poolInclusionFee(s.chain.GetMemPool().GetStats().Transactions, c, target),
+			HistoryFeePerByte: historyInclusionFee(s.recentInclusionFees(c), target),
+		}
+	)
+	res.FeePerByte = res.PoolFeePerByte
+	if res.HistoryFeePerByte > res.FeePerByte {
+		res.FeePerByte = res.HistoryFeePerByte
+	}
+	return res, nil
+}
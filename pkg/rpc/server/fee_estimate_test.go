@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/block"
+	"github.com/nspcc-dev/neo-go/pkg/core/mempool"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/vm/opcode"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoolInclusionFee(t *testing.T) {
+	c := blockCapacity{txs: 2, size: 250}
+	txs := []mempool.TxStats{
+		{FeePerByte: 1, Size: 100, HighPriority: true},
+		{FeePerByte: 50, Size: 100},
+		{FeePerByte: 40, Size: 100},
+		{FeePerByte: 30, Size: 100},
+		{FeePerByte: 20, Size: 100},
+	}
+	require.Equal(t, []int{0, 0, 1, 1, 2}, fillBlocks(txs, c))
+	require.Equal(t, int64(41), poolInclusionFee(txs, c, 1))
+	require.Equal(t, int64(21), poolInclusionFee(txs, c, 2))
+	require.Equal(t, int64(0), poolInclusionFee(txs, c, 3))
+	require.Equal(t, int64(0), poolInclusionFee(nil, c, 1))
+
+	// Size limit.
+	require.Equal(t, []int{0, 1, 2}, fillBlocks([]mempool.TxStats{{Size: 200}, {Size: 100}, {Size: 200}}, c))
+}
+
+func TestHistoryInclusionFee(t *testing.T) {
+	require.Equal(t, int64(0), historyInclusionFee(nil, 1))
+	fees := []int64{10, 0, 30, 40, 20}
+	require.Equal(t, int64(20), historyInclusionFee(fees, 1))
+	// Windows minimums are 0, 0, 30, 20.
+	require.Equal(t, int64(20), historyInclusionFee(fees, 2))
+	require.Equal(t, int64(0), historyInclusionFee(fees, 3))
+	require.Equal(t, int64(0), historyInclusionFee(fees, 10))
+}
+
+func TestBlockInclusionFee(t *testing.T) {
+	newTx := func(netFee int64, highPriority bool) *transaction.Transaction {
+		tx := transaction.New([]byte{byte(opcode.PUSH1)}, 0)
+		tx.NetworkFee = netFee
+		if highPriority {
+			tx.Attributes = []transaction.Attribute{{Type: transaction.HighPriority}}
+		}
+		return tx
+	}
+	b := block.New(false)
+	b.Transactions = []*transaction.Transaction{newTx(1000, false), newTx(10, true), newTx(2000, false)}
+	expected := b.Transactions[0].FeePerByte()
+
+	require.Equal(t, int64(0), blockInclusionFee(b, blockCapacity{txs: 10, size: 1000000}))
+	require.Equal(t, expected, blockInclusionFee(b, blockCapacity{txs: 3, size: 1000000}))
+	require.Equal(t, expected, blockInclusionFee(b, blockCapacity{txs: 10, size: 50}))
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"crypto/elliptic"
 	"encoding/binary"
@@ -23,6 +24,7 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/fee"
 	"github.com/nspcc-dev/neo-go/pkg/core/mempoolevent"
 	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
+	"github.com/nspcc-dev/neo-go/pkg/core/native"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/hash"
@@ -56,6 +58,7 @@ type (
 		oracle           *oracle.Oracle
 		log              *zap.Logger
 		https            *http.Server
+		admin            *http.Server
 		shutdown         chan struct{}
 
 		subsLock          sync.RWMutex
@@ -93,44 +96,62 @@ const (
 
 	// Maximum number of elements for get*transfers requests.
 	maxTransfersLimit = 1000
+
+	// Maximum number of elements for findnotifications requests.
+	maxNotificationsLimit = 1000
+
+	// Maximum number of changed storage items for getstatediff requests.
+	maxStateDiffLimit = 1000
 )
 
 var rpcHandlers = map[string]func(*Server, request.Params) (interface{}, *response.Error){
-	"calculatenetworkfee":    (*Server).calculateNetworkFee,
-	"getapplicationlog":      (*Server).getApplicationLog,
-	"getbestblockhash":       (*Server).getBestBlockHash,
-	"getblock":               (*Server).getBlock,
-	"getblockcount":          (*Server).getBlockCount,
-	"getblockhash":           (*Server).getBlockHash,
-	"getblockheader":         (*Server).getBlockHeader,
-	"getblockheadercount":    (*Server).getBlockHeaderCount,
-	"getblocksysfee":         (*Server).getBlockSysFee,
-	"getcommittee":           (*Server).getCommittee,
-	"getconnectioncount":     (*Server).getConnectionCount,
-	"getcontractstate":       (*Server).getContractState,
-	"getnativecontracts":     (*Server).getNativeContracts,
-	"getnep17balances":       (*Server).getNEP17Balances,
-	"getnep17transfers":      (*Server).getNEP17Transfers,
-	"getpeers":               (*Server).getPeers,
-	"getproof":               (*Server).getProof,
-	"getrawmempool":          (*Server).getRawMempool,
-	"getrawtransaction":      (*Server).getrawtransaction,
-	"getstateheight":         (*Server).getStateHeight,
-	"getstateroot":           (*Server).getStateRoot,
-	"getstorage":             (*Server).getStorage,
-	"gettransactionheight":   (*Server).getTransactionHeight,
-	"getunclaimedgas":        (*Server).getUnclaimedGas,
-	"getnextblockvalidators": (*Server).getNextBlockValidators,
-	"getversion":             (*Server).getVersion,
-	"invokefunction":         (*Server).invokeFunction,
-	"invokescript":           (*Server).invokescript,
-	"invokecontractverify":   (*Server).invokeContractVerify,
-	"sendrawtransaction":     (*Server).sendrawtransaction,
-	"submitblock":            (*Server).submitBlock,
-	"submitnotaryrequest":    (*Server).submitNotaryRequest,
-	"submitoracleresponse":   (*Server).submitOracleResponse,
-	"validateaddress":        (*Server).validateAddress,
-	"verifyproof":            (*Server).verifyProof,
+	"calculatenetworkfee":      (*Server).calculateNetworkFee,
+	"estimatefee":              (*Server).estimateFee,
+	"findnotifications":        (*Server).findNotifications,
+	"findstates":               (*Server).findStates,
+	"getaccounttransactions":   (*Server).getAccountTransactions,
+	"getapplicationlog":        (*Server).getApplicationLog,
+	"getbestblockhash":         (*Server).getBestBlockHash,
+	"getblock":                 (*Server).getBlock,
+	"getblockcount":            (*Server).getBlockCount,
+	"getblockhash":             (*Server).getBlockHash,
+	"getblockheader":           (*Server).getBlockHeader,
+	"getblockheadercount":      (*Server).getBlockHeaderCount,
+	"getblocksysfee":           (*Server).getBlockSysFee,
+	"getcommittee":             (*Server).getCommittee,
+	"getconnectioncount":       (*Server).getConnectionCount,
+	"getconsensusstate":        (*Server).getConsensusState,
+	"getcontractstate":         (*Server).getContractState,
+	"getmempoolinfo":           (*Server).getMempoolInfo,
+	"getnativecontracts":       (*Server).getNativeContracts,
+	"getnep17balances":         (*Server).getNEP17Balances,
+	"getnep17transfers":        (*Server).getNEP17Transfers,
+	"getnotaryrequests":        (*Server).getNotaryRequests,
+	"getoraclerequests":        (*Server).getOracleRequests,
+	"getoraclestatus":          (*Server).getOracleStatus,
+	"getpeers":                 (*Server).getPeers,
+	"getproof":                 (*Server).getProof,
+	"getrawmempool":            (*Server).getRawMempool,
+	"getrawtransaction":        (*Server).getrawtransaction,
+	"getstate":                 (*Server).getState,
+	"getstatediff":             (*Server).getStateDiff,
+	"getstateheight":           (*Server).getStateHeight,
+	"getstateroot":             (*Server).getStateRoot,
+	"getstaterootverification": (*Server).getStateRootVerification,
+	"getstorage":               (*Server).getStorage,
+	"gettransactionheight":     (*Server).getTransactionHeight,
+	"getunclaimedgas":          (*Server).getUnclaimedGas,
+	"getnextblockvalidators":   (*Server).getNextBlockValidators,
+	"getversion":               (*Server).getVersion,
+	"invokefunction":           (*Server).invokeFunction,
+	"invokescript":             (*Server).invokescript,
+	"invokecontractverify":     (*Server).invokeContractVerify,
+	"sendrawtransaction":       (*Server).sendrawtransaction,
+	"submitblock":              (*Server).submitBlock,
+	"submitnotaryrequest":      (*Server).submitNotaryRequest,
+	"submitoracleresponse":     (*Server).submitOracleResponse,
+	"validateaddress":          (*Server).validateAddress,
+	"verifyproof":              (*Server).verifyProof,
 }
 
 var rpcWsHandlers = map[string]func(*Server, request.Params, *subscriber) (interface{}, *response.Error){
@@ -138,6 +159,14 @@ var rpcWsHandlers = map[string]func(*Server, request.Params, *subscriber) (inter
 	"unsubscribe": (*Server).unsubscribe,
 }
 
+// rpcAdminHandlers are only available via admin endpoint.
+var rpcAdminHandlers = map[string]func(*Server, request.Params) (interface{}, *response.Error){
+	"banpeer":            (*Server).banPeer,
+	"retryoraclerequest": (*Server).retryOracleRequest,
+	"skiporaclerequest":  (*Server).skipOracleRequest,
+	"unbanpeer":          (*Server).unbanPeer,
+}
+
 var invalidBlockHeightError = func(index int, height int) *response.Error {
 	return response.NewRPCError(fmt.Sprintf("Param at index %d should be greater than or equal to 0 and less then or equal to current block height, got: %d", index, height), "", nil)
 }
@@ -160,9 +189,22 @@ func New(chain blockchainer.Blockchainer, conf rpc.Config, coreServer *network.S
 		}
 	}
 
+	var adminServer *http.Server
+	if conf.AdminConfig.Enabled && conf.AdminConfig.Address == "" {
+		conf.AdminConfig.Address = rpc.DefaultAdminAddress
+	}
+	if cfg := conf.AdminConfig; cfg.Enabled {
+		adminServer = &http.Server{
+			Addr: net.JoinHostPort(cfg.Address, strconv.FormatUint(uint64(cfg.Port), 10)),
+		}
+	}
+
 	if orc != nil {
 		orc.SetBroadcaster(broadcaster.New(orc.MainCfg, log))
 	}
+	if conf.MaxFindResultItems <= 0 {
+		conf.MaxFindResultItems = rpc.DefaultMaxFindResultItems
+	}
 	return Server{
 		Server:           httpServer,
 		chain:            chain,
@@ -173,6 +215,7 @@ func New(chain blockchainer.Blockchainer, conf rpc.Config, coreServer *network.S
 		log:              log,
 		oracle:           orc,
 		https:            tlsServer,
+		admin:            adminServer,
 		shutdown:         make(chan struct{}),
 
 		subscribers: make(map[*subscriber]bool),
@@ -214,6 +257,23 @@ func (s *Server) Start(errChan chan error) {
 			}
 		}()
 	}
+	if s.config.AdminConfig.Enabled {
+		s.admin.Handler = http.HandlerFunc(s.handleAdminHTTPRequest)
+		s.log.Info("starting rpc-server (admin)", zap.String("endpoint", s.admin.Addr))
+		ln, err := net.Listen("tcp", s.admin.Addr)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		s.admin.Addr = ln.Addr().String()
+		go func() {
+			err := s.admin.Serve(ln)
+			if err != http.ErrServerClosed {
+				s.log.Error("failed to start admin RPC server", zap.Error(err))
+				errChan <- err
+			}
+		}()
+	}
 	ln, err := net.Listen("tcp", s.Addr)
 	if err != nil {
 		errChan <- err
@@ -229,10 +289,19 @@ func (s *Server) Start(errChan chan error) {
 	}()
 }
 
+// AdminAddress returns the address admin endpoint listens on (it's only set
+// after Start), empty string is returned if admin endpoint is disabled.
+func (s *Server) AdminAddress() string {
+	if s.admin == nil {
+		return ""
+	}
+	return s.admin.Addr
+}
+
 // Shutdown overrides the http.Server Shutdown
 // method.
 func (s *Server) Shutdown() error {
-	var httpsErr error
+	var httpsErr, adminErr error
 
 	// Signal to websocket writer routines and handleSubEvents.
 	close(s.shutdown)
@@ -241,6 +310,10 @@ func (s *Server) Shutdown() error {
 		s.log.Info("shutting down rpc-server (https)", zap.String("endpoint", s.https.Addr))
 		httpsErr = s.https.Shutdown(context.Background())
 	}
+	if s.config.AdminConfig.Enabled {
+		s.log.Info("shutting down rpc-server (admin)", zap.String("endpoint", s.admin.Addr))
+		adminErr = s.admin.Shutdown(context.Background())
+	}
 
 	s.log.Info("shutting down rpc-server", zap.String("endpoint", s.Addr))
 	err := s.Server.Shutdown(context.Background())
@@ -249,12 +322,25 @@ func (s *Server) Shutdown() error {
 	<-s.executionCh
 
 	if err == nil {
-		return httpsErr
+		err = httpsErr
+	}
+	if err == nil {
+		err = adminErr
 	}
 	return err
 }
 
 func (s *Server) handleHTTPRequest(w http.ResponseWriter, httpRequest *http.Request) {
+	s.handleHTTP(w, httpRequest, false)
+}
+
+// handleAdminHTTPRequest handles requests to admin endpoint, admin methods
+// are available there in addition to the regular ones.
+func (s *Server) handleAdminHTTPRequest(w http.ResponseWriter, httpRequest *http.Request) {
+	s.handleHTTP(w, httpRequest, true)
+}
+
+func (s *Server) handleHTTP(w http.ResponseWriter, httpRequest *http.Request, admin bool) {
 	req := request.NewRequest()
 
 	if httpRequest.URL.Path == "/ws" && httpRequest.Method == "GET" {
@@ -285,7 +371,7 @@ func (s *Server) handleHTTPRequest(w http.ResponseWriter, httpRequest *http.Requ
 		s.subscribers[subscr] = true
 		s.subsLock.Unlock()
 		go s.handleWsWrites(ws, resChan, subChan)
-		s.handleWsReads(ws, resChan, subscr)
+		s.handleWsReads(ws, resChan, subscr, admin)
 		return
 	}
 
@@ -306,22 +392,22 @@ func (s *Server) handleHTTPRequest(w http.ResponseWriter, httpRequest *http.Requ
 		return
 	}
 
-	resp := s.handleRequest(req, nil)
+	resp := s.handleRequest(req, nil, admin)
 	s.writeHTTPServerResponse(req, w, resp)
 }
 
-func (s *Server) handleRequest(req *request.Request, sub *subscriber) response.AbstractResult {
+func (s *Server) handleRequest(req *request.Request, sub *subscriber, admin bool) response.AbstractResult {
 	if req.In != nil {
-		return s.handleIn(req.In, sub)
+		return s.handleIn(req.In, sub, admin)
 	}
 	resp := make(response.AbstractBatch, len(req.Batch))
 	for i, in := range req.Batch {
-		resp[i] = s.handleIn(&in, sub)
+		resp[i] = s.handleIn(&in, sub, admin)
 	}
 	return resp
 }
 
-func (s *Server) handleIn(req *request.In, sub *subscriber) response.Abstract {
+func (s *Server) handleIn(req *request.In, sub *subscriber, admin bool) response.Abstract {
 	var res interface{}
 	var resErr *response.Error
 	if req.JSONRPC != request.JSONRPCVersion {
@@ -341,6 +427,9 @@ func (s *Server) handleIn(req *request.In, sub *subscriber) response.Abstract {
 
 	resErr = response.NewMethodNotFoundError(fmt.Sprintf("Method '%s' not supported", req.Method), nil)
 	handler, ok := rpcHandlers[req.Method]
+	if !ok && admin {
+		handler, ok = rpcAdminHandlers[req.Method]
+	}
 	if ok {
 		res, resErr = handler(s, *reqParams)
 	} else if sub != nil {
@@ -405,7 +494,7 @@ drainloop:
 	}
 }
 
-func (s *Server) handleWsReads(ws *websocket.Conn, resChan chan<- response.AbstractResult, subscr *subscriber) {
+func (s *Server) handleWsReads(ws *websocket.Conn, resChan chan<- response.AbstractResult, subscr *subscriber, admin bool) {
 	ws.SetReadLimit(wsReadLimit)
 	err := ws.SetReadDeadline(time.Now().Add(wsPongLimit))
 	ws.SetPongHandler(func(string) error { return ws.SetReadDeadline(time.Now().Add(wsPongLimit)) })
@@ -416,7 +505,7 @@ requestloop:
 		if err != nil {
 			break
 		}
-		res := s.handleRequest(req, subscr)
+		res := s.handleRequest(req, subscr, admin)
 		res.RunForErrors(func(jsonErr *response.Error) {
 			s.logRequestError(req, jsonErr)
 		})
@@ -454,6 +543,28 @@ func (s *Server) getConnectionCount(_ request.Params) (interface{}, *response.Er
 	return s.coreServer.PeerCount(), nil
 }
 
+func (s *Server) getConsensusState(_ request.Params) (interface{}, *response.Error) {
+	st, err := s.coreServer.GetConsensus().GetState()
+	if err != nil {
+		return nil, response.NewInternalServerError(err.Error(), nil)
+	}
+	res := &result.ConsensusState{
+		Height:     st.Height,
+		View:       st.View,
+		Primary:    st.Primary,
+		Validators: make([]result.ConsensusValidatorState, len(st.Validators)),
+	}
+	for i, v := range st.Validators {
+		res.Validators[i] = result.ConsensusValidatorState{
+			PublicKey:   *v.PublicKey,
+			Preparation: v.Preparation,
+			Commit:      v.Commit,
+			ChangeView:  v.ChangeView,
+		}
+	}
+	return res, nil
+}
+
 func (s *Server) blockHashFromParam(param *request.Param) (util.Uint256, *response.Error) {
 	var hash util.Uint256
 
@@ -532,9 +643,71 @@ func (s *Server) getPeers(_ request.Params) (interface{}, *response.Error) {
 	peers.AddUnconnected(s.coreServer.UnconnectedPeers())
 	peers.AddConnected(s.coreServer.ConnectedPeers())
 	peers.AddBad(s.coreServer.BadPeers())
+	for i := range peers.Connected {
+		p := &peers.Connected[i]
+		p.Score = s.coreServer.PeerScore(p.Address)
+	}
+	for _, b := range s.coreServer.BannedPeers() {
+		peers.AddBanned(b.Host, b.Until.UnixNano()/int64(time.Millisecond))
+	}
 	return peers, nil
 }
 
+// banPeer bans the given host for the given number of seconds (or for the
+// default node's ban duration if it's not specified).
+func (s *Server) banPeer(ps request.Params) (interface{}, *response.Error) {
+	if !s.config.EnablePeerManagement {
+		return nil, response.NewInternalServerError("peer management is disabled", nil)
+	}
+	host, respErr := peerHostFromParam(ps.Value(0))
+	if respErr != nil {
+		return nil, respErr
+	}
+	var dur time.Duration
+	if p := ps.Value(1); p != nil {
+		secs, err := p.GetInt()
+		if err != nil || secs < 0 {
+			return nil, response.NewInvalidParamsError("invalid ban duration", err)
+		}
+		dur = time.Duration(secs) * time.Second
+	}
+	s.coreServer.BanPeer(host, dur)
+	return true, nil
+}
+
+// unbanPeer removes the ban for the given host, it returns false if the host
+// wasn't banned.
+func (s *Server) unbanPeer(ps request.Params) (interface{}, *response.Error) {
+	if !s.config.EnablePeerManagement {
+		return nil, response.NewInternalServerError("peer management is disabled", nil)
+	}
+	host, respErr := peerHostFromParam(ps.Value(0))
+	if respErr != nil {
+		return nil, respErr
+	}
+	return s.coreServer.UnbanPeer(host), nil
+}
+
+// peerHostFromParam returns host part of the peer address (which can be
+// either a plain host or host:port pair) given as a parameter.
+func peerHostFromParam(param *request.Param) (string, *response.Error) {
+	if param == nil {
+		return "", response.ErrInvalidParams
+	}
+	addr, err := param.GetString()
+	if err != nil {
+		return "", response.ErrInvalidParams
+	}
+	host := addr
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		host = h
+	}
+	if net.ParseIP(host) == nil {
+		return "", response.NewInvalidParamsError("invalid peer address", nil)
+	}
+	return host, nil
+}
+
 func (s *Server) getRawMempool(reqParams request.Params) (interface{}, *response.Error) {
 	verbose := reqParams.Value(0).GetBoolean()
 	mp := s.chain.GetMemPool()
@@ -693,52 +866,198 @@ func (s *Server) getNEP17Balances(ps request.Params) (interface{}, *response.Err
 
 func getTimestampsAndLimit(ps request.Params, index int) (uint64, uint64, int, int, error) {
 	var start, end uint64
-	var limit, page int
 
-	limit = maxTransfersLimit
-	pStart, pEnd, pLimit, pPage := ps.Value(index), ps.Value(index+1), ps.Value(index+2), ps.Value(index+3)
+	limit, page, err := getLimitAndPage(ps, index+2, maxTransfersLimit)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+	pStart, pEnd := ps.Value(index), ps.Value(index+1)
+	if pEnd != nil {
+		val, err := pEnd.GetInt()
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		end = uint64(val)
+	} else {
+		end = uint64(time.Now().Unix() * 1000)
+	}
+	if pStart != nil {
+		val, err := pStart.GetInt()
+		if err != nil {
+			return 0, 0, 0, 0, err
+		}
+		start = uint64(val)
+	} else {
+		start = uint64(time.Now().Add(-time.Hour*24*7).Unix() * 1000)
+	}
+	return start, end, limit, page, nil
+}
+
+// getLimitAndPage returns limit and page from parameters at index and index+1.
+// Both are optional, limit is maxLimit by default and page is 0.
+func getLimitAndPage(ps request.Params, index int, maxLimit int) (int, int, error) {
+	var limit, page = maxLimit, 0
+
+	pLimit, pPage := ps.Value(index), ps.Value(index+1)
 	if pPage != nil {
 		p, err := pPage.GetInt()
 		if err != nil {
-			return 0, 0, 0, 0, err
+			return 0, 0, err
 		}
 		if p < 0 {
-			return 0, 0, 0, 0, errors.New("can't use negative page")
+			return 0, 0, errors.New("can't use negative page")
 		}
 		page = p
 	}
 	if pLimit != nil {
 		l, err := pLimit.GetInt()
 		if err != nil {
-			return 0, 0, 0, 0, err
+			return 0, 0, err
 		}
 		if l <= 0 {
-			return 0, 0, 0, 0, errors.New("can't use negative or zero limit")
+			return 0, 0, errors.New("can't use negative or zero limit")
 		}
-		if l > maxTransfersLimit {
-			return 0, 0, 0, 0, errors.New("too big limit requested")
+		if l > maxLimit {
+			return 0, 0, errors.New("too big limit requested")
 		}
 		limit = l
 	}
+	return limit, page, nil
+}
+
+// pageOffset returns the number of items on pages preceding the given one.
+func pageOffset(limit, page int) int {
+	if limit != 0 && page > math.MaxInt32/limit {
+		return math.MaxInt32
+	}
+	return limit * page
+}
+
+// getHeightBounds returns block height bounds from parameters at index and
+// index+1. Both are optional, by default all the heights are included.
+func getHeightBounds(ps request.Params, index int) (uint32, uint32, error) {
+	var start, end uint32 = 0, math.MaxUint32
+
+	pStart, pEnd := ps.Value(index), ps.Value(index+1)
+	if pStart != nil {
+		val, err := pStart.GetInt()
+		if err != nil {
+			return 0, 0, err
+		}
+		if val < 0 {
+			return 0, 0, errors.New("can't use negative start height")
+		}
+		start = uint32(val)
+	}
 	if pEnd != nil {
 		val, err := pEnd.GetInt()
 		if err != nil {
-			return 0, 0, 0, 0, err
+			return 0, 0, err
 		}
-		end = uint64(val)
-	} else {
-		end = uint64(time.Now().Unix() * 1000)
+		if val < 0 {
+			return 0, 0, errors.New("can't use negative end height")
+		}
+		end = uint32(val)
 	}
-	if pStart != nil {
-		val, err := pStart.GetInt()
+	return start, end, nil
+}
+
+func (s *Server) findNotifications(ps request.Params) (interface{}, *response.Error) {
+	contract, respErr := s.contractScriptHashFromParam(ps.Value(0))
+	if respErr != nil {
+		return nil, respErr
+	}
+	var name string
+	if p := ps.Value(1); p != nil {
+		var err error
+		name, err = p.GetString()
 		if err != nil {
-			return 0, 0, 0, 0, err
+			return nil, response.ErrInvalidParams
 		}
-		start = uint64(val)
-	} else {
-		start = uint64(time.Now().Add(-time.Hour*24*7).Unix() * 1000)
 	}
-	return start, end, limit, page, nil
+	start, end, err := getHeightBounds(ps, 2)
+	if err != nil {
+		return nil, response.NewInvalidParamsError(err.Error(), err)
+	}
+	limit, page, err := getLimitAndPage(ps, 4, maxNotificationsLimit)
+	if err != nil {
+		return nil, response.NewInvalidParamsError(err.Error(), err)
+	}
+
+	res := &result.Notifications{
+		Notifications: []result.Notification{},
+	}
+	newer := func(n *state.IndexedNotification) bool {
+		return n.Block > end
+	}
+	err = s.chain.ForEachNotification(contract, name, newer, pageOffset(limit, page), func(n *state.IndexedNotification) (bool, error) {
+		// Iterating from newest to oldest, moved past required
+		// height, stop looping.
+		if n.Block < start {
+			return false, nil
+		}
+		res.Notifications = append(res.Notifications, result.Notification{
+			Container:   n.Container,
+			BlockIndex:  n.Block,
+			NotifyIndex: n.Index,
+			Event:       n.Event,
+		})
+		// Using limits, reached limit.
+		return len(res.Notifications) < limit, nil
+	})
+	if err != nil {
+		if errors.Is(err, core.ErrNotificationsIndexDisabled) {
+			return nil, response.NewRPCError("Notifications index is disabled", err.Error(), err)
+		}
+		return nil, response.NewInternalServerError("invalid notifications index", err)
+	}
+	return res, nil
+}
+
+func (s *Server) getAccountTransactions(ps request.Params) (interface{}, *response.Error) {
+	u, err := ps.Value(0).GetUint160FromAddressOrHex()
+	if err != nil {
+		return nil, response.ErrInvalidParams
+	}
+
+	start, end, limit, page, err := getTimestampsAndLimit(ps, 1)
+	if err != nil {
+		return nil, response.NewInvalidParamsError(err.Error(), err)
+	}
+	startHeight, endHeight, err := getHeightBounds(ps, 5)
+	if err != nil {
+		return nil, response.NewInvalidParamsError(err.Error(), err)
+	}
+
+	res := &result.AccountTransactions{
+		Address:      address.Uint160ToString(u),
+		Transactions: []result.AccountTransaction{},
+	}
+	// Transactions newer than the required frame and the ones from the
+	// previous pages are skipped by the index without iterating over them.
+	err = s.chain.ForEachAccountTransaction(u, func(tr *state.AccountTransaction) bool {
+		return tr.Timestamp > end || tr.Block > endHeight
+	}, pageOffset(limit, page), func(tr *state.AccountTransaction) (bool, error) {
+		// Iterating from newest to oldest, moved past required
+		// frame, stop looping.
+		if tr.Timestamp < start || tr.Block < startHeight {
+			return false, nil
+		}
+		res.Transactions = append(res.Transactions, result.AccountTransaction{
+			Timestamp: tr.Timestamp,
+			Index:     tr.Block,
+			TxHash:    tr.Tx,
+		})
+		// Using limits, reached limit.
+		return len(res.Transactions) < limit, nil
+	})
+	if err != nil {
+		if errors.Is(err, core.ErrAccountTransactionsIndexDisabled) {
+			return nil, response.NewRPCError("Account transactions index is disabled", err.Error(), err)
+		}
+		return nil, response.NewInternalServerError("invalid account transactions index", err)
+	}
+	return res, nil
 }
 
 func (s *Server) getNEP17Transfers(ps request.Params) (interface{}, *response.Error) {
@@ -962,6 +1281,245 @@ func (s *Server) verifyProof(ps request.Params) (interface{}, *response.Error) {
 	return vp, nil
 }
 
+func (s *Server) getState(ps request.Params) (interface{}, *response.Error) {
+	if s.chain.GetConfig().KeepOnlyLatestState {
+		return nil, response.NewInvalidRequestError("'getstate' is not supported", errKeepOnlyLatestState)
+	}
+	root, err := ps.Value(0).GetUint256()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid stateroot"))
+	}
+	csHash, err := ps.Value(1).GetUint160FromHex()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid contract hash"))
+	}
+	key, err := ps.Value(2).GetBytesBase64()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid key"))
+	}
+	id, respErr := s.getHistoricalContractID(root, csHash)
+	if respErr != nil {
+		return nil, respErr
+	}
+	val, err := s.chain.GetStateModule().GetState(root, makeStorageKey(id, key))
+	if err != nil {
+		return nil, response.NewRPCError("Unknown storage item.", "", err)
+	}
+	return val, nil
+}
+
+func (s *Server) findStates(ps request.Params) (interface{}, *response.Error) {
+	if s.chain.GetConfig().KeepOnlyLatestState {
+		return nil, response.NewInvalidRequestError("'findstates' is not supported", errKeepOnlyLatestState)
+	}
+	root, err := ps.Value(0).GetUint256()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid stateroot"))
+	}
+	csHash, err := ps.Value(1).GetUint160FromHex()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid contract hash"))
+	}
+	prefix, err := ps.Value(2).GetBytesBase64()
+	if err != nil {
+		return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid prefix"))
+	}
+	var key []byte
+	if p := ps.Value(3); p != nil {
+		key, err = p.GetBytesBase64()
+		if err != nil {
+			return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid key"))
+		}
+		if len(key) > 0 && !bytes.HasPrefix(key, prefix) {
+			return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("key doesn't match prefix"))
+		}
+	}
+	count := s.config.MaxFindResultItems
+	if p := ps.Value(4); p != nil {
+		c, err := p.GetInt()
+		if err != nil || c <= 0 {
+			return nil, response.WrapErrorWithData(response.ErrInvalidParams, errors.New("invalid count"))
+		}
+		if c < count {
+			count = c
+		}
+	}
+	id, respErr := s.getHistoricalContractID(root, csHash)
+	if respErr != nil {
+		return nil, respErr
+	}
+	var from []byte
+	if len(key) > 0 {
+		from = makeStorageKey(id, key)
+	}
+	// One more item is requested to find out whether the result is truncated.
+	kvs, err := s.chain.GetStateModule().FindStates(root, makeStorageKey(id, prefix), from, count+1)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to find historical items", err)
+	}
+	res := result.FindStates{
+		Results: make([]result.KeyValue, 0, len(kvs)),
+	}
+	if len(kvs) > count {
+		res.Truncated = true
+		kvs = kvs[:count]
+	}
+	if len(kvs) > 0 {
+		if res.FirstProof, err = s.getProofWithKey(root, kvs[0].Key); err != nil {
+			return nil, response.NewInternalServerError("failed to get first proof", err)
+		}
+	} else {
+		// Nothing is found, so the proof of the range start (which can be
+		// missing in the trie) is returned.
+		boundary := from
+		if len(boundary) == 0 {
+			boundary = makeStorageKey(id, prefix)
+		}
+		proof, err := s.chain.GetStateModule().GetStatePathProof(root, boundary)
+		if err != nil {
+			return nil, response.NewInternalServerError("failed to get boundary proof", err)
+		}
+		res.FirstProof = &result.ProofWithKey{
+			Key:   boundary,
+			Proof: proof,
+		}
+	}
+	if len(kvs) > 1 {
+		if res.LastProof, err = s.getProofWithKey(root, kvs[len(kvs)-1].Key); err != nil {
+			return nil, response.NewInternalServerError("failed to get last proof", err)
+		}
+	}
+	for _, kv := range kvs {
+		res.Results = append(res.Results, result.KeyValue{
+			Key:   kv.Key[4:], // cut contract ID
+			Value: kv.Value,
+		})
+	}
+	return res, nil
+}
+
+func (s *Server) getProofWithKey(root util.Uint256, key []byte) (*result.ProofWithKey, error) {
+	proof, err := s.chain.GetStateModule().GetStateProof(root, key)
+	if err != nil {
+		return nil, err
+	}
+	return &result.ProofWithKey{
+		Key:   key,
+		Proof: proof,
+	}, nil
+}
+
+// getHistoricalContractID returns ID of the contract with the specified hash
+// at the given state root.
+func (s *Server) getHistoricalContractID(root util.Uint256, h util.Uint160) (int32, *response.Error) {
+	// Native contracts are deployed in the genesis block and never change.
+	if cs := s.chain.GetContractState(h); cs != nil && cs.ID < 0 {
+		return cs.ID, nil
+	}
+	mgmt := s.chain.GetContractState(s.chain.ManagementContractHash())
+	key := makeStorageKey(mgmt.ID, append([]byte{native.PrefixContract}, h.BytesBE()...))
+	data, err := s.chain.GetStateModule().GetState(root, key)
+	if err != nil {
+		return 0, response.NewRPCError("Unknown contract.", "", err)
+	}
+	var cs state.Contract
+	r := io.NewBinReaderFromBuf(data)
+	cs.DecodeBinary(r)
+	if r.Err != nil {
+		return 0, response.NewInternalServerError("failed to decode contract state", r.Err)
+	}
+	return cs.ID, nil
+}
+
+func (s *Server) getStateDiff(ps request.Params) (interface{}, *response.Error) {
+	if s.chain.GetConfig().KeepOnlyLatestState {
+		return nil, response.NewInvalidRequestError("'getstatediff' is not supported", errKeepOnlyLatestState)
+	}
+	oldRoot, respErr := s.stateRootHashFromParam(ps.Value(0))
+	if respErr != nil {
+		return nil, respErr
+	}
+	newRoot, respErr := s.stateRootHashFromParam(ps.Value(1))
+	if respErr != nil {
+		return nil, respErr
+	}
+	limit := maxStateDiffLimit
+	if p := ps.Value(2); p != nil {
+		l, err := p.GetInt()
+		if err != nil {
+			return nil, response.ErrInvalidParams
+		}
+		if l <= 0 || l > maxStateDiffLimit {
+			return nil, response.WrapErrorWithData(response.ErrInvalidParams, fmt.Errorf("limit should be in [1, %d] range", maxStateDiffLimit))
+		}
+		limit = l
+	}
+	var after []byte
+	if p := ps.Value(3); p != nil {
+		var err error
+		if after, err = p.GetBytesBase64(); err != nil {
+			return nil, response.ErrInvalidParams
+		}
+	}
+	items, more, err := s.chain.GetStateModule().GetStateDiff(oldRoot, newRoot, after, limit)
+	if err != nil {
+		return nil, response.NewInternalServerError("failed to get state diff", err)
+	}
+	res := &result.StateDiff{
+		Contracts: []result.ContractStateDiff{},
+	}
+	for _, item := range items {
+		if len(item.Key) < 4 {
+			continue
+		}
+		id := int32(binary.LittleEndian.Uint32(item.Key))
+		if n := len(res.Contracts); n == 0 || res.Contracts[n-1].ID != id {
+			res.Contracts = append(res.Contracts, result.ContractStateDiff{ID: id})
+		}
+		cd := &res.Contracts[len(res.Contracts)-1]
+		di := result.StateDiffItem{
+			Key:      item.Key[4:],
+			OldValue: item.OldValue,
+			NewValue: item.NewValue,
+		}
+		switch {
+		case item.OldValue == nil:
+			cd.Added = append(cd.Added, di)
+		case item.NewValue == nil:
+			cd.Deleted = append(cd.Deleted, di)
+		default:
+			cd.Modified = append(cd.Modified, di)
+		}
+	}
+	if more {
+		res.Next = items[len(items)-1].Key
+	}
+	return res, nil
+}
+
+// stateRootHashFromParam returns state root hash specified either by height or
+// by hash.
+func (s *Server) stateRootHashFromParam(p *request.Param) (util.Uint256, *response.Error) {
+	if p == nil {
+		return util.Uint256{}, response.ErrInvalidParams
+	}
+	if height, err := p.GetInt(); err == nil {
+		if err := checkUint32(height); err != nil {
+			return util.Uint256{}, response.WrapErrorWithData(response.ErrInvalidParams, err)
+		}
+		r, err := s.chain.GetStateModule().GetStateRoot(uint32(height))
+		if err != nil {
+			return util.Uint256{}, response.NewRPCError("Unknown state root.", "", err)
+		}
+		return r.Root, nil
+	}
+	h, err := p.GetUint256()
+	if err != nil {
+		return util.Uint256{}, response.ErrInvalidParams
+	}
+	return h, nil
+}
+
 func (s *Server) getStateHeight(_ request.Params) (interface{}, *response.Error) {
 	var height = s.chain.BlockHeight()
 	var stateHeight = s.chain.GetStateModule().CurrentValidatedHeight()
@@ -1000,6 +1558,34 @@ func (s *Server) getStateRoot(ps request.Params) (interface{}, *response.Error)
 	return rt, nil
 }
 
+func (s *Server) getStateRootVerification(_ request.Params) (interface{}, *response.Error) {
+	st, err := s.coreServer.GetStateRoot().GetVerificationStatus()
+	if err != nil {
+		return nil, response.NewInternalServerError(err.Error(), nil)
+	}
+	res := &result.StateRootVerification{
+		Halted: st.Halted,
+		Nodes:  make([]result.StateRootVerificationNode, len(st.Nodes)),
+	}
+	for i, n := range st.Nodes {
+		res.Nodes[i] = result.StateRootVerificationNode{
+			Address:        n.Address,
+			VerifiedHeight: n.VerifiedHeight,
+		}
+		if n.LastError != nil {
+			res.Nodes[i].LastError = n.LastError.Error()
+		}
+		if n.Mismatch != nil {
+			res.Nodes[i].Mismatch = &result.StateRootMismatch{
+				Height:     n.Mismatch.Height,
+				LocalRoot:  n.Mismatch.LocalRoot,
+				RemoteRoot: n.Mismatch.RemoteRoot,
+			}
+		}
+	}
+	return res, nil
+}
+
 func (s *Server) getStorage(ps request.Params) (interface{}, *response.Error) {
 	id, rErr := s.contractIDFromParam(ps.Value(0))
 	if rErr == response.ErrUnknown {
@@ -1343,6 +1929,9 @@ func (s *Server) submitBlock(reqParams request.Params) (interface{}, *response.E
 	if r.Err != nil {
 		return nil, response.NewInvalidParamsError("can't decode block", r.Err)
 	}
+	if s.coreServer.IsBlockProcessingHalted() {
+		return nil, response.NewInternalServerError("block processing is halted because of state mismatch", nil)
+	}
 	err = s.chain.AddBlock(b)
 	if err != nil {
 		switch {
@@ -1427,6 +2016,139 @@ func (s *Server) submitOracleResponse(ps request.Params) (interface{}, *response
 	return json.RawMessage([]byte("{}")), nil
 }
 
+// getOracleStatus returns oracle service state.
+func (s *Server) getOracleStatus(_ request.Params) (interface{}, *response.Error) {
+	if s.oracle == nil {
+		return nil, response.NewInternalServerError("oracle is not enabled", nil)
+	}
+	st := s.oracle.GetStatus()
+	res := result.OracleStatus{
+		Nodes:    st.Nodes,
+		Required: st.Required,
+		Pending:  st.Pending,
+		InFlight: st.InFlight,
+		Cached:   st.Cached,
+		Errors:   make([]result.OracleURLError, len(st.Errors)),
+	}
+	if st.Account != nil {
+		res.Account = address.Uint160ToString(*st.Account)
+	}
+	for i, e := range st.Errors {
+		res.Errors[i] = result.OracleURLError{
+			URL:   e.URL,
+			Code:  e.Code,
+			Error: e.Error,
+			Time:  e.Time.UnixNano() / int64(time.Millisecond),
+			Count: e.Count,
+		}
+	}
+	return res, nil
+}
+
+// getOracleRequests returns the state of requests being processed by oracle
+// service or the state of a single request if its ID is given.
+func (s *Server) getOracleRequests(ps request.Params) (interface{}, *response.Error) {
+	if s.oracle == nil {
+		return nil, response.NewInternalServerError("oracle is not enabled", nil)
+	}
+	var reqs []oracle.RequestStatus
+	if p := ps.Value(0); p != nil {
+		id, err := p.GetInt()
+		if err != nil || id < 0 {
+			return nil, response.NewInvalidParamsError("invalid request ID", err)
+		}
+		st, err := s.oracle.GetRequest(uint64(id))
+		if err != nil {
+			return nil, response.NewRPCError("Unknown request", "", err)
+		}
+		reqs = append(reqs, st)
+	} else {
+		reqs = s.oracle.GetRequests()
+	}
+	res := make([]result.OracleRequest, len(reqs))
+	for i, r := range reqs {
+		res[i] = result.OracleRequest{
+			ID:               r.ID,
+			URL:              r.URL,
+			Attempts:         r.Attempts,
+			Sent:             r.Sent,
+			TxHash:           r.TxHash,
+			BackupTxHash:     r.BackupTxHash,
+			Signatures:       r.Signatures,
+			BackupSignatures: r.BackupSignatures,
+		}
+		if !r.LastProcessed.IsZero() {
+			res[i].LastProcessed = r.LastProcessed.UnixNano() / int64(time.Millisecond)
+		}
+	}
+	return res, nil
+}
+
+// getNotaryRequests returns the state of main transactions being completed by
+// notary service.
+func (s *Server) getNotaryRequests(_ request.Params) (interface{}, *response.Error) {
+	ntr := s.coreServer.GetNotary()
+	if ntr == nil {
+		return nil, response.NewInternalServerError("notary is not enabled", nil)
+	}
+	reqs := ntr.GetRequests()
+	res := make([]result.NotaryRequest, len(reqs))
+	for i, r := range reqs {
+		res[i] = result.NotaryRequest{
+			Main:           r.Main,
+			Type:           r.Type.String(),
+			Sent:           r.Sent,
+			NSigs:          r.NSigs,
+			NSigsCollected: r.NSigsCollected,
+			Witnesses:      make([]result.NotaryWitness, len(r.Witnesses)),
+			Fallbacks:      make([]result.NotaryFallback, len(r.Fallbacks)),
+		}
+		for j, w := range r.Witnesses {
+			res[i].Witnesses[j] = result.NotaryWitness{
+				Account:  w.Account,
+				Contract: w.Contract,
+				Keys:     w.Keys,
+				M:        w.M,
+				Signed:   w.Signed,
+			}
+		}
+		for j, fb := range r.Fallbacks {
+			res[i].Fallbacks[j] = result.NotaryFallback{
+				Hash:           fb.Hash,
+				NotValidBefore: fb.NotValidBefore,
+			}
+		}
+	}
+	return res, nil
+}
+
+// retryOracleRequest schedules oracle request to be processed again.
+func (s *Server) retryOracleRequest(ps request.Params) (interface{}, *response.Error) {
+	return s.controlOracleRequest(ps, func(id uint64) error { return s.oracle.RetryRequest(id) })
+}
+
+// skipOracleRequest makes oracle service send failed response for the request.
+func (s *Server) skipOracleRequest(ps request.Params) (interface{}, *response.Error) {
+	return s.controlOracleRequest(ps, func(id uint64) error { return s.oracle.SkipRequest(id) })
+}
+
+func (s *Server) controlOracleRequest(ps request.Params, f func(uint64) error) (interface{}, *response.Error) {
+	if !s.config.EnableOracleControl {
+		return nil, response.NewInternalServerError("oracle control is disabled", nil)
+	}
+	if s.oracle == nil {
+		return nil, response.NewInternalServerError("oracle is not enabled", nil)
+	}
+	id, err := ps.Value(0).GetInt()
+	if err != nil || id < 0 {
+		return nil, response.NewInvalidParamsError("invalid request ID", err)
+	}
+	if err := f(uint64(id)); err != nil {
+		return nil, response.NewRPCError("Can't process request", "", err)
+	}
+	return true, nil
+}
+
 func (s *Server) sendrawtransaction(reqParams request.Params) (interface{}, *response.Error) {
 	if len(reqParams) < 1 {
 		return nil, response.NewInvalidParamsError("not enough parameters", nil)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -19,15 +20,19 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/nspcc-dev/neo-go/internal/testchain"
 	"github.com/nspcc-dev/neo-go/internal/testserdes"
+	"github.com/nspcc-dev/neo-go/pkg/config"
 	"github.com/nspcc-dev/neo-go/pkg/core"
 	"github.com/nspcc-dev/neo-go/pkg/core/block"
 	"github.com/nspcc-dev/neo-go/pkg/core/fee"
+	"github.com/nspcc-dev/neo-go/pkg/core/mpt"
+	"github.com/nspcc-dev/neo-go/pkg/core/native/nativenames"
 	"github.com/nspcc-dev/neo-go/pkg/core/state"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neo-go/pkg/encoding/address"
 	"github.com/nspcc-dev/neo-go/pkg/io"
 	"github.com/nspcc-dev/neo-go/pkg/network/payload"
+	"github.com/nspcc-dev/neo-go/pkg/rpc"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response"
 	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
 	rpc2 "github.com/nspcc-dev/neo-go/pkg/services/oracle/broadcaster"
@@ -65,6 +70,70 @@ const invokescriptContractAVM = "VwIADBQBDAMOBQYMDQIODw0DDgcJAAAAANswcGhB+CfsjCG
 const nameServiceContractHash = "66206eb850818ec862a9332e0da10b9b7826cb0b"
 
 var rpcTestCases = map[string][]rpcTestCase{
+	"findnotifications": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid contract",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid name",
+			params: `["` + testContractHash + `", 1]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid start height",
+			params: `["` + testContractHash + `", "Transfer", "-1"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid limit",
+			params: `["` + testContractHash + `", "Transfer", "1", "2", "0"]`,
+			fail:   true,
+		},
+		{
+			name:   "index is disabled",
+			params: `["` + testContractHash + `", "Transfer"]`,
+			fail:   true,
+		},
+	},
+	"getaccounttransactions": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid address",
+			params: `["notahex"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid limit",
+			params: `["` + testchain.PrivateKeyByID(0).Address() + `", "1", "2", "0"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid start height",
+			params: `["` + testchain.PrivateKeyByID(0).Address() + `", "1", "2", "3", "0", "-1"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid stop height",
+			params: `["` + testchain.PrivateKeyByID(0).Address() + `", "1", "2", "3", "0", "1", "blah"]`,
+			fail:   true,
+		},
+		{
+			name:   "index is disabled",
+			params: `["` + testchain.PrivateKeyByID(0).Address() + `"]`,
+			fail:   true,
+		},
+	},
 	"getapplicationlog": {
 		{
 			name:   "positive",
@@ -315,6 +384,112 @@ var rpcTestCases = map[string][]rpcTestCase{
 			fail:   true,
 		},
 	},
+	"getstate": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid root",
+			params: `["0xabcdef"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid contract",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "0xabcdef"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid key",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "` + testContractHash + `", "notabase64%"]`,
+			fail:   true,
+		},
+		{
+			name:   "unknown root",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "` + testContractHash + `", "QQ=="]`,
+			fail:   true,
+		},
+	},
+	"findstates": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid root",
+			params: `["0xabcdef"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid contract",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "0xabcdef"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid prefix",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "` + testContractHash + `", "notabase64%"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid key",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "` + testContractHash + `", "QQ==", "notabase64%"]`,
+			fail:   true,
+		},
+		{
+			name:   "key doesn't match prefix",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "` + testContractHash + `", "QQ==", "Qg=="]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid count",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "` + testContractHash + `", "QQ==", "", 0]`,
+			fail:   true,
+		},
+		{
+			name:   "unknown root",
+			params: `["0000000000000000000000000000000000000000000000000000000000000000", "` + testContractHash + `", "QQ=="]`,
+			fail:   true,
+		},
+	},
+	"getstatediff": {
+		{
+			name:   "no params",
+			params: `[]`,
+			fail:   true,
+		},
+		{
+			name:   "no new root",
+			params: `[1]`,
+			fail:   true,
+		},
+		{
+			name:   "unknown height",
+			params: `[1, 100500]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid root",
+			params: `[1, "0xabcdef"]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid limit",
+			params: `[1, 2, 0]`,
+			fail:   true,
+		},
+		{
+			name:   "too big limit",
+			params: `[1, 2, 1001]`,
+			fail:   true,
+		},
+		{
+			name:   "invalid token",
+			params: `[1, 2, 10, "not a base64"]`,
+			fail:   true,
+		},
+	},
 	"getstateheight": {
 		{
 			name:   "positive",
@@ -542,6 +717,37 @@ var rpcTestCases = map[string][]rpcTestCase{
 			},
 		},
 	},
+	"estimatefee": {
+		{
+			name:   "default target",
+			params: "[]",
+			result: func(*executor) interface{} {
+				return &result.FeeEstimate{Target: 1}
+			},
+		},
+		{
+			name:   "custom target",
+			params: "[5]",
+			result: func(*executor) interface{} {
+				return &result.FeeEstimate{Target: 5}
+			},
+		},
+		{
+			name:   "zero target",
+			params: "[0]",
+			fail:   true,
+		},
+		{
+			name:   "too big target",
+			params: "[21]",
+			fail:   true,
+		},
+		{
+			name:   "invalid target",
+			params: `["one"]`,
+			fail:   true,
+		},
+	},
 	"getconnectioncount": {
 		{
 			params: "[]",
@@ -576,6 +782,7 @@ var rpcTestCases = map[string][]rpcTestCase{
 					Unconnected: []result.Peer{},
 					Connected:   []result.Peer{},
 					Bad:         []result.Peer{},
+					Banned:      []result.BannedPeer{},
 				}
 			},
 		},
@@ -1041,6 +1248,277 @@ func TestSubmitOracle(t *testing.T) {
 	t.Run("Valid", runCase(t, false, pubStr, `1`, txSigStr, msgSigStr))
 }
 
+func TestOracleStatus(t *testing.T) {
+	rpc := `{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": %s}`
+
+	t.Run("disabled oracle", func(t *testing.T) {
+		chain, rpcSrv, httpSrv := initClearServerWithServices(t, false, false)
+		defer chain.Close()
+		defer func() { _ = rpcSrv.Shutdown() }()
+		rpcSrv.config.EnableOracleControl = true
+		for _, method := range []string{"getoraclestatus", "getoraclerequests"} {
+			body := doRPCCallOverHTTP(fmt.Sprintf(rpc, method, `[]`), httpSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		}
+		adminSrv := httptest.NewServer(http.HandlerFunc(rpcSrv.handleAdminHTTPRequest))
+		defer adminSrv.Close()
+		for _, method := range []string{"retryoraclerequest", "skiporaclerequest"} {
+			body := doRPCCallOverHTTP(fmt.Sprintf(rpc, method, `[1]`), adminSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		}
+	})
+
+	chain, rpcSrv, httpSrv := initClearServerWithServices(t, true, false)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	body := doRPCCallOverHTTP(fmt.Sprintf(rpc, "getoraclestatus", `[]`), httpSrv.URL, t)
+	res := checkErrGetResult(t, body, false)
+	var st result.OracleStatus
+	require.NoError(t, json.Unmarshal(res, &st))
+	require.Equal(t, 0, st.Pending)
+	require.Equal(t, 0, len(st.InFlight))
+	require.Equal(t, 0, len(st.Errors))
+
+	body = doRPCCallOverHTTP(fmt.Sprintf(rpc, "getoraclerequests", `[]`), httpSrv.URL, t)
+	res = checkErrGetResult(t, body, false)
+	var reqs []result.OracleRequest
+	require.NoError(t, json.Unmarshal(res, &reqs))
+	require.Equal(t, 0, len(reqs))
+
+	for _, ps := range []string{`[1]`, `["bad"]`, `[-1]`} {
+		body = doRPCCallOverHTTP(fmt.Sprintf(rpc, "getoraclerequests", ps), httpSrv.URL, t)
+		checkErrGetResult(t, body, true)
+	}
+
+	adminSrv := httptest.NewServer(http.HandlerFunc(rpcSrv.handleAdminHTTPRequest))
+	defer adminSrv.Close()
+
+	t.Run("control disabled", func(t *testing.T) {
+		for _, method := range []string{"retryoraclerequest", "skiporaclerequest"} {
+			body := doRPCCallOverHTTP(fmt.Sprintf(rpc, method, `[1]`), adminSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		}
+	})
+
+	rpcSrv.config.EnableOracleControl = true
+	t.Run("regular endpoint", func(t *testing.T) {
+		for _, method := range []string{"retryoraclerequest", "skiporaclerequest"} {
+			body := doRPCCallOverHTTP(fmt.Sprintf(rpc, method, `[1]`), httpSrv.URL, t)
+			var resp response.Raw
+			require.NoError(t, json.Unmarshal(body, &resp))
+			require.NotNil(t, resp.Error)
+			require.Equal(t, response.NewMethodNotFoundError("", nil).Code, resp.Error.Code)
+		}
+	})
+	for _, method := range []string{"retryoraclerequest", "skiporaclerequest"} {
+		for _, ps := range []string{`[]`, `["bad"]`, `[-1]`, `[1]`} {
+			body := doRPCCallOverHTTP(fmt.Sprintf(rpc, method, ps), adminSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		}
+	}
+	// Regular methods are available via admin endpoint too.
+	body = doRPCCallOverHTTP(fmt.Sprintf(rpc, "getoraclestatus", `[]`), adminSrv.URL, t)
+	checkErrGetResult(t, body, false)
+}
+
+func TestGetNotaryRequests(t *testing.T) {
+	rpc := `{"jsonrpc": "2.0", "id": 1, "method": "getnotaryrequests", "params": []}`
+
+	t.Run("disabled notary", func(t *testing.T) {
+		chain, rpcSrv, httpSrv := initClearServerWithServices(t, false, false)
+		defer chain.Close()
+		defer func() { _ = rpcSrv.Shutdown() }()
+		body := doRPCCallOverHTTP(rpc, httpSrv.URL, t)
+		checkErrGetResult(t, body, true)
+	})
+
+	chain, rpcSrv, httpSrv := initClearServerWithServices(t, false, true)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	body := doRPCCallOverHTTP(rpc, httpSrv.URL, t)
+	res := checkErrGetResult(t, body, false)
+	var reqs []result.NotaryRequest
+	require.NoError(t, json.Unmarshal(res, &reqs))
+	require.Equal(t, 0, len(reqs))
+}
+
+func TestGetAccountTransactions(t *testing.T) {
+	chain, rpcSrv, httpSrv := initClearServerWithCustomConfig(t, false, false, func(cfg *config.Config) {
+		cfg.ApplicationConfiguration.AccountTransactionsIndex = true
+	})
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	acc := testchain.MultisigScriptHash()
+	var expected []result.AccountTransaction
+	for _, b := range getTestBlocks(t) {
+		require.NoError(t, chain.AddBlock(b))
+		for _, tx := range b.Transactions {
+			for _, s := range tx.Signers {
+				if s.Account.Equals(acc) {
+					expected = append([]result.AccountTransaction{{
+						Timestamp: b.Timestamp,
+						Index:     b.Index,
+						TxHash:    tx.Hash(),
+					}}, expected...)
+				}
+			}
+		}
+	}
+	require.True(t, len(expected) > 4)
+
+	addr := address.Uint160ToString(acc)
+	check := func(t *testing.T, params string, expected []result.AccountTransaction) {
+		rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getaccounttransactions", "params": ["%s", %s]}`, addr, params)
+		body := doRPCCallOverHTTP(rpc, httpSrv.URL, t)
+		res := checkErrGetResult(t, body, false)
+		actual := new(result.AccountTransactions)
+		require.NoError(t, json.Unmarshal(res, actual))
+		require.Equal(t, addr, actual.Address)
+		if len(expected) == 0 {
+			expected = []result.AccountTransaction{}
+		}
+		require.Equal(t, expected, actual.Transactions)
+	}
+
+	const maxTimestamp = 1 << 50
+	t.Run("all", func(t *testing.T) {
+		check(t, fmt.Sprintf("0, %d", maxTimestamp), expected)
+	})
+	t.Run("paging", func(t *testing.T) {
+		check(t, fmt.Sprintf("0, %d, 2, 0", maxTimestamp), expected[:2])
+		check(t, fmt.Sprintf("0, %d, 2, 1", maxTimestamp), expected[2:4])
+		check(t, fmt.Sprintf("0, %d, 2, %d", maxTimestamp, len(expected)), nil)
+	})
+	t.Run("timestamps", func(t *testing.T) {
+		start, end := expected[3].Timestamp, expected[1].Timestamp
+		var exp []result.AccountTransaction
+		for _, tr := range expected {
+			if tr.Timestamp >= start && tr.Timestamp <= end {
+				exp = append(exp, tr)
+			}
+		}
+		check(t, fmt.Sprintf("%d, %d", start, end), exp)
+		check(t, fmt.Sprintf("%d, %d, 1, 1", start, end), exp[1:2])
+	})
+	t.Run("heights", func(t *testing.T) {
+		start, end := expected[3].Index, expected[1].Index
+		var exp []result.AccountTransaction
+		for _, tr := range expected {
+			if tr.Index >= start && tr.Index <= end {
+				exp = append(exp, tr)
+			}
+		}
+		check(t, fmt.Sprintf("0, %d, 100, 0, %d, %d", maxTimestamp, start, end), exp)
+	})
+}
+
+func TestFindNotifications(t *testing.T) {
+	chain, rpcSrv, httpSrv := initClearServerWithCustomConfig(t, false, false, func(cfg *config.Config) {
+		cfg.ApplicationConfiguration.NotificationsIndex = true
+	})
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	gas, err := chain.GetNativeContractScriptHash(nativenames.Gas)
+	require.NoError(t, err)
+	var expected []result.Notification
+	addExpected := func(t *testing.T, b *block.Block, h util.Uint256, trig trigger.Type) {
+		aers, err := chain.GetAppExecResults(h, trig)
+		require.NoError(t, err)
+		if aers[0].VMState != vm.HaltState {
+			return
+		}
+		for i, ev := range aers[0].Events {
+			if ev.ScriptHash.Equals(gas) && ev.Name == "Transfer" {
+				expected = append([]result.Notification{{
+					Container:   h,
+					BlockIndex:  b.Index,
+					NotifyIndex: uint32(i),
+				}}, expected...)
+			}
+		}
+	}
+	for _, b := range getTestBlocks(t) {
+		require.NoError(t, chain.AddBlock(b))
+		addExpected(t, b, b.Hash(), trigger.OnPersist)
+		for _, tx := range b.Transactions {
+			addExpected(t, b, tx.Hash(), trigger.Application)
+		}
+		addExpected(t, b, b.Hash(), trigger.PostPersist)
+	}
+	require.True(t, len(expected) > 4)
+
+	// Genesis block notifications are excluded by the start height.
+	check := func(t *testing.T, params string, expected []result.Notification) {
+		rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "findnotifications", "params": ["%s", "Transfer", 1, %s]}`, gas.StringLE(), params)
+		body := doRPCCallOverHTTP(rpc, httpSrv.URL, t)
+		res := checkErrGetResult(t, body, false)
+		actual := new(result.Notifications)
+		require.NoError(t, json.Unmarshal(res, actual))
+		require.Equal(t, len(expected), len(actual.Notifications))
+		for i := range expected {
+			n := actual.Notifications[i]
+			require.Equal(t, expected[i].Container, n.Container)
+			require.Equal(t, expected[i].BlockIndex, n.BlockIndex)
+			require.Equal(t, expected[i].NotifyIndex, n.NotifyIndex)
+			require.Equal(t, gas, n.Event.ScriptHash)
+			require.Equal(t, "Transfer", n.Event.Name)
+		}
+	}
+
+	height := chain.BlockHeight()
+	t.Run("all", func(t *testing.T) {
+		check(t, fmt.Sprintf("%d, 1000", height), expected)
+	})
+	t.Run("paging", func(t *testing.T) {
+		check(t, fmt.Sprintf("%d, 2, 0", height), expected[:2])
+		check(t, fmt.Sprintf("%d, 2, 1", height), expected[2:4])
+		check(t, fmt.Sprintf("%d, 2, %d", height, len(expected)), nil)
+	})
+	t.Run("heights", func(t *testing.T) {
+		start, end := expected[len(expected)-1].BlockIndex+1, expected[0].BlockIndex-1
+		var exp []result.Notification
+		for _, n := range expected {
+			if n.BlockIndex >= start && n.BlockIndex <= end {
+				exp = append(exp, n)
+			}
+		}
+		rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "findnotifications", "params": ["%s", "Transfer", %d, %d, 1000]}`, gas.StringLE(), start, end)
+		body := doRPCCallOverHTTP(rpc, httpSrv.URL, t)
+		res := checkErrGetResult(t, body, false)
+		actual := new(result.Notifications)
+		require.NoError(t, json.Unmarshal(res, actual))
+		require.Equal(t, len(exp), len(actual.Notifications))
+		for i := range exp {
+			require.Equal(t, exp[i].Container, actual.Notifications[i].Container)
+			require.Equal(t, exp[i].NotifyIndex, actual.Notifications[i].NotifyIndex)
+		}
+	})
+}
+
+func TestGetStateRootVerification(t *testing.T) {
+	chain, rpcSrv, httpSrv := initClearServerWithServices(t, false, false)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	rpc := `{"jsonrpc": "2.0", "id": 1, "method": "getstaterootverification", "params": []}`
+	body := doRPCCallOverHTTP(rpc, httpSrv.URL, t)
+	checkErrGetResult(t, body, true)
+}
+
+func TestGetConsensusState(t *testing.T) {
+	chain, rpcSrv, httpSrv := initClearServerWithServices(t, false, false)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+
+	rpc := `{"jsonrpc": "2.0", "id": 1, "method": "getconsensusstate", "params": []}`
+	body := doRPCCallOverHTTP(rpc, httpSrv.URL, t)
+	checkErrGetResult(t, body, true)
+}
+
 func TestSubmitNotaryRequest(t *testing.T) {
 	rpc := `{"jsonrpc": "2.0", "id": 1, "method": "submitnotaryrequest", "params": %s}`
 
@@ -1357,6 +1835,144 @@ func testRPCProtocol(t *testing.T, doRPCCall func(string, string, *testing.T) []
 		require.NoError(t, json.Unmarshal(rawRes, vp))
 		require.Equal(t, []byte("testvalue"), vp.Value)
 	})
+	t.Run("getstate", func(t *testing.T) {
+		r, err := chain.GetStateModule().GetStateRoot(chain.BlockHeight())
+		require.NoError(t, err)
+		rpc := `{"jsonrpc": "2.0", "id": 1, "method": "getstate", "params": ["%s", "%s", "%s"]}`
+
+		body := doRPCCall(fmt.Sprintf(rpc, r.Root.StringLE(), testContractHash,
+			base64.StdEncoding.EncodeToString([]byte("testkey"))), httpSrv.URL, t)
+		rawRes := checkErrGetResult(t, body, false)
+		var val []byte
+		require.NoError(t, json.Unmarshal(rawRes, &val))
+		require.Equal(t, []byte("testvalue"), val)
+
+		t.Run("unknown key", func(t *testing.T) {
+			body := doRPCCall(fmt.Sprintf(rpc, r.Root.StringLE(), testContractHash,
+				base64.StdEncoding.EncodeToString([]byte("unknownkey"))), httpSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		})
+		t.Run("unknown contract", func(t *testing.T) {
+			body := doRPCCall(fmt.Sprintf(rpc, r.Root.StringLE(), util.Uint160{1, 2, 3}.StringLE(),
+				base64.StdEncoding.EncodeToString([]byte("testkey"))), httpSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		})
+		t.Run("contract is not deployed yet", func(t *testing.T) {
+			r, err := chain.GetStateModule().GetStateRoot(0)
+			require.NoError(t, err)
+			body := doRPCCall(fmt.Sprintf(rpc, r.Root.StringLE(), testContractHash,
+				base64.StdEncoding.EncodeToString([]byte("testkey"))), httpSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		})
+	})
+	t.Run("findstates", func(t *testing.T) {
+		r, err := chain.GetStateModule().GetStateRoot(chain.BlockHeight())
+		require.NoError(t, err)
+		findStates := func(t *testing.T, params string) *result.FindStates {
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "findstates", "params": ["%s", "%s", %s]}`,
+				r.Root.StringLE(), testContractHash, params)
+			body := doRPCCall(rpc, httpSrv.URL, t)
+			rawRes := checkErrGetResult(t, body, false)
+			res := new(result.FindStates)
+			require.NoError(t, json.Unmarshal(rawRes, res))
+			return res
+		}
+		h, _ := util.Uint160DecodeStringLE(testContractHash)
+		id := chain.GetContractState(h).ID
+		checkProof := func(t *testing.T, proof *result.ProofWithKey, kv result.KeyValue) {
+			require.NotNil(t, proof)
+			require.Equal(t, makeStorageKey(id, kv.Key), proof.Key)
+			val, ok := mpt.VerifyProof(r.Root, proof.Key, proof.Proof)
+			require.True(t, ok)
+			require.Equal(t, kv.Value, val)
+		}
+
+		full := findStates(t, `""`)
+		require.False(t, full.Truncated)
+		require.True(t, len(full.Results) > 1)
+		checkProof(t, full.FirstProof, full.Results[0])
+		checkProof(t, full.LastProof, full.Results[len(full.Results)-1])
+
+		prefixed := findStates(t, `"`+base64.StdEncoding.EncodeToString([]byte("testkey"))+`"`)
+		require.Equal(t, result.KeyValue{Key: []byte("testkey"), Value: []byte("testvalue")}, prefixed.Results[0])
+
+		t.Run("paging", func(t *testing.T) {
+			first := findStates(t, `"", "", 1`)
+			require.True(t, first.Truncated)
+			require.Equal(t, full.Results[:1], first.Results)
+			checkProof(t, first.FirstProof, first.Results[0])
+			require.Nil(t, first.LastProof)
+
+			next := findStates(t, `"", "`+base64.StdEncoding.EncodeToString(first.Results[0].Key)+`"`)
+			require.False(t, next.Truncated)
+			require.Equal(t, full.Results[1:], next.Results)
+		})
+		t.Run("empty", func(t *testing.T) {
+			prefix := []byte("unknownprefix")
+			res := findStates(t, `"`+base64.StdEncoding.EncodeToString(prefix)+`"`)
+			require.False(t, res.Truncated)
+			require.Equal(t, 0, len(res.Results))
+			require.Nil(t, res.LastProof)
+			require.NotNil(t, res.FirstProof)
+			require.Equal(t, makeStorageKey(id, prefix), res.FirstProof.Key)
+			require.NotEqual(t, 0, len(res.FirstProof.Proof))
+			_, ok := mpt.VerifyProof(r.Root, res.FirstProof.Key, res.FirstProof.Proof)
+			require.False(t, ok)
+
+			last := full.Results[len(full.Results)-1]
+			res = findStates(t, `"", "`+base64.StdEncoding.EncodeToString(last.Key)+`"`)
+			require.Equal(t, 0, len(res.Results))
+			checkProof(t, res.FirstProof, last)
+		})
+	})
+	t.Run("getstatediff", func(t *testing.T) {
+		getDiff := func(t *testing.T, params string) *result.StateDiff {
+			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getstatediff", "params": [%s]}`, params)
+			body := doRPCCall(rpc, httpSrv.URL, t)
+			rawRes := checkErrGetResult(t, body, false)
+			res := new(result.StateDiff)
+			require.NoError(t, json.Unmarshal(rawRes, res))
+			return res
+		}
+		t.Run("same root", func(t *testing.T) {
+			res := getDiff(t, "5, 5")
+			require.Equal(t, 0, len(res.Contracts))
+			require.Nil(t, res.Next)
+		})
+
+		full := getDiff(t, fmt.Sprintf("1, %d", chain.BlockHeight()))
+		require.True(t, len(full.Contracts) > 0)
+		require.Nil(t, full.Next)
+
+		r, err := chain.GetStateModule().GetStateRoot(chain.BlockHeight())
+		require.NoError(t, err)
+		byHash := getDiff(t, fmt.Sprintf(`1, "%s"`, r.Root.StringLE()))
+		require.Equal(t, full, byHash)
+
+		// Collect the same changes one by one.
+		var (
+			items []result.StateDiffItem
+			next  string
+		)
+		for {
+			res := getDiff(t, fmt.Sprintf("1, %d, 1%s", chain.BlockHeight(), next))
+			require.Equal(t, 1, len(res.Contracts))
+			c := res.Contracts[0]
+			require.Equal(t, 1, len(c.Added)+len(c.Modified)+len(c.Deleted))
+			items = append(items, c.Added...)
+			items = append(items, c.Modified...)
+			items = append(items, c.Deleted...)
+			if res.Next == nil {
+				break
+			}
+			next = `, "` + base64.StdEncoding.EncodeToString(res.Next) + `"`
+		}
+		var expected int
+		for _, c := range full.Contracts {
+			expected += len(c.Added) + len(c.Modified) + len(c.Deleted)
+		}
+		require.Equal(t, expected, len(items))
+	})
 	t.Run("getstateroot", func(t *testing.T) {
 		testRoot := func(t *testing.T, p string) {
 			rpc := fmt.Sprintf(`{"jsonrpc": "2.0", "id": 1, "method": "getstateroot", "params": [%s]}`, p)
@@ -1498,6 +2114,22 @@ func testRPCProtocol(t *testing.T, doRPCCall func(string, string, *testing.T) []
 		assert.ElementsMatch(t, expected, actual)
 	})
 
+	t.Run("getmempoolinfo", func(t *testing.T) {
+		mp := chain.GetMemPool()
+		rpc := `{"jsonrpc": "2.0", "id": 1, "method": "getmempoolinfo", "params": []}`
+		body := doRPCCall(rpc, httpSrv.URL, t)
+		res := checkErrGetResult(t, body, false)
+
+		var actual result.MempoolInfo
+		require.NoErrorf(t, json.Unmarshal(res, &actual), "could not parse response: %s", res)
+		require.Equal(t, chain.BlockHeight(), actual.Height)
+		require.Equal(t, mp.Count(), actual.Count)
+		require.Equal(t, mp.GetStats().Size, actual.Size)
+		require.Equal(t, chain.FeePerByte(), actual.MinFeePerByte)
+		require.Equal(t, int(chain.GetConfig().MaxTransactionsPerBlock), actual.MaxTransactionsPerBlock)
+		require.Equal(t, 1, actual.BlocksToClear)
+	})
+
 	t.Run("getnep17transfers", func(t *testing.T) {
 		testNEP17T := func(t *testing.T, start, stop, limit, page int, sent, rcvd []int) {
 			ps := []string{`"` + testchain.PrivateKeyByID(0).Address() + `"`}
@@ -1570,6 +2202,80 @@ func (tc rpcTestCase) getResultPair(e *executor) (expected interface{}, res inte
 	return expected, res
 }
 
+func TestAdminAddress(t *testing.T) {
+	chain, rpcSrv, httpSrv := initClearServerWithCustomConfig(t, false, false, func(cfg *config.Config) {
+		cfg.ApplicationConfiguration.RPC.AdminConfig = rpc.AdminConfig{Enabled: true}
+	})
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+	defer httpSrv.Close()
+
+	host, _, err := net.SplitHostPort(rpcSrv.AdminAddress())
+	require.NoError(t, err)
+	require.Equal(t, rpc.DefaultAdminAddress, host)
+}
+
+func TestBanPeer(t *testing.T) {
+	rpc := `{"jsonrpc": "2.0", "id": 1, "method": "%s", "params": %s}`
+	chain, rpcSrv, httpSrv := initClearServerWithServices(t, false, false)
+	defer chain.Close()
+	defer func() { _ = rpcSrv.Shutdown() }()
+	adminSrv := httptest.NewServer(http.HandlerFunc(rpcSrv.handleAdminHTTPRequest))
+	defer adminSrv.Close()
+
+	t.Run("disabled", func(t *testing.T) {
+		body := doRPCCallOverHTTP(fmt.Sprintf(rpc, "banpeer", `["1.2.3.4"]`), adminSrv.URL, t)
+		checkErrGetResult(t, body, true)
+		body = doRPCCallOverHTTP(fmt.Sprintf(rpc, "unbanpeer", `["1.2.3.4"]`), adminSrv.URL, t)
+		checkErrGetResult(t, body, true)
+	})
+
+	rpcSrv.config.EnablePeerManagement = true
+	t.Run("public endpoint", func(t *testing.T) {
+		for _, method := range []string{"banpeer", "unbanpeer"} {
+			body := doRPCCallOverHTTP(fmt.Sprintf(rpc, method, `["1.2.3.4"]`), httpSrv.URL, t)
+			var resp response.Raw
+			require.NoError(t, json.Unmarshal(body, &resp))
+			require.NotNil(t, resp.Error)
+			require.Equal(t, int64(-32601), resp.Error.Code)
+		}
+		require.Equal(t, 0, len(rpcSrv.coreServer.BannedPeers()))
+	})
+	t.Run("invalid params", func(t *testing.T) {
+		for _, ps := range []string{`[]`, `[1]`, `["notanip"]`, `["1.2.3.4", "-1"]`, `["1.2.3.4", "bad"]`} {
+			body := doRPCCallOverHTTP(fmt.Sprintf(rpc, "banpeer", ps), adminSrv.URL, t)
+			checkErrGetResult(t, body, true)
+		}
+		body := doRPCCallOverHTTP(fmt.Sprintf(rpc, "unbanpeer", `[]`), adminSrv.URL, t)
+		checkErrGetResult(t, body, true)
+	})
+
+	getBanned := func(t *testing.T) []result.BannedPeer {
+		body := doRPCCallOverHTTP(fmt.Sprintf(rpc, "getpeers", `[]`), httpSrv.URL, t)
+		res := checkErrGetResult(t, body, false)
+		var peers result.GetPeers
+		require.NoError(t, json.Unmarshal(res, &peers))
+		return peers.Banned
+	}
+
+	body := doRPCCallOverHTTP(fmt.Sprintf(rpc, "banpeer", `["1.2.3.4:20333", 3600]`), adminSrv.URL, t)
+	res := checkErrGetResult(t, body, false)
+	require.Equal(t, "true", string(res))
+	banned := getBanned(t)
+	require.Equal(t, 1, len(banned))
+	require.Equal(t, "1.2.3.4", banned[0].Address)
+	require.True(t, banned[0].Until > time.Now().UnixNano()/int64(time.Millisecond))
+
+	body = doRPCCallOverHTTP(fmt.Sprintf(rpc, "unbanpeer", `["1.2.3.4"]`), adminSrv.URL, t)
+	res = checkErrGetResult(t, body, false)
+	require.Equal(t, "true", string(res))
+	require.Equal(t, 0, len(getBanned(t)))
+
+	body = doRPCCallOverHTTP(fmt.Sprintf(rpc, "unbanpeer", `["1.2.3.4"]`), adminSrv.URL, t)
+	res = checkErrGetResult(t, body, false)
+	require.Equal(t, "false", string(res))
+}
+
 func checkErrGetResult(t *testing.T, body []byte, expectingFail bool) json.RawMessage {
 	var resp response.Raw
 	err := json.Unmarshal(body, &resp)
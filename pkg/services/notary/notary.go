@@ -181,6 +181,7 @@ func (n *Notary) OnNewRequest(payload *payload.P2PNotaryRequest) {
 			minNotValidBefore: nvbFallback,
 		}
 		n.requests[payload.MainTransaction.Hash()] = r
+		setPendingRequestsMetric(len(n.requests))
 	}
 	r.fallbacks = append(r.fallbacks, payload.FallbackTransaction)
 	if exists && r.typ != Unknown && r.nSigsCollected >= r.nSigs { // already collected sufficient number of signatures to complete main transaction
@@ -240,6 +241,7 @@ func (n *Notary) OnNewRequest(payload *payload.P2PNotaryRequest) {
 			n.Config.Log.Error("failed to finalize main transaction", zap.Error(err))
 		} else {
 			r.isSent = true
+			addCompletedRequestsMetric()
 		}
 	}
 }
@@ -265,6 +267,7 @@ func (n *Notary) OnRequestRemoval(pld *payload.P2PNotaryRequest) {
 	}
 	if len(r.fallbacks) == 0 {
 		delete(n.requests, r.main.Hash())
+		setPendingRequestsMetric(len(n.requests))
 	}
 }
 
@@ -284,6 +287,7 @@ func (n *Notary) PostPersist() {
 				n.Config.Log.Error("failed to finalize main transaction", zap.Error(err))
 			} else {
 				r.isSent = true
+				addCompletedRequestsMetric()
 			}
 			continue
 		}
@@ -293,6 +297,8 @@ func (n *Notary) PostPersist() {
 				if nvb := fb.GetAttributes(transaction.NotValidBeforeT)[0].Value.(*transaction.NotValidBefore).Height; nvb <= currHeight {
 					if err := n.finalize(fb); err != nil {
 						newFallbacks = append(newFallbacks, fb) // wait for the next block to resend them
+					} else {
+						addFinalizedFallbacksMetric()
 					}
 				} else {
 					newFallbacks = append(newFallbacks, fb)
@@ -305,6 +311,7 @@ func (n *Notary) PostPersist() {
 			}
 		}
 	}
+	setPendingRequestsMetric(len(n.requests))
 }
 
 // finalize adds missing Notary witnesses to the transaction (main or fallback) and pushes it to the network.
@@ -0,0 +1,47 @@
+package notary
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	pendingRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of main transactions being completed by notary service",
+			Name:      "notary_pending_requests",
+			Namespace: "neogo",
+		},
+	)
+	completedRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of completed main transactions sent by notary service",
+			Name:      "notary_completed_requests",
+			Namespace: "neogo",
+		},
+	)
+	finalizedFallbacks = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of fallback transactions sent by notary service",
+			Name:      "notary_finalized_fallbacks",
+			Namespace: "neogo",
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		pendingRequests,
+		completedRequests,
+		finalizedFallbacks,
+	)
+}
+
+func setPendingRequestsMetric(n int) {
+	pendingRequests.Set(float64(n))
+}
+
+func addCompletedRequestsMetric() {
+	completedRequests.Inc()
+}
+
+func addFinalizedFallbacksMetric() {
+	finalizedFallbacks.Inc()
+}
@@ -11,3 +11,15 @@ const (
 	// MultiSignature represents m out of n multisignature request type.
 	MultiSignature RequestType = 0x02
 )
+
+// String implements fmt.Stringer interface.
+func (t RequestType) String() string {
+	switch t {
+	case Signature:
+		return "signature"
+	case MultiSignature:
+		return "multisignature"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,127 @@
+package notary
+
+import (
+	"crypto/elliptic"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/nspcc-dev/neo-go/pkg/vm"
+)
+
+type (
+	// RequestStatus is the state of the main transaction being completed by
+	// the Notary module.
+	RequestStatus struct {
+		// Main is a copy of the main transaction with witnesses collected
+		// so far.
+		Main *transaction.Transaction
+		Type RequestType
+		// Sent is true if completed main transaction was already sent.
+		Sent bool
+		// NSigs is the number of signatures to be collected, it's 0 for
+		// requests of Unknown type.
+		NSigs int
+		// NSigsCollected is the number of signatures already collected.
+		NSigsCollected int
+		// Witnesses contains the state of main transaction witnesses except
+		// the Notary contract one.
+		Witnesses []WitnessStatus
+		// Fallbacks are the fallback transactions received for the request.
+		Fallbacks []FallbackStatus
+	}
+
+	// WitnessStatus is the state of a single main transaction witness.
+	WitnessStatus struct {
+		Account util.Uint160
+		// Contract is true if the witness is checked by the contract (the
+		// verification script is empty).
+		Contract bool
+		// Keys are the keys required for the witness (one key for standard
+		// signature and n keys for m out of n multisignature).
+		Keys keys.PublicKeys
+		// M is the number of signatures required for the witness.
+		M int
+		// Signed are the keys which signatures were already collected.
+		Signed keys.PublicKeys
+	}
+
+	// FallbackStatus is the fallback transaction received for the request.
+	FallbackStatus struct {
+		Hash           util.Uint256
+		NotValidBefore uint32
+	}
+)
+
+// GetRequests returns the state of requests being processed by the Notary
+// module ordered by main transaction hash.
+func (n *Notary) GetRequests() []RequestStatus {
+	n.reqMtx.RLock()
+	res := make([]RequestStatus, 0, len(n.requests))
+	for _, r := range n.requests {
+		res = append(res, n.requestStatus(r))
+	}
+	n.reqMtx.RUnlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].Main.Hash().CompareTo(res[j].Main.Hash()) < 0 })
+	return res
+}
+
+// requestStatus returns the state of r, it must be called under reqMtx.
+func (n *Notary) requestStatus(r *request) RequestStatus {
+	// Witnesses of the main transaction are replaced when signatures are
+	// collected, so its copy is returned.
+	main, err := updateTxSize(r.main)
+	if err != nil { // unreachable, main transaction was decoded from the payload
+		main = r.main
+	}
+	s := RequestStatus{
+		Main:           main,
+		Type:           r.typ,
+		Sent:           r.isSent,
+		NSigs:          int(r.nSigs),
+		NSigsCollected: int(r.nSigsCollected),
+		Fallbacks:      make([]FallbackStatus, len(r.fallbacks)),
+	}
+	notaryHash := n.Config.Chain.GetNotaryContractScriptHash()
+	for i, w := range r.main.Scripts {
+		if i >= len(r.main.Signers) || r.main.Signers[i].Account.Equals(notaryHash) {
+			continue
+		}
+		ws := WitnessStatus{Account: r.main.Signers[i].Account}
+		if len(w.VerificationScript) == 0 {
+			ws.Contract = true
+		} else if m, pubsBytes, ok := vm.ParseMultiSigContract(w.VerificationScript); ok {
+			ws.M = m
+			for _, b := range pubsBytes {
+				pub, err := keys.NewPublicKeyFromBytes(b, elliptic.P256())
+				if err != nil {
+					continue
+				}
+				ws.Keys = append(ws.Keys, pub)
+				for signed := range r.sigs {
+					if signed.Equal(pub) {
+						ws.Signed = append(ws.Signed, pub)
+						break
+					}
+				}
+			}
+		} else if b, ok := vm.ParseSignatureContract(w.VerificationScript); ok {
+			ws.M = 1
+			if pub, err := keys.NewPublicKeyFromBytes(b, elliptic.P256()); err == nil {
+				ws.Keys = keys.PublicKeys{pub}
+				if len(w.InvocationScript) != 0 {
+					ws.Signed = keys.PublicKeys{pub}
+				}
+			}
+		}
+		s.Witnesses = append(s.Witnesses, ws)
+	}
+	for i, fb := range r.fallbacks {
+		s.Fallbacks[i] = FallbackStatus{
+			Hash:           fb.Hash(),
+			NotValidBefore: fb.GetAttributes(transaction.NotValidBeforeT)[0].Value.(*transaction.NotValidBefore).Height,
+		}
+	}
+	return s
+}
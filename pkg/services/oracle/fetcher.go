@@ -0,0 +1,366 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+)
+
+const (
+	// defaultCacheMaxEntries is the default number of cached URLs.
+	defaultCacheMaxEntries = 1000
+	// maxHostLimiters is the number of host limiters kept by fetcher, idle
+	// ones are evicted when it's exceeded.
+	maxHostLimiters = 1000
+)
+
+var errProtocolNotSupported = errors.New("protocol is not supported")
+
+type (
+	// fetcher gets data for oracle requests via protocol handlers. It caches
+	// the data, deduplicates concurrent requests for the same URL and limits
+	// the load on remote hosts. It's shared by all request workers.
+	fetcher struct {
+		handlers map[string]ProtocolHandler
+		cacheCfg config.OracleCache
+		limits   config.OracleHostLimits
+
+		mtx      sync.Mutex
+		cache    map[string]cacheEntry
+		inFlight map[string]*fetchCall
+		hosts    map[string]*hostLimiter
+	}
+
+	cacheEntry struct {
+		data    []byte
+		expires time.Time
+	}
+
+	// fetchCall is a request being processed, other requests for the same
+	// URL wait for it to complete.
+	fetchCall struct {
+		done chan struct{}
+		data []byte
+		err  error
+	}
+
+	// hostLimiter limits the number of concurrent requests to the host
+	// (with a semaphore) and their rate (with a token bucket).
+	hostLimiter struct {
+		sem    chan struct{}
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+		// users is the number of requests using the limiter, it's protected
+		// by fetcher mutex.
+		users int
+	}
+)
+
+func newFetcher(handlers map[string]ProtocolHandler, cacheCfg config.OracleCache, limits config.OracleHostLimits) *fetcher {
+	if cacheCfg.MaxEntries <= 0 {
+		cacheCfg.MaxEntries = defaultCacheMaxEntries
+	}
+	return &fetcher{
+		handlers: handlers,
+		cacheCfg: cacheCfg,
+		limits:   limits,
+		cache:    make(map[string]cacheEntry),
+		inFlight: make(map[string]*fetchCall),
+		hosts:    make(map[string]*hostLimiter),
+	}
+}
+
+// fetch returns data for the request from the cache or from the protocol
+// handler. Only one request for the same URL is processed at a time, others
+// get its result.
+func (f *fetcher) fetch(ctx context.Context, req *ProtocolRequest) ([]byte, error) {
+	h, ok := f.handlers[req.URL.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errProtocolNotSupported, req.URL.Scheme)
+	}
+
+	key := req.URL.String()
+	f.mtx.Lock()
+	if e, ok := f.cache[key]; ok {
+		if time.Now().Before(e.expires) {
+			f.mtx.Unlock()
+			addCacheHitsMetric()
+			return e.data, nil
+		}
+		delete(f.cache, key)
+	}
+	if c, ok := f.inFlight[key]; ok {
+		f.mtx.Unlock()
+		addDeduplicatedRequestsMetric()
+		select {
+		case <-c.done:
+			return c.data, c.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &fetchCall{done: make(chan struct{})}
+	f.inFlight[key] = c
+	f.mtx.Unlock()
+
+	res, err := f.fetchLimited(ctx, h, req)
+
+	f.mtx.Lock()
+	delete(f.inFlight, key)
+	if err == nil {
+		c.data = res.Data
+		if ttl := f.cacheTTL(res); ttl > 0 {
+			f.store(key, res.Data, time.Now().Add(ttl))
+		}
+	}
+	c.err = err
+	f.mtx.Unlock()
+	close(c.done)
+	return c.data, c.err
+}
+
+// invalidate drops cached data for the URL, so that it's fetched again.
+func (f *fetcher) invalidate(u *url.URL) {
+	f.mtx.Lock()
+	delete(f.cache, u.String())
+	f.mtx.Unlock()
+}
+
+// stats returns URLs being fetched now and the number of cached entries.
+func (f *fetcher) stats() ([]string, int) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	urls := make([]string, 0, len(f.inFlight))
+	for u := range f.inFlight {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+	return urls, len(f.cache)
+}
+
+// fetchLimited fetches data via the handler respecting the limits of the
+// request host.
+func (f *fetcher) fetchLimited(ctx context.Context, h ProtocolHandler, req *ProtocolRequest) (*ProtocolResponse, error) {
+	if req.URL.Host == "" {
+		return h.Fetch(ctx, req)
+	}
+	now := time.Now()
+	f.mtx.Lock()
+	l := f.getHostLimiter(req.URL.Host, req.URL.Hostname(), now)
+	if l == nil {
+		f.mtx.Unlock()
+		return h.Fetch(ctx, req)
+	}
+	wait := l.reserve(now)
+	f.mtx.Unlock()
+	defer func() {
+		f.mtx.Lock()
+		l.users--
+		f.mtx.Unlock()
+	}()
+
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		default:
+			addThrottledRequestsMetric("concurrency")
+			select {
+			case l.sem <- struct{}{}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		defer func() { <-l.sem }()
+	}
+	if wait > 0 {
+		addThrottledRequestsMetric("rate")
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+	return h.Fetch(ctx, req)
+}
+
+// getHostLimiter returns limiter for the host creating it if needed, nil is
+// returned if there are no limits for the host. Host with port is looked up
+// in the configuration first, then just the name. Returned limiter is marked
+// as used and it must be released by the caller. It must be called with f.mtx
+// held.
+func (f *fetcher) getHostLimiter(host, name string, now time.Time) *hostLimiter {
+	l, ok := f.hosts[host]
+	if !ok {
+		cfg, ok := f.limits.Hosts[host]
+		if !ok {
+			cfg, ok = f.limits.Hosts[name]
+		}
+		if !ok {
+			cfg = f.limits.OracleHostLimit
+		}
+		if cfg.Rate <= 0 && cfg.MaxConcurrentRequests <= 0 {
+			return nil
+		}
+		if len(f.hosts) >= maxHostLimiters {
+			f.evictHostLimiters(now)
+		}
+		l = newHostLimiter(cfg)
+		f.hosts[host] = l
+	}
+	l.users++
+	return l
+}
+
+// evictHostLimiters removes idle host limiters, they're recreated in the same
+// state when needed. If it's not enough, the least recently used one not
+// being used now is removed. It must be called with f.mtx held.
+func (f *fetcher) evictHostLimiters(now time.Time) {
+	var (
+		lru     string
+		lruLast time.Time
+	)
+	for host, l := range f.hosts {
+		if l.users != 0 {
+			continue
+		}
+		if l.isIdle(now) {
+			delete(f.hosts, host)
+			continue
+		}
+		if lru == "" || l.last.Before(lruLast) {
+			lru, lruLast = host, l.last
+		}
+	}
+	if len(f.hosts) >= maxHostLimiters && lru != "" {
+		delete(f.hosts, lru)
+	}
+}
+
+// cacheTTL returns the time data can be cached for.
+func (f *fetcher) cacheTTL(res *ProtocolResponse) time.Duration {
+	if f.cacheCfg.MaxAge <= 0 || res.NoCache {
+		return 0
+	}
+	if res.MaxAge > 0 && res.MaxAge < f.cacheCfg.MaxAge {
+		return res.MaxAge
+	}
+	return f.cacheCfg.MaxAge
+}
+
+// store puts data into the cache evicting expired entries (or the one that
+// expires first) if it's full. It must be called with f.mtx held.
+func (f *fetcher) store(key string, data []byte, expires time.Time) {
+	if len(f.cache) >= f.cacheCfg.MaxEntries {
+		var (
+			now      = time.Now()
+			first    string
+			firstExp time.Time
+		)
+		for k, e := range f.cache {
+			if !now.Before(e.expires) {
+				delete(f.cache, k)
+				continue
+			}
+			if first == "" || e.expires.Before(firstExp) {
+				first, firstExp = k, e.expires
+			}
+		}
+		if len(f.cache) >= f.cacheCfg.MaxEntries {
+			delete(f.cache, first)
+		}
+	}
+	f.cache[key] = cacheEntry{data: data, expires: expires}
+}
+
+func newHostLimiter(cfg config.OracleHostLimit) *hostLimiter {
+	l := &hostLimiter{
+		rate:  cfg.Rate,
+		burst: float64(cfg.Burst),
+		last:  time.Now(),
+	}
+	if l.burst < 1 {
+		l.burst = 1
+	}
+	l.tokens = l.burst
+	if cfg.MaxConcurrentRequests > 0 {
+		l.sem = make(chan struct{}, cfg.MaxConcurrentRequests)
+	}
+	return l
+}
+
+// isIdle checks whether the limiter has its bucket full at the given time, so
+// it's the same as a new one if it's not used.
+func (l *hostLimiter) isIdle(now time.Time) bool {
+	return l.rate <= 0 || l.tokens+now.Sub(l.last).Seconds()*l.rate >= l.burst
+}
+
+// reserve takes a token from the bucket and returns the time to wait before
+// making the request.
+func (l *hostLimiter) reserve(now time.Time) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cachePolicy returns caching policy specified by HTTP response headers:
+// the time data can be cached for (zero if not specified) and whether
+// caching is forbidden.
+func cachePolicy(h http.Header, now time.Time) (time.Duration, bool) {
+	var (
+		maxAge    time.Duration
+		hasMaxAge bool
+	)
+	for _, d := range strings.Split(h.Get("Cache-Control"), ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		switch {
+		case d == "no-store" || d == "no-cache":
+			return 0, true
+		case strings.HasPrefix(d, "max-age="):
+			sec, err := strconv.ParseInt(strings.Trim(d[len("max-age="):], `"`), 10, 64)
+			if err != nil || sec <= 0 {
+				return 0, true
+			}
+			maxAge, hasMaxAge = time.Duration(sec)*time.Second, true
+		}
+	}
+	if hasMaxAge {
+		return maxAge, false
+	}
+	if exp := h.Get("Expires"); exp != "" {
+		t, err := http.ParseTime(exp)
+		if err != nil {
+			return 0, true
+		}
+		if date, err := http.ParseTime(h.Get("Date")); err == nil {
+			now = date
+		}
+		if !t.After(now) {
+			return 0, true
+		}
+		return t.Sub(now), false
+	}
+	return 0, false
+}
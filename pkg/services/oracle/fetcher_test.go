@@ -0,0 +1,290 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+// countingHandler is a ProtocolHandler returning fixed response and
+// counting requests.
+type countingHandler struct {
+	calls   int32
+	active  int32
+	maxSeen int32
+	release chan struct{}
+	resp    ProtocolResponse
+	err     error
+}
+
+func (h *countingHandler) Fetch(_ context.Context, req *ProtocolRequest) (*ProtocolResponse, error) {
+	atomic.AddInt32(&h.calls, 1)
+	active := atomic.AddInt32(&h.active, 1)
+	defer atomic.AddInt32(&h.active, -1)
+	for {
+		m := atomic.LoadInt32(&h.maxSeen)
+		if active <= m || atomic.CompareAndSwapInt32(&h.maxSeen, m, active) {
+			break
+		}
+	}
+	if h.release != nil {
+		<-h.release
+	}
+	if h.err != nil {
+		return nil, h.err
+	}
+	resp := h.resp
+	resp.Data = []byte(req.URL.String())
+	return &resp, nil
+}
+
+func fetchURL(t *testing.T, f *fetcher, uri string) ([]byte, error) {
+	u, err := url.ParseRequestURI(uri)
+	require.NoError(t, err)
+	return f.fetch(context.Background(), &ProtocolRequest{URL: u})
+}
+
+func TestFetcherCache(t *testing.T) {
+	h := &countingHandler{}
+	f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{MaxAge: time.Hour, MaxEntries: 2}, config.OracleHostLimits{})
+
+	for i := 0; i < 3; i++ {
+		res, err := fetchURL(t, f, "https://example.com/1")
+		require.NoError(t, err)
+		require.Equal(t, "https://example.com/1", string(res))
+	}
+	require.EqualValues(t, 1, h.calls)
+
+	_, err := fetchURL(t, f, "ftp://example.com/1")
+	require.True(t, errors.Is(err, errProtocolNotSupported))
+	require.Equal(t, transaction.ProtocolNotSupported, responseCode(err))
+
+	t.Run("eviction", func(t *testing.T) {
+		_, err := fetchURL(t, f, "https://example.com/2")
+		require.NoError(t, err)
+		_, err = fetchURL(t, f, "https://example.com/3")
+		require.NoError(t, err)
+		require.Equal(t, 2, len(f.cache))
+		require.EqualValues(t, 3, h.calls)
+	})
+
+	t.Run("expiration", func(t *testing.T) {
+		h := &countingHandler{resp: ProtocolResponse{MaxAge: 50 * time.Millisecond}}
+		f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{MaxAge: time.Hour}, config.OracleHostLimits{})
+		_, err := fetchURL(t, f, "https://example.com")
+		require.NoError(t, err)
+		_, err = fetchURL(t, f, "https://example.com")
+		require.NoError(t, err)
+		require.EqualValues(t, 1, h.calls)
+		time.Sleep(60 * time.Millisecond)
+		_, err = fetchURL(t, f, "https://example.com")
+		require.NoError(t, err)
+		require.EqualValues(t, 2, h.calls)
+	})
+
+	t.Run("no cache", func(t *testing.T) {
+		h := &countingHandler{resp: ProtocolResponse{NoCache: true}}
+		f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{MaxAge: time.Hour}, config.OracleHostLimits{})
+		for i := 0; i < 2; i++ {
+			_, err := fetchURL(t, f, "https://example.com")
+			require.NoError(t, err)
+		}
+		require.EqualValues(t, 2, h.calls)
+	})
+
+	t.Run("invalidation", func(t *testing.T) {
+		h := &countingHandler{}
+		f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{MaxAge: time.Hour}, config.OracleHostLimits{})
+		_, err := fetchURL(t, f, "https://example.com")
+		require.NoError(t, err)
+		u, err := url.ParseRequestURI("https://example.com")
+		require.NoError(t, err)
+		f.invalidate(u)
+		_, err = fetchURL(t, f, "https://example.com")
+		require.NoError(t, err)
+		require.EqualValues(t, 2, h.calls)
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		h := &countingHandler{err: ErrNotFound}
+		f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{MaxAge: time.Hour}, config.OracleHostLimits{})
+		for i := 0; i < 2; i++ {
+			_, err := fetchURL(t, f, "https://example.com")
+			require.True(t, errors.Is(err, ErrNotFound))
+		}
+		require.EqualValues(t, 2, h.calls)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		h := &countingHandler{}
+		f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{}, config.OracleHostLimits{})
+		for i := 0; i < 2; i++ {
+			_, err := fetchURL(t, f, "https://example.com")
+			require.NoError(t, err)
+		}
+		require.EqualValues(t, 2, h.calls)
+	})
+}
+
+func TestFetcherDeduplication(t *testing.T) {
+	h := &countingHandler{release: make(chan struct{})}
+	f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{}, config.OracleHostLimits{})
+
+	const n = 5
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func() {
+			defer wg.Done()
+			res, err := fetchURL(t, f, "https://example.com")
+			require.NoError(t, err)
+			require.Equal(t, "https://example.com", string(res))
+		}()
+	}
+	require.Eventually(t, func() bool {
+		f.mtx.Lock()
+		defer f.mtx.Unlock()
+		return atomic.LoadInt32(&h.calls) == 1 && len(f.inFlight) == 1
+	}, time.Second, 10*time.Millisecond)
+	// Let the other requests join the one being processed.
+	time.Sleep(50 * time.Millisecond)
+	close(h.release)
+	wg.Wait()
+	require.EqualValues(t, 1, h.calls)
+	require.Equal(t, 0, len(f.inFlight))
+}
+
+func TestFetcherHostLimits(t *testing.T) {
+	t.Run("concurrency", func(t *testing.T) {
+		h := &countingHandler{release: make(chan struct{})}
+		f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{}, config.OracleHostLimits{
+			OracleHostLimit: config.OracleHostLimit{MaxConcurrentRequests: 2},
+		})
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := fetchURL(t, f, "https://example.com/"+string(rune('a'+i)))
+				require.NoError(t, err)
+			}(i)
+		}
+		require.Eventually(t, func() bool { return atomic.LoadInt32(&h.active) == 2 }, time.Second, 10*time.Millisecond)
+		close(h.release)
+		wg.Wait()
+		require.EqualValues(t, 5, h.calls)
+		require.EqualValues(t, 2, h.maxSeen)
+	})
+	t.Run("rate", func(t *testing.T) {
+		h := &countingHandler{}
+		f := newFetcher(map[string]ProtocolHandler{"https": h}, config.OracleCache{}, config.OracleHostLimits{
+			Hosts: map[string]config.OracleHostLimit{
+				"limited.com": {Rate: 20, Burst: 2},
+			},
+		})
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			_, err := fetchURL(t, f, "https://limited.com/"+string(rune('a'+i)))
+			require.NoError(t, err)
+		}
+		// Two requests are made at once, then one per 50ms.
+		require.True(t, time.Since(start) >= 90*time.Millisecond)
+
+		start = time.Now()
+		for i := 0; i < 4; i++ {
+			_, err := fetchURL(t, f, "https://other.com/"+string(rune('a'+i)))
+			require.NoError(t, err)
+		}
+		require.True(t, time.Since(start) < 50*time.Millisecond)
+		// There are no limiters for hosts without limits.
+		require.Equal(t, 1, len(f.hosts))
+	})
+}
+
+func TestFetcherHostLimitersEviction(t *testing.T) {
+	f := newFetcher(nil, config.OracleCache{}, config.OracleHostLimits{
+		OracleHostLimit: config.OracleHostLimit{Rate: 1},
+	})
+	now := time.Now()
+	get := func(i int, now time.Time) *hostLimiter {
+		host := "host" + strconv.Itoa(i)
+		l := f.getHostLimiter(host, host, now)
+		require.NotNil(t, l)
+		l.reserve(now)
+		return l
+	}
+	for i := 0; i < maxHostLimiters; i++ {
+		l := get(i, now.Add(time.Duration(i)*time.Millisecond))
+		if i != 0 {
+			l.users--
+		}
+	}
+	require.Equal(t, maxHostLimiters, len(f.hosts))
+
+	// The least recently used one is evicted if nothing is idle, limiters
+	// being used are kept.
+	get(maxHostLimiters, now.Add(time.Second))
+	require.Equal(t, maxHostLimiters, len(f.hosts))
+	require.NotNil(t, f.hosts["host0"])
+	require.Nil(t, f.hosts["host1"])
+
+	// Idle ones are evicted.
+	get(maxHostLimiters+1, now.Add(time.Hour))
+	require.Equal(t, 3, len(f.hosts))
+}
+
+func TestHostLimiterReserve(t *testing.T) {
+	l := newHostLimiter(config.OracleHostLimit{Rate: 2, Burst: 2})
+	now := l.last
+	require.Equal(t, time.Duration(0), l.reserve(now))
+	require.Equal(t, time.Duration(0), l.reserve(now))
+	require.Equal(t, 500*time.Millisecond, l.reserve(now))
+	require.Equal(t, time.Second, l.reserve(now))
+	// Tokens are refilled with time.
+	require.Equal(t, time.Duration(0), l.reserve(now.Add(3*time.Second)))
+
+	l = newHostLimiter(config.OracleHostLimit{})
+	for i := 0; i < 10; i++ {
+		require.Equal(t, time.Duration(0), l.reserve(now))
+	}
+}
+
+func TestCachePolicy(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	check := func(t *testing.T, hdr map[string]string, maxAge time.Duration, noCache bool) {
+		h := make(http.Header)
+		for k, v := range hdr {
+			h.Set(k, v)
+		}
+		a, n := cachePolicy(h, now)
+		require.Equal(t, maxAge, a)
+		require.Equal(t, noCache, n)
+	}
+	check(t, nil, 0, false)
+	check(t, map[string]string{"Cache-Control": "public, max-age=60"}, time.Minute, false)
+	check(t, map[string]string{"Cache-Control": "max-age=60, no-store"}, 0, true)
+	check(t, map[string]string{"Cache-Control": "no-cache"}, 0, true)
+	check(t, map[string]string{"Cache-Control": "max-age=0"}, 0, true)
+	check(t, map[string]string{"Expires": now.Add(time.Hour).Format(http.TimeFormat)}, time.Hour, false)
+	check(t, map[string]string{
+		"Expires": now.Add(time.Hour).Format(http.TimeFormat),
+		"Date":    now.Add(30 * time.Minute).Format(http.TimeFormat),
+	}, 30*time.Minute, false)
+	check(t, map[string]string{"Expires": "0"}, 0, true)
+	check(t, map[string]string{"Expires": now.Add(-time.Hour).Format(http.TimeFormat)}, 0, true)
+	// max-age takes precedence over Expires.
+	check(t, map[string]string{
+		"Cache-Control": "max-age=10",
+		"Expires":       now.Add(time.Hour).Format(http.TimeFormat),
+	}, 10*time.Second, false)
+}
@@ -11,7 +11,9 @@ import (
 	json "github.com/virtuald/go-ordered-json"
 )
 
-func filter(value []byte, path string) ([]byte, error) {
+// filter applies JSONPath to the value, extended syntax (not supported by the
+// C# node) is only accepted if extended is true.
+func filter(value []byte, path string, extended bool) ([]byte, error) {
 	if !utf8.Valid(value) {
 		return nil, errors.New("not an UTF-8")
 	}
@@ -25,17 +27,21 @@ func filter(value []byte, path string) ([]byte, error) {
 		return nil, err
 	}
 
-	result, ok := jsonpath.Get(path, v)
+	get := jsonpath.Get
+	if extended {
+		get = jsonpath.GetExtended
+	}
+	result, ok := get(path, v)
 	if !ok {
 		return nil, errors.New("invalid filter")
 	}
 	return json.Marshal(result)
 }
 
-func filterRequest(result []byte, req *state.OracleRequest) (transaction.OracleResponseCode, []byte) {
+func filterRequest(result []byte, req *state.OracleRequest, extended bool) (transaction.OracleResponseCode, []byte) {
 	if req.Filter != nil {
 		var err error
-		result, err = filter(result, *req.Filter)
+		result, err = filter(result, *req.Filter, extended)
 		if err != nil {
 			return transaction.Error, nil
 		}
@@ -38,14 +38,23 @@ func TestFilter(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.path, func(t *testing.T) {
-			actual, err := filter([]byte(js), tc.path)
+			actual, err := filter([]byte(js), tc.path, false)
 			require.NoError(t, err)
 			require.Equal(t, tc.result, string(actual))
 		})
 	}
 
 	t.Run("not an UTF-8", func(t *testing.T) {
-		_, err := filter([]byte{0xFF}, "Manufacturers[0].Name")
+		_, err := filter([]byte{0xFF}, "Manufacturers[0].Name", false)
 		require.Error(t, err)
 	})
+
+	t.Run("extended", func(t *testing.T) {
+		path := "$.Manufacturers[?(@.Name == 'Contoso')].Products[?(@.Price < 10)].Name"
+		_, err := filter([]byte(js), path, false)
+		require.Error(t, err)
+		actual, err := filter([]byte(js), path, true)
+		require.NoError(t, err)
+		require.Equal(t, `["Headlight Fluid"]`, string(actual))
+	})
 }
@@ -0,0 +1,194 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/services/oracle/neofs"
+)
+
+type (
+	// ProtocolHandler fetches data for oracle requests with some URI scheme.
+	// Additional response parameters are to be added to ProtocolResponse, so
+	// implementations don't break with them.
+	ProtocolHandler interface {
+		// Fetch returns data for the request. Errors wrapping ErrForbidden,
+		// ErrNotFound, ErrTimeout, ErrResponseTooLarge and
+		// ErrContentTypeNotSupported are converted to the corresponding
+		// response codes, any other error results in Error code.
+		Fetch(ctx context.Context, req *ProtocolRequest) (*ProtocolResponse, error)
+	}
+
+	// ProtocolRequest is an oracle request passed to ProtocolHandler.
+	ProtocolRequest struct {
+		ID  uint64
+		URL *url.URL
+		// Attempt is the number of previous attempts to process the request.
+		Attempt int
+		// Key is the oracle node key.
+		Key *keys.PrivateKey
+	}
+
+	// ProtocolResponse is the data fetched by ProtocolHandler.
+	ProtocolResponse struct {
+		Data []byte
+		// MaxAge is the time data can be cached for, configured maximum
+		// is used if it's zero.
+		MaxAge time.Duration
+		// NoCache forbids data caching.
+		NoCache bool
+	}
+
+	httpsHandler struct {
+		client       HTTPClient
+		validator    URIValidator
+		allowedTypes []string
+	}
+
+	neofsHandler struct {
+		nodes   []string
+		timeout time.Duration
+	}
+)
+
+// Errors to be returned by ProtocolHandler.
+var (
+	ErrForbidden               = errors.New("forbidden")
+	ErrNotFound                = errors.New("not found")
+	ErrTimeout                 = errors.New("timeout")
+	ErrContentTypeNotSupported = errors.New("content type is not supported")
+)
+
+// responseCode returns oracle response code for the error returned from
+// ProtocolHandler.
+func responseCode(err error) transaction.OracleResponseCode {
+	switch {
+	case errors.Is(err, ErrForbidden):
+		return transaction.Forbidden
+	case errors.Is(err, ErrNotFound):
+		return transaction.NotFound
+	case errors.Is(err, ErrTimeout):
+		return transaction.Timeout
+	case errors.Is(err, ErrResponseTooLarge):
+		return transaction.ResponseTooLarge
+	case errors.Is(err, ErrContentTypeNotSupported):
+		return transaction.ContentTypeNotSupported
+	case errors.Is(err, errProtocolNotSupported):
+		return transaction.ProtocolNotSupported
+	default:
+		return transaction.Error
+	}
+}
+
+// initHandlers creates protocol handlers enabled in the configuration.
+// Handlers specified in the Config override them.
+func (o *Oracle) initHandlers() error {
+	o.handlers = make(map[string]ProtocolHandler)
+	https := &httpsHandler{
+		client:       o.Client,
+		allowedTypes: o.MainCfg.AllowedContentTypes,
+	}
+	if !o.MainCfg.AllowPrivateHost {
+		https.validator = o.URIValidator
+	}
+	o.handlers["https"] = https
+	if len(o.MainCfg.NeoFS.Nodes) != 0 {
+		o.handlers[neofs.URIScheme] = &neofsHandler{
+			nodes:   o.MainCfg.NeoFS.Nodes,
+			timeout: o.MainCfg.NeoFS.Timeout,
+		}
+	}
+	if cfg := o.MainCfg.IPFS; cfg.Enabled {
+		h, err := newIPFSHandler(cfg, o.Client, o.MainCfg.RequestTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid IPFS configuration: %w", err)
+		}
+		o.handlers[ipfsURIScheme] = h
+	}
+	if cfg := o.MainCfg.File; cfg.Enabled {
+		h, err := newFileHandler(cfg, o.MainCfg.RequestTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid File configuration: %w", err)
+		}
+		o.handlers[fileURIScheme] = h
+	}
+	if cfg := o.MainCfg.Exec; cfg.Enabled {
+		h, err := newExecHandler(cfg, o.MainCfg.RequestTimeout)
+		if err != nil {
+			return fmt.Errorf("invalid Exec configuration: %w", err)
+		}
+		o.handlers[execURIScheme] = h
+	}
+	for scheme, h := range o.Handlers {
+		o.handlers[scheme] = h
+	}
+	return nil
+}
+
+// Fetch implements ProtocolHandler interface.
+func (h *httpsHandler) Fetch(ctx context.Context, req *ProtocolRequest) (*ProtocolResponse, error) {
+	if h.validator != nil {
+		if err := h.validator(req.URL); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrForbidden, err)
+		}
+	}
+	return fetchHTTP(ctx, h.client, req.URL.String(), h.allowedTypes, transaction.MaxOracleResultSize)
+}
+
+// fetchHTTP performs HTTP GET request and checks the response. Caching
+// policy is taken from the response headers.
+func fetchHTTP(ctx context.Context, client HTTPClient, u string, allowedTypes []string, maxSize int) (*ProtocolResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http request: %w", err)
+	}
+	httpReq.Header.Set("User-Agent", "NeoOracleService/3.0")
+	httpReq.Header.Set("Content-Type", "application/json")
+	r, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	switch r.StatusCode {
+	case http.StatusOK:
+		if !checkMediaType(r.Header.Get("Content-Type"), allowedTypes) {
+			r.Body.Close()
+			return nil, ErrContentTypeNotSupported
+		}
+		data, err := readResponse(r.Body, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		res := &ProtocolResponse{Data: data}
+		res.MaxAge, res.NoCache = cachePolicy(r.Header, time.Now())
+		return res, nil
+	case http.StatusForbidden:
+		err = ErrForbidden
+	case http.StatusNotFound:
+		err = ErrNotFound
+	case http.StatusRequestTimeout:
+		err = ErrTimeout
+	default:
+		err = fmt.Errorf("unexpected status code %d", r.StatusCode)
+	}
+	r.Body.Close()
+	return nil, err
+}
+
+// Fetch implements ProtocolHandler interface. NeoFS nodes are used in
+// round-robin fashion.
+func (h *neofsHandler) Fetch(ctx context.Context, req *ProtocolRequest) (*ProtocolResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	index := (int(req.ID) + req.Attempt) % len(h.nodes)
+	data, err := neofs.Get(ctx, req.Key, req.URL, h.nodes[index])
+	if err != nil {
+		return nil, err
+	}
+	return &ProtocolResponse{Data: data}, nil
+}
@@ -0,0 +1,402 @@
+package jsonpath
+
+import (
+	"strconv"
+	"strings"
+
+	json "github.com/virtuald/go-ordered-json"
+)
+
+type (
+	// filterExpr is a boolean expression of `[?(...)]` filter.
+	filterExpr interface {
+		// eval checks whether the value matches the expression. False is
+		// returned as the second result if evaluation limits are exceeded.
+		eval(p *pathParser, value interface{}) (bool, bool)
+	}
+
+	orExpr struct {
+		left, right filterExpr
+	}
+
+	andExpr struct {
+		left, right filterExpr
+	}
+
+	notExpr struct {
+		expr filterExpr
+	}
+
+	// existsExpr matches if path selects at least one value.
+	existsExpr struct {
+		path filterOperand
+	}
+
+	compareExpr struct {
+		op          string
+		left, right filterOperand
+	}
+
+	literalExpr bool
+
+	// filterOperand is either a literal or a path relative to the current
+	// (`@`) or root (`$`) value. Paths are stored as offsets in the original
+	// string and are parsed again on every evaluation.
+	filterOperand struct {
+		isPath   bool
+		fromRoot bool
+		start    int
+		nesting  int
+		value    interface{}
+	}
+)
+
+// maxFilterNesting is the maximum nesting level of filters, parentheses and
+// negations in filter expressions. Operand paths have their own depth limit,
+// so filters can be nested only this way.
+const maxFilterNesting = 8
+
+// comparisonOperators are checked in order, so longer ones go first.
+var comparisonOperators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// processFilter processes `[?(<expr>)]` filter expression selecting elements
+// of arrays and values of maps matching it.
+func (p *pathParser) processFilter(objs []interface{}) ([]interface{}, bool) {
+	if p.depth <= 0 || p.nesting >= maxFilterNesting {
+		return nil, false
+	}
+	p.depth--
+
+	if !p.consume("(") {
+		return nil, false
+	}
+	expr, ok := p.parseOr(p.nesting + 1)
+	if !ok || !p.consume(")") || !p.consume("]") {
+		return nil, false
+	}
+
+	var values []interface{}
+	for i := range objs {
+		var children []interface{}
+		switch obj := objs[i].(type) {
+		case []interface{}:
+			children = obj
+		case json.OrderedObject:
+			for j := range obj {
+				children = append(children, obj[j].Value)
+			}
+		}
+		if !p.visit(len(children)) {
+			return nil, false
+		}
+		for _, c := range children {
+			match, ok := expr.eval(p, c)
+			if !ok {
+				return nil, false
+			}
+			if match {
+				values = append(values, c)
+			}
+		}
+	}
+	return values, true
+}
+
+// skipSpaces moves parser position to the next non-space character.
+func (p *pathParser) skipSpaces() {
+	for p.i < len(p.s) && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+// consume skips spaces and checks if the path continues with s, moving the
+// position past it if so.
+func (p *pathParser) consume(s string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.s[p.i:], s) {
+		p.i += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *pathParser) parseOr(nesting int) (filterExpr, bool) {
+	left, ok := p.parseAnd(nesting)
+	for ok && p.consume("||") {
+		var right filterExpr
+		right, ok = p.parseAnd(nesting)
+		left = orExpr{left: left, right: right}
+	}
+	return left, ok
+}
+
+func (p *pathParser) parseAnd(nesting int) (filterExpr, bool) {
+	left, ok := p.parseUnary(nesting)
+	for ok && p.consume("&&") {
+		var right filterExpr
+		right, ok = p.parseUnary(nesting)
+		left = andExpr{left: left, right: right}
+	}
+	return left, ok
+}
+
+// parseUnary parses negation, expression in parentheses, comparison or
+// existence check.
+func (p *pathParser) parseUnary(nesting int) (filterExpr, bool) {
+	switch {
+	case p.consume("!"):
+		if nesting >= maxFilterNesting {
+			return nil, false
+		}
+		expr, ok := p.parseUnary(nesting + 1)
+		return notExpr{expr: expr}, ok
+	case p.consume("("):
+		if nesting >= maxFilterNesting {
+			return nil, false
+		}
+		expr, ok := p.parseOr(nesting + 1)
+		if !ok || !p.consume(")") {
+			return nil, false
+		}
+		return expr, true
+	}
+
+	left, ok := p.parseOperand(nesting)
+	if !ok {
+		return nil, false
+	}
+	for _, op := range comparisonOperators {
+		if p.consume(op) {
+			right, ok := p.parseOperand(nesting)
+			if !ok {
+				return nil, false
+			}
+			return compareExpr{op: op, left: left, right: right}, true
+		}
+	}
+	if left.isPath {
+		return existsExpr{path: left}, true
+	}
+	if b, ok := left.value.(bool); ok {
+		return literalExpr(b), true
+	}
+	return nil, false
+}
+
+// parseOperand parses path starting with `@` or `$`, number, string in single
+// quotes, `true`, `false` or `null`.
+func (p *pathParser) parseOperand(nesting int) (filterOperand, bool) {
+	p.skipSpaces()
+	if p.i >= len(p.s) {
+		return filterOperand{}, false
+	}
+
+	switch c := p.s[p.i]; {
+	case c == '@' || c == '$':
+		op := filterOperand{
+			isPath:   true,
+			fromRoot: c == '$',
+			start:    p.i + 1,
+			nesting:  nesting,
+		}
+		// Path is applied to nothing here just to check its syntax and
+		// find where it ends.
+		sub := p.operandParser(op)
+		if _, ok := sub.processSegments(nil); !ok {
+			return filterOperand{}, false
+		}
+		p.i = sub.i
+		return op, true
+	case c == '\'':
+		value, n, ok := p.parseString()
+		if !ok {
+			return filterOperand{}, false
+		}
+		s := strings.Trim(value, "'")
+		if err := json.Unmarshal([]byte(`"`+s+`"`), &s); err != nil {
+			return filterOperand{}, false
+		}
+		p.i += n
+		return filterOperand{value: s}, true
+	case c == '-' || ('0' <= c && c <= '9'):
+		end := p.i + 1
+		for ; end < len(p.s); end++ {
+			c := p.s[end]
+			if !('0' <= c && c <= '9') && c != '.' && c != 'e' && c != 'E' &&
+				!((c == '-' || c == '+') && (p.s[end-1] == 'e' || p.s[end-1] == 'E')) {
+				break
+			}
+		}
+		f, err := strconv.ParseFloat(p.s[p.i:end], 64)
+		if err != nil {
+			return filterOperand{}, false
+		}
+		p.i = end
+		return filterOperand{value: f}, true
+	default:
+		ident, n, _ := p.parseIdent()
+		var value interface{}
+		switch ident {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+		default:
+			return filterOperand{}, false
+		}
+		p.i += n
+		return filterOperand{value: value}, true
+	}
+}
+
+// operandParser returns parser for the path of the operand.
+func (p *pathParser) operandParser(op filterOperand) *pathParser {
+	return &pathParser{
+		s:        p.s,
+		i:        op.start,
+		depth:    maxNestingDepth,
+		nesting:  op.nesting,
+		root:     p.root,
+		extended: p.extended,
+		budget:   p.budget,
+	}
+}
+
+// evalOperand returns values selected by the operand.
+func (p *pathParser) evalOperand(op filterOperand, value interface{}) ([]interface{}, bool) {
+	if !op.isPath {
+		return []interface{}{op.value}, true
+	}
+	if !p.visit(1) {
+		return nil, false
+	}
+	if op.fromRoot {
+		value = p.root
+	}
+	return p.operandParser(op).processSegments([]interface{}{value})
+}
+
+func (e orExpr) eval(p *pathParser, value interface{}) (bool, bool) {
+	res, ok := e.left.eval(p, value)
+	if !ok || res {
+		return res, ok
+	}
+	return e.right.eval(p, value)
+}
+
+func (e andExpr) eval(p *pathParser, value interface{}) (bool, bool) {
+	res, ok := e.left.eval(p, value)
+	if !ok || !res {
+		return res, ok
+	}
+	return e.right.eval(p, value)
+}
+
+func (e notExpr) eval(p *pathParser, value interface{}) (bool, bool) {
+	res, ok := e.expr.eval(p, value)
+	return !res, ok
+}
+
+func (e existsExpr) eval(p *pathParser, value interface{}) (bool, bool) {
+	values, ok := p.evalOperand(e.path, value)
+	return len(values) != 0, ok
+}
+
+func (e literalExpr) eval(*pathParser, interface{}) (bool, bool) {
+	return bool(e), true
+}
+
+// eval implements filterExpr interface. Both operands must select exactly one
+// value, otherwise the expression doesn't match.
+func (e compareExpr) eval(p *pathParser, value interface{}) (bool, bool) {
+	left, ok := p.evalOperand(e.left, value)
+	if !ok {
+		return false, false
+	}
+	right, ok := p.evalOperand(e.right, value)
+	if !ok {
+		return false, false
+	}
+	if len(left) != 1 || len(right) != 1 {
+		return false, true
+	}
+	return compareValues(e.op, left[0], right[0]), true
+}
+
+// compareValues compares two JSON values. Only numbers and strings can be
+// ordered, any values can be checked for equality.
+func compareValues(op string, a, b interface{}) bool {
+	switch op {
+	case "==":
+		return equalValues(a, b)
+	case "!=":
+		return !equalValues(a, b)
+	}
+
+	var cmp int
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		if !ok {
+			return false
+		}
+		switch {
+		case x < y:
+			cmp = -1
+		case x > y:
+			cmp = 1
+		}
+	} else if x, ok := a.(string); ok {
+		y, ok := b.(string)
+		if !ok {
+			return false
+		}
+		cmp = strings.Compare(x, y)
+	} else {
+		return false
+	}
+
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default: // ">="
+		return cmp >= 0
+	}
+}
+
+// equalValues checks simple JSON values for equality, arrays and maps are
+// never equal.
+func equalValues(a, b interface{}) bool {
+	if x, ok := toNumber(a); ok {
+		y, ok := toNumber(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case string:
+		y, ok := b.(string)
+		return ok && x == y
+	case bool:
+		y, ok := b.(bool)
+		return ok && x == y
+	case nil:
+		return b == nil
+	default:
+		return false
+	}
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
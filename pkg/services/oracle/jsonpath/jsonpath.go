@@ -16,6 +16,15 @@ type (
 		s     string
 		i     int
 		depth int
+		// nesting is the number of filters path is nested in.
+		nesting int
+		// root is the value path is applied to, it's used by filters.
+		root interface{}
+		// extended enables syntax not supported by the C# node.
+		extended bool
+		// budget is the number of nodes that can still be visited, it's
+		// shared by all parsers created for the path.
+		budget *int
 	}
 )
 
@@ -31,20 +40,43 @@ const (
 	pathIdentifier
 	pathString
 	pathNumber
+	pathQuestion
 )
 
-const maxNestingDepth = 6
+const (
+	maxNestingDepth = 6
+	// maxVisitedNodes limits the number of nodes selected at every step of
+	// the path and evaluated by filters for a single query, filters with
+	// recursive descent in them can otherwise take quadratic time.
+	maxVisitedNodes = 1000000
+)
 
-// Get returns substructures of value selected by path.
+// Get returns substructures of value selected by path. Only the syntax
+// supported by the C# node is accepted.
 // The result is always non-nil unless path is invalid.
 func Get(path string, value interface{}) ([]interface{}, bool) {
+	return get(path, value, false)
+}
+
+// GetExtended is similar to Get, but it also accepts recursive descent with
+// wildcard and bracket expressions (`$..*`, `$..[0]`) and filter expressions
+// (`[?(...)]`).
+func GetExtended(path string, value interface{}) ([]interface{}, bool) {
+	return get(path, value, true)
+}
+
+func get(path string, value interface{}, extended bool) ([]interface{}, bool) {
 	if path == "" {
 		return []interface{}{value}, true
 	}
 
+	budget := maxVisitedNodes
 	p := pathParser{
-		depth: maxNestingDepth,
-		s:     path,
+		depth:    maxNestingDepth,
+		s:        path,
+		root:     value,
+		extended: extended,
+		budget:   &budget,
 	}
 
 	typ, _ := p.nextToken()
@@ -52,28 +84,49 @@ func Get(path string, value interface{}) ([]interface{}, bool) {
 		return nil, false
 	}
 
-	objs := []interface{}{value}
+	objs, ok := p.processSegments([]interface{}{value})
+	// Some steps stop on exhausted budget without failing.
+	if !ok || p.i < len(p.s) || budget < 0 {
+		return nil, false
+	}
+
+	if objs == nil {
+		objs = []interface{}{}
+	}
+	return objs, true
+}
+
+// processSegments applies path segments (dot and bracket expressions) to objs
+// until the end of the path or some other token is reached.
+func (p *pathParser) processSegments(objs []interface{}) ([]interface{}, bool) {
 	for p.i < len(p.s) {
 		var ok bool
 
-		switch typ, _ := p.nextToken(); typ {
-		case pathDot:
+		switch p.s[p.i] {
+		case '.':
+			p.i++
 			objs, ok = p.processDot(objs)
-		case pathLeftBracket:
+		case '[':
+			p.i++
 			objs, ok = p.processLeftBracket(objs)
+		default:
+			return objs, true
 		}
 
 		if !ok {
 			return nil, false
 		}
 	}
-
-	if objs == nil {
-		objs = []interface{}{}
-	}
 	return objs, true
 }
 
+// visit charges n visited nodes to the budget, false is returned if it's
+// exhausted.
+func (p *pathParser) visit(n int) bool {
+	*p.budget -= n
+	return *p.budget >= 0
+}
+
 func (p *pathParser) nextToken() (pathTokenType, string) {
 	var (
 		typ     pathTokenType
@@ -101,6 +154,8 @@ func (p *pathParser) nextToken() (pathTokenType, string) {
 		typ = pathComma
 	case ':':
 		typ = pathColon
+	case '?':
+		typ = pathQuestion
 	case '\'':
 		typ = pathString
 		value, numRead, ok = p.parseString()
@@ -204,22 +259,52 @@ func (p *pathParser) descend(objs []interface{}) ([]interface{}, bool) {
 		}
 	}
 
+	if !p.visit(len(values)) {
+		return nil, false
+	}
 	return values, true
 }
 
-// descendRecursive performs recursive descent.
+// descendRecursive performs recursive descent. It can be followed by an
+// identifier, wildcard or bracket expression which is applied to objs and all
+// of their descendants.
 func (p *pathParser) descendRecursive(objs []interface{}) ([]interface{}, bool) {
-	typ, val := p.nextToken()
-	if typ != pathIdentifier {
-		return nil, false
-	}
-
 	var values []interface{}
 
-	for len(objs) > 0 {
-		newObjs, _ := p.descendByIdentAux(objs, false, val)
-		values = append(values, newObjs...)
-		objs, _ = p.descend(objs)
+	typ, val := p.nextToken()
+	switch typ {
+	case pathIdentifier:
+		for len(objs) > 0 {
+			newObjs, _ := p.descendByIdentAux(objs, false, val)
+			values = append(values, newObjs...)
+			objs, _ = p.descend(objs)
+		}
+	case pathAsterisk:
+		if !p.extended {
+			return nil, false
+		}
+		for {
+			objs, _ = p.descend(objs)
+			if len(objs) == 0 {
+				break
+			}
+			values = append(values, objs...)
+		}
+	case pathLeftBracket:
+		if !p.extended {
+			return nil, false
+		}
+		// One level is left for the bracket expression itself.
+		for len(objs) > 0 {
+			values = append(values, objs...)
+			if p.depth <= 1 {
+				break
+			}
+			objs, _ = p.descend(objs)
+		}
+		return p.processLeftBracket(values)
+	default:
+		return nil, false
 	}
 
 	return values, true
@@ -254,6 +339,9 @@ func (p *pathParser) descendByIdentAux(objs []interface{}, checkDepth bool, name
 			}
 		}
 	}
+	if !p.visit(len(values)) {
+		return nil, false
+	}
 	return values, true
 }
 
@@ -281,14 +369,22 @@ func (p *pathParser) descendByIndex(objs []interface{}, indices ...int) ([]inter
 		}
 	}
 
+	if !p.visit(len(values)) {
+		return nil, false
+	}
 	return values, true
 }
 
 // processLeftBracket processes index expressions which can be either
-// array/map access, array sub-slice or union of indices.
+// array/map access, array sub-slice, union of indices or filter.
 func (p *pathParser) processLeftBracket(objs []interface{}) ([]interface{}, bool) {
 	typ, value := p.nextToken()
 	switch typ {
+	case pathQuestion:
+		if !p.extended {
+			return nil, false
+		}
+		return p.processFilter(objs)
 	case pathAsterisk:
 		typ, _ := p.nextToken()
 		if typ != pathRightBracket {
@@ -441,5 +537,8 @@ func (p *pathParser) descendByRange(objs []interface{}, start, end int) ([]inter
 		values = append(values, arr[subStart:subEnd]...)
 	}
 
+	if !p.visit(len(values)) {
+		return nil, false
+	}
 	return values, true
 }
@@ -2,8 +2,10 @@ package jsonpath
 
 import (
 	"bytes"
+	"io/ioutil"
 	"math"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -15,22 +17,39 @@ type pathTestCase struct {
 	result string
 }
 
-func unmarshalGet(t *testing.T, js string, path string) ([]interface{}, bool) {
+func decodeJSON(t *testing.T, js string) interface{} {
 	var v interface{}
 	buf := bytes.NewBuffer([]byte(js))
 	d := json.NewDecoder(buf)
 	d.UseOrderedObject()
 	require.NoError(t, d.Decode(&v))
-	return Get(path, v)
+	return v
+}
+
+func unmarshalGet(t *testing.T, js string, path string) ([]interface{}, bool) {
+	return Get(path, decodeJSON(t, js))
+}
+
+func unmarshalGetExtended(t *testing.T, js string, path string) ([]interface{}, bool) {
+	return GetExtended(path, decodeJSON(t, js))
 }
 
 func (p *pathTestCase) testUnmarshalGet(t *testing.T, js string) {
 	res, ok := unmarshalGet(t, js, p.path)
 	require.True(t, ok)
+	requireResult(t, p.result, res)
+}
+
+func (p *pathTestCase) testUnmarshalGetExtended(t *testing.T, js string) {
+	res, ok := unmarshalGetExtended(t, js, p.path)
+	require.True(t, ok)
+	requireResult(t, p.result, res)
+}
 
+func requireResult(t *testing.T, expected string, res []interface{}) {
 	data, err := json.Marshal(res)
 	require.NoError(t, err)
-	require.JSONEq(t, p.result, string(data))
+	require.JSONEq(t, expected, string(data))
 }
 
 func TestInvalidPaths(t *testing.T) {
@@ -80,6 +99,36 @@ func TestInvalidPaths(t *testing.T) {
 			require.False(t, ok)
 		})
 	}
+
+	// filterErrCases contains invalid filter expressions.
+	filterErrCases := []string{
+		"$[?",
+		"$[?@.a]",
+		"$[?(@.a]",
+		"$[?(@.a)",
+		"$[?()]",
+		"$[?(1)]",
+		"$[?('a')]",
+		"$[?(@.&)]",
+		"$[?(@.a ==)]",
+		"$[?(@.a = 1)]",
+		"$[?(@.a === 1)]",
+		"$[?(@.a > 1 &&)]",
+		"$[?(@.a || || @.b)]",
+		"$[?(@.a == 'b)]",
+		"$[?(@.a == 1e)]",
+		"$[?(@.a == nil)]",
+		"$[?((@.a)]",
+		"$[?(!!!!!!!!!true)]",
+		"$[?(@[?(@[?(@[?(@[?(@[?(@[?(@[?(@[?(@.a)])])])])])])])])]",
+	}
+
+	for _, tc := range filterErrCases {
+		t.Run(tc, func(t *testing.T) {
+			_, ok := unmarshalGetExtended(t, "{}", tc)
+			require.False(t, ok)
+		})
+	}
 }
 
 func TestDescendByIdent(t *testing.T) {
@@ -212,6 +261,86 @@ func TestUnion(t *testing.T) {
 	}
 }
 
+func TestRecursiveDescent(t *testing.T) {
+	js := `{"a":{"b":{"c":{"d":{"e":{"f":{"g":1}}}}}}}`
+	p := pathTestCase{"$..g", `[1]`}
+	p.testUnmarshalGet(t, js)
+	// Bracket expression needs one more level.
+	p = pathTestCase{"$..['g']", `[]`}
+	p.testUnmarshalGetExtended(t, js)
+	p = pathTestCase{"$..*", `[{"b":{"c":{"d":{"e":{"f":{"g":1}}}}}},{"c":{"d":{"e":{"f":{"g":1}}}}},{"d":{"e":{"f":{"g":1}}}},{"e":{"f":{"g":1}}},{"f":{"g":1}},{"g":1}]`}
+	p.testUnmarshalGetExtended(t, js)
+}
+
+func TestFilter(t *testing.T) {
+	t.Run("depth", func(t *testing.T) {
+		js := `[[[[[[{"a":1}]]]]]]`
+		p := pathTestCase{"$[0][0][0][0][0][?(@.a)]", `[{"a":1}]`}
+		p.testUnmarshalGetExtended(t, js)
+
+		_, ok := unmarshalGetExtended(t, js, "$[0][0][0][0][0][0][?(@.a)]")
+		require.False(t, ok)
+	})
+
+	t.Run("visited nodes limit", func(t *testing.T) {
+		items := make([]string, 1000)
+		for i := range items {
+			items[i] = `{"a":` + strconv.Itoa(i) + `}`
+		}
+		js := "[" + strings.Join(items, ",") + "]"
+
+		p := pathTestCase{"$[?(@.a >= 998)].a", `[998,999]`}
+		p.testUnmarshalGetExtended(t, js)
+
+		// Every element is compared with every other one.
+		_, ok := unmarshalGetExtended(t, js, "$[?($[?(@.a > 1000)])]")
+		require.False(t, ok)
+		_, ok = unmarshalGetExtended(t, js, "$..[?($..* == 1)]")
+		require.False(t, ok)
+	})
+}
+
+// conformanceCorpus contains documents and paths applied to them. Cases
+// marked as extended are only valid for GetExtended, null result means the
+// path is invalid.
+type conformanceCorpus struct {
+	Documents map[string]json.RawMessage `json:"documents"`
+	Cases     []struct {
+		Document string          `json:"document"`
+		Path     string          `json:"path"`
+		Extended bool            `json:"extended"`
+		Result   json.RawMessage `json:"result"`
+	} `json:"cases"`
+}
+
+func TestConformance(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/conformance.json")
+	require.NoError(t, err)
+	var corpus conformanceCorpus
+	require.NoError(t, json.Unmarshal(data, &corpus))
+
+	for _, tc := range corpus.Cases {
+		tc := tc
+		t.Run(tc.Path, func(t *testing.T) {
+			doc, ok := corpus.Documents[tc.Document]
+			require.True(t, ok, "unknown document %s", tc.Document)
+			valid := len(tc.Result) != 0 && string(tc.Result) != "null"
+
+			res, ok := unmarshalGetExtended(t, string(doc), tc.Path)
+			require.Equal(t, valid, ok)
+			if valid {
+				requireResult(t, string(tc.Result), res)
+			}
+
+			res, ok = unmarshalGet(t, string(doc), tc.Path)
+			require.Equal(t, valid && !tc.Extended, ok)
+			if valid && !tc.Extended {
+				requireResult(t, string(tc.Result), res)
+			}
+		})
+	}
+}
+
 // These tests are taken directly from C# code.
 func TestCSharpCompat(t *testing.T) {
 	js := `{
@@ -49,7 +49,15 @@ type (
 		// removed contains ids of requests which won't be processed further due to expiration.
 		removed map[uint64]bool
 
+		// errMtx protects urlErrors map.
+		errMtx    sync.RWMutex
+		urlErrors map[string]*URLError
+
 		wallet *wallet.Wallet
+
+		// handlers contains protocol handlers by URI scheme, it's readonly.
+		handlers map[string]ProtocolHandler
+		fetcher  *fetcher
 	}
 
 	// Config contains oracle module parameters.
@@ -62,6 +70,9 @@ type (
 		ResponseHandler Broadcaster
 		OnTransaction   TxCallback
 		URIValidator    URIValidator
+		// Handlers contains additional protocol handlers by URI scheme, they
+		// override the ones created from the configuration.
+		Handlers map[string]ProtocolHandler
 	}
 
 	// HTTPClient is an interface capable of doing oracle requests.
@@ -104,6 +115,7 @@ func NewOracle(cfg Config) (*Oracle, error) {
 		requestMap: make(chan map[uint64]*state.OracleRequest, 1),
 		responses:  make(map[uint64]*incompleteTx),
 		removed:    make(map[uint64]bool),
+		urlErrors:  make(map[string]*URLError),
 	}
 	if o.MainCfg.RequestTimeout == 0 {
 		o.MainCfg.RequestTimeout = defaultRequestTimeout
@@ -154,6 +166,10 @@ func NewOracle(cfg Config) (*Oracle, error) {
 	if o.URIValidator == nil {
 		o.URIValidator = defaultURIValidator
 	}
+	if err := o.initHandlers(); err != nil {
+		return nil, err
+	}
+	o.fetcher = newFetcher(o.handlers, o.MainCfg.Cache, o.MainCfg.HostLimits)
 	return o, nil
 }
 
@@ -193,6 +209,7 @@ func (o *Oracle) Run() {
 			for id := range o.removed {
 				delete(o.responses, id)
 			}
+			setPendingRequestsMetric(len(o.responses))
 			o.respMtx.Unlock()
 
 			for _, id := range reprocess {
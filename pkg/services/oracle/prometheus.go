@@ -0,0 +1,76 @@
+package oracle
+
+import (
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of oracle requests served from the cache",
+			Name:      "oracle_cache_hits",
+			Namespace: "neogo",
+		},
+	)
+	deduplicatedRequests = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Help:      "Number of oracle requests that waited for the same URL to be fetched by another request",
+			Name:      "oracle_deduplicated_requests",
+			Namespace: "neogo",
+		},
+	)
+	throttledRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of oracle requests delayed because of host limits",
+			Name:      "oracle_throttled_requests",
+			Namespace: "neogo",
+		},
+		[]string{"limit"},
+	)
+	pendingRequests = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Help:      "Number of oracle requests being processed",
+			Name:      "oracle_pending_requests",
+			Namespace: "neogo",
+		},
+	)
+	processedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of oracle requests processed by response code",
+			Name:      "oracle_processed_requests",
+			Namespace: "neogo",
+		},
+		[]string{"code"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		cacheHits,
+		deduplicatedRequests,
+		throttledRequests,
+		pendingRequests,
+		processedRequests,
+	)
+}
+
+func addCacheHitsMetric() {
+	cacheHits.Inc()
+}
+
+func addDeduplicatedRequestsMetric() {
+	deduplicatedRequests.Inc()
+}
+
+func addThrottledRequestsMetric(limit string) {
+	throttledRequests.WithLabelValues(limit).Inc()
+}
+
+func setPendingRequestsMetric(n int) {
+	pendingRequests.Set(float64(n))
+}
+
+func addProcessedRequestsMetric(code transaction.OracleResponseCode) {
+	processedRequests.WithLabelValues(code.String()).Inc()
+}
@@ -0,0 +1,268 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+)
+
+const (
+	ipfsURIScheme = "ipfs"
+	fileURIScheme = "file"
+	execURIScheme = "exec"
+)
+
+type (
+	// protocolLimits are limits common for additional protocol handlers.
+	protocolLimits struct {
+		timeout      time.Duration
+		maxSize      int
+		allowedTypes []string
+	}
+
+	// ipfsHandler gets IPFS data via HTTP gateway.
+	ipfsHandler struct {
+		protocolLimits
+		client  HTTPClient
+		gateway string
+	}
+
+	// fileHandler reads local files from the root directory.
+	fileHandler struct {
+		protocolLimits
+		root string
+	}
+
+	// execHandler runs local command and returns its output.
+	execHandler struct {
+		protocolLimits
+		command string
+		args    []string
+	}
+)
+
+func newProtocolLimits(cfg config.OracleProtocolConfiguration, defaultTimeout time.Duration) protocolLimits {
+	l := protocolLimits{
+		timeout:      cfg.Timeout,
+		maxSize:      cfg.MaxResponseSize,
+		allowedTypes: cfg.AllowedContentTypes,
+	}
+	if l.timeout <= 0 {
+		l.timeout = defaultTimeout
+	}
+	if l.maxSize <= 0 || l.maxSize > transaction.MaxOracleResultSize {
+		l.maxSize = transaction.MaxOracleResultSize
+	}
+	return l
+}
+
+// checkContentType checks data type (derived from file extension if it's
+// not empty or detected from the data itself).
+func (l protocolLimits) checkContentType(data []byte, ext string) error {
+	if len(l.allowedTypes) == 0 {
+		return nil
+	}
+	typ := mime.TypeByExtension(ext)
+	if typ == "" {
+		typ = http.DetectContentType(data)
+	}
+	if !checkMediaType(typ, l.allowedTypes) {
+		return fmt.Errorf("%w: %s", ErrContentTypeNotSupported, typ)
+	}
+	return nil
+}
+
+func newIPFSHandler(cfg config.IPFSConfiguration, client HTTPClient, defaultTimeout time.Duration) (*ipfsHandler, error) {
+	if cfg.Gateway == "" {
+		return nil, errors.New("gateway is not set")
+	}
+	return &ipfsHandler{
+		protocolLimits: newProtocolLimits(cfg.OracleProtocolConfiguration, defaultTimeout),
+		client:         client,
+		gateway:        strings.TrimSuffix(cfg.Gateway, "/"),
+	}, nil
+}
+
+// Fetch implements ProtocolHandler interface. Both "ipfs://<CID>/<path>" and
+// "ipfs:<CID>/<path>" forms are accepted, path can't point outside of the CID.
+func (h *ipfsHandler) Fetch(ctx context.Context, req *ProtocolRequest) (*ProtocolResponse, error) {
+	p, err := ipfsPath(req.URL)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	res, err := fetchHTTP(ctx, h.client, h.gateway+p, h.allowedTypes, h.maxSize)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return res, err
+}
+
+// ipfsPath returns escaped gateway path for the given IPFS URI. The path is
+// cleaned and it must stay within /ipfs/<CID>.
+func ipfsPath(u *url.URL) (string, error) {
+	p := u.Opaque
+	if p == "" {
+		p = u.Host + u.EscapedPath()
+	}
+	p, err := url.PathUnescape(p)
+	if err != nil {
+		return "", fmt.Errorf("bad path: %w", err)
+	}
+	cid := strings.SplitN(p, "/", 2)[0]
+	if cid == "" || cid == "." || cid == ".." {
+		return "", errors.New("CID is missing from URI")
+	}
+	root := "/ipfs/" + cid
+	p = path.Clean("/ipfs/" + p)
+	if p != root && !strings.HasPrefix(p, root+"/") {
+		return "", fmt.Errorf("%w: path is outside of %s", ErrForbidden, cid)
+	}
+	return (&url.URL{Path: p}).EscapedPath(), nil
+}
+
+func newFileHandler(cfg config.FileConfiguration, defaultTimeout time.Duration) (*fileHandler, error) {
+	if cfg.Root == "" {
+		return nil, errors.New("root directory is not set")
+	}
+	root, err := filepath.Abs(cfg.Root)
+	if err == nil {
+		root, err = filepath.EvalSymlinks(root)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("bad root directory: %w", err)
+	}
+	return &fileHandler{
+		protocolLimits: newProtocolLimits(cfg.OracleProtocolConfiguration, defaultTimeout),
+		root:           root,
+	}, nil
+}
+
+// Fetch implements ProtocolHandler interface. URI path is relative to the
+// root directory, files outside of it (including symlinks pointing outside)
+// can't be read.
+func (h *fileHandler) Fetch(ctx context.Context, req *ProtocolRequest) (*ProtocolResponse, error) {
+	if req.URL.Host != "" && req.URL.Host != "localhost" {
+		return nil, fmt.Errorf("%w: remote host %s", ErrForbidden, req.URL.Host)
+	}
+	p := req.URL.Opaque
+	if p == "" {
+		p = req.URL.Path
+	}
+	name := filepath.Join(h.root, filepath.FromSlash(path.Clean("/"+p)))
+	name, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
+		}
+		return nil, err
+	}
+	if name != h.root && !strings.HasPrefix(name, h.root+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%w: path is outside of the root directory", ErrForbidden)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, fmt.Errorf("%w: %v", ErrForbidden, err)
+		}
+		return nil, err
+	}
+	if st, err := f.Stat(); err != nil || st.IsDir() {
+		f.Close()
+		return nil, fmt.Errorf("%w: not a regular file", ErrNotFound)
+	}
+	data, err := readResponse(f, h.maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+	}
+	if err := h.checkContentType(data, filepath.Ext(name)); err != nil {
+		return nil, err
+	}
+	return &ProtocolResponse{Data: data}, nil
+}
+
+func newExecHandler(cfg config.ExecConfiguration, defaultTimeout time.Duration) (*execHandler, error) {
+	if cfg.Command == "" {
+		return nil, errors.New("command is not set")
+	}
+	return &execHandler{
+		protocolLimits: newProtocolLimits(cfg.OracleProtocolConfiguration, defaultTimeout),
+		command:        cfg.Command,
+		args:           cfg.Args,
+	}, nil
+}
+
+// Fetch implements ProtocolHandler interface. The command is run with the
+// request URI as the last argument, its standard output is the result and
+// non-zero exit code means failure. Output is read in a separate goroutine,
+// so that Fetch returns on timeout even if some child process of the command
+// keeps its standard output open.
+func (h *execHandler) Fetch(ctx context.Context, req *ProtocolRequest) (*ProtocolResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	args := make([]string, len(h.args), len(h.args)+1)
+	copy(args, h.args)
+	cmd := exec.CommandContext(ctx, h.command, append(args, req.URL.String())...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start command: %w", err)
+	}
+	type readResult struct {
+		data []byte
+		err  error
+	}
+	ch := make(chan readResult, 1)
+	go func() {
+		data, err := readResponse(stdout, h.maxSize)
+		ch <- readResult{data, err}
+	}()
+	var (
+		data    []byte
+		readErr error
+	)
+	select {
+	case <-ctx.Done():
+		// The command is killed by the context, Wait closes the pipe
+		// which stops the reader.
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("%w: command is running for too long", ErrTimeout)
+	case res := <-ch:
+		data, readErr = res.data, res.err
+	}
+	if readErr != nil {
+		_ = cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return nil, fmt.Errorf("%w: command is running for too long", ErrTimeout)
+	case readErr != nil:
+		return nil, readErr
+	case waitErr != nil:
+		return nil, fmt.Errorf("command failed: %w", waitErr)
+	}
+	if err := h.checkContentType(data, ""); err != nil {
+		return nil, err
+	}
+	return &ProtocolResponse{Data: data}, nil
+}
@@ -0,0 +1,211 @@
+package oracle
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/stretchr/testify/require"
+)
+
+func fetch(t *testing.T, h ProtocolHandler, uri string) ([]byte, error) {
+	u, err := url.ParseRequestURI(uri)
+	require.NoError(t, err)
+	res, err := h.Fetch(context.Background(), &ProtocolRequest{URL: u})
+	if err != nil {
+		return nil, err
+	}
+	return res.Data, nil
+}
+
+func requireCode(t *testing.T, code transaction.OracleResponseCode, err error) {
+	require.Error(t, err)
+	require.Equal(t, code, responseCode(err), err.Error())
+}
+
+func TestInitHandlers(t *testing.T) {
+	o := &Oracle{Config: Config{Client: http.DefaultClient}}
+	require.NoError(t, o.initHandlers())
+	require.Equal(t, 1, len(o.handlers))
+	require.NotNil(t, o.handlers["https"])
+
+	custom := &execHandler{}
+	o.MainCfg.NeoFS.Nodes = []string{"localhost:8080"}
+	o.MainCfg.IPFS.Enabled = true
+	o.MainCfg.File.Enabled = true
+	o.MainCfg.Exec.Enabled = true
+	o.Handlers = map[string]ProtocolHandler{"custom": custom}
+	require.Error(t, o.initHandlers())
+	o.MainCfg.IPFS.Gateway = "http://localhost:8080"
+	require.Error(t, o.initHandlers())
+	o.MainCfg.File.Root = os.TempDir()
+	require.Error(t, o.initHandlers())
+	o.MainCfg.Exec.Command = "echo"
+	require.NoError(t, o.initHandlers())
+	for _, scheme := range []string{"https", "neofs", "ipfs", "file", "exec"} {
+		require.NotNil(t, o.handlers[scheme], scheme)
+	}
+	require.Equal(t, custom, o.handlers["custom"])
+}
+
+func TestIPFSHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ipfs/QmJSON/data.json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"key":"value"}`))
+		case "/ipfs/QmGIF":
+			w.Header().Set("Content-Type", "image/gif")
+			_, _ = w.Write([]byte{1, 2, 3})
+		case "/ipfs/QmBig":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(make([]byte, 21))
+		case "/ipfs/QmSlow":
+			time.Sleep(time.Second)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	h, err := newIPFSHandler(config.IPFSConfiguration{
+		OracleProtocolConfiguration: config.OracleProtocolConfiguration{
+			Timeout:             100 * time.Millisecond,
+			MaxResponseSize:     20,
+			AllowedContentTypes: []string{"application/json"},
+		},
+		Gateway: srv.URL + "/",
+	}, http.DefaultClient, time.Second)
+	require.NoError(t, err)
+
+	for _, uri := range []string{"ipfs://QmJSON/data.json", "ipfs:QmJSON/data.json", "ipfs://QmJSON/sub/../data.json"} {
+		res, err := fetch(t, h, uri)
+		require.NoError(t, err, uri)
+		require.Equal(t, `{"key":"value"}`, string(res))
+	}
+
+	_, err = fetch(t, h, "ipfs://QmGIF")
+	requireCode(t, transaction.ContentTypeNotSupported, err)
+	_, err = fetch(t, h, "ipfs://QmBig")
+	requireCode(t, transaction.ResponseTooLarge, err)
+	_, err = fetch(t, h, "ipfs://QmUnknown")
+	requireCode(t, transaction.NotFound, err)
+	_, err = fetch(t, h, "ipfs://QmSlow")
+	requireCode(t, transaction.Timeout, err)
+
+	// Paths can't point outside of the CID.
+	for _, uri := range []string{"ipfs://QmGIF/../QmJSON/data.json", "ipfs:QmGIF/%2e%2e/QmJSON/data.json"} {
+		_, err = fetch(t, h, uri)
+		requireCode(t, transaction.Forbidden, err)
+	}
+	for _, uri := range []string{"ipfs:", "ipfs:../QmJSON/data.json"} {
+		_, err = fetch(t, h, uri)
+		requireCode(t, transaction.Error, err)
+	}
+}
+
+func TestFileHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "oracle-file")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	root := filepath.Join(dir, "root")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sub"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "sub", "data.json"), []byte(`{"a":1}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "image.gif"), []byte("GIF89a"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(root, "big.json"), make([]byte, 100), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "secret.json"), []byte(`{"password":1}`), 0644))
+
+	h, err := newFileHandler(config.FileConfiguration{
+		OracleProtocolConfiguration: config.OracleProtocolConfiguration{
+			MaxResponseSize:     50,
+			AllowedContentTypes: []string{"application/json"},
+		},
+		Root: root,
+	}, time.Second)
+	require.NoError(t, err)
+
+	_, err = newFileHandler(config.FileConfiguration{Root: filepath.Join(dir, "unknown")}, time.Second)
+	require.Error(t, err)
+
+	for _, uri := range []string{"file:///sub/data.json", "file://localhost/sub/data.json", "file:sub/data.json"} {
+		res, err := fetch(t, h, uri)
+		require.NoError(t, err, uri)
+		require.Equal(t, `{"a":1}`, string(res))
+	}
+
+	_, err = fetch(t, h, "file:///image.gif")
+	requireCode(t, transaction.ContentTypeNotSupported, err)
+	_, err = fetch(t, h, "file:///big.json")
+	requireCode(t, transaction.ResponseTooLarge, err)
+	_, err = fetch(t, h, "file:///unknown.json")
+	requireCode(t, transaction.NotFound, err)
+	_, err = fetch(t, h, "file:///sub")
+	requireCode(t, transaction.NotFound, err)
+	_, err = fetch(t, h, "file://remote.host/sub/data.json")
+	requireCode(t, transaction.Forbidden, err)
+	// Paths are cleaned, so nothing outside of the root can be accessed.
+	_, err = fetch(t, h, "file:///../secret.json")
+	requireCode(t, transaction.NotFound, err)
+
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Symlink(filepath.Join(dir, "secret.json"), filepath.Join(root, "link.json")))
+		_, err = fetch(t, h, "file:///link.json")
+		requireCode(t, transaction.Forbidden, err)
+	}
+}
+
+func TestExecHandler(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX shell is required")
+	}
+	newHandler := func(t *testing.T, script string) ProtocolHandler {
+		h, err := newExecHandler(config.ExecConfiguration{
+			OracleProtocolConfiguration: config.OracleProtocolConfiguration{
+				Timeout:             200 * time.Millisecond,
+				MaxResponseSize:     20,
+				AllowedContentTypes: []string{"text/plain"},
+			},
+			Command: "sh",
+			Args:    []string{"-c", script},
+		}, time.Second)
+		require.NoError(t, err)
+		return h
+	}
+
+	// URI is passed as the last argument which is $0 for `sh -c`.
+	res, err := fetch(t, newHandler(t, `printf %s "$0"`), "exec://price/neo")
+	require.NoError(t, err)
+	require.Equal(t, "exec://price/neo", string(res))
+
+	_, err = fetch(t, newHandler(t, "exit 1"), "exec://fail")
+	requireCode(t, transaction.Error, err)
+	_, err = fetch(t, newHandler(t, "head -c 100 /dev/zero | tr '\\0' a"), "exec://big")
+	requireCode(t, transaction.ResponseTooLarge, err)
+	_, err = fetch(t, newHandler(t, "printf 'GIF89a'"), "exec://gif")
+	requireCode(t, transaction.ContentTypeNotSupported, err)
+	_, err = fetch(t, newHandler(t, "exec sleep 5"), "exec://slow")
+	requireCode(t, transaction.Timeout, err)
+	// Child process keeps the output open after the command exits.
+	start := time.Now()
+	_, err = fetch(t, newHandler(t, "sleep 5 & printf 'a'"), "exec://child")
+	requireCode(t, transaction.Timeout, err)
+	require.True(t, time.Since(start) < 2*time.Second)
+
+	_, err = newExecHandler(config.ExecConfiguration{}, time.Second)
+	require.Error(t, err)
+	h, err := newExecHandler(config.ExecConfiguration{Command: filepath.Join(os.TempDir(), "unknown-command")}, time.Second)
+	require.NoError(t, err)
+	_, err = fetch(t, h, "exec://x")
+	require.True(t, err != nil && !errors.Is(err, ErrTimeout))
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"mime"
-	"net/http"
 	"net/url"
 	"time"
 
@@ -12,7 +11,6 @@ import (
 	"github.com/nspcc-dev/neo-go/pkg/core/storage"
 	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
-	"github.com/nspcc-dev/neo-go/pkg/services/oracle/neofs"
 	"go.uber.org/zap"
 )
 
@@ -49,6 +47,7 @@ func (o *Oracle) RemoveRequests(ids []uint64) {
 	for _, id := range ids {
 		delete(o.responses, id)
 	}
+	setPendingRequestsMetric(len(o.responses))
 }
 
 // AddRequests saves all requests in-fly for further processing.
@@ -101,74 +100,24 @@ func (o *Oracle) processRequest(priv *keys.PrivateKey, req request) error {
 	if err != nil {
 		o.Log.Warn("malformed oracle request", zap.String("url", req.Req.URL), zap.Error(err))
 		resp.Code = transaction.ProtocolNotSupported
+		o.addURLError(req.Req.URL, resp.Code, err)
 	} else {
-		switch u.Scheme {
-		case "https":
-			if !o.MainCfg.AllowPrivateHost {
-				err = o.URIValidator(u)
-				if err != nil {
-					o.Log.Warn("forbidden oracle request", zap.String("url", req.Req.URL))
-					resp.Code = transaction.Forbidden
-					break
-				}
-			}
-			httpReq, err := http.NewRequest("GET", req.Req.URL, nil)
-			if err != nil {
-				o.Log.Warn("failed to create http request", zap.String("url", req.Req.URL), zap.Error(err))
-				resp.Code = transaction.Error
-				break
-			}
-			httpReq.Header.Set("User-Agent", "NeoOracleService/3.0")
-			httpReq.Header.Set("Content-Type", "application/json")
-			r, err := o.Client.Do(httpReq)
-			if err != nil {
-				o.Log.Warn("oracle request failed", zap.String("url", req.Req.URL), zap.Error(err))
-				resp.Code = transaction.Error
-				break
-			}
-			switch r.StatusCode {
-			case http.StatusOK:
-				if !checkMediaType(r.Header.Get("Content-Type"), o.MainCfg.AllowedContentTypes) {
-					resp.Code = transaction.ContentTypeNotSupported
-					break
-				}
-
-				result, err := readResponse(r.Body, transaction.MaxOracleResultSize)
-				if err != nil {
-					if errors.Is(err, ErrResponseTooLarge) {
-						resp.Code = transaction.ResponseTooLarge
-					} else {
-						resp.Code = transaction.Error
-					}
-					o.Log.Warn("failed to read data for oracle request", zap.String("url", req.Req.URL), zap.Error(err))
-					break
-				}
-				resp.Code, resp.Result = filterRequest(result, req.Req)
-			case http.StatusForbidden:
-				resp.Code = transaction.Forbidden
-			case http.StatusNotFound:
-				resp.Code = transaction.NotFound
-			case http.StatusRequestTimeout:
-				resp.Code = transaction.Timeout
-			default:
-				resp.Code = transaction.Error
-			}
-		case neofs.URIScheme:
-			ctx, cancel := context.WithTimeout(context.Background(), o.MainCfg.NeoFS.Timeout)
-			defer cancel()
-			index := (int(req.ID) + incTx.attempts) % len(o.MainCfg.NeoFS.Nodes)
-			res, err := neofs.Get(ctx, priv, u, o.MainCfg.NeoFS.Nodes[index])
-			if err != nil {
-				o.Log.Warn("oracle request failed", zap.String("url", req.Req.URL), zap.Error(err))
-				resp.Code = transaction.Error
-			} else {
-				resp.Code, resp.Result = filterRequest(res, req.Req)
-			}
-		default:
-			resp.Code = transaction.ProtocolNotSupported
-			o.Log.Warn("unknown oracle request scheme", zap.String("url", req.Req.URL))
+		res, err := o.fetcher.fetch(context.Background(), &ProtocolRequest{
+			ID:      req.ID,
+			URL:     u,
+			Attempt: incTx.attempts,
+			Key:     priv,
+		})
+		if err != nil {
+			o.Log.Warn("oracle request failed", zap.String("url", req.Req.URL), zap.Error(err))
+			resp.Code = responseCode(err)
+			o.addURLError(req.Req.URL, resp.Code, err)
+		} else {
+			o.removeURLError(req.Req.URL)
+			resp.Code, resp.Result = filterRequest(res, req.Req, o.MainCfg.ExtendedFilters)
 		}
 	}
+	addProcessedRequestsMetric(resp.Code)
 	o.Log.Debug("oracle request processed", zap.String("url", req.Req.URL), zap.Int("code", int(resp.Code)), zap.String("result", string(resp.Result)))
 
 	currentHeight := o.Chain.BlockHeight()
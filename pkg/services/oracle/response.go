@@ -23,6 +23,7 @@ func (o *Oracle) getResponse(reqID uint64, create bool) *incompleteTx {
 	if !ok && create && !o.removed[reqID] {
 		incTx = newIncompleteTx()
 		o.responses[reqID] = incTx
+		setPendingRequestsMetric(len(o.responses))
 	}
 	return incTx
 }
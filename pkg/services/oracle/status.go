@@ -0,0 +1,249 @@
+package oracle
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/core/transaction"
+	"github.com/nspcc-dev/neo-go/pkg/smartcontract"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+)
+
+// maxURLErrors is the maximum number of URLs last errors are kept for.
+const maxURLErrors = 100
+
+type (
+	// Status is the oracle service state.
+	Status struct {
+		// Account is the script hash of the oracle node account, it's
+		// nil if the node is not in the oracle nodes list.
+		Account *util.Uint160
+		// Nodes is the number of oracle nodes.
+		Nodes int
+		// Required is the number of signatures needed for response.
+		Required int
+		// Pending is the number of requests being processed.
+		Pending int
+		// InFlight contains URLs being fetched now.
+		InFlight []string
+		// Cached is the number of cached URLs.
+		Cached int
+		// Errors contains last errors for URLs failing to be fetched.
+		Errors []URLError
+	}
+
+	// RequestStatus is the state of a single request.
+	RequestStatus struct {
+		ID uint64
+		// URL is empty if the request wasn't processed by this node yet
+		// (only other nodes' signatures were received for it).
+		URL string
+		// Attempts is the number of times request was processed.
+		Attempts int
+		// LastProcessed is the time request was processed last.
+		LastProcessed time.Time
+		// Sent is true if response transaction was already sent.
+		Sent bool
+		// TxHash and BackupTxHash are the hashes of response transactions,
+		// they're nil if the request wasn't processed yet.
+		TxHash       *util.Uint256
+		BackupTxHash *util.Uint256
+		// Signatures and BackupSignatures are the numbers of valid
+		// signatures collected for response and backup transactions.
+		Signatures       int
+		BackupSignatures int
+	}
+
+	// URLError is the last error occurred while fetching some URL.
+	URLError struct {
+		URL   string
+		Code  transaction.OracleResponseCode
+		Error string
+		Time  time.Time
+		// Count is the number of failures in a row.
+		Count int
+	}
+)
+
+// Errors returned by request control methods.
+var (
+	ErrRequestNotFound     = errors.New("request is not found")
+	ErrRequestNotProcessed = errors.New("request wasn't processed by this node yet")
+	ErrResponseSent        = errors.New("response is already sent")
+)
+
+// GetStatus returns the current oracle service state.
+func (o *Oracle) GetStatus() Status {
+	nodes := o.getOracleNodes()
+	s := Status{
+		Nodes:    len(nodes),
+		Required: smartcontract.GetDefaultHonestNodeCount(len(nodes)),
+	}
+	if acc := o.getAccount(); acc != nil {
+		h := acc.Contract.ScriptHash()
+		s.Account = &h
+	}
+
+	o.respMtx.RLock()
+	s.Pending = len(o.responses)
+	o.respMtx.RUnlock()
+
+	s.InFlight, s.Cached = o.fetcher.stats()
+
+	o.errMtx.RLock()
+	s.Errors = make([]URLError, 0, len(o.urlErrors))
+	for _, e := range o.urlErrors {
+		s.Errors = append(s.Errors, *e)
+	}
+	o.errMtx.RUnlock()
+	sort.Slice(s.Errors, func(i, j int) bool { return s.Errors[i].Time.After(s.Errors[j].Time) })
+	return s
+}
+
+// GetRequests returns the states of requests being processed ordered by ID.
+func (o *Oracle) GetRequests() []RequestStatus {
+	o.respMtx.RLock()
+	res := make([]RequestStatus, 0, len(o.responses))
+	for id, incTx := range o.responses {
+		res = append(res, incTx.status(id))
+	}
+	o.respMtx.RUnlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res
+}
+
+// GetRequest returns the state of the request with the specified ID.
+func (o *Oracle) GetRequest(id uint64) (RequestStatus, error) {
+	incTx := o.getResponse(id, false)
+	if incTx == nil {
+		return RequestStatus{}, ErrRequestNotFound
+	}
+	return incTx.status(id), nil
+}
+
+// RetryRequest schedules request with the specified ID to be processed
+// again, the data is fetched again (cached data for its URL is dropped) and
+// new response is signed. It can only be done for requests already processed
+// by this node which response wasn't sent yet.
+func (o *Oracle) RetryRequest(id uint64) error {
+	incTx, err := o.getControlledResponse(id)
+	if err != nil {
+		return err
+	}
+	incTx.RLock()
+	req := request{ID: id, Req: incTx.request}
+	incTx.RUnlock()
+
+	if u, err := url.ParseRequestURI(req.Req.URL); err == nil {
+		o.fetcher.invalidate(u)
+	}
+
+	go func() {
+		select {
+		case o.requestCh <- req:
+		case <-o.close:
+		}
+	}()
+	return nil
+}
+
+// SkipRequest stops processing the request with the specified ID and sends
+// backup (failed) response for it immediately, like it's done for requests
+// that can't be processed for too long. It can only be done for requests
+// already processed by this node which response wasn't sent yet.
+func (o *Oracle) SkipRequest(id uint64) error {
+	if _, err := o.getControlledResponse(id); err != nil {
+		return err
+	}
+	acc := o.getAccount()
+	if acc == nil {
+		return errors.New("oracle account is not available")
+	}
+	o.processFailedRequest(acc.PrivateKey(), request{ID: id})
+	return nil
+}
+
+// getControlledResponse returns request that can be retried or skipped.
+func (o *Oracle) getControlledResponse(id uint64) (*incompleteTx, error) {
+	incTx := o.getResponse(id, false)
+	if incTx == nil {
+		return nil, ErrRequestNotFound
+	}
+	incTx.RLock()
+	defer incTx.RUnlock()
+	switch {
+	case incTx.request == nil || incTx.backupTx == nil:
+		return nil, ErrRequestNotProcessed
+	case incTx.isSent:
+		return nil, ErrResponseSent
+	}
+	return incTx, nil
+}
+
+// addURLError saves the error occurred while fetching URL.
+func (o *Oracle) addURLError(u string, code transaction.OracleResponseCode, err error) {
+	o.errMtx.Lock()
+	defer o.errMtx.Unlock()
+	e, ok := o.urlErrors[u]
+	if !ok {
+		if len(o.urlErrors) >= maxURLErrors {
+			var oldest *URLError
+			for _, e := range o.urlErrors {
+				if oldest == nil || e.Time.Before(oldest.Time) {
+					oldest = e
+				}
+			}
+			delete(o.urlErrors, oldest.URL)
+		}
+		e = &URLError{URL: u}
+		o.urlErrors[u] = e
+	}
+	e.Code = code
+	e.Error = err.Error()
+	e.Time = time.Now()
+	e.Count++
+}
+
+// removeURLError removes the error for URL fetched successfully.
+func (o *Oracle) removeURLError(u string) {
+	o.errMtx.Lock()
+	delete(o.urlErrors, u)
+	o.errMtx.Unlock()
+}
+
+func (t *incompleteTx) status(id uint64) RequestStatus {
+	t.RLock()
+	defer t.RUnlock()
+	s := RequestStatus{
+		ID:               id,
+		Attempts:         t.attempts,
+		LastProcessed:    t.time,
+		Sent:             t.isSent,
+		Signatures:       countSignatures(t.sigs),
+		BackupSignatures: countSignatures(t.backupSigs),
+	}
+	if t.request != nil {
+		s.URL = t.request.URL
+	}
+	if t.tx != nil {
+		h := t.tx.Hash()
+		s.TxHash = &h
+	}
+	if t.backupTx != nil {
+		h := t.backupTx.Hash()
+		s.BackupTxHash = &h
+	}
+	return s
+}
+
+func countSignatures(sigs map[string]*txSignature) int {
+	var n int
+	for _, sig := range sigs {
+		if sig.ok {
+			n++
+		}
+	}
+	return n
+}
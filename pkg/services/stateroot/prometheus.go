@@ -0,0 +1,50 @@
+package stateroot
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	verifiedHeight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Help:      "Latest height local state root was verified against trusted node at",
+			Name:      "stateroot_verified_height",
+			Namespace: "neogo",
+		},
+		[]string{"node"},
+	)
+	mismatchHeight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Help:      "First height local state root differs from trusted node's one at",
+			Name:      "stateroot_mismatch_height",
+			Namespace: "neogo",
+		},
+		[]string{"node"},
+	)
+	verificationErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Help:      "Number of failed state root verification attempts",
+			Name:      "stateroot_verification_errors",
+			Namespace: "neogo",
+		},
+		[]string{"node"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		verifiedHeight,
+		mismatchHeight,
+		verificationErrors,
+	)
+}
+
+func setVerifiedHeightMetric(node string, h uint32) {
+	verifiedHeight.WithLabelValues(node).Set(float64(h))
+}
+
+func setMismatchHeightMetric(node string, h uint32) {
+	mismatchHeight.WithLabelValues(node).Set(float64(h))
+}
+
+func addVerificationErrorsMetric(node string) {
+	verificationErrors.WithLabelValues(node).Inc()
+}
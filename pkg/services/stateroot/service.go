@@ -25,6 +25,8 @@ type (
 		OnPayload(p *payload.Extensible) error
 		AddSignature(height uint32, validatorIndex int32, sig []byte) error
 		GetConfig() config.StateRoot
+		GetVerificationStatus() (VerificationStatus, error)
+		SetHaltCallback(f func())
 		Run()
 		Shutdown()
 	}
@@ -51,6 +53,12 @@ type (
 		relayExtensible RelayCallback
 		blockCh         chan *block.Block
 		done            chan struct{}
+
+		verMtx         sync.RWMutex
+		verifyInterval time.Duration
+		trustedNodes   []*trustedNode
+		halted         bool
+		onHalt         func()
 	}
 )
 
@@ -96,6 +104,11 @@ func New(cfg config.StateRoot, log *zap.Logger, bc blockchainer.Blockchainer, cb
 
 		s.SetUpdateValidatorsCallback(s.updateValidators)
 	}
+	if cfg.Verification.Enabled {
+		if err := s.initVerification(cfg.Verification); err != nil {
+			return nil, err
+		}
+	}
 	return s, nil
 }
 
@@ -19,9 +19,16 @@ const (
 
 // Run runs service instance in a separate goroutine.
 func (s *service) Run() {
-	s.log.Info("starting state validation service")
-	s.chain.SubscribeForBlocks(s.blockCh)
-	go s.run()
+	if s.MainCfg.Enabled {
+		s.log.Info("starting state validation service")
+		s.chain.SubscribeForBlocks(s.blockCh)
+		go s.run()
+	}
+	if s.MainCfg.Verification.Enabled {
+		s.log.Info("starting state root verification",
+			zap.Strings("nodes", s.MainCfg.Verification.TrustedNodes))
+		go s.runVerification()
+	}
 }
 
 func (s *service) run() {
@@ -54,7 +61,9 @@ drainloop:
 
 // Shutdown stops the service.
 func (s *service) Shutdown() {
-	s.chain.UnsubscribeFromBlocks(s.blockCh)
+	if s.MainCfg.Enabled {
+		s.chain.UnsubscribeFromBlocks(s.blockCh)
+	}
 	close(s.done)
 }
 
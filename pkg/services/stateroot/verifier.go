@@ -0,0 +1,236 @@
+package stateroot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/client"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"go.uber.org/zap"
+)
+
+type (
+	// VerificationStatus is the state of local state roots verification
+	// against trusted nodes.
+	VerificationStatus struct {
+		// Halted is true if block processing was stopped because of state
+		// mismatch.
+		Halted bool
+		Nodes  []NodeStatus
+	}
+
+	// NodeStatus is the state of verification against a single trusted node.
+	NodeStatus struct {
+		Address string
+		// VerifiedHeight is the latest height local state root was found to
+		// be the same as the node's one at.
+		VerifiedHeight uint32
+		// LastError is the error of the latest verification attempt, if any.
+		LastError error
+		// Mismatch is not nil if local state diverges from the node's one.
+		Mismatch *Mismatch
+	}
+
+	// Mismatch describes the first height local state root differs from the
+	// trusted node's one at.
+	Mismatch struct {
+		Height     uint32
+		LocalRoot  util.Uint256
+		RemoteRoot util.Uint256
+	}
+
+	// rootFetcher is an interface to get state roots from a trusted node,
+	// it's implemented by RPC client.
+	rootFetcher interface {
+		GetStateHeight() (*result.StateHeight, error)
+		GetStateRootByHeight(height uint32) (*state.MPTRoot, error)
+	}
+
+	trustedNode struct {
+		fetcher rootFetcher
+		// verified is true if at least one state root was verified.
+		verified bool
+		status   NodeStatus
+	}
+)
+
+var errVerificationDisabled = errors.New("state root verification is not enabled")
+
+// initVerification creates clients for trusted nodes.
+func (s *service) initVerification(cfg config.StateRootVerification) error {
+	if len(cfg.TrustedNodes) == 0 {
+		return errors.New("no trusted nodes specified for state root verification")
+	}
+	s.verifyInterval = cfg.Interval
+	if s.verifyInterval <= 0 {
+		s.verifyInterval = s.timePerBlock
+	}
+	for _, addr := range cfg.TrustedNodes {
+		c, err := client.New(context.Background(), addr, client.Options{
+			DialTimeout:    cfg.RequestTimeout,
+			RequestTimeout: cfg.RequestTimeout,
+		})
+		if err != nil {
+			return fmt.Errorf("can't create client for trusted node %s: %w", addr, err)
+		}
+		s.trustedNodes = append(s.trustedNodes, &trustedNode{
+			fetcher: c,
+			status:  NodeStatus{Address: addr},
+		})
+	}
+	return nil
+}
+
+// SetHaltCallback sets callback to stop block processing, it's called once
+// local state mismatch is detected if HaltOnMismatch is enabled.
+func (s *service) SetHaltCallback(f func()) {
+	s.verMtx.Lock()
+	s.onHalt = f
+	s.verMtx.Unlock()
+}
+
+// GetVerificationStatus returns the state of local state roots verification.
+func (s *service) GetVerificationStatus() (VerificationStatus, error) {
+	if !s.MainCfg.Verification.Enabled {
+		return VerificationStatus{}, errVerificationDisabled
+	}
+	s.verMtx.RLock()
+	defer s.verMtx.RUnlock()
+	res := VerificationStatus{
+		Halted: s.halted,
+		Nodes:  make([]NodeStatus, len(s.trustedNodes)),
+	}
+	for i, n := range s.trustedNodes {
+		res.Nodes[i] = n.status
+		if n.status.Mismatch != nil {
+			m := *n.status.Mismatch
+			res.Nodes[i].Mismatch = &m
+		}
+	}
+	return res, nil
+}
+
+func (s *service) runVerification() {
+	t := time.NewTicker(s.verifyInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			for _, n := range s.trustedNodes {
+				s.verifyNode(n)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// verifyNode compares the latest common state root with the one of n and
+// searches for the first diverging height if they differ. Once the mismatch
+// is found the node is not checked anymore.
+func (s *service) verifyNode(n *trustedNode) {
+	s.verMtx.RLock()
+	st := n.status
+	verified := n.verified
+	s.verMtx.RUnlock()
+	if st.Mismatch != nil {
+		return
+	}
+
+	sh, err := n.fetcher.GetStateHeight()
+	if err != nil {
+		s.setNodeError(n, fmt.Errorf("can't get state height: %w", err))
+		return
+	}
+	h := sh.BlockHeight
+	if local := s.CurrentLocalHeight(); local < h {
+		h = local
+	}
+	if verified && h <= st.VerifiedHeight {
+		return
+	}
+	local, remote, err := s.getRoots(n, h)
+	if err != nil {
+		s.setNodeError(n, err)
+		return
+	}
+	if local.Equals(remote) {
+		s.verMtx.Lock()
+		n.verified = true
+		n.status.VerifiedHeight = h
+		n.status.LastError = nil
+		s.verMtx.Unlock()
+		setVerifiedHeightMetric(st.Address, h)
+		return
+	}
+
+	// State roots are cumulative, so once diverged they stay different and
+	// the first mismatch can be found with binary search.
+	var lo uint32
+	if verified {
+		lo = st.VerifiedHeight + 1
+	}
+	m := &Mismatch{Height: h, LocalRoot: local, RemoteRoot: remote}
+	for hi := h; lo < hi; {
+		mid := lo + (hi-lo)/2
+		local, remote, err = s.getRoots(n, mid)
+		if err != nil {
+			s.setNodeError(n, err)
+			return
+		}
+		if local.Equals(remote) {
+			lo = mid + 1
+		} else {
+			hi = mid
+			m = &Mismatch{Height: mid, LocalRoot: local, RemoteRoot: remote}
+		}
+	}
+	s.log.Error("local state root differs from the trusted node's one",
+		zap.String("node", st.Address),
+		zap.Uint32("height", m.Height),
+		zap.Stringer("local", m.LocalRoot),
+		zap.Stringer("remote", m.RemoteRoot))
+	setMismatchHeightMetric(st.Address, m.Height)
+
+	s.verMtx.Lock()
+	n.status.Mismatch = m
+	n.status.LastError = nil
+	halt := s.MainCfg.Verification.HaltOnMismatch && !s.halted && s.onHalt != nil
+	if halt {
+		s.halted = true
+	}
+	onHalt := s.onHalt
+	s.verMtx.Unlock()
+	if halt {
+		s.log.Error("halting block processing because of state mismatch")
+		onHalt()
+	}
+}
+
+// getRoots returns local and n's state roots at the specified height.
+func (s *service) getRoots(n *trustedNode, height uint32) (util.Uint256, util.Uint256, error) {
+	local, err := s.GetStateRoot(height)
+	if err != nil {
+		return util.Uint256{}, util.Uint256{}, fmt.Errorf("can't get local state root %d: %w", height, err)
+	}
+	remote, err := n.fetcher.GetStateRootByHeight(height)
+	if err != nil {
+		return util.Uint256{}, util.Uint256{}, fmt.Errorf("can't get state root %d: %w", height, err)
+	}
+	return local.Root, remote.Root, nil
+}
+
+func (s *service) setNodeError(n *trustedNode, err error) {
+	s.verMtx.Lock()
+	n.status.LastError = err
+	s.verMtx.Unlock()
+	addVerificationErrorsMetric(n.status.Address)
+	s.log.Warn("state root verification failed",
+		zap.String("node", n.status.Address),
+		zap.Error(err))
+}
@@ -0,0 +1,140 @@
+package stateroot
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/config"
+	"github.com/nspcc-dev/neo-go/pkg/core/blockchainer"
+	"github.com/nspcc-dev/neo-go/pkg/core/state"
+	"github.com/nspcc-dev/neo-go/pkg/rpc/response/result"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeRoots serves state roots which differ starting from diverged height
+// (if it's non-zero) depending on remote flag.
+type fakeRoots struct {
+	blockchainer.StateRoot
+	height   uint32
+	diverged uint32
+	remote   bool
+	err      error
+}
+
+func (f *fakeRoots) GetStateRoot(height uint32) (*state.MPTRoot, error) {
+	if height > f.height {
+		return nil, errors.New("unknown height")
+	}
+	r := &state.MPTRoot{Index: height}
+	r.Root[0] = byte(height)
+	if f.remote && f.diverged != 0 && height >= f.diverged {
+		r.Root[1] = 1
+	}
+	return r, nil
+}
+
+func (f *fakeRoots) CurrentLocalHeight() uint32 {
+	return f.height
+}
+
+func (f *fakeRoots) GetStateHeight() (*result.StateHeight, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &result.StateHeight{BlockHeight: f.height}, nil
+}
+
+func (f *fakeRoots) GetStateRootByHeight(height uint32) (*state.MPTRoot, error) {
+	return f.GetStateRoot(height)
+}
+
+func newTestVerifier(t *testing.T, local *fakeRoots, remote *fakeRoots, halt bool) (*service, *trustedNode) {
+	n := &trustedNode{
+		fetcher: remote,
+		status:  NodeStatus{Address: "http://localhost:10332"},
+	}
+	s := &service{
+		StateRoot: local,
+		MainCfg: config.StateRoot{
+			Verification: config.StateRootVerification{
+				Enabled:        true,
+				HaltOnMismatch: halt,
+			},
+		},
+		log:          zaptest.NewLogger(t),
+		trustedNodes: []*trustedNode{n},
+	}
+	return s, n
+}
+
+func TestVerifyNode(t *testing.T) {
+	t.Run("same roots", func(t *testing.T) {
+		local := &fakeRoots{height: 10}
+		s, n := newTestVerifier(t, local, &fakeRoots{height: 20, remote: true}, true)
+		s.verifyNode(n)
+
+		st, err := s.GetVerificationStatus()
+		require.NoError(t, err)
+		require.False(t, st.Halted)
+		require.Equal(t, 1, len(st.Nodes))
+		require.Equal(t, uint32(10), st.Nodes[0].VerifiedHeight)
+		require.Nil(t, st.Nodes[0].Mismatch)
+		require.NoError(t, st.Nodes[0].LastError)
+
+		local.height = 15
+		s.verifyNode(n)
+		st, err = s.GetVerificationStatus()
+		require.NoError(t, err)
+		require.Equal(t, uint32(15), st.Nodes[0].VerifiedHeight)
+	})
+	t.Run("node error", func(t *testing.T) {
+		s, n := newTestVerifier(t, &fakeRoots{height: 10}, &fakeRoots{height: 10, err: errors.New("timeout")}, true)
+		s.verifyNode(n)
+
+		st, err := s.GetVerificationStatus()
+		require.NoError(t, err)
+		require.Error(t, st.Nodes[0].LastError)
+		require.Nil(t, st.Nodes[0].Mismatch)
+	})
+	t.Run("mismatch", func(t *testing.T) {
+		for _, diverged := range []uint32{1, 7, 10} {
+			local := &fakeRoots{height: 5}
+			s, n := newTestVerifier(t, local, &fakeRoots{height: 20, diverged: diverged, remote: true}, true)
+			var halted bool
+			s.SetHaltCallback(func() { halted = true })
+			s.verifyNode(n)
+
+			local.height = 10
+			s.verifyNode(n)
+			st, err := s.GetVerificationStatus()
+			require.NoError(t, err)
+			require.True(t, st.Halted)
+			require.True(t, halted)
+			m := st.Nodes[0].Mismatch
+			require.NotNil(t, m)
+			require.Equal(t, diverged, m.Height)
+			require.NotEqual(t, m.LocalRoot, m.RemoteRoot)
+			require.Equal(t, byte(diverged), m.LocalRoot[0])
+		}
+	})
+	t.Run("mismatch without halt", func(t *testing.T) {
+		s, n := newTestVerifier(t, &fakeRoots{height: 10}, &fakeRoots{height: 10, diverged: 3, remote: true}, false)
+		var halted bool
+		s.SetHaltCallback(func() { halted = true })
+		s.verifyNode(n)
+
+		st, err := s.GetVerificationStatus()
+		require.NoError(t, err)
+		require.False(t, st.Halted)
+		require.False(t, halted)
+		require.Equal(t, util.Uint256{3, 1}, st.Nodes[0].Mismatch.RemoteRoot)
+	})
+	t.Run("disabled", func(t *testing.T) {
+		s, _ := newTestVerifier(t, &fakeRoots{}, &fakeRoots{}, false)
+		s.MainCfg.Verification.Enabled = false
+		_, err := s.GetVerificationStatus()
+		require.Error(t, err)
+	})
+}
@@ -140,7 +140,7 @@ func newChain() (*core.Blockchain, error) {
 	if err != nil {
 		return nil, err
 	}
-	chain, err := core.NewBlockchain(storage.NewMemoryStore(), unitTestNetCfg.ProtocolConfiguration, log)
+	chain, err := core.NewBlockchain(storage.NewMemoryStore(), unitTestNetCfg.ProtocolConfiguration, unitTestNetCfg.ApplicationConfiguration, log)
 	if err != nil {
 		return nil, err
 	}